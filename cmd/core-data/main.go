@@ -16,9 +16,10 @@ package main
 import (
 	"flag"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
-	"net/http"
 	"strconv"
 	"syscall"
 	"time"
@@ -85,13 +86,17 @@ func main() {
 
 	heartbeat.Start(configuration.HeartBeatMsg, configuration.HeartBeatTime, loggingClient)
 
-	errs := make(chan error, 2)
+	errs := make(chan error, 3)
 	listenForInterrupt(errs)
 	startHttpServer(errs, configuration.ServicePort)
+	startGrpcServer(errs, configuration.GRPCPort)
 
 	// Time it took to start service
 	loggingClient.Info("Service started in: "+time.Since(start).String(), "")
 	loggingClient.Info("Listening on port: " + strconv.Itoa(configuration.ServicePort))
+	if configuration.GRPCPort != 0 {
+		loggingClient.Info("Listening for gRPC on port: " + strconv.Itoa(configuration.GRPCPort))
+	}
 	c := <-errs
 	data.Destruct()
 	loggingClient.Warn(fmt.Sprintf("terminating: %v", c))
@@ -123,4 +128,18 @@ func startHttpServer(errChan chan error, port int) {
 		r := data.LoadRestRoutes()
 		errChan <- http.ListenAndServe(":"+strconv.Itoa(port), r)
 	}()
-}
\ No newline at end of file
+}
+
+func startGrpcServer(errChan chan error, port int) {
+	if port == 0 {
+		return
+	}
+	go func() {
+		l, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+		if err != nil {
+			errChan <- err
+			return
+		}
+		errChan <- data.NewGrpcServer().Serve(l)
+	}()
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/edgexfoundry/edgex-go/export/distro"
 	"github.com/edgexfoundry/edgex-go/internal"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/config"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/messagebus"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/usage"
 
 	"go.uber.org/zap"
@@ -31,7 +32,7 @@ func main() {
 	logger, _ = zap.NewProduction()
 	defer logger.Sync()
 
-	logger.Info("Starting " + internal.ExportDistroServiceKey, zap.String("version", edgex.Version))
+	logger.Info("Starting "+internal.ExportDistroServiceKey, zap.String("version", edgex.Version))
 
 	var (
 		useConsul  bool
@@ -79,7 +80,12 @@ func main() {
 	}()
 
 	// There can be another receivers that can be initialiced here
-	distro.ZeroMQReceiver(eventCh)
+	switch configuration.MessageBus {
+	case messagebus.NATS, messagebus.JetStream:
+		distro.MessageBusReceiver(eventCh)
+	default:
+		distro.ZeroMQReceiver(eventCh)
+	}
 
 	distro.Loop(errs, eventCh)
 
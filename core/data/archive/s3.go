@@ -0,0 +1,144 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package archive
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+)
+
+// Object name used when the configuration has none
+const DefaultObjectPattern = "coredata/{date}/events-{start}-{end}-{count}.ndjson.gz"
+
+var ErrInvalidS3Configuration = errors.New("The S3 archive needs an endpoint URL and a bucket")
+
+// Configuration struct for an S3 compatible archive, like AWS S3 or MinIO
+type S3Configuration struct {
+	Endpoint      string // URL of the service, e.g. http://minio:9000, the bucket is addressed in the path
+	Region        string // Region signed in the requests, MinIO accepts us-east-1
+	Bucket        string
+	AccessKey     string
+	SecretKey     string
+	ObjectPattern string // Name of the objects, {date} {time} {start} {end} and {count} are replaced
+	Timeout       int    // Timeout of an upload in milliseconds (0 - none)
+}
+
+// Returned when the service refuses an upload
+type S3Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e S3Error) Error() string {
+	return "S3 upload failed with status " + strconv.Itoa(e.StatusCode) + ": " + e.Message
+}
+
+// Archive the events as gzip compressed NDJSON objects in an S3 bucket, one object per batch
+type S3Archiver struct {
+	config S3Configuration
+	client *http.Client
+	now    func() time.Time
+}
+
+func NewS3Archiver(config S3Configuration) (*S3Archiver, error) {
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || endpoint.Host == "" || config.Bucket == "" {
+		return nil, ErrInvalidS3Configuration
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.ObjectPattern == "" {
+		config.ObjectPattern = DefaultObjectPattern
+	}
+
+	return &S3Archiver{
+		config: config,
+		client: &http.Client{Timeout: time.Duration(config.Timeout) * time.Millisecond},
+		now:    time.Now,
+	}, nil
+}
+
+// Upload the events as one object, nothing is uploaded for no events
+func (a *S3Archiver) ArchiveEvents(events []models.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	body, err := encodeNDJSON(events)
+	if err != nil {
+		return err
+	}
+
+	now := a.now()
+	objectURL := strings.TrimRight(a.config.Endpoint, "/") + "/" + url.PathEscape(a.config.Bucket) + "/" +
+		escapeObjectName(a.objectName(events, now))
+	req, err := http.NewRequest(http.MethodPut, objectURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/gzip")
+	signV4(req, sha256Hex(body), a.config.Region, a.config.AccessKey, a.config.SecretKey, now)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		message, _ := ioutil.ReadAll(resp.Body)
+		return S3Error{StatusCode: resp.StatusCode, Message: string(message)}
+	}
+	return nil
+}
+
+// Name the object of the events from the pattern
+// start and end are the oldest and newest creation times of the events
+func (a *S3Archiver) objectName(events []models.Event, now time.Time) string {
+	start, end := events[0].Created, events[0].Created
+	for _, e := range events {
+		if e.Created < start {
+			start = e.Created
+		}
+		if e.Created > end {
+			end = e.Created
+		}
+	}
+
+	return strings.NewReplacer(
+		"{date}", now.UTC().Format("2006-01-02"),
+		"{time}", now.UTC().Format("150405"),
+		"{start}", strconv.FormatInt(start, 10),
+		"{end}", strconv.FormatInt(end, 10),
+		"{count}", strconv.Itoa(len(events)),
+	).Replace(a.config.ObjectPattern)
+}
+
+// Escape each segment of the object name, keeping the slashes as prefixes
+func escapeObjectName(name string) string {
+	segments := strings.Split(name, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,113 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package archive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+)
+
+func TestNewS3ArchiverInvalid(t *testing.T) {
+	if _, err := NewS3Archiver(S3Configuration{Bucket: "archive"}); err != ErrInvalidS3Configuration {
+		t.Fatalf("An archive without endpoint should return ErrInvalidS3Configuration, got %v", err)
+	}
+	if _, err := NewS3Archiver(S3Configuration{Endpoint: "http://minio:9000"}); err != ErrInvalidS3Configuration {
+		t.Fatalf("An archive without bucket should return ErrInvalidS3Configuration, got %v", err)
+	}
+}
+
+func TestS3ArchiveEvents(t *testing.T) {
+	var method, path, auth, hash string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path, auth, hash = r.Method, r.URL.Path, r.Header.Get("Authorization"), r.Header.Get("X-Amz-Content-Sha256")
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	a, err := NewS3Archiver(S3Configuration{
+		Endpoint:      server.URL,
+		Bucket:        "archive",
+		AccessKey:     "access",
+		SecretKey:     "secret",
+		ObjectPattern: "coredata/{date}/{start}-{end}-{count}.ndjson.gz",
+	})
+	if err != nil {
+		t.Fatalf("Error creating the archiver: %v", err)
+	}
+	a.now = func() time.Time { return time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC) }
+
+	events := []models.Event{
+		{Device: "device1", Created: 200, Readings: []models.Reading{{Name: "temperature", Value: "1"}}},
+		{Device: "device2", Created: 100},
+	}
+	if err = a.ArchiveEvents(events); err != nil {
+		t.Fatalf("Error archiving the events: %v", err)
+	}
+
+	if method != http.MethodPut || path != "/archive/coredata/2018-06-01/100-200-2.ndjson.gz" {
+		t.Fatalf("The events should be put in the named object, not %s %s", method, path)
+	}
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=access/20180601/us-east-1/s3/aws4_request, ") {
+		t.Fatalf("The upload should be signed, Authorization is %q", auth)
+	}
+	if hash != sha256Hex(body) {
+		t.Fatalf("The signed payload hash doesn't match the body")
+	}
+
+	zr, err := gzip.NewReader(strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatalf("The object should be gzip compressed: %v", err)
+	}
+	var archived []models.Event
+	scanner := bufio.NewScanner(zr)
+	for scanner.Scan() {
+		var e models.Event
+		if err = json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("Each line should be an event: %v", err)
+		}
+		archived = append(archived, e)
+	}
+	if len(archived) != 2 || archived[0].Device != "device1" || len(archived[0].Readings) != 1 {
+		t.Fatalf("The events should be archived with their readings: %v", archived)
+	}
+}
+
+func TestS3ArchiveEventsRefused(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	a, _ := NewS3Archiver(S3Configuration{Endpoint: server.URL, Bucket: "archive"})
+	err := a.ArchiveEvents([]models.Event{{Device: "device"}})
+	if s3Err, ok := err.(S3Error); !ok || s3Err.StatusCode != http.StatusForbidden {
+		t.Fatalf("A refused upload should return an S3Error, got %v", err)
+	}
+}
+
+func TestUriEncodePath(t *testing.T) {
+	if encoded := uriEncodePath("/archive/a b+c/d~e.gz"); encoded != "/archive/a%20b%2Bc/d~e.gz" {
+		t.Fatalf("Only the unreserved characters and slashes should be kept, got %s", encoded)
+	}
+}
@@ -0,0 +1,295 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/cbor"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Return whether the request body is CBOR rather than JSON
+func isCBORBody(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == cbor.ContentType
+}
+
+// Return whether the client accepts CBOR in the response
+func acceptsCBOR(r *http.Request) bool {
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err == nil && mediaType == cbor.ContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// Decode the posted event, from CBOR when the Content-Type says so and from JSON otherwise
+func decodeEvent(r *http.Request, e *models.Event) error {
+	if !isCBORBody(r) {
+		return json.NewDecoder(r.Body).Decode(e)
+	}
+
+	v, err := readCBOR(r)
+	if err != nil {
+		return err
+	}
+	*e, err = eventFromCBOR(v)
+	return err
+}
+
+// Decode the posted list of events, from CBOR when the Content-Type says so and from JSON otherwise
+func decodeEvents(r *http.Request, events *[]models.Event) error {
+	if !isCBORBody(r) {
+		return json.NewDecoder(r.Body).Decode(events)
+	}
+
+	v, err := readCBOR(r)
+	if err != nil {
+		return err
+	}
+	items, ok := v.([]interface{})
+	if !ok {
+		return fmt.Errorf("cbor: expected a list of events, not %T", v)
+	}
+	*events = make([]models.Event, len(items))
+	for i, item := range items {
+		if (*events)[i], err = eventFromCBOR(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readCBOR(r *http.Request) (interface{}, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	return cbor.Unmarshal(body)
+}
+
+// Write the event or the list of events as CBOR when the client accepts it, and as JSON otherwise
+func encodeEvents(r *http.Request, v interface{}, w http.ResponseWriter) {
+	if !acceptsCBOR(r) {
+		encode(v, w)
+		return
+	}
+
+	var doc interface{}
+	switch v := v.(type) {
+	case models.Event:
+		doc = eventToCBOR(v)
+	case []models.Event:
+		list := make([]interface{}, len(v))
+		for i, e := range v {
+			list[i] = eventToCBOR(e)
+		}
+		doc = list
+	}
+
+	data, err := cbor.Marshal(doc)
+	if err != nil {
+		loggingClient.Error("Error encoding CBOR: " + err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", cbor.ContentType)
+	w.Write(data)
+}
+
+// Map the event to the generic CBOR values, with the keys of the JSON encoding
+// Unlike in JSON the empty strings are left out rather than null
+func eventToCBOR(e models.Event) map[string]interface{} {
+	readings := make([]interface{}, len(e.Readings))
+	for i, r := range e.Readings {
+		readings[i] = readingToCBOR(r)
+	}
+
+	m := map[string]interface{}{
+		"pushed":   e.Pushed,
+		"created":  e.Created,
+		"modified": e.Modified,
+		"origin":   e.Origin,
+		"readings": readings,
+	}
+	putString(m, "id", objectIdHex(e.ID))
+	putString(m, "device", e.Device)
+	putString(m, "schedule", e.Schedule)
+	putString(m, "event", e.Event)
+	return m
+}
+
+func readingToCBOR(r models.Reading) map[string]interface{} {
+	m := map[string]interface{}{
+		"pushed":   r.Pushed,
+		"created":  r.Created,
+		"origin":   r.Origin,
+		"modified": r.Modified,
+	}
+	putString(m, "id", objectIdHex(r.Id))
+	putString(m, "device", r.Device)
+	putString(m, "name", r.Name)
+	putString(m, "value", r.Value)
+	// A byte string, not the base64 text of the JSON encoding
+	if len(r.BinaryValue) > 0 {
+		m["binaryValue"] = r.BinaryValue
+	}
+	return m
+}
+
+func putString(m map[string]interface{}, key, value string) {
+	if value != "" {
+		m[key] = value
+	}
+}
+
+func objectIdHex(id bson.ObjectId) string {
+	if !id.Valid() {
+		return ""
+	}
+	return id.Hex()
+}
+
+// Read an event from the decoded CBOR document, the unknown keys are ignored like in JSON
+func eventFromCBOR(v interface{}) (models.Event, error) {
+	var e models.Event
+	f, err := newCBORFields(v, "event")
+	if err != nil {
+		return e, err
+	}
+
+	e.ID = f.objectId("id")
+	e.Pushed = f.int("pushed")
+	e.Device = f.string("device")
+	e.Created = f.int("created")
+	e.Modified = f.int("modified")
+	e.Origin = f.int("origin")
+	e.Schedule = f.string("schedule")
+	e.Event = f.string("event")
+	if f.err != nil {
+		return e, f.err
+	}
+
+	if readings, ok := f.m["readings"]; ok && readings != nil {
+		list, ok := readings.([]interface{})
+		if !ok {
+			return e, fmt.Errorf("cbor: event readings should be a list, not %T", readings)
+		}
+		for _, item := range list {
+			r, err := readingFromCBOR(item)
+			if err != nil {
+				return e, err
+			}
+			e.Readings = append(e.Readings, r)
+		}
+	}
+	return e, nil
+}
+
+func readingFromCBOR(v interface{}) (models.Reading, error) {
+	var r models.Reading
+	f, err := newCBORFields(v, "reading")
+	if err != nil {
+		return r, err
+	}
+
+	r.Id = f.objectId("id")
+	r.Pushed = f.int("pushed")
+	r.Created = f.int("created")
+	r.Origin = f.int("origin")
+	r.Modified = f.int("modified")
+	r.Device = f.string("device")
+	r.Name = f.string("name")
+	r.Value = f.string("value")
+	r.BinaryValue = f.bytes("binaryValue")
+	return r, f.err
+}
+
+// Typed access to the fields of a decoded CBOR map, the first type mismatch is kept in err
+type cborFields struct {
+	m    map[string]interface{}
+	kind string
+	err  error
+}
+
+func newCBORFields(v interface{}, kind string) (*cborFields, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cbor: %s should be a map, not %T", kind, v)
+	}
+	return &cborFields{m: m, kind: kind}, nil
+}
+
+func (f *cborFields) mismatch(key, expected string, v interface{}) {
+	if f.err == nil {
+		f.err = fmt.Errorf("cbor: %s %s should be %s, not %T", f.kind, key, expected, v)
+	}
+}
+
+func (f *cborFields) int(key string) int64 {
+	switch v := f.m[key].(type) {
+	case nil:
+		return 0
+	case int64:
+		return v
+	default:
+		f.mismatch(key, "an integer", v)
+		return 0
+	}
+}
+
+func (f *cborFields) string(key string) string {
+	switch v := f.m[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		f.mismatch(key, "a text string", v)
+		return ""
+	}
+}
+
+func (f *cborFields) bytes(key string) []byte {
+	switch v := f.m[key].(type) {
+	case nil:
+		return nil
+	case []byte:
+		return v
+	default:
+		f.mismatch(key, "a byte string", v)
+		return nil
+	}
+}
+
+func (f *cborFields) objectId(key string) bson.ObjectId {
+	id := f.string(key)
+	if id == "" {
+		return ""
+	}
+	if !bson.IsObjectIdHex(id) {
+		f.mismatch(key, "a hex object id", id)
+		return ""
+	}
+	return bson.ObjectIdHex(id)
+}
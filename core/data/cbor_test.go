@@ -0,0 +1,105 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/cbor"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestEventCBORRoundTrip(t *testing.T) {
+	e := models.Event{
+		ID:     bson.NewObjectId(),
+		Device: "camera",
+		Origin: 1471806386919,
+		Readings: []models.Reading{
+			{Id: bson.NewObjectId(), Name: "image", BinaryValue: []byte{0xff, 0xd8, 0xff, 0xe0}},
+			{Name: "temperature", Value: "38"},
+		},
+	}
+
+	data, err := cbor.Marshal(eventToCBOR(e))
+	if err != nil {
+		t.Fatalf("Error encoding the event: %v", err)
+	}
+	v, err := cbor.Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Error decoding the event: %v", err)
+	}
+	got, err := eventFromCBOR(v)
+	if err != nil {
+		t.Fatalf("Error reading the event: %v", err)
+	}
+
+	if got.ID != e.ID || got.Device != e.Device || got.Origin != e.Origin || len(got.Readings) != 2 {
+		t.Fatalf("Event mismatch, expected %v, received %v", e, got)
+	}
+	if got.Readings[0].Id != e.Readings[0].Id || !bytes.Equal(got.Readings[0].BinaryValue, e.Readings[0].BinaryValue) {
+		t.Errorf("Binary reading mismatch, expected %v, received %v", e.Readings[0], got.Readings[0])
+	}
+	if got.Readings[1].Value != "38" || got.Readings[1].BinaryValue != nil {
+		t.Errorf("Reading mismatch, expected %v, received %v", e.Readings[1], got.Readings[1])
+	}
+}
+
+func TestEventFromCBORInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  interface{}
+	}{
+		{"not a map", []interface{}{}},
+		{"text origin", map[string]interface{}{"origin": "123"}},
+		{"invalid id", map[string]interface{}{"id": "not an id"}},
+		{"readings not a list", map[string]interface{}{"readings": "none"}},
+		{"text binary value", map[string]interface{}{"readings": []interface{}{
+			map[string]interface{}{"binaryValue": "/9j/"},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := eventFromCBOR(tt.doc); err == nil {
+				t.Errorf("The document should be refused")
+			}
+		})
+	}
+}
+
+func TestAcceptsCBOR(t *testing.T) {
+	tests := []struct {
+		accept string
+		cbor   bool
+	}{
+		{"", false},
+		{"application/json", false},
+		{"application/cbor", true},
+		{"application/json;q=0.9, application/cbor", true},
+		{"Application/CBOR; q=1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			r, _ := http.NewRequest(http.MethodGet, "/api/v1/event", nil)
+			r.Header.Set("Accept", tt.accept)
+			if acceptsCBOR(r) != tt.cbor {
+				t.Errorf("acceptsCBOR should be %v", tt.cbor)
+			}
+		})
+	}
+}
@@ -14,6 +14,8 @@
 package clients
 
 import (
+	"fmt"
+
 	"github.com/edgexfoundry/edgex-go/core/domain/models"
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
@@ -24,6 +26,13 @@ type MongoEvent struct {
 	models.Event
 }
 
+// Struct that adds the back-reference to the event of a reading in mongo
+// The model doesn't carry it, so reading documents read back as models.Reading drop it
+type mongoReading struct {
+	models.Reading `bson:",inline"`
+	EventId        bson.ObjectId `bson:"eventId,omitempty"`
+}
+
 // Custom marshaling into mongo
 func (me MongoEvent) GetBSON() (interface{}, error) {
 	// Turn the readings into DBRef objects
@@ -71,7 +80,7 @@ func (me *MongoEvent) SetBSON(raw bson.Raw) error {
 
 	bsonErr := raw.Unmarshal(decoded)
 	if bsonErr != nil {
-		return bsonErr
+		return corruptEvent(raw, bsonErr)
 	}
 
 	// Copy over the non-DBRef fields
@@ -91,12 +100,24 @@ func (me *MongoEvent) SetBSON(raw bson.Raw) error {
 		return err
 	}
 
+	// Copy the current session, the one of mc.Database is closed by Reconnect
+	s := mc.getSessionCopy()
+	defer s.Close()
+
 	var readings []models.Reading
 
 	// Get all of the reading objects
 	for _, rRef := range decoded.Readings {
 		var reading models.Reading
-		err := mc.Database.C(READINGS_COLLECTION).FindId(rRef.Id).One(&reading)
+		err := s.DB(mc.Database.Name).C(READINGS_COLLECTION).FindId(rRef.Id).One(&reading)
+		if err == mgo.ErrNotFound {
+			// The capped readings collection evicted it, the event keeps the readings left
+			if mc.config.CappedReadings {
+				continue
+			}
+			// Not the event missing, it references a reading that was removed
+			return ErrCorruptEvent{Id: decoded.ID.Hex(), Err: fmt.Errorf("reading %v doesn't exist", rRef.Id)}
+		}
 		if err != nil {
 			return err
 		}
@@ -108,3 +129,17 @@ func (me *MongoEvent) SetBSON(raw bson.Raw) error {
 
 	return nil
 }
+
+// Wrap the decode error of an event document with the id of the document when it can be read
+func corruptEvent(raw bson.Raw, err error) error {
+	var doc struct {
+		ID interface{} `bson:"_id"`
+	}
+	if raw.Unmarshal(&doc) != nil {
+		return ErrCorruptEvent{Err: err}
+	}
+	if id, ok := doc.ID.(bson.ObjectId); ok {
+		return ErrCorruptEvent{Id: id.Hex(), Err: err}
+	}
+	return ErrCorruptEvent{Id: fmt.Sprint(doc.ID), Err: err}
+}
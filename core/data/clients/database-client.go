@@ -14,7 +14,12 @@
 package clients
 
 import (
+	"encoding/base64"
 	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/edgexfoundry/edgex-go/core/domain/models"
 	"github.com/edgexfoundry/edgex-go/support/logging-client"
@@ -26,8 +31,19 @@ const (
 	MONGO DatabaseType = iota
 	INFLUX
 	MEMORY
+	REDIS
 )
 
+// Names the drivers of the database types are registered under
+var databaseArr = [...]string{"mongodb", "influxdb", "memorydb", "redisdb"}
+
+func (db DatabaseType) String() string {
+	if db >= MONGO && db <= REDIS {
+		return databaseArr[db]
+	}
+	return "invalid"
+}
+
 type DBClient interface {
 	CloseSession()
 
@@ -39,20 +55,32 @@ type DBClient interface {
 	// Add a new event
 	// UnexpectedError - failed to add to database
 	// NoValueDescriptor - no existing value descriptor for a reading in the event
+	// ErrNilEvent - the event is nil
+	// ErrEventTooLarge - the event has more readings than configured or is too big to store
 	AddEvent(e *models.Event) (bson.ObjectId, error)
 
+	// Add a batch of events, giving them and their readings their ids and timestamps in place
+	// One bad event doesn't fail the others, the results are in the order of the events and report the ID
+	// or the error of each one
+	// When the whole batch fails, like on a lost connection, the error is returned too and the events
+	// that weren't added carry it in their result
+	AddEvents(events []models.Event) ([]AddEventResult, error)
+
 	// Update an event - do NOT update readings
 	// UnexpectedError - problem updating in database
 	// NotFound - no event with the ID was found
 	UpdateEvent(e models.Event) error
 
 	// Get an event by id
+	// ErrNotFound - no event with the id was found
+	// ErrCorruptEvent - the event can't be decoded or references a reading that doesn't exist
 	EventById(id string) (models.Event, error)
 
 	// Get the number of events in Core Data
 	EventCount() (int, error)
 
 	// Get the number of events in Core Data for the device specified by id
+	// ErrEmptyDeviceId - the device id is empty
 	EventCountByDeviceId(id string) (int, error)
 
 	// Update an event by ID
@@ -67,9 +95,11 @@ type DBClient interface {
 	DeleteEventById(id string) error
 
 	// Get a list of events based on the device id and limit
+	// ErrEmptyDeviceId - the device id is empty
 	EventsForDeviceLimit(id string, limit int) ([]models.Event, error)
 
 	// Get a list of events based on the device id
+	// ErrEmptyDeviceId - the device id is empty
 	EventsForDevice(id string) ([]models.Event, error)
 
 	// Delete all of the events by the device id (and the readings)
@@ -77,10 +107,12 @@ type DBClient interface {
 
 	// Return a list of events whos creation time is between startTime and endTime
 	// Limit the number of results by limit
+	// InvalidTimeRange - a time is negative or startTime is after endTime
 	EventsByCreationTime(startTime, endTime int64, limit int) ([]models.Event, error)
 
 	// Return a list of readings for a device filtered by the value descriptor and limited by the limit
 	// The readings are linked to the device through an event
+	// ErrEmptyDeviceId - the device id is empty
 	ReadingsByDeviceAndValueDescriptor(deviceId, valueDescriptor string, limit int) ([]models.Reading, error)
 
 	// Remove all the events that are older than the given age
@@ -96,7 +128,25 @@ type DBClient interface {
 	// Get events that have been pushed (pushed field is not 0)
 	EventsPushed() ([]models.Event, error)
 
+	// Delete at most limit of the events that have been pushed, along with their readings
+	// Return the number of events removed, fewer than the limit once there are none left
+	// ErrInvalidLimit - the limit is negative
+	DeleteEventsPushed(limit int) (int, error)
+
+	// Return a page of the events oldest first, skipping the first offset of them
+	// ErrInvalidPage - the offset is negative
+	// ErrInvalidLimit - the limit is negative
+	EventsPagedAscending(offset, limit int) ([]models.Event, error)
+
+	// Return at most limit events oldest first, following the cursor, and the cursor of the next page
+	// An empty cursor starts from the oldest event, an empty next cursor means there are no more events
+	// Unlike the offset the cursor doesn't shift when events are added or removed meanwhile
+	// ErrInvalidCursor - the cursor wasn't returned by this database
+	// ErrInvalidLimit - the limit is negative
+	EventsAfterCursor(cursor string, limit int) ([]models.Event, string, error)
+
 	// Delete all readings and events
+	// ErrScrubNotAllowed - the configuration doesn't allow scrubbing the database
 	ScrubAllEvents() error
 
 	// ********************* READING FUNCTIONS *************************
@@ -105,6 +155,7 @@ type DBClient interface {
 
 	// Post a new reading
 	// Check if valuedescriptor exists in the database
+	// ErrInvalidReading - the reading has no name
 	AddReading(r models.Reading) (bson.ObjectId, error)
 
 	// Update a reading
@@ -126,6 +177,7 @@ type DBClient interface {
 	// Return a list of readings for the given device (id or name)
 	// 404 - meta data checking enabled and can't find the device
 	// Sort the list of readings on creation date
+	// ErrEmptyDeviceId - the device id is empty
 	ReadingsByDevice(id string, limit int) ([]models.Reading, error)
 
 	// Return a list of readings for the given value descriptor
@@ -133,6 +185,7 @@ type DBClient interface {
 	ReadingsByValueDescriptor(name string, limit int) ([]models.Reading, error)
 
 	// Return a list of readings whose name is in the list of value descriptor names
+	// An empty list of names returns an empty list of readings
 	ReadingsByValueDescriptorNames(names []string, limit int) ([]models.Reading, error)
 
 	// Return a list of readings specified by the UOM label
@@ -146,8 +199,20 @@ type DBClient interface {
 	//ReadingsByType(typeString string, limit int) ([]models.Reading, error)
 
 	// Return a list of readings whos created time is between the start and end times
+	// InvalidTimeRange - a time is negative or start is after end
 	ReadingsByCreationTime(start, end int64, limit int) ([]models.Reading, error)
 
+	// Return a page of the readings oldest first, skipping the first offset of them
+	// ErrInvalidPage - the offset is negative
+	// ErrInvalidLimit - the limit is negative
+	ReadingsPagedAscending(offset, limit int) ([]models.Reading, error)
+
+	// Return at most limit readings oldest first, following the cursor, and the cursor of the next page
+	// The cursor works like the one of EventsAfterCursor
+	// ErrInvalidCursor - the cursor wasn't returned by this database
+	// ErrInvalidLimit - the limit is negative
+	ReadingsAfterCursor(cursor string, limit int) ([]models.Reading, string, error)
+
 	// ************************** VALUE DESCRIPTOR FUNCTIONS ***************************
 	// Add a value descriptor
 	// 409 - Formatting is bad or it is not unique
@@ -172,10 +237,15 @@ type DBClient interface {
 	ValueDescriptorByName(name string) (models.ValueDescriptor, error)
 
 	// Return value descriptors based on the names
+	// An empty list of names returns an empty list of value descriptors
 	ValueDescriptorsByName(names []string) ([]models.ValueDescriptor, error)
 
 	// Delete a valuedescriptor based on the name
-	//DeleteValueDescriptorByName(name string) error
+	// ErrNotFound - no value descriptor has the name
+	// ErrValueDescriptorInUse - readings still use the name
+	// The value descriptor is looked up before the readings are checked, the check and the delete
+	// aren't atomic so a reading added in between may be left without its value descriptor
+	DeleteValueDescriptorByName(name string) error
 
 	// Return a value descriptor based on the id
 	ValueDescriptorById(id string) (models.ValueDescriptor, error)
@@ -190,50 +260,268 @@ type DBClient interface {
 	ValueDescriptorsByType(t string) ([]models.ValueDescriptor, error)
 
 	// Delete all value descriptors
+	// ErrScrubNotAllowed - the configuration doesn't allow scrubbing the database
 	ScrubAllValueDescriptors() error
 }
 
 type DBConfiguration struct {
-	DbType       DatabaseType
-	Host         string
-	Port         int
-	Timeout      int
-	DatabaseName string
-	Username     string
-	Password     string
+	DbType          DatabaseType
+	Driver          string // Name of the registered driver creating the client, the one of DbType when empty
+	Host            string
+	Port            int
+	Hosts           []string // Seed list of host:port addresses of a replica set, replaces Host and Port when set
+	ReplicaSet      string   // Name of the replica set, the servers of other sets are ignored
+	ReadPreference  string   // Members the sessions read from, one of the Read* modes (empty - primary)
+	Timeout         int
+	DatabaseName    string
+	Username        string
+	Password        string
+	BatchDeleteSize int    // Max number of documents removed per bulk delete round trip
+	AppName         string // Name identifying the client's connections on the database side
+	SocketKeepAlive int    // TCP keepalive period of the connections in milliseconds (0 - driver default)
+	MaxIdleTime     int    // Ping the database after this many idle milliseconds (0 - disabled)
+	QueryTimeout    int    // Socket timeout of the lookups in milliseconds (0 - connection timeout)
+	ScanTimeout     int    // Socket timeout of the aggregations, scans and bulk deletes in milliseconds (0 - connection timeout)
+	CappedReadings  bool   // Create the readings collection capped, the oldest readings are evicted once a bound is reached
+	ReadingsCapSize int    // Size bound of the capped readings collection in bytes, required when capped
+	ReadingsCapDocs int    // Document bound of the capped readings collection (0 - size bound only)
+	SlowQuery       int    // Log the queries running longer than this many milliseconds (0 - disabled)
+	CacheVDs        bool   // Cache the value descriptors found by name
+	VDCacheTTL      int    // How long a value descriptor stays cached in milliseconds
+	AllowScrub      bool   // Allow ScrubAllEvents and ScrubAllValueDescriptors to wipe the database
+	MaxReadings     int    // Reject the events with more readings than this (0 - no limit)
+	CountsSecondary bool   // Read the counts from a secondary when there is one, they can lag behind the writes
+	Compression     bool   // Compress the traffic with the database when the driver can negotiate it
+	TrackSessions   bool   // Warn about the session copies collected without being closed, for development
+	LeakTimeout     int    // Warn about the tracked session copies open longer than this many milliseconds (0 - disabled)
+	SkipIndexes     bool   // Don't create the indexes used by the queries when connecting
+	TLS             bool   // Connect to the database over TLS
+	TLSCAFile       string // PEM file of the CAs verifying the server (empty - system roots)
+	TLSCertFile     string // PEM file of the client certificate, along with TLSKeyFile
+	TLSKeyFile      string // PEM file of the client certificate's private key
+	TLSSkipVerify   bool   // Don't verify the server certificate, for testing only
 }
 
 var ErrNotFound error = errors.New("Item not found")
 var ErrUnsupportedDatabase error = errors.New("Unsuppored database type")
 var ErrInvalidObjectId error = errors.New("Invalid object ID")
 var ErrNotUnique error = errors.New("Resource already exists")
+var ErrInvalidTimeRange error = errors.New("Invalid time range")
+var ErrInvalidPage error = errors.New("Invalid page")
+var ErrEmptyDeviceId error = errors.New("Empty device id")
+var ErrQueryTimeout error = errors.New("Query timed out")
+var ErrValueDescriptorInUse error = errors.New("Value descriptor still referenced by readings")
+var ErrNilEvent error = errors.New("Event is nil")
+var ErrInvalidReading error = errors.New("Reading has no value descriptor name")
+var ErrInvalidCapSize error = errors.New("Capped readings need a positive size")
+var ErrInvalidSortField error = errors.New("Invalid sort field")
+var ErrScrubNotAllowed error = errors.New("Scrubbing the database is not allowed by the configuration")
+var ErrUnsupportedConversion error = errors.New("Unsupported unit conversion")
+var ErrDeleteLimitExceeded error = errors.New("More items match than the delete limit allows")
+var ErrInvalidProjection error = errors.New("Invalid projection fields")
+var ErrEventTooLarge error = errors.New("Event is too large")
+var ErrInvalidLimit error = errors.New("Invalid limit")
+var ErrInvalidCursor error = errors.New("Invalid cursor")
+var ErrInvalidTLSConfig error = errors.New("Invalid TLS configuration")
+var ErrInvalidReadPreference error = errors.New("Invalid read preference")
+
+// Result of adding one event of a batch with AddEvents
+type AddEventResult struct {
+	Index int           // Position of the event in the batch
+	ID    bson.ObjectId // ID of the added event, empty when Err is set
+	Err   error         // Why the event wasn't added
+}
+
+// Give the error to the events of the batch that don't have a result yet
+func failPending(results []AddEventResult, err error) []AddEventResult {
+	for i := range results {
+		if results[i].ID == "" && results[i].Err == nil {
+			results[i].Err = err
+		}
+	}
+	return results
+}
+
+// Add the events of a batch one at a time, for the databases without a batch insert
+func addEachEvent(add func(e *models.Event) (bson.ObjectId, error), events []models.Event) []AddEventResult {
+	results := make([]AddEventResult, len(events))
+	for i := range events {
+		results[i].Index = i
+		results[i].ID, results[i].Err = add(&events[i])
+		if results[i].Err != nil {
+			results[i].ID = ""
+		}
+	}
+	return results
+}
+
+// Returned when an event document can't be decoded or references a reading that doesn't exist
+// Unlike ErrNotFound the event is there, Id identifies the document to repair
+type ErrCorruptEvent struct {
+	Id  string
+	Err error
+}
+
+func (e ErrCorruptEvent) Error() string {
+	return "Corrupt event " + e.Id + ": " + e.Err.Error()
+}
+
+// Returned by the batch deletes for the value descriptors kept because readings still reference them
+type ErrValueDescriptorsInUse struct {
+	Ids []string
+}
+
+func (e ErrValueDescriptorsInUse) Error() string {
+	return "Value descriptors still referenced by readings: " + strings.Join(e.Ids, ", ")
+}
+
 var DataClient = "dataClient"
 var loggingClient = logger.NewClient(DataClient, false, "")
 
-// Return the dbClient interface
+// Check that the times are not negative and that start is not after end
+func validateTimeRange(start, end int64) error {
+	if start < 0 || end < 0 || start > end {
+		return ErrInvalidTimeRange
+	}
+	return nil
+}
+
+// Reject the negative limit, mgo would quietly return at most its absolute value in a single batch
+func validateLimit(limit int) error {
+	if limit < 0 {
+		return ErrInvalidLimit
+	}
+	return nil
+}
+
+// Check the offset and the limit of a page
+func validatePage(offset, limit int) error {
+	if offset < 0 {
+		return ErrInvalidPage
+	}
+	return validateLimit(limit)
+}
+
+// Position of a cursor, the pages are sorted on the created time and then on the id
+type cursorPosition struct {
+	created int64
+	id      bson.ObjectId
+}
+
+// Whether the position precedes the item created at the time with the id
+func (p cursorPosition) precedes(created int64, id bson.ObjectId) bool {
+	return created > p.created || (created == p.created && id > p.id)
+}
+
+// Return the opaque cursor of the next page, following the last item of a page
+func encodeCursor(created int64, id bson.ObjectId) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(created, 10) + ":" + id.Hex()))
+}
+
+// Return the cursor following a page of count items that ends with the item created at the time with the id
+// A short page is the last one, so there is no next cursor
+func nextCursor(count, limit int, created int64, id bson.ObjectId) string {
+	if count < limit {
+		return ""
+	}
+	return encodeCursor(created, id)
+}
+
+// Return the position of a cursor, the zero position for the empty cursor
+// ErrInvalidCursor - the cursor wasn't returned by encodeCursor
+func decodeCursor(cursor string) (cursorPosition, error) {
+	if cursor == "" {
+		return cursorPosition{created: -1}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return cursorPosition{}, ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 || !bson.IsObjectIdHex(parts[1]) {
+		return cursorPosition{}, ErrInvalidCursor
+	}
+	created, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return cursorPosition{}, ErrInvalidCursor
+	}
+	return cursorPosition{created: created, id: bson.ObjectIdHex(parts[1])}, nil
+}
+
+// Reject the reading that can't be tied to a value descriptor
+func validateReading(r models.Reading) error {
+	if r.Name == "" {
+		return ErrInvalidReading
+	}
+	return nil
+}
+
+// Reject the empty device id, it would match the events and readings without a device
+func validateDeviceId(id string) error {
+	if id == "" {
+		return ErrEmptyDeviceId
+	}
+	return nil
+}
+
+// Function creating the client of a database from the configuration
+type DBClientFactory func(config DBConfiguration) (DBClient, error)
+
+var dbClientFactories = struct {
+	sync.RWMutex
+	m map[string]DBClientFactory
+}{m: make(map[string]DBClientFactory)}
+
+// Make a database driver available to NewDBClient under the name
+// The drivers register from an init function, like the ones of this package do
+// Panics when the factory is nil or a driver already has the name
+func RegisterDBClient(name string, factory func(DBConfiguration) (DBClient, error)) {
+	if factory == nil {
+		panic("clients: RegisterDBClient factory is nil for " + name)
+	}
+
+	dbClientFactories.Lock()
+	defer dbClientFactories.Unlock()
+	if _, dup := dbClientFactories.m[name]; dup {
+		panic("clients: RegisterDBClient called twice for " + name)
+	}
+	dbClientFactories.m[name] = factory
+}
+
+// Return the names of the registered database drivers, sorted
+func RegisteredDBClients() []string {
+	dbClientFactories.RLock()
+	defer dbClientFactories.RUnlock()
+
+	names := make([]string, 0, len(dbClientFactories.m))
+	for name := range dbClientFactories.m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Return the dbClient interface created by the driver of the configuration
+// The driver is config.Driver when set, otherwise the one of config.DbType
+// ErrUnsupportedDatabase - no driver is registered under the name
 func NewDBClient(config DBConfiguration) (DBClient, error) {
-	switch config.DbType {
-	case MONGO:
-		// Create the mongo client
-		mc, err := newMongoClient(config)
-		if err != nil {
-			loggingClient.Error("Error creating the mongo client: " + err.Error())
-			return nil, err
-		}
-		return mc, nil
-	case INFLUX:
-		// Create the influx client
-		ic, err := newInfluxClient(config)
-		if err != nil {
-			loggingClient.Error("Error creating the influx client: " + err.Error())
-			return nil, err
-		}
-		return ic, nil
-	case MEMORY:
-		// Create the memory client
-		mem := &memDB{}
-		return mem, nil
-	default:
+	name := config.Driver
+	if name == "" {
+		name = config.DbType.String()
+	}
+
+	dbClientFactories.RLock()
+	factory, ok := dbClientFactories.m[name]
+	dbClientFactories.RUnlock()
+	if !ok {
 		return nil, ErrUnsupportedDatabase
 	}
+
+	db, err := factory(config)
+	if err != nil {
+		loggingClient.Error("Error creating the " + name + " client: " + err.Error())
+		return nil, err
+	}
+	return db, nil
 }
@@ -0,0 +1,83 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package clients
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRegisterDBClient(t *testing.T) {
+	var got DBConfiguration
+	RegisterDBClient("test-registry", func(config DBConfiguration) (DBClient, error) {
+		got = config
+		return &memDB{}, nil
+	})
+
+	db, err := NewDBClient(DBConfiguration{Driver: "test-registry", DatabaseName: "coredata"})
+	if err != nil {
+		t.Fatalf("Error creating the registered client: %v", err)
+	}
+	if _, ok := db.(*memDB); !ok || got.DatabaseName != "coredata" {
+		t.Fatalf("The registered factory should create the client from the configuration: %T %v", db, got)
+	}
+
+	found := false
+	for _, name := range RegisteredDBClients() {
+		found = found || name == "test-registry"
+	}
+	if !found {
+		t.Fatalf("The registered driver should be listed: %v", RegisteredDBClients())
+	}
+}
+
+func TestRegisterDBClientTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Registering a name twice should panic")
+		}
+	}()
+	RegisterDBClient(MEMORY.String(), func(config DBConfiguration) (DBClient, error) {
+		return &memDB{}, nil
+	})
+}
+
+func TestNewDBClientByType(t *testing.T) {
+	db, err := NewDBClient(DBConfiguration{DbType: MEMORY})
+	if err != nil {
+		t.Fatalf("Error creating the memory client: %v", err)
+	}
+	if _, ok := db.(*memDB); !ok {
+		t.Fatalf("The memory type should create a memory client, not %T", db)
+	}
+}
+
+func TestNewDBClientUnknownDriver(t *testing.T) {
+	_, err := NewDBClient(DBConfiguration{Driver: "unknown"})
+	if err != ErrUnsupportedDatabase {
+		t.Fatalf("Expected ErrUnsupportedDatabase, got %v", err)
+	}
+}
+
+func TestNewDBClientFactoryError(t *testing.T) {
+	factoryErr := errors.New("unreachable")
+	RegisterDBClient("test-failing", func(config DBConfiguration) (DBClient, error) {
+		return nil, factoryErr
+	})
+
+	db, err := NewDBClient(DBConfiguration{Driver: "test-failing"})
+	if err != factoryErr || db != nil {
+		t.Fatalf("The factory error should be returned without a client: %v %v", db, err)
+	}
+}
@@ -15,6 +15,7 @@
 package clients
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"strconv"
@@ -34,8 +35,20 @@ Has functions for interacting with the core data influxdb
 */
 
 type InfluxClient struct {
-	Client   client.Client // Influxdb client
-	Database string        // Influxdb database name
+	Client     client.Client // Influxdb client
+	Database   string        // Influxdb database name
+	allowScrub bool
+}
+
+func init() {
+	RegisterDBClient(INFLUX.String(), func(config DBConfiguration) (DBClient, error) {
+		// Keep the interface nil on errors, not holding a nil *InfluxClient
+		ic, err := newInfluxClient(config)
+		if err != nil {
+			return nil, err
+		}
+		return ic, nil
+	})
 }
 
 // Return a pointer to the InfluxClient
@@ -53,7 +66,7 @@ func newInfluxClient(config DBConfiguration) (*InfluxClient, error) {
 		return nil, err
 	}
 
-	influxClient := &InfluxClient{Client: c, Database: config.DatabaseName}
+	influxClient := &InfluxClient{Client: c, Database: config.DatabaseName, allowScrub: config.AllowScrub}
 	currentInfluxClient = influxClient // Set the singleton
 	return influxClient, nil
 }
@@ -111,6 +124,10 @@ func (ic *InfluxClient) Events() ([]models.Event, error) {
 // UnexpectedError - failed to add to database
 // NoValueDescriptor - no existing value descriptor for a reading in the event
 func (ic *InfluxClient) AddEvent(e *models.Event) (bson.ObjectId, error) {
+	if e == nil {
+		return "", ErrNilEvent
+	}
+
 	e.Created = time.Now().UnixNano() / int64(time.Millisecond)
 	e.ID = bson.NewObjectId()
 
@@ -123,6 +140,11 @@ func (ic *InfluxClient) AddEvent(e *models.Event) (bson.ObjectId, error) {
 	return e.ID, err
 }
 
+// Add a batch of events, one write per event
+func (ic *InfluxClient) AddEvents(events []models.Event) ([]AddEventResult, error) {
+	return addEachEvent(ic.AddEvent, events), nil
+}
+
 // Update an event - do NOT update readings
 // UnexpectedError - problem updating in database
 // NotFound - no event with the ID was found
@@ -159,6 +181,10 @@ func (ic *InfluxClient) EventCount() (int, error) {
 
 // Get the number of events in Influx for the device
 func (ic *InfluxClient) EventCountByDeviceId(id string) (int, error) {
+	if err := validateDeviceId(id); err != nil {
+		return 0, err
+	}
+
 	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE device = '%s'", EVENTS_COLLECTION, id)
 	return ic.getCount(query)
 }
@@ -172,12 +198,20 @@ func (ic *InfluxClient) DeleteEventById(id string) error {
 
 // Get a list of events based on the device id and limit
 func (ic *InfluxClient) EventsForDeviceLimit(id string, limit int) ([]models.Event, error) {
+	if err := validateDeviceId(id); err != nil {
+		return []models.Event{}, err
+	}
+
 	query := fmt.Sprintf("WHERE device = '%s' LIMIT %d", id, limit)
 	return ic.getEvents(query)
 }
 
 // Get a list of events based on the device id
 func (ic *InfluxClient) EventsForDevice(id string) ([]models.Event, error) {
+	if err := validateDeviceId(id); err != nil {
+		return []models.Event{}, err
+	}
+
 	query := fmt.Sprintf("WHERE device = '%s'", id)
 	return ic.getEvents(query)
 }
@@ -185,6 +219,10 @@ func (ic *InfluxClient) EventsForDevice(id string) ([]models.Event, error) {
 // Return a list of events whos creation time is between startTime and endTime
 // Limit the number of results by limit
 func (ic *InfluxClient) EventsByCreationTime(startTime, endTime int64, limit int) ([]models.Event, error) {
+	if err := validateTimeRange(startTime, endTime); err != nil {
+		return []models.Event{}, err
+	}
+
 	query := fmt.Sprintf("WHERE created >= %d AND created <= %d LIMIT %d", startTime, endTime, limit)
 	return ic.getEvents(query)
 }
@@ -202,8 +240,65 @@ func (ic *InfluxClient) EventsPushed() ([]models.Event, error) {
 	return ic.getEvents(query)
 }
 
+// Delete at most limit of the pushed events along with their readings, oldest first
+func (ic *InfluxClient) DeleteEventsPushed(limit int) (int, error) {
+	if err := validateLimit(limit); err != nil {
+		return 0, err
+	}
+	if limit == 0 {
+		return 0, nil
+	}
+
+	events, err := ic.getEvents(fmt.Sprintf("WHERE pushed > 0 ORDER BY time LIMIT %d", limit))
+	if err != nil {
+		return 0, err
+	}
+	for i, e := range events {
+		for _, r := range e.Readings {
+			if err = ic.deleteById(READINGS_COLLECTION, r.Id.Hex()); err != nil {
+				return i, err
+			}
+		}
+		if err = ic.deleteById(EVENTS_COLLECTION, e.ID.Hex()); err != nil {
+			return i, err
+		}
+	}
+	return len(events), nil
+}
+
 // Delete all of the readings and all of the events
+func (ic *InfluxClient) EventsPagedAscending(offset, limit int) ([]models.Event, error) {
+	if err := validatePage(offset, limit); err != nil || limit == 0 {
+		return []models.Event{}, err
+	}
+
+	query := fmt.Sprintf("ORDER BY time LIMIT %d OFFSET %d", limit, offset)
+	return ic.getEvents(query)
+}
+
+// Influx can't compare the id tags, so the cursor holds the offset of the next page
+// It only shifts when older events are removed meanwhile
+func (ic *InfluxClient) EventsAfterCursor(cursor string, limit int) ([]models.Event, string, error) {
+	offset, err := decodeOffsetCursor(cursor)
+	if err != nil {
+		return []models.Event{}, cursor, err
+	}
+	if err = validateLimit(limit); err != nil || limit == 0 {
+		return []models.Event{}, cursor, err
+	}
+
+	events, err := ic.EventsPagedAscending(offset, limit)
+	if err != nil || len(events) < limit {
+		return events, "", err
+	}
+	return events, encodeOffsetCursor(offset + limit), nil
+}
+
 func (ic *InfluxClient) ScrubAllEvents() error {
+	if !ic.allowScrub {
+		return ErrScrubNotAllowed
+	}
+
 	err := ic.deleteAll(READINGS_COLLECTION)
 	if err != nil {
 		return err
@@ -273,6 +368,7 @@ func (ic *InfluxClient) eventToDB(db string, collection string, e *models.Event,
 				"origin":   e.Readings[i].Origin,
 				"modified": e.Readings[i].Modified,
 			}
+			putBinaryValue(fields, e.Readings[i].BinaryValue)
 
 			tags := map[string]string{
 				"id":     e.Readings[i].Id.Hex(),
@@ -324,6 +420,28 @@ func (ic *InfluxClient) eventToDB(db string, collection string, e *models.Event,
 	return ic.Client.Write(bp)
 }
 
+func encodeOffsetCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+// Return the offset of a cursor, 0 for the empty cursor
+// ErrInvalidCursor - the cursor wasn't returned by encodeOffsetCursor
+func decodeOffsetCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), "offset:") {
+		return 0, ErrInvalidCursor
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(raw), "offset:"))
+	if err != nil || offset < 0 {
+		return 0, ErrInvalidCursor
+	}
+	return offset, nil
+}
+
 func parseEvents(res client.Result) ([]models.Event, error) {
 	var events []models.Event
 	for i, _ := range res.Series[0].Values {
@@ -398,6 +516,10 @@ func (ic *InfluxClient) Readings() ([]models.Reading, error) {
 
 // Post a new reading
 func (ic *InfluxClient) AddReading(r models.Reading) (bson.ObjectId, error) {
+	if err := validateReading(r); err != nil {
+		return "", err
+	}
+
 	// Get the reading ready
 	r.Id = bson.NewObjectId()
 	r.Created = time.Now().UnixNano() / int64(time.Millisecond)
@@ -448,6 +570,10 @@ func (ic *InfluxClient) DeleteReadingById(id string) error {
 // Return a list of readings for the given device (id or name)
 // Sort the list of readings on creation date
 func (ic *InfluxClient) ReadingsByDevice(id string, limit int) ([]models.Reading, error) {
+	if err := validateDeviceId(id); err != nil {
+		return []models.Reading{}, err
+	}
+
 	query := fmt.Sprintf("WHERE device = '%s' LIMIT %d", id, limit)
 	return ic.getReadings(query)
 }
@@ -476,13 +602,47 @@ func (ic *InfluxClient) ReadingsByValueDescriptorNames(names []string, limit int
 // Return a list of readings whos creation time is in-between start and end
 // Limit by the limit parameter
 func (ic *InfluxClient) ReadingsByCreationTime(start, end int64, limit int) ([]models.Reading, error) {
+	if err := validateTimeRange(start, end); err != nil {
+		return []models.Reading{}, err
+	}
+
 	query := fmt.Sprintf("WHERE created >= %d AND created <= %d LIMIT %d", start, end, limit)
 	return ic.getReadings(query)
 }
 
 // Return a list of readings for a device filtered by the value descriptor and limited by the limit
 // The readings are linked to the device through an event
+func (ic *InfluxClient) ReadingsPagedAscending(offset, limit int) ([]models.Reading, error) {
+	if err := validatePage(offset, limit); err != nil || limit == 0 {
+		return []models.Reading{}, err
+	}
+
+	query := fmt.Sprintf("ORDER BY time LIMIT %d OFFSET %d", limit, offset)
+	return ic.getReadings(query)
+}
+
+// The cursor holds the offset of the next page, like the one of EventsAfterCursor
+func (ic *InfluxClient) ReadingsAfterCursor(cursor string, limit int) ([]models.Reading, string, error) {
+	offset, err := decodeOffsetCursor(cursor)
+	if err != nil {
+		return []models.Reading{}, cursor, err
+	}
+	if err = validateLimit(limit); err != nil || limit == 0 {
+		return []models.Reading{}, cursor, err
+	}
+
+	readings, err := ic.ReadingsPagedAscending(offset, limit)
+	if err != nil || len(readings) < limit {
+		return readings, "", err
+	}
+	return readings, encodeOffsetCursor(offset + limit), nil
+}
+
 func (ic *InfluxClient) ReadingsByDeviceAndValueDescriptor(deviceId, valueDescriptor string, limit int) ([]models.Reading, error) {
+	if err := validateDeviceId(deviceId); err != nil {
+		return []models.Reading{}, err
+	}
+
 	query := fmt.Sprintf("WHERE device = '%s' AND value = '%s' LIMIT %d", deviceId, valueDescriptor, limit)
 	return ic.getReadings(query)
 }
@@ -522,6 +682,7 @@ func (ic *InfluxClient) addReadingToDB(db string, collection string, r *models.R
 		"origin":   r.Origin,
 		"modified": r.Modified,
 	}
+	putBinaryValue(fields, r.BinaryValue)
 
 	tags := map[string]string{
 		"id":     r.Id.Hex(),
@@ -543,6 +704,13 @@ func (ic *InfluxClient) addReadingToDB(db string, collection string, r *models.R
 	return ic.Client.Write(bp)
 }
 
+// Influx fields can't hold bytes, the binary value is kept base64 encoded
+func putBinaryValue(fields map[string]interface{}, value []byte) {
+	if len(value) > 0 {
+		fields["binaryValue"] = base64.StdEncoding.EncodeToString(value)
+	}
+}
+
 func parseReadings(res client.Result) ([]models.Reading, error) {
 	var readings []models.Reading
 	for i, _ := range res.Series[0].Values {
@@ -587,6 +755,14 @@ func parseReadings(res client.Result) ([]models.Reading, error) {
 				if res.Series[0].Values[i][j] != nil {
 					reading.Value = res.Series[0].Values[i][j].(string)
 				}
+			case "binaryValue":
+				if res.Series[0].Values[i][j] != nil {
+					b, err := base64.StdEncoding.DecodeString(res.Series[0].Values[i][j].(string))
+					if err != nil {
+						return readings, err
+					}
+					reading.BinaryValue = b
+				}
 			}
 		}
 
@@ -675,6 +851,31 @@ func (ic *InfluxClient) DeleteValueDescriptorById(id string) error {
 	return ic.deleteValueDescriptorBy(query)
 }
 
+// Delete the value descriptor based on the name
+// Not found error if there isn't a value descriptor for the name
+// ErrValueDescriptorInUse if the value descriptor is still referenced by readings
+// The lookup, the check of the readings and the removal aren't atomic
+func (ic *InfluxClient) DeleteValueDescriptorByName(name string) error {
+	v, err := ic.ValueDescriptorByName(name)
+	if err != nil {
+		return err
+	}
+	if v.Name == "" {
+		return ErrNotFound
+	}
+
+	readings, err := ic.ReadingsByValueDescriptor(name, 1)
+	if err != nil {
+		return err
+	}
+	if len(readings) > 0 {
+		return ErrValueDescriptorInUse
+	}
+
+	query := fmt.Sprintf("WHERE \"name\" = '%s'", name)
+	return ic.deleteValueDescriptorBy(query)
+}
+
 // Return a value descriptor based on the name
 // Can return null if no value descriptor is found
 func (ic *InfluxClient) ValueDescriptorByName(name string) (models.ValueDescriptor, error) {
@@ -738,6 +939,10 @@ func (ic *InfluxClient) ValueDescriptorsByType(t string) ([]models.ValueDescript
 
 // Delete all of the value descriptors
 func (ic *InfluxClient) ScrubAllValueDescriptors() error {
+	if !ic.allowScrub {
+		return ErrScrubNotAllowed
+	}
+
 	return ic.deleteAll(VALUE_DESCRIPTOR_COLLECTION)
 }
 
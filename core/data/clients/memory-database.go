@@ -26,10 +26,20 @@ type memDB struct {
 	vDescriptors []models.ValueDescriptor
 }
 
+func init() {
+	RegisterDBClient(MEMORY.String(), func(config DBConfiguration) (DBClient, error) {
+		return &memDB{}, nil
+	})
+}
+
 func (m *memDB) CloseSession() {
 }
 
 func (m *memDB) AddReading(r models.Reading) (bson.ObjectId, error) {
+	if err := validateReading(r); err != nil {
+		return "", err
+	}
+
 	currentTime := time.Now().UnixNano() / int64(time.Millisecond)
 	r.Created = currentTime
 	r.Modified = currentTime
@@ -45,6 +55,10 @@ func (m *memDB) Events() ([]models.Event, error) {
 }
 
 func (m *memDB) AddEvent(e *models.Event) (bson.ObjectId, error) {
+	if e == nil {
+		return "", ErrNilEvent
+	}
+
 	currentTime := time.Now().UnixNano() / int64(time.Millisecond)
 
 	for i := range e.Readings {
@@ -64,6 +78,10 @@ func (m *memDB) AddEvent(e *models.Event) (bson.ObjectId, error) {
 	return e.ID, nil
 }
 
+func (m *memDB) AddEvents(events []models.Event) ([]AddEventResult, error) {
+	return addEachEvent(m.AddEvent, events), nil
+}
+
 func (m *memDB) UpdateEvent(event models.Event) error {
 	for i, e := range m.events {
 		if e.ID == event.ID {
@@ -88,6 +106,10 @@ func (m *memDB) EventCount() (int, error) {
 }
 
 func (m *memDB) EventCountByDeviceId(id string) (int, error) {
+	if err := validateDeviceId(id); err != nil {
+		return 0, err
+	}
+
 	count := 0
 	for _, e := range m.events {
 		if e.Device == id {
@@ -109,6 +131,10 @@ func (m *memDB) DeleteEventById(id string) error {
 
 func (m *memDB) EventsForDeviceLimit(id string, limit int) ([]models.Event, error) {
 	events := []models.Event{}
+	if err := validateDeviceId(id); err != nil {
+		return events, err
+	}
+
 	count := 0
 	for _, e := range m.events {
 		if e.Device == id {
@@ -124,6 +150,10 @@ func (m *memDB) EventsForDeviceLimit(id string, limit int) ([]models.Event, erro
 
 func (m *memDB) EventsForDevice(id string) ([]models.Event, error) {
 	events := []models.Event{}
+	if err := validateDeviceId(id); err != nil {
+		return events, err
+	}
+
 	for _, e := range m.events {
 		if e.Device == id {
 			events = append(events, e)
@@ -134,6 +164,10 @@ func (m *memDB) EventsForDevice(id string) ([]models.Event, error) {
 
 func (m *memDB) EventsByCreationTime(startTime, endTime int64, limit int) ([]models.Event, error) {
 	events := []models.Event{}
+	if err := validateTimeRange(startTime, endTime); err != nil {
+		return events, err
+	}
+
 	count := 0
 	for _, e := range m.events {
 		if e.Created >= startTime && e.Created < endTime {
@@ -149,6 +183,10 @@ func (m *memDB) EventsByCreationTime(startTime, endTime int64, limit int) ([]mod
 
 func (m *memDB) ReadingsByDeviceAndValueDescriptor(deviceId, valueDescriptor string, limit int) ([]models.Reading, error) {
 	readings := []models.Reading{}
+	if err := validateDeviceId(deviceId); err != nil {
+		return readings, err
+	}
+
 	count := 0
 	for _, r := range m.readings {
 		if r.Device == deviceId && r.Name == valueDescriptor {
@@ -173,6 +211,27 @@ func (m *memDB) EventsOlderThanAge(age int64) ([]models.Event, error) {
 	return events, nil
 }
 
+func (m *memDB) DeleteEventsPushed(limit int) (int, error) {
+	if err := validateLimit(limit); err != nil {
+		return 0, err
+	}
+
+	kept := []models.Event{}
+	removed := 0
+	for _, e := range m.events {
+		if e.Pushed == 0 || removed == limit {
+			kept = append(kept, e)
+			continue
+		}
+		for _, r := range e.Readings {
+			m.DeleteReadingById(r.Id.Hex())
+		}
+		removed++
+	}
+	m.events = kept
+	return removed, nil
+}
+
 func (m *memDB) EventsPushed() ([]models.Event, error) {
 	events := []models.Event{}
 	for _, e := range m.events {
@@ -183,6 +242,43 @@ func (m *memDB) EventsPushed() ([]models.Event, error) {
 	return events, nil
 }
 
+func (m *memDB) EventsPagedAscending(offset, limit int) ([]models.Event, error) {
+	events := []models.Event{}
+	if err := validatePage(offset, limit); err != nil {
+		return events, err
+	}
+
+	for i := offset; i < len(m.events) && len(events) < limit; i++ {
+		events = append(events, m.events[i])
+	}
+	return events, nil
+}
+
+func (m *memDB) EventsAfterCursor(cursor string, limit int) ([]models.Event, string, error) {
+	events := []models.Event{}
+	p, err := decodeCursor(cursor)
+	if err != nil {
+		return events, cursor, err
+	}
+	if err = validateLimit(limit); err != nil || limit == 0 {
+		return events, cursor, err
+	}
+
+	for _, e := range m.events {
+		if p.precedes(e.Created, e.ID) {
+			events = append(events, e)
+			if len(events) == limit {
+				break
+			}
+		}
+	}
+	if len(events) == 0 {
+		return events, "", nil
+	}
+	last := events[len(events)-1]
+	return events, nextCursor(len(events), limit, last.Created, last.ID), nil
+}
+
 func (m *memDB) ScrubAllEvents() error {
 	m.events = nil
 	m.readings = nil
@@ -228,6 +324,10 @@ func (m *memDB) DeleteReadingById(id string) error {
 
 func (m *memDB) ReadingsByDevice(id string, limit int) ([]models.Reading, error) {
 	readings := []models.Reading{}
+	if err := validateDeviceId(id); err != nil {
+		return readings, err
+	}
+
 	count := 0
 	for _, r := range m.readings {
 		if r.Device == id {
@@ -267,6 +367,10 @@ func stringInSlice(a string, list []string) bool {
 
 func (m *memDB) ReadingsByValueDescriptorNames(names []string, limit int) ([]models.Reading, error) {
 	readings := []models.Reading{}
+	if len(names) == 0 {
+		return readings, nil
+	}
+
 	count := 0
 	for _, r := range m.readings {
 		if stringInSlice(r.Name, names) {
@@ -282,6 +386,10 @@ func (m *memDB) ReadingsByValueDescriptorNames(names []string, limit int) ([]mod
 
 func (m *memDB) ReadingsByCreationTime(start, end int64, limit int) ([]models.Reading, error) {
 	readings := []models.Reading{}
+	if err := validateTimeRange(start, end); err != nil {
+		return readings, err
+	}
+
 	count := 0
 	for _, r := range m.readings {
 		if r.Created >= start && r.Created < end {
@@ -295,6 +403,43 @@ func (m *memDB) ReadingsByCreationTime(start, end int64, limit int) ([]models.Re
 	return readings, nil
 }
 
+func (m *memDB) ReadingsPagedAscending(offset, limit int) ([]models.Reading, error) {
+	readings := []models.Reading{}
+	if err := validatePage(offset, limit); err != nil {
+		return readings, err
+	}
+
+	for i := offset; i < len(m.readings) && len(readings) < limit; i++ {
+		readings = append(readings, m.readings[i])
+	}
+	return readings, nil
+}
+
+func (m *memDB) ReadingsAfterCursor(cursor string, limit int) ([]models.Reading, string, error) {
+	readings := []models.Reading{}
+	p, err := decodeCursor(cursor)
+	if err != nil {
+		return readings, cursor, err
+	}
+	if err = validateLimit(limit); err != nil || limit == 0 {
+		return readings, cursor, err
+	}
+
+	for _, r := range m.readings {
+		if p.precedes(r.Created, r.Id) {
+			readings = append(readings, r)
+			if len(readings) == limit {
+				break
+			}
+		}
+	}
+	if len(readings) == 0 {
+		return readings, "", nil
+	}
+	last := readings[len(readings)-1]
+	return readings, nextCursor(len(readings), limit, last.Created, last.Id), nil
+}
+
 func (m *memDB) AddValueDescriptor(value models.ValueDescriptor) (bson.ObjectId, error) {
 	currentTime := time.Now().UnixNano() / int64(time.Millisecond)
 	value.Created = currentTime
@@ -336,6 +481,21 @@ func (m *memDB) DeleteValueDescriptorById(id string) error {
 	return ErrNotFound
 }
 
+func (m *memDB) DeleteValueDescriptorByName(name string) error {
+	for i, v := range m.vDescriptors {
+		if v.Name == name {
+			for _, r := range m.readings {
+				if r.Name == name {
+					return ErrValueDescriptorInUse
+				}
+			}
+			m.vDescriptors = append(m.vDescriptors[:i], m.vDescriptors[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
 func (m *memDB) ValueDescriptorByName(name string) (models.ValueDescriptor, error) {
 	for _, v := range m.vDescriptors {
 		if v.Name == name {
@@ -87,6 +87,15 @@ func testDBReadings(t *testing.T, db DBClient) {
 	if err != nil {
 		t.Fatalf("Error populating db: %v\n", err)
 	}
+
+	_, err = db.AddReading(models.Reading{})
+	if err != ErrInvalidReading {
+		t.Fatalf("Expected ErrInvalidReading for a reading without a name, got %v", err)
+	}
+	_, err = db.AddReading(models.Reading{Device: "name1"})
+	if err != ErrInvalidReading {
+		t.Fatalf("Expected ErrInvalidReading for a reading without a name, got %v", err)
+	}
 	afterTime := time.Now().UnixNano() / int64(time.Millisecond)
 
 	count, err := db.ReadingCount()
@@ -159,6 +168,14 @@ func testDBReadings(t *testing.T, db DBClient) {
 	if len(readings) != 1 {
 		t.Fatalf("There should be 1 readings, not %d", len(readings))
 	}
+	_, err = db.ReadingsByDevice("", 10)
+	if err != ErrEmptyDeviceId {
+		t.Fatalf("Expected ErrEmptyDeviceId for ReadingsByDevice, got %v", err)
+	}
+	_, err = db.ReadingsByDeviceAndValueDescriptor("", "name1", 10)
+	if err != ErrEmptyDeviceId {
+		t.Fatalf("Expected ErrEmptyDeviceId for ReadingsByDeviceAndValueDescriptor, got %v", err)
+	}
 
 	readings, err = db.ReadingsByValueDescriptor("name1", 10)
 	if err != nil {
@@ -217,6 +234,15 @@ func testDBReadings(t *testing.T, db DBClient) {
 	if len(readings) != 1 {
 		t.Fatalf("There should be 1 readings, not %d", len(readings))
 	}
+	for _, names := range [][]string{nil, {}} {
+		readings, err = db.ReadingsByValueDescriptorNames(names, 10)
+		if err != nil {
+			t.Fatalf("Error getting ReadingsByValueDescriptorNames: %v", err)
+		}
+		if readings == nil || len(readings) != 0 {
+			t.Fatalf("There should be an empty list of readings, not %v", readings)
+		}
+	}
 
 	readings, err = db.ReadingsByCreationTime(beforeTime, afterTime+10, 200)
 	if err != nil {
@@ -232,6 +258,14 @@ func testDBReadings(t *testing.T, db DBClient) {
 	if len(readings) != 100 {
 		t.Fatalf("There should be 100 readings, not %d", len(readings))
 	}
+	_, err = db.ReadingsByCreationTime(beforeTime+10, beforeTime, 100)
+	if err != ErrInvalidTimeRange {
+		t.Fatalf("Start after end should return ErrInvalidTimeRange, not %v", err)
+	}
+	_, err = db.ReadingsByCreationTime(-1, afterTime, 100)
+	if err != ErrInvalidTimeRange {
+		t.Fatalf("Negative start should return ErrInvalidTimeRange, not %v", err)
+	}
 
 	r := models.Reading{}
 	r.Id = id
@@ -276,6 +310,11 @@ func testDBEvents(t *testing.T, db DBClient) {
 		t.Fatalf("Error populating db: %v\n", err)
 	}
 
+	_, err = db.AddEvent(nil)
+	if err != ErrNilEvent {
+		t.Fatalf("Expected ErrNilEvent, got %v", err)
+	}
+
 	// To have two events with the same name
 	id, err = populateDbEvents(db, 10, 1)
 	if err != nil {
@@ -384,6 +423,18 @@ func testDBEvents(t *testing.T, db DBClient) {
 	if len(events) != 0 {
 		t.Fatalf("There should be 0 events, not %d", len(events))
 	}
+	_, err = db.EventsForDevice("")
+	if err != ErrEmptyDeviceId {
+		t.Fatalf("Expected ErrEmptyDeviceId for EventsForDevice, got %v", err)
+	}
+	_, err = db.EventsForDeviceLimit("", 10)
+	if err != ErrEmptyDeviceId {
+		t.Fatalf("Expected ErrEmptyDeviceId for EventsForDeviceLimit, got %v", err)
+	}
+	_, err = db.EventCountByDeviceId("")
+	if err != ErrEmptyDeviceId {
+		t.Fatalf("Expected ErrEmptyDeviceId for EventCountByDeviceId, got %v", err)
+	}
 
 	events, err = db.EventsByCreationTime(beforeTime, afterTime+10, 200)
 	if err != nil {
@@ -399,6 +450,14 @@ func testDBEvents(t *testing.T, db DBClient) {
 	if len(events) != 100 {
 		t.Fatalf("There should be 100 events, not %d", len(events))
 	}
+	_, err = db.EventsByCreationTime(beforeTime+10, beforeTime, 100)
+	if err != ErrInvalidTimeRange {
+		t.Fatalf("Start after end should return ErrInvalidTimeRange, not %v", err)
+	}
+	_, err = db.EventsByCreationTime(-1, afterTime, 100)
+	if err != ErrInvalidTimeRange {
+		t.Fatalf("Negative start should return ErrInvalidTimeRange, not %v", err)
+	}
 
 	events, err = db.EventsOlderThanAge(0)
 	if err != nil {
@@ -537,6 +596,16 @@ func testDBValueDescriptors(t *testing.T, db DBClient) {
 		t.Fatalf("There should be 0 Values, not %d", len(values))
 	}
 
+	for _, names := range [][]string{nil, {}} {
+		values, err = db.ValueDescriptorsByName(names)
+		if err != nil {
+			t.Fatalf("Error getting ValuesByValueDescriptorNames: %v", err)
+		}
+		if values == nil || len(values) != 0 {
+			t.Fatalf("There should be an empty list of Values, not %v", values)
+		}
+	}
+
 	values, err = db.ValueDescriptorsByUomLabel("name1")
 	if err != nil {
 		t.Fatalf("Error getting ValuesByValueDescriptorNames: %v", err)
@@ -612,15 +681,264 @@ func testDBValueDescriptors(t *testing.T, db DBClient) {
 		t.Fatalf("Update should return error")
 	}
 
+	_, err = db.AddValueDescriptor(models.ValueDescriptor{Name: "unused"})
+	if err != nil {
+		t.Fatalf("Error adding value descriptor: %v", err)
+	}
+	err = db.DeleteValueDescriptorByName("unused")
+	if err != nil {
+		t.Fatalf("Value should be deleted by name: %v", err)
+	}
+	err = db.DeleteValueDescriptorByName("unused")
+	if err != ErrNotFound {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+
+	_, err = db.AddValueDescriptor(models.ValueDescriptor{Name: "used"})
+	if err != nil {
+		t.Fatalf("Error adding value descriptor: %v", err)
+	}
+	_, err = db.AddReading(models.Reading{Name: "used"})
+	if err != nil {
+		t.Fatalf("Error adding reading: %v", err)
+	}
+	err = db.DeleteValueDescriptorByName("used")
+	if err != ErrValueDescriptorInUse {
+		t.Fatalf("Expected ErrValueDescriptorInUse, got %v", err)
+	}
+	_, err = db.AddReading(models.Reading{Name: "undescribed"})
+	if err != nil {
+		t.Fatalf("Error adding reading: %v", err)
+	}
+	err = db.DeleteValueDescriptorByName("undescribed")
+	if err != ErrNotFound {
+		t.Fatalf("A missing value descriptor should be ErrNotFound even when readings use the name, got %v", err)
+	}
+	err = db.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events: %v", err)
+	}
+	err = db.DeleteValueDescriptorByName("used")
+	if err != nil {
+		t.Fatalf("Value should be deleted by name: %v", err)
+	}
+
 	err = db.ScrubAllValueDescriptors()
 	if err != nil {
 		t.Fatalf("Error removing all value descriptors")
 	}
 }
 
+func testDBPaging(t *testing.T, db DBClient) {
+	err := db.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events")
+	}
+
+	_, err = populateDbEvents(db, 25, 0)
+	if err != nil {
+		t.Fatalf("Error populating db: %v\n", err)
+	}
+	_, err = populateDbReadings(db, 25)
+	if err != nil {
+		t.Fatalf("Error populating db: %v\n", err)
+	}
+
+	events, err := db.EventsPagedAscending(20, 10)
+	if err != nil {
+		t.Fatalf("Error getting EventsPagedAscending: %v", err)
+	}
+	if len(events) != 5 {
+		t.Fatalf("The last page should have 5 events, not %d", len(events))
+	}
+	events, err = db.EventsPagedAscending(30, 10)
+	if err != nil || len(events) != 0 {
+		t.Fatalf("There should be no events past the end: %v %v", events, err)
+	}
+	_, err = db.EventsPagedAscending(-1, 10)
+	if err != ErrInvalidPage {
+		t.Fatalf("Expected ErrInvalidPage for a negative offset, got %v", err)
+	}
+	_, err = db.EventsPagedAscending(0, -1)
+	if err != ErrInvalidLimit {
+		t.Fatalf("Expected ErrInvalidLimit for a negative limit, got %v", err)
+	}
+
+	// The cursor walks the events in the order of the pages
+	all, err := db.EventsPagedAscending(0, 25)
+	if err != nil {
+		t.Fatalf("Error getting EventsPagedAscending: %v", err)
+	}
+	var walked []models.Event
+	cursor := ""
+	for i := 0; i < 5; i++ {
+		var page []models.Event
+		page, cursor, err = db.EventsAfterCursor(cursor, 10)
+		if err != nil {
+			t.Fatalf("Error getting EventsAfterCursor: %v", err)
+		}
+		walked = append(walked, page...)
+		if cursor == "" {
+			break
+		}
+	}
+	if cursor != "" || len(walked) != len(all) {
+		t.Fatalf("The cursor should walk the %d events, walked %d", len(all), len(walked))
+	}
+	for i := range all {
+		if walked[i].ID != all[i].ID {
+			t.Fatalf("The cursor should follow the order of the pages at %d: %s %s", i, walked[i].ID, all[i].ID)
+		}
+	}
+	_, _, err = db.EventsAfterCursor("not a cursor", 10)
+	if err != ErrInvalidCursor {
+		t.Fatalf("Expected ErrInvalidCursor, got %v", err)
+	}
+
+	readings, err := db.ReadingsPagedAscending(20, 10)
+	if err != nil {
+		t.Fatalf("Error getting ReadingsPagedAscending: %v", err)
+	}
+	if len(readings) != 5 {
+		t.Fatalf("The last page should have 5 readings, not %d", len(readings))
+	}
+	_, err = db.ReadingsPagedAscending(-1, 10)
+	if err != ErrInvalidPage {
+		t.Fatalf("Expected ErrInvalidPage for a negative offset, got %v", err)
+	}
+
+	allReadings, err := db.ReadingsPagedAscending(0, 25)
+	if err != nil {
+		t.Fatalf("Error getting ReadingsPagedAscending: %v", err)
+	}
+	var walkedReadings []models.Reading
+	cursor = ""
+	for i := 0; i < 5; i++ {
+		var page []models.Reading
+		page, cursor, err = db.ReadingsAfterCursor(cursor, 10)
+		if err != nil {
+			t.Fatalf("Error getting ReadingsAfterCursor: %v", err)
+		}
+		walkedReadings = append(walkedReadings, page...)
+		if cursor == "" {
+			break
+		}
+	}
+	if cursor != "" || len(walkedReadings) != len(allReadings) {
+		t.Fatalf("The cursor should walk the %d readings, walked %d", len(allReadings), len(walkedReadings))
+	}
+	for i := range allReadings {
+		if walkedReadings[i].Id != allReadings[i].Id {
+			t.Fatalf("The cursor should follow the order of the pages at %d: %s %s", i, walkedReadings[i].Id, allReadings[i].Id)
+		}
+	}
+	_, _, err = db.ReadingsAfterCursor("not a cursor", 10)
+	if err != ErrInvalidCursor {
+		t.Fatalf("Expected ErrInvalidCursor, got %v", err)
+	}
+
+	err = db.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events")
+	}
+}
+
+func testDBAddEvents(t *testing.T, db DBClient) {
+	err := db.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events")
+	}
+
+	events := []models.Event{
+		{Device: "device1", Readings: []models.Reading{{Name: "temperature", Value: "1"}}},
+		{Device: "device2", Readings: []models.Reading{{Name: "temperature", Value: "2"}, {Name: "humidity", Value: "3"}}},
+	}
+	results, err := db.AddEvents(events)
+	if err != nil {
+		t.Fatalf("Error adding events: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("There should be 2 results, not %d", len(results))
+	}
+	for i, r := range results {
+		if r.Index != i || r.Err != nil || r.ID != events[i].ID || !r.ID.Valid() {
+			t.Fatalf("Event %d should be added with its id: %v", i, r)
+		}
+	}
+
+	e, err := db.EventById(results[1].ID.Hex())
+	if err != nil {
+		t.Fatalf("Error getting the added event: %v", err)
+	}
+	if e.Device != "device2" || len(e.Readings) != 2 || e.Readings[1].Device != "device2" {
+		t.Fatalf("The event should be stored with its readings: %v", e)
+	}
+
+	count, err := db.ReadingCount()
+	if err != nil {
+		t.Fatalf("Error getting readings count: %v", err)
+	}
+	if count < 3 {
+		t.Fatalf("The readings of the batch should be added, there are only %d", count)
+	}
+
+	results, err = db.AddEvents([]models.Event{})
+	if err != nil || len(results) != 0 {
+		t.Fatalf("An empty batch should add nothing: %v %v", results, err)
+	}
+}
+
+func testDBDeleteEventsPushed(t *testing.T, db DBClient) {
+	err := db.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events")
+	}
+
+	for i := 0; i < 5; i++ {
+		e := models.Event{Device: "device", Readings: []models.Reading{{Name: "temperature", Value: "1"}}}
+		if i < 3 {
+			e.Pushed = 1
+		}
+		if _, err = db.AddEvent(&e); err != nil {
+			t.Fatalf("Error adding an event: %v", err)
+		}
+	}
+
+	if _, err = db.DeleteEventsPushed(-1); err != ErrInvalidLimit {
+		t.Fatalf("Expected ErrInvalidLimit, got %v", err)
+	}
+	for _, expected := range []int{2, 1, 0} {
+		removed, err := db.DeleteEventsPushed(2)
+		if err != nil {
+			t.Fatalf("Error deleting the pushed events: %v", err)
+		}
+		if removed != expected {
+			t.Fatalf("There should be %d events removed, not %d", expected, removed)
+		}
+	}
+
+	count, err := db.EventCount()
+	if err != nil {
+		t.Fatalf("Error getting events count: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("Only the 2 events not pushed should be left, not %d", count)
+	}
+	count, err = db.ReadingCount()
+	if err != nil {
+		t.Fatalf("Error getting readings count: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("Only the readings of the events not pushed should be left, not %d", count)
+	}
+}
+
 func testDB(t *testing.T, db DBClient) {
 	testDBReadings(t, db)
 	testDBEvents(t, db)
+	testDBPaging(t, db)
+	testDBAddEvents(t, db)
+	testDBDeleteEventsPushed(t, db)
 	testDBValueDescriptors(t, db)
 
 	db.CloseSession()
@@ -15,7 +15,14 @@ package clients
 
 import (
 	"errors"
-	"strconv"
+	"fmt"
+	"io"
+	"net"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/edgexfoundry/edgex-go/core/domain/models"
@@ -29,11 +36,39 @@ const (
 	VALUE_DESCRIPTOR_COLLECTION = "valueDescriptor"
 )
 
-var currentMongoClient *MongoClient // Singleton used so that MongoEvent can use it to de-reference readings
+// Largest event document AddEvent stores, below the 16MB BSON limit so the estimate has room to be off
+const maxEventDocumentSize = 15 * 1024 * 1024
+
+// Upper bound of the size of a reading document without its strings
+const readingFieldsSize = 512
+
+const (
+	DefaultBatchDeleteSize = 1000              // Used when BatchDeleteSize isn't configured
+	DefaultMongoAppName    = "edgex-core-data" // Used when AppName isn't configured
+)
+
+// Newest first, ties on the creation time are broken by the ObjectId so paging is deterministic
+var newestFirst = []string{"-created", "-_id"}
+
+// Oldest first in the same order, the order of the pages of EventsPagedAscending,
+// ReadingsPagedAscending and the cursors
+var oldestFirst = []string{"created", "_id"}
+
+// Fields the events can be sorted by in EventsForDevicePagedSorted
+var eventSortFields = map[string]bool{"created": true, "origin": true, "modified": true}
+
+// Fields of the reading documents ReadingsProjected can select
+var readingFields = map[string]bool{
+	"_id": true, "pushed": true, "created": true, "origin": true, "modified": true, "device": true, "name": true, "value": true,
+}
+
+var currentMongoClient *MongoClient      // Singleton used so that MongoEvent can use it to de-reference readings
+var currentMongoClientMutex sync.RWMutex // Guards currentMongoClient, clients may be created concurrently
 
 /*
 Core data client
 Has functions for interacting with the core data mongo database
+The methods taking a limit return an empty list for a limit of 0 and ErrInvalidLimit for a negative one
 */
 
 // Type used to sort the readings by creation date
@@ -44,35 +79,326 @@ func (a ByReadingCreationDate) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a ByReadingCreationDate) Less(i, j int) bool { return (a[i].Created < a[j].Created) }
 
 type MongoClient struct {
-	Session  *mgo.Session  // Mongo database session
-	Database *mgo.Database // Mongo database
+	Session   *mgo.Session  // Mongo database session, swapped by Reconnect so read it through session()
+	Database  *mgo.Database // Mongo database, only its name is used so it isn't swapped by Reconnect
+	config    DBConfiguration
+	stop      chan struct{} // Closed to stop the idle pinger
+	closeOnce sync.Once
+	mutex     sync.RWMutex          // Guards Session
+	vdCache   *valueDescriptorCache // Value descriptors by name, nil when disabled
+	lookup    struct {              // Whether the server supports the aggregation stages of EventsWithReadings
+		sync.Once
+		supported bool
+	}
+}
+
+func init() {
+	RegisterDBClient(MONGO.String(), func(config DBConfiguration) (DBClient, error) {
+		// Keep the interface nil on errors, not holding a nil *MongoClient
+		mc, err := newMongoClient(config)
+		if err != nil {
+			return nil, err
+		}
+		return mc, nil
+	})
 }
 
 // Return a pointer to the MongoClient
 func newMongoClient(config DBConfiguration) (*MongoClient, error) {
+	if config.AppName == "" {
+		config.AppName = DefaultMongoAppName
+	}
+	// NOTE: gopkg.in/mgo.v2 predates OP_COMPRESSED and rejects the compressors URL option, so the
+	// connection stays uncompressed until the driver can negotiate zstd or snappy
+	if config.Compression {
+		loggingClient.Warn("The mongo driver doesn't support wire compression, connecting uncompressed")
+	}
+
+	session, err := dialMongo(config)
+	if err != nil {
+		return nil, err
+	}
+
+	mongoClient := &MongoClient{Session: session, Database: session.DB(config.DatabaseName), config: config, stop: make(chan struct{})}
+
+	if config.CacheVDs {
+		mongoClient.vdCache = newValueDescriptorCache(time.Duration(config.VDCacheTTL) * time.Millisecond)
+	}
+
+	if config.MaxIdleTime > 0 {
+		go mongoClient.pingWhileIdle(time.Duration(config.MaxIdleTime) * time.Millisecond)
+	}
+
+	if config.CappedReadings {
+		if err = mongoClient.ensureCappedReadings(); err != nil {
+			loggingClient.Warn("Error creating the capped readings collection: " + err.Error())
+		}
+	}
+
+	// Missing indexes only slow down the queries, so don't fail the connection
+	if config.SkipIndexes {
+		loggingClient.Info("Skipping the creation of the mongo indexes")
+	} else {
+		indexes, err := mongoClient.ensureIndexes()
+		if err != nil {
+			loggingClient.Warn("Error creating the mongo indexes: " + err.Error())
+		}
+		logIndexResults(indexes)
+	}
+
+	// Only publish the client once it's set up
+	setCurrentMongoClient(mongoClient)
+
+	return mongoClient, nil
+}
+
+// Log the indexes created, the ones already there are only counted so restarts stay quiet
+func logIndexResults(results []IndexResult) {
+	existing := 0
+	for _, r := range results {
+		if r.Created {
+			loggingClient.Info("Created mongo index " + r.Name + " on " + r.Collection)
+		} else {
+			existing++
+		}
+	}
+	loggingClient.Info(fmt.Sprintf("%d mongo indexes created, %d already present", len(results)-existing, existing))
+}
+
+// Dial a new master session for the configuration
+func dialMongo(config DBConfiguration) (*mgo.Session, error) {
 	// Create the dial info for the Mongo session
-	connectionString := config.Host + ":" + strconv.Itoa(config.Port)
-	loggingClient.Info("INFO: Connecting to mongo at: " + connectionString)
+	// NOTE: gopkg.in/mgo.v2 doesn't send client metadata in its handshake (and rejects the appName
+	// URL option) so the name can't be shown by db.currentOp() until the driver supports it
+	mode, err := readPreferenceMode(config.ReadPreference)
+	if err != nil {
+		loggingClient.Error("Error in the mongo read preference " + config.ReadPreference + ": " + err.Error())
+		return nil, err
+	}
+
+	addrs := mongoAddrs(config)
+	connectionString := strings.Join(addrs, ",")
+	if config.ReplicaSet != "" {
+		connectionString += " (replica set " + config.ReplicaSet + ")"
+	}
+	loggingClient.Info("INFO: Connecting to mongo at: " + connectionString + " as " + config.AppName)
 	mongoDBDialInfo := &mgo.DialInfo{
-		Addrs:    []string{connectionString},
-		Timeout:  time.Duration(config.Timeout) * time.Millisecond,
-		Database: config.DatabaseName,
-		Username: config.Username,
-		Password: config.Password,
+		Addrs:          addrs,
+		ReplicaSetName: config.ReplicaSet,
+		Timeout:        time.Duration(config.Timeout) * time.Millisecond,
+		Database:       config.DatabaseName,
+		Username:       config.Username,
+		Password:       config.Password,
+	}
+	if config.SocketKeepAlive > 0 || config.TLS {
+		d := &mongoDialer{
+			dialer: &net.Dialer{
+				Timeout:   mongoDBDialInfo.Timeout,
+				KeepAlive: time.Duration(config.SocketKeepAlive) * time.Millisecond,
+			},
+			timeout: mongoDBDialInfo.Timeout,
+		}
+		if config.TLS {
+			tlsConfig, err := mongoTLSConfig(config)
+			if err != nil {
+				loggingClient.Error("Error loading the mongo TLS configuration: " + err.Error())
+				return nil, err
+			}
+			if config.TLSSkipVerify {
+				loggingClient.Warn("The mongo server certificate isn't verified")
+			}
+			d.tls = tlsConfig
+		}
+		mongoDBDialInfo.DialServer = func(addr *mgo.ServerAddr) (net.Conn, error) {
+			return d.dial(addr.String())
+		}
 	}
 	session, err := mgo.DialWithInfo(mongoDBDialInfo)
 	if err != nil {
 		loggingClient.Error("Error dialing the mongo server: " + err.Error())
 		return nil, err
 	}
+	// The copies inherit the mode, after a failover they follow the new primary
+	session.SetMode(mode, true)
 
-	mongoClient := &MongoClient{Session: session, Database: session.DB(config.DatabaseName)}
-	currentMongoClient = mongoClient // Set the singleton
-	return mongoClient, nil
+	return session, nil
+}
+
+// Re-dial the database with the original configuration and swap in the new master session
+// The old session is only closed once the new one is in place, so concurrent callers always
+// copy a usable session. On a dial error the old session is kept
+func (mc *MongoClient) Reconnect() error {
+	session, err := dialMongo(mc.config)
+	if err != nil {
+		return err
+	}
+
+	mc.mutex.Lock()
+	old := mc.Session
+	mc.Session = session
+	mc.mutex.Unlock()
+
+	old.Close()
+	return nil
+}
+
+// Get the current master session
+func (mc *MongoClient) session() *mgo.Session {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	return mc.Session
+}
+
+// Outcome of ensuring an index at startup
+type IndexResult struct {
+	Name       string // Name Mongo gave the index
+	Collection string
+	Created    bool // False when the index already existed
+}
+
+// Indexes used by the queries
+var mongoIndexes = []struct {
+	collection string
+	key        []string
+}{
+	{VALUE_DESCRIPTOR_COLLECTION, []string{"created"}},
+	{VALUE_DESCRIPTOR_COLLECTION, []string{"modified"}},
+	// Serve the newestFirst sort and the ReadingsAfter cursor
+	{EVENTS_COLLECTION, []string{"created", "_id"}},
+	{READINGS_COLLECTION, []string{"created", "_id"}},
+	// Serves EventsPushedBetween
+	{EVENTS_COLLECTION, []string{"pushed"}},
+	// Serves ReadingsModifiedSince
+	{READINGS_COLLECTION, []string{"modified", "_id"}},
+	// Serve the device scoped time range queries
+	{EVENTS_COLLECTION, []string{"device", "-created"}},
+	{READINGS_COLLECTION, []string{"device", "-created"}},
+	// Serve the lookups by value descriptor name, and the readings of a name newest first
+	{VALUE_DESCRIPTOR_COLLECTION, []string{"name"}},
+	{READINGS_COLLECTION, []string{"name", "-created"}},
+}
+
+// Create the indexes used by the queries if they don't exist yet
+// EnsureIndex doesn't tell whether it created the index, so the indexes of the collection are
+// compared before and after. Return the results of the indexes ensured before any error
+func (mc *MongoClient) ensureIndexes() ([]IndexResult, error) {
+	s := mc.getScanSessionCopy()
+	defer s.Close()
+
+	results := []IndexResult{}
+	for n, index := range mongoIndexes {
+		c := s.DB(mc.Database.Name).C(index.collection)
+		// Building an index on a large collection takes a while, show where it's at
+		loggingClient.Info(fmt.Sprintf("Ensuring mongo index %d of %d on %s (%s)",
+			n+1, len(mongoIndexes), index.collection, strings.Join(index.key, ", ")))
+
+		// The collection doesn't exist before the first insert, it then has no indexes
+		before, err := c.Indexes()
+		if err != nil && !isNamespaceNotFound(err) {
+			return results, err
+		}
+		existing := map[string]bool{}
+		for _, i := range before {
+			existing[i.Name] = true
+		}
+
+		if err = c.EnsureIndexKey(index.key...); err != nil {
+			return results, err
+		}
+
+		after, err := c.Indexes()
+		if err != nil {
+			return results, err
+		}
+		for _, i := range after {
+			if strings.Join(i.Key, ",") == strings.Join(index.key, ",") {
+				results = append(results, IndexResult{Name: i.Name, Collection: index.collection, Created: !existing[i.Name]})
+				break
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// Return whether the error is about a collection or database that doesn't exist
+func isNamespaceNotFound(err error) bool {
+	if qErr, ok := err.(*mgo.QueryError); ok && qErr.Code == 26 {
+		return true
+	}
+	return strings.Contains(err.Error(), "ns not found") || strings.Contains(err.Error(), "doesn't exist")
+}
+
+// Create the readings collection capped to the configured bounds if it doesn't exist yet
+// Once a bound is reached Mongo evicts the oldest readings on insert, so the collection is a rolling
+// buffer of the most recent readings. This is mutually exclusive with a TTL expiry of the readings,
+// capped collections don't support TTL indexes. Before MongoDB 5.0 readings can't be deleted one by
+// one from a capped collection either, so the reading deletes and scrubs fail.
+// The events aren't capped with their readings, so they keep referencing the evicted ones. Their
+// evicted readings are skipped when they're read, an event whose readings were all evicted has none.
+// An existing collection is left as is, capping it would need a copy of all of its readings
+func (mc *MongoClient) ensureCappedReadings() error {
+	if mc.config.ReadingsCapSize <= 0 {
+		return ErrInvalidCapSize
+	}
+
+	s := mc.getScanSessionCopy()
+	defer s.Close()
+
+	db := s.DB(mc.Database.Name)
+	names, err := db.CollectionNames()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if name != READINGS_COLLECTION {
+			continue
+		}
+
+		var stats struct {
+			Capped bool `bson:"capped"`
+		}
+		if err = db.Run(bson.D{{Name: "collStats", Value: READINGS_COLLECTION}}, &stats); err != nil {
+			return err
+		}
+		if !stats.Capped {
+			loggingClient.Warn("The readings collection already exists and isn't capped, drop it to cap it")
+		}
+		return nil
+	}
+
+	return db.C(READINGS_COLLECTION).Create(&mgo.CollectionInfo{
+		Capped:   true,
+		MaxBytes: mc.config.ReadingsCapSize,
+		MaxDocs:  mc.config.ReadingsCapDocs,
+	})
+}
+
+// Get the number of events, readings and value descriptors in Mongo on a single session
+func (mc *MongoClient) DBStats() (events, readings, valueDescriptors int, err error) {
+	s := mc.countsFromSecondary(mc.getSessionCopy())
+	defer s.Close()
+
+	db := s.DB(mc.Database.Name)
+	if events, err = db.C(EVENTS_COLLECTION).Find(nil).Count(); err != nil {
+		return 0, 0, 0, queryError(err)
+	}
+	if readings, err = db.C(READINGS_COLLECTION).Find(nil).Count(); err != nil {
+		return 0, 0, 0, queryError(err)
+	}
+	if valueDescriptors, err = db.C(VALUE_DESCRIPTOR_COLLECTION).Find(nil).Count(); err != nil {
+		return 0, 0, 0, queryError(err)
+	}
+
+	return events, readings, valueDescriptors, nil
 }
 
 // Get the current Mongo Client
 func getCurrentMongoClient() (*MongoClient, error) {
+	currentMongoClientMutex.RLock()
+	defer currentMongoClientMutex.RUnlock()
+
 	if currentMongoClient == nil {
 		return nil, errors.New("No current mongo client, please create a new client before requesting it")
 	}
@@ -80,13 +406,145 @@ func getCurrentMongoClient() (*MongoClient, error) {
 	return currentMongoClient, nil
 }
 
-// Get a copy of the session
-func (mc *MongoClient) getSessionCopy() *mgo.Session {
-	return mc.Session.Copy()
+// Set the singleton, the last client created wins
+func setCurrentMongoClient(mc *MongoClient) {
+	currentMongoClientMutex.Lock()
+	defer currentMongoClientMutex.Unlock()
+
+	currentMongoClient = mc
+}
+
+// Let the session read from a secondary when the counts are configured to
+// The counts can then lag behind the writes by the replication delay
+func (mc *MongoClient) countsFromSecondary(s *sessionCopy) *sessionCopy {
+	if mc.config.CountsSecondary {
+		s.SetMode(mgo.SecondaryPreferred, true)
+	}
+	return s
+}
+
+// Get a copy of the session for a lookup, bounded by the query timeout
+func (mc *MongoClient) getSessionCopy() *sessionCopy {
+	return mc.copySession(mc.config.QueryTimeout)
+}
+
+// Get a copy of the session for an aggregation, scan or bulk delete, bounded by the scan timeout
+func (mc *MongoClient) getScanSessionCopy() *sessionCopy {
+	return mc.copySession(mc.config.ScanTimeout)
+}
+
+// Copy the session, a timeout in milliseconds replaces the connection's socket timeout
+func (mc *MongoClient) copySession(timeout int) *sessionCopy {
+	s := &sessionCopy{Session: mc.session().Copy()}
+	if timeout > 0 {
+		s.SetSocketTimeout(time.Duration(timeout) * time.Millisecond)
+	}
+	if mc.config.TrackSessions {
+		s.trackLeak(time.Duration(mc.config.LeakTimeout) * time.Millisecond)
+	}
+	return s
+}
+
+// Copy of the session, closing it stops the leak tracking
+type sessionCopy struct {
+	*mgo.Session
+	closed int32       // Set once closed, read by the leak tracking
+	leak   *time.Timer // Warns about the copy still open after the leak timeout
+}
+
+func (s *sessionCopy) Close() {
+	atomic.StoreInt32(&s.closed, 1)
+	if s.leak != nil {
+		s.leak.Stop()
+	}
+	s.Session.Close()
+}
+
+// Warn about the copy still open after the timeout (0 - disabled), with the stack that copied it.
+// The stack is captured at every copy, so it's only meant for development. The copy isn't closed
+// then since its owner may still be using it. The copy garbage collected without having been closed
+// is still warned about and closed, once the timeout is over
+func (s *sessionCopy) trackLeak(timeout time.Duration) {
+	opened := time.Now()
+	stack := debug.Stack()
+	if timeout > 0 {
+		s.leak = time.AfterFunc(timeout, func() {
+			if atomic.LoadInt32(&s.closed) == 0 {
+				loggingClient.Warn(fmt.Sprintf("Mongo session copy open for more than %v, opened at:\n%s", timeout, stack))
+			}
+		})
+	}
+	runtime.SetFinalizer(s, func(s *sessionCopy) {
+		if atomic.LoadInt32(&s.closed) != 0 {
+			return
+		}
+		loggingClient.Warn(fmt.Sprintf("Mongo session copy leaked, opened %v ago at:\n%s", time.Since(opened), stack))
+		s.Session.Close()
+	})
+}
+
+// Log the operation at warn level when it ran longer than the slow query threshold
+// The query is only formatted for the slow operations
+func (mc *MongoClient) logIfSlow(start time.Time, operation string, q interface{}) {
+	if mc.config.SlowQuery <= 0 {
+		return
+	}
+
+	elapsed := time.Since(start)
+	if elapsed >= time.Duration(mc.config.SlowQuery)*time.Millisecond {
+		loggingClient.Warn(fmt.Sprintf("Slow mongo query: %s %v took %v", operation, q, elapsed))
+	}
+}
+
+// Return whether the error comes from the connection to the database rather than from the document
+func isConnectionError(err error) bool {
+	if err == io.EOF || err == ErrQueryTimeout {
+		return true
+	}
+	_, ok := err.(net.Error)
+	return ok
+}
+
+// Report a socket timeout as ErrQueryTimeout, other errors are returned as is
+func queryError(err error) error {
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return ErrQueryTimeout
+	}
+	return err
 }
 
+// Close the session and stop the idle pinger
+// Safe to call more than once
 func (mc *MongoClient) CloseSession() {
-	mc.Session.Close()
+	mc.closeOnce.Do(func() {
+		if mc.stop != nil {
+			close(mc.stop)
+		}
+	})
+	mc.session().Close()
+}
+
+// Ping the database every interval so pooled connections don't go stale behind NATs and firewalls
+// When the ping fails the master session is refreshed so the broken sockets are dropped
+// instead of failing the next query
+func (mc *MongoClient) pingWhileIdle(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-mc.stop:
+			return
+		case <-ticker.C:
+			s := mc.getSessionCopy()
+			err := s.Ping()
+			s.Close()
+			if err != nil {
+				loggingClient.Warn("Mongo ping failed, refreshing the session: " + err.Error())
+				mc.session().Refresh()
+			}
+		}
+	}
 }
 
 // ******************************* EVENTS **********************************
@@ -102,20 +560,21 @@ func (mc *MongoClient) Events() ([]models.Event, error) {
 // UnexpectedError - failed to add to database
 // NoValueDescriptor - no existing value descriptor for a reading in the event
 func (mc *MongoClient) AddEvent(e *models.Event) (bson.ObjectId, error) {
+	if e == nil {
+		return "", ErrNilEvent
+	}
+	if mc.config.MaxReadings > 0 && len(e.Readings) > mc.config.MaxReadings {
+		return "", ErrEventTooLarge
+	}
+
 	s := mc.getSessionCopy()
 	defer s.Close()
 
-	e.Created = time.Now().UnixNano() / int64(time.Millisecond)
-	e.ID = bson.NewObjectId()
-
 	// Insert readings
-	var ui []interface{}
-	if len(e.Readings) != 0 {
-		for i := range e.Readings {
-			e.Readings[i].Id = bson.NewObjectId()
-			e.Readings[i].Created = e.Created
-			e.Readings[i].Device = e.Device
-			ui = append(ui, e.Readings[i])
+	ui := newEventDocuments(e, time.Now().UnixNano()/int64(time.Millisecond))
+	if len(ui) != 0 {
+		if err := checkEventSize(e); err != nil {
+			return e.ID, err
 		}
 		err := s.DB(mc.Database.Name).C(READINGS_COLLECTION).Insert(ui...)
 		if err != nil {
@@ -135,6 +594,162 @@ func (mc *MongoClient) AddEvent(e *models.Event) (bson.ObjectId, error) {
 	return e.ID, err
 }
 
+// Give the event and its readings their ids and timestamps, and return the documents of the readings
+func newEventDocuments(e *models.Event, created int64) []interface{} {
+	e.Created = created
+	e.Modified = e.Created
+	e.ID = bson.NewObjectId()
+
+	var ui []interface{}
+	for i := range e.Readings {
+		e.Readings[i].Id = bson.NewObjectId()
+		e.Readings[i].Created = e.Created
+		e.Readings[i].Modified = e.Created
+		e.Readings[i].Device = e.Device
+		ui = append(ui, mongoReading{Reading: e.Readings[i], EventId: e.ID})
+	}
+	return ui
+}
+
+// Reject the event when its document or one of its reading documents would come near the BSON limit
+// Mongo would only refuse it with a cryptic error after some of the readings were inserted
+func checkEventSize(e *models.Event) error {
+	// The event only holds references to the readings, so it's small enough to measure
+	doc, err := bson.Marshal(MongoEvent{Event: *e})
+	if err != nil {
+		return err
+	}
+	if len(doc) > maxEventDocumentSize {
+		return ErrEventTooLarge
+	}
+
+	// The strings dominate the size of a reading, the other fields take a few hundred bytes at most
+	for _, r := range e.Readings {
+		if len(r.Device)+len(r.Name)+len(r.Value)+readingFieldsSize > maxEventDocumentSize {
+			return ErrEventTooLarge
+		}
+	}
+	return nil
+}
+
+// Add a batch of events with one bulk insert for the readings and one for the events
+// An event that is too large or whose documents are refused is reported in its result, the others are still added
+// The readings already inserted for a refused event are removed
+func (mc *MongoClient) AddEvents(events []models.Event) ([]AddEventResult, error) {
+	results := make([]AddEventResult, len(events))
+	created := time.Now().UnixNano() / int64(time.Millisecond)
+
+	var readings []interface{}
+	var readingEvents []int // Position in the batch of the event of each reading
+	for i := range events {
+		results[i].Index = i
+		e := &events[i]
+		if mc.config.MaxReadings > 0 && len(e.Readings) > mc.config.MaxReadings {
+			results[i].Err = ErrEventTooLarge
+			continue
+		}
+
+		ui := newEventDocuments(e, created)
+		if err := checkEventSize(e); err != nil {
+			results[i].Err = err
+			continue
+		}
+		readings = append(readings, ui...)
+		for range ui {
+			readingEvents = append(readingEvents, i)
+		}
+	}
+
+	s := mc.getSessionCopy()
+	defer s.Close()
+	col := s.DB(mc.Database.Name).C(READINGS_COLLECTION)
+
+	if len(readings) != 0 {
+		bulk := col.Bulk()
+		bulk.Unordered()
+		bulk.Insert(readings...)
+		refused, err := runBulk(bulk)
+		if err != nil {
+			return failPending(results, err), err
+		}
+		for index, err := range refused {
+			results[readingEvents[index]].Err = err
+		}
+	}
+
+	var docs []interface{}
+	var docEvents []int // Position in the batch of each event document
+	for i := range events {
+		if results[i].Err == nil {
+			docs = append(docs, MongoEvent{Event: events[i]})
+			docEvents = append(docEvents, i)
+		}
+	}
+	if len(docs) != 0 {
+		bulk := s.DB(mc.Database.Name).C(EVENTS_COLLECTION).Bulk()
+		bulk.Unordered()
+		bulk.Insert(docs...)
+		refused, err := runBulk(bulk)
+		if err != nil {
+			return failPending(results, err), err
+		}
+		for index, err := range refused {
+			results[docEvents[index]].Err = err
+		}
+	}
+
+	// Don't leave the readings of the refused events behind
+	var refusedIds []bson.ObjectId
+	for i := range events {
+		if results[i].Err == nil {
+			results[i].ID = events[i].ID
+		} else if events[i].ID != "" {
+			refusedIds = append(refusedIds, events[i].ID)
+		}
+	}
+	if len(refusedIds) != 0 {
+		if _, err := col.RemoveAll(bson.M{"eventId": bson.M{"$in": refusedIds}}); err != nil {
+			loggingClient.Error("Error removing the readings of the refused events: " + err.Error())
+		}
+	}
+
+	return results, nil
+}
+
+// Run the bulk and return the errors of the documents it refused by their position in the bulk
+// A failure that isn't about a document, like a lost connection, is returned as the error
+func runBulk(bulk *mgo.Bulk) (map[int]error, error) {
+	_, err := bulk.Run()
+	if err == nil {
+		return nil, nil
+	}
+	bulkErr, ok := err.(*mgo.BulkError)
+	if !ok {
+		return nil, queryError(err)
+	}
+
+	refused := map[int]error{}
+	for _, c := range bulkErr.Cases() {
+		if c.Index < 0 || isConnectionError(c.Err) {
+			return nil, queryError(c.Err)
+		}
+		refused[c.Index] = c.Err
+	}
+	return refused, nil
+}
+
+// Add a new event and return it as stored, with the generated ids and timestamps of the event and its readings
+// Saves reading the event back after AddEvent
+func (mc *MongoClient) AddEventReturning(e *models.Event) (models.Event, error) {
+	if _, err := mc.AddEvent(e); err != nil {
+		return models.Event{}, err
+	}
+
+	added := *e
+	added.Readings = append([]models.Reading{}, e.Readings...)
+	return added, nil
+}
+
 // Update an event - do NOT update readings
 // UnexpectedError - problem updating in database
 // NotFound - no event with the ID was found
@@ -157,29 +772,99 @@ func (mc *MongoClient) UpdateEvent(e models.Event) error {
 
 // Get an event by id
 func (mc *MongoClient) EventById(id string) (models.Event, error) {
+	return mc.eventById(id, true)
+}
+
+// Get an event by id without de-referencing its readings
+// The event is returned with an empty list of readings
+func (mc *MongoClient) EventByIdMetadata(id string) (models.Event, error) {
+	return mc.eventById(id, false)
+}
+
+// Get an event by id, the readings are only de-referenced if includeReadings is set
+func (mc *MongoClient) eventById(id string, includeReadings bool) (models.Event, error) {
 	if !bson.IsObjectIdHex(id) {
 		return models.Event{}, ErrInvalidObjectId
 	}
-	return mc.getEvent(bson.M{"_id": bson.ObjectIdHex(id)})
+
+	query := bson.M{"_id": bson.ObjectIdHex(id)}
+	if includeReadings {
+		return mc.getEvent(query)
+	}
+
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	// Leaving the readings out of the projection skips the DBRef lookups
+	var me MongoEvent
+	err := s.DB(mc.Database.Name).C(EVENTS_COLLECTION).Find(query).Select(bson.M{"readings": 0}).One(&me)
+	if err == mgo.ErrNotFound {
+		return me.Event, ErrNotFound
+	}
+	me.Readings = []models.Reading{}
+
+	return me.Event, err
+}
+
+// Return whether an event exists for the id, without de-referencing its readings
+func (mc *MongoClient) EventExists(id string) (bool, error) {
+	return mc.exists(EVENTS_COLLECTION, id)
 }
 
 // Get the number of events in Mongo
 func (mc *MongoClient) EventCount() (int, error) {
-	s := mc.getSessionCopy()
+	s := mc.countsFromSecondary(mc.getSessionCopy())
 	defer s.Close()
 
 	return s.DB(mc.Database.Name).C(EVENTS_COLLECTION).Find(nil).Count()
 }
 
+// Get the approximate number of events in Mongo from the collection metadata
+// It doesn't scan the collection so it stays fast on huge collections, but it can be off after an
+// unclean shutdown or while documents are being added or removed. Use EventCount when it has to be exact
+func (mc *MongoClient) EstimatedEventCount() (int, error) {
+	return mc.estimatedCount(EVENTS_COLLECTION)
+}
+
 // Get the number of events in Mongo for the device
 func (mc *MongoClient) EventCountByDeviceId(id string) (int, error) {
-	s := mc.getSessionCopy()
+	if err := validateDeviceId(id); err != nil {
+		return 0, err
+	}
+
+	s := mc.countsFromSecondary(mc.getSessionCopy())
 	defer s.Close()
 
 	query := bson.M{"device": id}
 	return s.DB(mc.Database.Name).C(EVENTS_COLLECTION).Find(query).Count()
 }
 
+// Get the number of events with at least one reading of the value descriptor
+// Shows how many events deleting the value descriptor's readings would affect
+// The readings are grouped by their eventId, so it misses the readings stored before it until
+// BackfillReadingEventIds has run
+func (mc *MongoClient) EventCountByReadingValueDescriptor(name string) (int, error) {
+	s := mc.countsFromSecondary(mc.getScanSessionCopy())
+	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "EventCountByReadingValueDescriptor", name)
+
+	pipeline := []bson.M{
+		{"$match": bson.M{"name": name, "eventId": bson.M{"$exists": true}}},
+		{"$group": bson.M{"_id": "$eventId"}},
+		{"$group": bson.M{"_id": nil, "count": bson.M{"$sum": 1}}},
+	}
+
+	var counts []struct {
+		Count int `bson:"count"`
+	}
+	// A busy value descriptor can outgrow the in memory limit of the group stage
+	err := s.DB(mc.Database.Name).C(READINGS_COLLECTION).Pipe(pipeline).AllowDiskUse().All(&counts)
+	if err != nil || len(counts) == 0 {
+		return 0, queryError(err)
+	}
+	return counts[0].Count, nil
+}
+
 // Delete an event by ID and all of its readings
 // 404 - Event not found
 // 503 - Unexpected problems
@@ -187,19 +872,91 @@ func (mc *MongoClient) DeleteEventById(id string) error {
 	return mc.deleteById(id, EVENTS_COLLECTION)
 }
 
+// Get the events for the list of ids
+// An empty list returns no events without querying the database
+func (mc *MongoClient) EventsByIds(ids []string) ([]models.Event, error) {
+	if len(ids) == 0 {
+		return []models.Event{}, nil
+	}
+
+	objectIds := make([]bson.ObjectId, len(ids))
+	for i, id := range ids {
+		if !bson.IsObjectIdHex(id) {
+			return []models.Event{}, ErrInvalidObjectId
+		}
+		objectIds[i] = bson.ObjectIdHex(id)
+	}
+
+	return mc.getEvents(bson.M{"_id": bson.M{"$in": objectIds}})
+}
+
 // Get a list of events based on the device id and limit
 func (mc *MongoClient) EventsForDeviceLimit(id string, limit int) ([]models.Event, error) {
+	if err := validateDeviceId(id); err != nil {
+		return []models.Event{}, err
+	}
+
 	return mc.getEventsLimit(bson.M{"device": id}, limit)
 }
 
 // Get a list of events based on the device id
 func (mc *MongoClient) EventsForDevice(id string) ([]models.Event, error) {
+	if err := validateDeviceId(id); err != nil {
+		return []models.Event{}, err
+	}
+
 	return mc.getEvents(bson.M{"device": id})
 }
 
-// Return a list of events whos creation time is between startTime and endTime
+// Return a page of the device's events sorted by sortField, which is one of created, origin or modified
+// Ties are broken by the ObjectId in the same direction so the pages don't overlap
+// ErrInvalidSortField - sortField isn't one of the allowed fields
+func (mc *MongoClient) EventsForDevicePagedSorted(id string, sortField string, asc bool, skip, limit int) ([]models.Event, error) {
+	events := []models.Event{}
+	if err := validateDeviceId(id); err != nil {
+		return events, err
+	}
+	if !eventSortFields[sortField] {
+		return events, ErrInvalidSortField
+	}
+	if skip < 0 {
+		return events, ErrInvalidPage
+	}
+	if err := validateLimit(limit); err != nil {
+		return events, err
+	}
+	if limit == 0 {
+		return events, nil
+	}
+
+	sort := []string{"-" + sortField, "-_id"}
+	if asc {
+		sort = []string{sortField, "_id"}
+	}
+
+	s := mc.getSessionCopy()
+	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "EventsForDevicePagedSorted", id)
+
+	var me []MongoEvent
+	err := s.DB(mc.Database.Name).C(EVENTS_COLLECTION).Find(bson.M{"device": id}).Sort(sort...).Skip(skip).Limit(limit).All(&me)
+	if err != nil {
+		return events, queryError(err)
+	}
+
+	for _, e := range me {
+		events = append(events, e.Event)
+	}
+	return events, nil
+}
+
+// Return a list of events whos creation time is between startTime and endTime
 // Limit the number of results by limit
 func (mc *MongoClient) EventsByCreationTime(startTime, endTime int64, limit int) ([]models.Event, error) {
+	if err := validateTimeRange(startTime, endTime); err != nil {
+		return []models.Event{}, err
+	}
+
 	query := bson.M{"created": bson.M{
 		"$gte": startTime,
 		"$lte": endTime,
@@ -207,28 +964,432 @@ func (mc *MongoClient) EventsByCreationTime(startTime, endTime int64, limit int)
 	return mc.getEventsLimit(query, limit)
 }
 
+// Return the events of any of the devices whos creation time is between startTime and endTime, newest first
+// Limit the number of results by limit
+// An empty list of devices returns no events without querying the database
+func (mc *MongoClient) EventsByDevicesAndCreationTime(deviceIds []string, startTime, endTime int64, limit int) ([]models.Event, error) {
+	if len(deviceIds) == 0 {
+		return []models.Event{}, nil
+	}
+	for _, id := range deviceIds {
+		if err := validateDeviceId(id); err != nil {
+			return []models.Event{}, err
+		}
+	}
+	if err := validateTimeRange(startTime, endTime); err != nil {
+		return []models.Event{}, err
+	}
+
+	query := bson.M{
+		"device": bson.M{"$in": deviceIds},
+		"created": bson.M{
+			"$gte": startTime,
+			"$lte": endTime,
+		},
+	}
+	return mc.getEventsLimit(query, limit)
+}
+
 // Get Events that are older than the given age (defined by age = now - created)
 func (mc *MongoClient) EventsOlderThanAge(age int64) ([]models.Event, error) {
 	expireDate := (time.Now().UnixNano() / int64(time.Millisecond)) - age
 	return mc.getEvents(bson.M{"created": bson.M{"$lt": expireDate}})
 }
 
+// Return the events that have a reading for the value descriptor, limited by limit
+// The readings of the returned events are trimmed to the ones for the value descriptor
+// The events are found from the eventId of the readings, see readingEventIds
+func (mc *MongoClient) EventsFilteredByValueDescriptor(name string, limit int) ([]models.Event, error) {
+	if err := validateLimit(limit); err != nil {
+		return []models.Event{}, err
+	}
+	if limit == 0 {
+		return []models.Event{}, nil
+	}
+
+	eventIds, err := mc.readingEventIds(bson.M{"name": name}, limit)
+	if err != nil || len(eventIds) == 0 {
+		return []models.Event{}, err
+	}
+
+	events, err := mc.getEventsLimit(bson.M{"_id": bson.M{"$in": eventIds}}, limit)
+	if err != nil {
+		return events, err
+	}
+
+	for i := range events {
+		readings := []models.Reading{}
+		for _, r := range events[i].Readings {
+			if r.Name == name {
+				readings = append(readings, r)
+			}
+		}
+		events[i].Readings = readings
+	}
+
+	return events, nil
+}
+
+// Return the events that have a reading whose value descriptor carries the label, limited by limit
+// An empty list is returned when no value descriptor carries the label
+// The events are found from the eventId of the readings, see readingEventIds
+func (mc *MongoClient) EventsByReadingLabel(label string, limit int) ([]models.Event, error) {
+	if err := validateLimit(limit); err != nil {
+		return []models.Event{}, err
+	}
+	if limit == 0 {
+		return []models.Event{}, nil
+	}
+
+	vds, err := mc.ValueDescriptorsByLabel(label)
+	if err != nil || len(vds) == 0 {
+		return []models.Event{}, err
+	}
+	names := make([]string, len(vds))
+	for i, v := range vds {
+		names[i] = v.Name
+	}
+
+	eventIds, err := mc.readingEventIds(bson.M{"name": bson.M{"$in": names}}, limit)
+	if err != nil || len(eventIds) == 0 {
+		return []models.Event{}, err
+	}
+
+	return mc.getEventsLimit(bson.M{"_id": bson.M{"$in": eventIds}}, limit)
+}
+
+// Return the events matching the query with their readings, newest first, limited by limit
+// The readings are joined with a $lookup in the same aggregation instead of one query per reading.
+// Servers older than MongoDB 3.4.4 lack the stages used to read the DBRefs, the readings are then
+// de-referenced like in the other event queries
+func (mc *MongoClient) EventsWithReadings(q bson.M, limit int) ([]models.Event, error) {
+	if !mc.lookupSupported() {
+		return mc.getEventsLimit(q, limit)
+	}
+
+	events := []models.Event{}
+	if err := validateLimit(limit); err != nil {
+		return events, err
+	}
+	if limit == 0 {
+		return events, nil
+	}
+
+	s := mc.getScanSessionCopy()
+	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "EventsWithReadings", q)
+
+	pipeline := []bson.M{
+		{"$match": q},
+		{"$sort": bson.D{{Name: "created", Value: -1}, {Name: "_id", Value: -1}}},
+		{"$limit": limit},
+		// A DBRef is {$ref, $id} and "$id" can't be used in a field path, so take the second value
+		{"$addFields": bson.M{"readingIds": bson.M{"$map": bson.M{
+			"input": "$readings",
+			"as":    "ref",
+			"in":    bson.M{"$arrayElemAt": []interface{}{bson.M{"$objectToArray": "$$ref"}, 1}},
+		}}}},
+		{"$addFields": bson.M{"readingIds": "$readingIds.v"}},
+		{"$lookup": bson.M{
+			"from":         READINGS_COLLECTION,
+			"localField":   "readingIds",
+			"foreignField": "_id",
+			"as":           "readings",
+		}},
+	}
+
+	var docs []struct {
+		models.Event `bson:",inline"`
+		ReadingIds   []bson.ObjectId `bson:"readingIds"`
+	}
+	err := s.DB(mc.Database.Name).C(EVENTS_COLLECTION).Pipe(pipeline).All(&docs)
+	if err != nil {
+		return events, queryError(err)
+	}
+
+	for _, doc := range docs {
+		// $lookup doesn't keep the order of the references
+		byId := make(map[bson.ObjectId]models.Reading, len(doc.Readings))
+		for _, r := range doc.Readings {
+			byId[r.Id] = r
+		}
+		e := doc.Event
+		e.Readings = make([]models.Reading, 0, len(doc.ReadingIds))
+		for _, id := range doc.ReadingIds {
+			if r, ok := byId[id]; ok {
+				e.Readings = append(e.Readings, r)
+			}
+		}
+		events = append(events, e)
+	}
+
+	return events, nil
+}
+
+// Return whether the server supports the aggregation of EventsWithReadings, checked once
+func (mc *MongoClient) lookupSupported() bool {
+	mc.lookup.Do(func() {
+		info, err := mc.session().BuildInfo()
+		if err != nil {
+			loggingClient.Warn("Error getting the mongo version, readings are de-referenced one by one: " + err.Error())
+			return
+		}
+		mc.lookup.supported = info.VersionAtLeast(3, 4, 4)
+	})
+	return mc.lookup.supported
+}
+
+// Return the newest events of all devices, newest first, limited by limit
+// Served by the created index, so no time range is needed to keep it cheap
+func (mc *MongoClient) LatestEvents(limit int) ([]models.Event, error) {
+	return mc.getEventsLimit(bson.M{}, limit)
+}
+
+// Return the events whose origin and creation time are further apart than the threshold in milliseconds,
+// newest first, limited by limit. Finds the devices with misconfigured clocks, the events without an
+// origin are skipped
+func (mc *MongoClient) EventsWithClockSkew(thresholdMs int64, limit int) ([]models.Event, error) {
+	events := []models.Event{}
+	if err := validateLimit(limit); err != nil {
+		return events, err
+	}
+	if limit == 0 {
+		return events, nil
+	}
+
+	s := mc.getScanSessionCopy()
+	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "EventsWithClockSkew", thresholdMs)
+
+	pipeline := []bson.M{
+		{"$match": bson.M{"origin": bson.M{"$ne": int64(0)}}},
+		{"$addFields": bson.M{"clockSkew": bson.M{"$abs": bson.M{"$subtract": []string{"$created", "$origin"}}}}},
+		{"$match": bson.M{"clockSkew": bson.M{"$gt": thresholdMs}}},
+		{"$sort": bson.D{{Name: "created", Value: -1}, {Name: "_id", Value: -1}}},
+		{"$limit": limit},
+		{"$project": bson.M{"clockSkew": 0}},
+	}
+
+	// Handle DBRefs
+	var me []MongoEvent
+	err := s.DB(mc.Database.Name).C(EVENTS_COLLECTION).Pipe(pipeline).All(&me)
+	if err != nil {
+		return events, queryError(err)
+	}
+	for _, e := range me {
+		events = append(events, e.Event)
+	}
+
+	return events, nil
+}
+
 // Get all of the events that have been pushed
 func (mc *MongoClient) EventsPushed() ([]models.Event, error) {
 	return mc.getEvents(bson.M{"pushed": bson.M{"$gt": int64(0)}})
 }
 
-// Delete all of the readings and all of the events
-func (mc *MongoClient) ScrubAllEvents() error {
+// Delete at most limit of the pushed events along with their readings, in a single batch
+func (mc *MongoClient) DeleteEventsPushed(limit int) (int, error) {
+	if err := validateLimit(limit); err != nil {
+		return 0, err
+	}
+	if limit == 0 {
+		return 0, nil
+	}
+
+	s := mc.getScanSessionCopy()
+	defer s.Close()
+	q := bson.M{"pushed": bson.M{"$gt": int64(0)}}
+	defer mc.logIfSlow(time.Now(), "DeleteEventsPushed", q)
+
+	return mc.deleteEventBatch(s, q, limit)
+}
+
+// Return the events pushed between start and end, in the order they were pushed
+// Limit the number of results by limit
+func (mc *MongoClient) EventsPushedBetween(start, end int64, limit int) ([]models.Event, error) {
+	events := []models.Event{}
+	if err := validateTimeRange(start, end); err != nil {
+		return events, err
+	}
+	if err := validateLimit(limit); err != nil {
+		return events, err
+	}
+	if limit == 0 {
+		return events, nil
+	}
+
 	s := mc.getSessionCopy()
 	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "EventsPushedBetween", start)
+
+	query := bson.M{"pushed": bson.M{
+		"$gte": start,
+		"$lte": end,
+	}}
+	var me []MongoEvent
+	err := s.DB(mc.Database.Name).C(EVENTS_COLLECTION).Find(query).Sort("pushed", "_id").Limit(limit).All(&me)
+	if err != nil {
+		return events, queryError(err)
+	}
+
+	for _, e := range me {
+		events = append(events, e.Event)
+	}
+	return events, nil
+}
+
+// Delete the events that are older than the given age (defined by age = now - created) and their readings
+// The events are removed in batches of BatchDeleteSize
+// Return the number of events removed
+func (mc *MongoClient) DeleteEventsOlderThanAge(age int64) (int, error) {
+	expireDate := (time.Now().UnixNano() / int64(time.Millisecond)) - age
+	return mc.deleteEventsInBatches(bson.M{"created": bson.M{"$lt": expireDate}})
+}
+
+// Delete the readings of the events older than the age but keep the events, one batch at a time
+// The events are left without readings, so EventById and the other event queries return them with
+// an empty list of readings. Return the number of readings removed
+func (mc *MongoClient) TrimReadingsForEventsOlderThan(age int64) (int, error) {
+	s := mc.getScanSessionCopy()
+	defer s.Close()
+
+	expireDate := (time.Now().UnixNano() / int64(time.Millisecond)) - age
+	defer mc.logIfSlow(time.Now(), "TrimReadingsForEventsOlderThan", expireDate)
+
+	events := s.DB(mc.Database.Name).C(EVENTS_COLLECTION)
+	readings := s.DB(mc.Database.Name).C(READINGS_COLLECTION)
+	query := bson.M{"created": bson.M{"$lt": expireDate}, "readings.0": bson.M{"$exists": true}}
+	removed := 0
+	for {
+		// Only pull the references, the readings are not de-referenced
+		var batch []struct {
+			Id       bson.ObjectId `bson:"_id"`
+			Readings []mgo.DBRef   `bson:"readings"`
+		}
+		err := events.Find(query).Select(bson.M{"_id": 1, "readings": 1}).Limit(mc.batchDeleteSize()).All(&batch)
+		if err != nil {
+			return removed, queryError(err)
+		}
+		if len(batch) == 0 {
+			return removed, nil
+		}
+
+		var eventIds, readingIds []interface{}
+		for _, e := range batch {
+			eventIds = append(eventIds, e.Id)
+			for _, rRef := range e.Readings {
+				readingIds = append(readingIds, rRef.Id)
+			}
+		}
+
+		info, err := readings.RemoveAll(bson.M{"_id": bson.M{"$in": readingIds}})
+		if err != nil {
+			return removed, queryError(err)
+		}
+		removed += info.Removed
+
+		trim := bson.M{"$set": bson.M{"readings": []mgo.DBRef{}}}
+		if _, err = events.UpdateAll(bson.M{"_id": bson.M{"$in": eventIds}}, trim); err != nil {
+			return removed, queryError(err)
+		}
+	}
+}
+
+// Set the eventId back-reference on the readings stored before AddEvent wrote it
+// The events are walked by id in batches, their readings are matched by device and creation time
+// within the readings the event references. Readings that have an eventId are left alone so the
+// migration can be re-run. Return the number of readings updated
+func (mc *MongoClient) BackfillReadingEventIds() (int, error) {
+	s := mc.getScanSessionCopy()
+	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "BackfillReadingEventIds", nil)
+
+	events := s.DB(mc.Database.Name).C(EVENTS_COLLECTION)
+	readings := s.DB(mc.Database.Name).C(READINGS_COLLECTION)
+	query := bson.M{"readings.0": bson.M{"$exists": true}}
+	updated, scanned := 0, 0
+	for {
+		// Only pull the references, the readings are not de-referenced
+		var batch []struct {
+			Id       bson.ObjectId `bson:"_id"`
+			Device   string        `bson:"device"`
+			Created  int64         `bson:"created"`
+			Readings []mgo.DBRef   `bson:"readings"`
+		}
+		err := events.Find(query).Select(bson.M{"_id": 1, "device": 1, "created": 1, "readings": 1}).Sort("_id").Limit(mc.batchDeleteSize()).All(&batch)
+		if err != nil {
+			return updated, queryError(err)
+		}
+		if len(batch) == 0 {
+			loggingClient.Info(fmt.Sprintf("Backfilled the event id of %d readings from %d events", updated, scanned))
+			return updated, nil
+		}
+
+		for _, e := range batch {
+			var readingIds []interface{}
+			for _, rRef := range e.Readings {
+				readingIds = append(readingIds, rRef.Id)
+			}
+			match := bson.M{
+				"_id":     bson.M{"$in": readingIds},
+				"device":  e.Device,
+				"created": e.Created,
+				"eventId": bson.M{"$exists": false},
+			}
+			info, err := readings.UpdateAll(match, bson.M{"$set": bson.M{"eventId": e.Id}})
+			if err != nil {
+				return updated, queryError(err)
+			}
+			updated += info.Updated
+		}
+
+		scanned += len(batch)
+		query["_id"] = bson.M{"$gt": batch[len(batch)-1].Id}
+		loggingClient.Info(fmt.Sprintf("Backfilling reading event ids: %d events scanned, %d readings updated", scanned, updated))
+	}
+}
+
+// Return the number of events DeleteEventsOlderThanAge would remove without removing them
+func (mc *MongoClient) DeleteEventsOlderThanAgeDryRun(age int64) (int, error) {
+	s := mc.getScanSessionCopy()
+	defer s.Close()
 
-	_, err := s.DB(mc.Database.Name).C(READINGS_COLLECTION).RemoveAll(nil)
+	expireDate := (time.Now().UnixNano() / int64(time.Millisecond)) - age
+	query := bson.M{"created": bson.M{"$lt": expireDate}}
+	count, err := s.DB(mc.Database.Name).C(EVENTS_COLLECTION).Find(query).Count()
+	return count, queryError(err)
+}
+
+// Return the number of events and readings ScrubAllEvents would remove without removing them
+func (mc *MongoClient) ScrubAllEventsDryRun() (int, int, error) {
+	s := mc.getScanSessionCopy()
+	defer s.Close()
+
+	events, err := s.DB(mc.Database.Name).C(EVENTS_COLLECTION).Find(nil).Count()
+	if err != nil {
+		return 0, 0, queryError(err)
+	}
+	readings, err := s.DB(mc.Database.Name).C(READINGS_COLLECTION).Find(nil).Count()
+	if err != nil {
+		return 0, 0, queryError(err)
+	}
+
+	return events, readings, nil
+}
+
+// Delete all of the readings and all of the events
+func (mc *MongoClient) ScrubAllEvents() error {
+	if !mc.config.AllowScrub {
+		return ErrScrubNotAllowed
+	}
+
+	_, err := mc.removeInBatches(READINGS_COLLECTION, nil)
 	if err != nil {
 		return err
 	}
 
-	_, err = s.DB(mc.Database.Name).C(EVENTS_COLLECTION).RemoveAll(nil)
+	_, err = mc.removeInBatches(EVENTS_COLLECTION, nil)
 	if err != nil {
 		return err
 	}
@@ -236,17 +1397,74 @@ func (mc *MongoClient) ScrubAllEvents() error {
 	return nil
 }
 
+// Delete the events matching the query along with their readings, one batch at a time
+// Return the number of events removed
+func (mc *MongoClient) deleteEventsInBatches(q bson.M) (int, error) {
+	s := mc.getScanSessionCopy()
+	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "deleteEventsInBatches", q)
+
+	removed := 0
+	for {
+		n, err := mc.deleteEventBatch(s, q, mc.batchDeleteSize())
+		removed += n
+		if err != nil || n == 0 {
+			return removed, err
+		}
+	}
+}
+
+// Delete at most limit of the events matching the query along with their readings
+// Return the number of events removed
+func (mc *MongoClient) deleteEventBatch(s *sessionCopy, q bson.M, limit int) (int, error) {
+	events := s.DB(mc.Database.Name).C(EVENTS_COLLECTION)
+	readings := s.DB(mc.Database.Name).C(READINGS_COLLECTION)
+
+	// Only pull the IDs, the readings are not de-referenced
+	var batch []struct {
+		Id       bson.ObjectId `bson:"_id"`
+		Readings []mgo.DBRef   `bson:"readings"`
+	}
+	err := events.Find(q).Select(bson.M{"_id": 1, "readings": 1}).Limit(limit).All(&batch)
+	if err != nil {
+		return 0, queryError(err)
+	}
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	var eventIds, readingIds []interface{}
+	for _, e := range batch {
+		eventIds = append(eventIds, e.Id)
+		for _, rRef := range e.Readings {
+			readingIds = append(readingIds, rRef.Id)
+		}
+	}
+
+	if len(readingIds) > 0 {
+		if _, err = readings.RemoveAll(bson.M{"_id": bson.M{"$in": readingIds}}); err != nil {
+			return 0, queryError(err)
+		}
+	}
+	info, err := events.RemoveAll(bson.M{"_id": bson.M{"$in": eventIds}})
+	if err != nil {
+		return 0, queryError(err)
+	}
+	return info.Removed, nil
+}
+
 // Get events for the passed query
 func (mc *MongoClient) getEvents(q bson.M) ([]models.Event, error) {
 	s := mc.getSessionCopy()
 	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "getEvents", q)
 
 	// Handle DBRefs
 	var me []MongoEvent
 	events := []models.Event{}
-	err := s.DB(mc.Database.Name).C(EVENTS_COLLECTION).Find(q).All(&me)
+	err := s.DB(mc.Database.Name).C(EVENTS_COLLECTION).Find(q).Sort(newestFirst...).All(&me)
 	if err != nil {
-		return events, err
+		return events, queryError(err)
 	}
 
 	// Append all the events
@@ -261,19 +1479,23 @@ func (mc *MongoClient) getEvents(q bson.M) ([]models.Event, error) {
 func (mc *MongoClient) getEventsLimit(q bson.M, limit int) ([]models.Event, error) {
 	s := mc.getSessionCopy()
 	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "getEventsLimit", q)
 
 	// Handle DBRefs
 	var me []MongoEvent
 	events := []models.Event{}
 
 	// Check if limit is 0
+	if err := validateLimit(limit); err != nil {
+		return events, err
+	}
 	if limit == 0 {
 		return events, nil
 	}
 
-	err := s.DB(mc.Database.Name).C(EVENTS_COLLECTION).Find(q).Limit(limit).All(&me)
+	err := s.DB(mc.Database.Name).C(EVENTS_COLLECTION).Find(q).Sort(newestFirst...).Limit(limit).All(&me)
 	if err != nil {
-		return events, err
+		return events, queryError(err)
 	}
 
 	// Append all the events
@@ -288,6 +1510,7 @@ func (mc *MongoClient) getEventsLimit(q bson.M, limit int) ([]models.Event, erro
 func (mc *MongoClient) getEvent(q bson.M) (models.Event, error) {
 	s := mc.getSessionCopy()
 	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "getEvent", q)
 
 	// Handle DBRef
 	var me MongoEvent
@@ -296,24 +1519,29 @@ func (mc *MongoClient) getEvent(q bson.M) (models.Event, error) {
 		return me.Event, ErrNotFound
 	}
 
-	return me.Event, err
+	return me.Event, queryError(err)
 }
 
 // ************************ READINGS ************************************8
 
-// Return a list of readings sorted by reading id
+// Return a list of readings, newest first
 func (mc *MongoClient) Readings() ([]models.Reading, error) {
 	return mc.getReadings(nil)
 }
 
 // Post a new reading
 func (mc *MongoClient) AddReading(r models.Reading) (bson.ObjectId, error) {
+	if err := validateReading(r); err != nil {
+		return "", err
+	}
+
 	s := mc.getSessionCopy()
 	defer s.Close()
 
 	// Get the reading ready
 	r.Id = bson.NewObjectId()
 	r.Created = time.Now().UnixNano() / int64(time.Millisecond)
+	r.Modified = r.Created
 
 	err := s.DB(mc.Database.Name).C(READINGS_COLLECTION).Insert(&r)
 	return r.Id, err
@@ -329,51 +1557,281 @@ func (mc *MongoClient) UpdateReading(r models.Reading) error {
 
 	r.Modified = time.Now().UnixNano() / int64(time.Millisecond)
 
-	// Update the reading
-	err := s.DB(mc.Database.Name).C(READINGS_COLLECTION).UpdateId(r.Id, r)
-	if err == mgo.ErrNotFound {
-		return ErrNotFound
+	// Update the reading, $set keeps the fields the model doesn't carry like eventId
+	update := bson.M{"$set": bson.M{
+		"pushed":   r.Pushed,
+		"created":  r.Created,
+		"origin":   r.Origin,
+		"modified": r.Modified,
+		"device":   r.Device,
+		"name":     r.Name,
+		"value":    r.Value,
+	}}
+	err := s.DB(mc.Database.Name).C(READINGS_COLLECTION).UpdateId(r.Id, update)
+	if err == mgo.ErrNotFound {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+// Get a reading by ID
+func (mc *MongoClient) ReadingById(id string) (models.Reading, error) {
+	// Check if the id is a id hex
+	if !bson.IsObjectIdHex(id) {
+		return models.Reading{}, ErrInvalidObjectId
+	}
+
+	query := bson.M{"_id": bson.ObjectIdHex(id)}
+
+	return mc.getReading(query)
+}
+
+// Return whether a reading exists for the id
+func (mc *MongoClient) ReadingExists(id string) (bool, error) {
+	return mc.exists(READINGS_COLLECTION, id)
+}
+
+// Get the count of readings in Mongo
+func (mc *MongoClient) ReadingCount() (int, error) {
+	s := mc.countsFromSecondary(mc.getSessionCopy())
+	defer s.Close()
+
+	return s.DB(mc.Database.Name).C(READINGS_COLLECTION).Find(bson.M{}).Count()
+}
+
+// Get the count of readings in Mongo for the value descriptor
+func (mc *MongoClient) ReadingCountByValueDescriptor(name string) (int, error) {
+	s := mc.countsFromSecondary(mc.getSessionCopy())
+	defer s.Close()
+
+	query := bson.M{"name": name}
+	return s.DB(mc.Database.Name).C(READINGS_COLLECTION).Find(query).Count()
+}
+
+// Get the count of readings in Mongo for every value descriptor
+// The map is keyed by value descriptor name
+func (mc *MongoClient) ReadingCountsGrouped() (map[string]int, error) {
+	s := mc.countsFromSecondary(mc.getScanSessionCopy())
+	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "ReadingCountsGrouped", nil)
+
+	pipeline := []bson.M{
+		{"$group": bson.M{"_id": "$name", "count": bson.M{"$sum": 1}}},
+	}
+
+	var groups []struct {
+		Name  string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+	counts := map[string]int{}
+	err := s.DB(mc.Database.Name).C(READINGS_COLLECTION).Pipe(pipeline).All(&groups)
+	if err != nil {
+		return counts, queryError(err)
+	}
+
+	for _, g := range groups {
+		counts[g.Name] = g.Count
+	}
+
+	return counts, nil
+}
+
+// Return the newest readings of the device grouped by value descriptor name
+// Each group holds at most limitPerGroup readings, newest first
+// A device without readings returns an empty map
+func (mc *MongoClient) ReadingsByDeviceGrouped(deviceId string, limitPerGroup int) (map[string][]models.Reading, error) {
+	groups := map[string][]models.Reading{}
+	if err := validateDeviceId(deviceId); err != nil {
+		return groups, err
+	}
+	if err := validateLimit(limitPerGroup); err != nil {
+		return groups, err
+	}
+	if limitPerGroup == 0 {
+		return groups, nil
+	}
+
+	s := mc.getScanSessionCopy()
+	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "ReadingsByDeviceGrouped", deviceId)
+
+	pipeline := []bson.M{
+		{"$match": bson.M{"device": deviceId}},
+		{"$sort": bson.D{{Name: "created", Value: -1}, {Name: "_id", Value: -1}}},
+		{"$group": bson.M{"_id": "$name", "readings": bson.M{"$push": "$$ROOT"}}},
+		{"$project": bson.M{"readings": bson.M{"$slice": []interface{}{"$readings", limitPerGroup}}}},
+	}
+
+	var results []struct {
+		Name     string           `bson:"_id"`
+		Readings []models.Reading `bson:"readings"`
+	}
+	// A chatty device can outgrow the in memory limit of the group stage
+	err := s.DB(mc.Database.Name).C(READINGS_COLLECTION).Pipe(pipeline).AllowDiskUse().All(&results)
+	if err != nil {
+		return groups, queryError(err)
+	}
+
+	for _, r := range results {
+		groups[r.Name] = r.Readings
+	}
+
+	return groups, nil
+}
+
+// Delete a reading by ID
+// 404 - can't find the reading with the given id
+func (mc *MongoClient) DeleteReadingById(id string) error {
+	// Check if the id is a bson id
+	if !bson.IsObjectIdHex(id) {
+		return ErrInvalidObjectId
+	}
+
+	return mc.deleteById(id, READINGS_COLLECTION)
+}
+
+// Return a list of readings for the given device (id or name)
+// Sort the list of readings on creation date
+func (mc *MongoClient) ReadingsByDevice(id string, limit int) ([]models.Reading, error) {
+	if err := validateDeviceId(id); err != nil {
+		return []models.Reading{}, err
+	}
+
+	query := bson.M{"device": id}
+	return mc.getReadingsLimit(query, limit)
+}
+
+// Return the readings for the given device created after sinceCreated, newest first
+// Limit by the given limit. Pollers pass the creation time of the newest reading they have seen
+func (mc *MongoClient) ReadingsByDeviceSince(id string, sinceCreated int64, limit int) ([]models.Reading, error) {
+	if err := validateDeviceId(id); err != nil {
+		return []models.Reading{}, err
+	}
+
+	query := bson.M{"device": id, "created": bson.M{"$gt": sinceCreated}}
+	return mc.getReadingsLimit(query, limit)
+}
+
+// Delete all of the readings for the given device (id or name)
+// The device's events are kept but no longer reference any readings
+// Return the number of readings removed
+func (mc *MongoClient) DeleteReadingsByDevice(id string) (int, error) {
+	if err := validateDeviceId(id); err != nil {
+		return 0, err
+	}
+
+	removed, err := mc.removeInBatches(READINGS_COLLECTION, bson.M{"device": id})
+	if err != nil {
+		return removed, queryError(err)
+	}
+
+	s := mc.getScanSessionCopy()
+	defer s.Close()
+
+	// Don't leave DBRefs to the removed readings behind
+	_, err = s.DB(mc.Database.Name).C(EVENTS_COLLECTION).UpdateAll(bson.M{"device": id}, bson.M{"$set": bson.M{"readings": []mgo.DBRef{}}})
+	return removed, queryError(err)
+}
+
+// Filter of DeleteReadingsByQuery, the empty fields don't restrict the match
+type ReadingFilter struct {
+	Device string // Device id or name
+	Name   string // Value descriptor name
+	Start  int64  // Readings created at or after this time
+	End    int64  // Readings created at or before this time (0 - no upper bound)
+}
+
+// Translate the filter to a query, only the typed fields can end up in it
+func (f ReadingFilter) query() (bson.M, error) {
+	q := bson.M{}
+	if f.Device != "" {
+		q["device"] = f.Device
 	}
-
-	return err
+	if f.Name != "" {
+		q["name"] = f.Name
+	}
+	if f.End != 0 {
+		if err := validateTimeRange(f.Start, f.End); err != nil {
+			return nil, err
+		}
+		q["created"] = bson.M{"$gte": f.Start, "$lte": f.End}
+	} else if f.Start < 0 {
+		return nil, ErrInvalidTimeRange
+	} else if f.Start > 0 {
+		q["created"] = bson.M{"$gte": f.Start}
+	}
+	return q, nil
 }
 
-// Get a reading by ID
-func (mc *MongoClient) ReadingById(id string) (models.Reading, error) {
-	// Check if the id is a id hex
-	if !bson.IsObjectIdHex(id) {
-		return models.Reading{}, ErrInvalidObjectId
+// Delete the readings matching the filter and pull them from their events
+// Nothing is removed and ErrDeleteLimitExceeded is returned when more than maxDelete readings match
+// Return the number of readings removed
+func (mc *MongoClient) DeleteReadingsByQuery(filter ReadingFilter, maxDelete int) (int, error) {
+	q, err := filter.query()
+	if err != nil {
+		return 0, err
+	}
+	if err = validateLimit(maxDelete); err != nil {
+		return 0, err
 	}
 
-	query := bson.M{"_id": bson.ObjectIdHex(id)}
+	s := mc.getScanSessionCopy()
+	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "DeleteReadingsByQuery", q)
 
-	return mc.getReading(query)
-}
+	// Fetch one more than allowed so the ids removed are exactly the ones counted
+	var docs []struct {
+		Id bson.ObjectId `bson:"_id"`
+	}
+	readings := s.DB(mc.Database.Name).C(READINGS_COLLECTION)
+	if err = readings.Find(q).Select(bson.M{"_id": 1}).Limit(maxDelete + 1).All(&docs); err != nil {
+		return 0, queryError(err)
+	}
+	if len(docs) > maxDelete {
+		return 0, ErrDeleteLimitExceeded
+	}
 
-// Get the count of readings in Mongo
-func (mc *MongoClient) ReadingCount() (int, error) {
-	s := mc.getSessionCopy()
-	defer s.Close()
+	events := s.DB(mc.Database.Name).C(EVENTS_COLLECTION)
+	removed := 0
+	for start := 0; start < len(docs); start += mc.batchDeleteSize() {
+		end := start + mc.batchDeleteSize()
+		if end > len(docs) {
+			end = len(docs)
+		}
+		var ids []bson.ObjectId
+		var refs []mgo.DBRef
+		for _, doc := range docs[start:end] {
+			ids = append(ids, doc.Id)
+			refs = append(refs, mgo.DBRef{Collection: READINGS_COLLECTION, Id: doc.Id})
+		}
 
-	return s.DB(mc.Database.Name).C(READINGS_COLLECTION).Find(bson.M{}).Count()
+		// The events can't be decoded with references to removed readings
+		pull := bson.M{"$pull": bson.M{"readings": bson.M{"$in": refs}}}
+		if _, err = events.UpdateAll(bson.M{"readings": bson.M{"$in": refs}}, pull); err != nil {
+			return removed, queryError(err)
+		}
+
+		info, err := readings.RemoveAll(bson.M{"_id": bson.M{"$in": ids}})
+		if err != nil {
+			return removed, queryError(err)
+		}
+		removed += info.Removed
+	}
+	return removed, nil
 }
 
-// Delete a reading by ID
-// 404 - can't find the reading with the given id
-func (mc *MongoClient) DeleteReadingById(id string) error {
-	// Check if the id is a bson id
-	if !bson.IsObjectIdHex(id) {
-		return ErrInvalidObjectId
+// Return the number of readings DeleteReadingsByDevice would remove without removing them
+func (mc *MongoClient) DeleteReadingsByDeviceDryRun(id string) (int, error) {
+	if err := validateDeviceId(id); err != nil {
+		return 0, err
 	}
 
-	return mc.deleteById(id, READINGS_COLLECTION)
-}
+	s := mc.getScanSessionCopy()
+	defer s.Close()
 
-// Return a list of readings for the given device (id or name)
-// Sort the list of readings on creation date
-func (mc *MongoClient) ReadingsByDevice(id string, limit int) ([]models.Reading, error) {
-	query := bson.M{"device": id}
-	return mc.getReadingsLimit(query, limit)
+	count, err := s.DB(mc.Database.Name).C(READINGS_COLLECTION).Find(bson.M{"device": id}).Count()
+	return count, queryError(err)
 }
 
 // Return a list of readings for the given value descriptor
@@ -383,15 +1841,85 @@ func (mc *MongoClient) ReadingsByValueDescriptor(name string, limit int) ([]mode
 	return mc.getReadingsLimit(query, limit)
 }
 
+// Return the readings of the value descriptor whose value is a number greater than the threshold, newest first
+// Limit by the given limit
+// Values are stored as strings so they are converted in an aggregation, readings whose value isn't a number
+// are skipped. The conversion needs MongoDB 4.0 or later
+func (mc *MongoClient) ReadingsByValueDescriptorAboveThreshold(name string, threshold float64, limit int) ([]models.Reading, error) {
+	readings := []models.Reading{}
+	if err := validateLimit(limit); err != nil {
+		return readings, err
+	}
+	if limit == 0 {
+		return readings, nil
+	}
+
+	s := mc.getScanSessionCopy()
+	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "ReadingsByValueDescriptorAboveThreshold", name)
+
+	pipeline := []bson.M{
+		{"$match": bson.M{"name": name}},
+		{"$addFields": bson.M{"numericValue": bson.M{"$convert": bson.M{
+			"input":   "$value",
+			"to":      "double",
+			"onError": nil,
+			"onNull":  nil,
+		}}}},
+		{"$match": bson.M{"numericValue": bson.M{"$gt": threshold}}},
+		{"$sort": bson.D{{Name: "created", Value: -1}, {Name: "_id", Value: -1}}},
+		{"$limit": limit},
+		{"$project": bson.M{"numericValue": 0}},
+	}
+	err := s.DB(mc.Database.Name).C(READINGS_COLLECTION).Pipe(pipeline).All(&readings)
+	return readings, queryError(err)
+}
+
 // Return a list of readings whose name is in the list of value descriptor names
+// An empty list returns no readings without querying the database
 func (mc *MongoClient) ReadingsByValueDescriptorNames(names []string, limit int) ([]models.Reading, error) {
+	if len(names) == 0 {
+		return []models.Reading{}, nil
+	}
+
 	query := bson.M{"name": bson.M{"$in": names}}
 	return mc.getReadingsLimit(query, limit)
 }
 
+// Return the readings modified after since, oldest change first, limited by limit
+// Meant to be polled with the modified time of the last reading returned, AddEvent, AddReading and
+// UpdateReading all set it. Readings modified in the same millisecond as the last one can be missed
+// when the limit cuts through them
+func (mc *MongoClient) ReadingsModifiedSince(since int64, limit int) ([]models.Reading, error) {
+	readings := []models.Reading{}
+	if err := validateLimit(limit); err != nil {
+		return readings, err
+	}
+	if limit == 0 {
+		return readings, nil
+	}
+
+	s := mc.getSessionCopy()
+	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "ReadingsModifiedSince", since)
+
+	query := bson.M{"modified": bson.M{"$gt": since}}
+	err := s.DB(mc.Database.Name).C(READINGS_COLLECTION).Find(query).Sort("modified", "_id").Limit(limit).All(&readings)
+	return readings, queryError(err)
+}
+
+// Return the newest readings of all devices, newest first, limited by limit
+func (mc *MongoClient) LatestReadings(limit int) ([]models.Reading, error) {
+	return mc.getReadingsLimit(bson.M{}, limit)
+}
+
 // Return a list of readings whos creation time is in-between start and end
 // Limit by the limit parameter
 func (mc *MongoClient) ReadingsByCreationTime(start, end int64, limit int) ([]models.Reading, error) {
+	if err := validateTimeRange(start, end); err != nil {
+		return []models.Reading{}, err
+	}
+
 	query := bson.M{"created": bson.M{
 		"$gte": start,
 		"$lte": end,
@@ -402,46 +1930,160 @@ func (mc *MongoClient) ReadingsByCreationTime(start, end int64, limit int) ([]mo
 // Return a list of readings for a device filtered by the value descriptor and limited by the limit
 // The readings are linked to the device through an event
 func (mc *MongoClient) ReadingsByDeviceAndValueDescriptor(deviceId, valueDescriptor string, limit int) ([]models.Reading, error) {
+	if err := validateDeviceId(deviceId); err != nil {
+		return []models.Reading{}, err
+	}
+
 	query := bson.M{"device": deviceId, "name": valueDescriptor}
 	return mc.getReadingsLimit(query, limit)
 }
 
+// Return a page of readings ordered by creation time then ID, starting after the given cursor
+// An empty afterId starts the page at the first reading created at or after afterCreated
+// The returned cursor is the position of the last reading in the page (the passed cursor if the page is empty)
+// Unlike skip/limit, resuming from the cursor stays cheap for large offsets and survives restarts
+func (mc *MongoClient) ReadingsAfter(afterCreated int64, afterId string, limit int) ([]models.Reading, int64, string, error) {
+	var query bson.M
+	if afterId == "" {
+		query = bson.M{"created": bson.M{"$gte": afterCreated}}
+	} else {
+		if !bson.IsObjectIdHex(afterId) {
+			return []models.Reading{}, afterCreated, afterId, ErrInvalidObjectId
+		}
+		query = bson.M{"$or": []bson.M{
+			{"created": bson.M{"$gt": afterCreated}},
+			{"created": afterCreated, "_id": bson.M{"$gt": bson.ObjectIdHex(afterId)}},
+		}}
+	}
+
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	readings := []models.Reading{}
+	if err := validateLimit(limit); err != nil {
+		return readings, afterCreated, afterId, err
+	}
+	if limit == 0 {
+		return readings, afterCreated, afterId, nil
+	}
+
+	err := s.DB(mc.Database.Name).C(READINGS_COLLECTION).Find(query).Sort("created", "_id").Limit(limit).All(&readings)
+	if err != nil || len(readings) == 0 {
+		return readings, afterCreated, afterId, err
+	}
+
+	last := readings[len(readings)-1]
+	return readings, last.Created, last.Id.Hex(), nil
+}
+
 func (mc *MongoClient) getReadingsLimit(q bson.M, limit int) ([]models.Reading, error) {
 	s := mc.getSessionCopy()
 	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "getReadingsLimit", q)
 
 	readings := []models.Reading{}
 
 	// Check if limit is 0
+	if err := validateLimit(limit); err != nil {
+		return readings, err
+	}
+	if limit == 0 {
+		return readings, nil
+	}
+
+	err := s.DB(mc.Database.Name).C(READINGS_COLLECTION).Find(q).Sort(newestFirst...).Limit(limit).All(&readings)
+	return readings, queryError(err)
+}
+
+// Return the readings matching the query with only the given fields, newest first
+// The fields are the bson names of the reading fields, _id is only returned when asked for
+// ErrInvalidProjection - the list of fields is empty or has a field readings don't have
+func (mc *MongoClient) ReadingsProjected(q bson.M, fields []string, limit int) ([]bson.M, error) {
+	if len(fields) == 0 {
+		return nil, ErrInvalidProjection
+	}
+	projection := bson.M{"_id": 0}
+	for _, f := range fields {
+		if !readingFields[f] {
+			return nil, ErrInvalidProjection
+		}
+		projection[f] = 1
+	}
+
+	readings := []bson.M{}
+	if err := validateLimit(limit); err != nil {
+		return readings, err
+	}
 	if limit == 0 {
 		return readings, nil
 	}
 
-	err := s.DB(mc.Database.Name).C(READINGS_COLLECTION).Find(q).Limit(limit).All(&readings)
-	return readings, err
+	s := mc.getScanSessionCopy()
+	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "ReadingsProjected", q)
+
+	err := s.DB(mc.Database.Name).C(READINGS_COLLECTION).Find(q).Select(projection).Sort(newestFirst...).Limit(limit).All(&readings)
+	return readings, queryError(err)
+}
+
+// Get the ids of the newest events with a reading matching the query, at most limit of them
+// The readings are grouped by their eventId instead of searching the events for the ids of
+// every matching reading, which can outgrow the 16MB query limit. The readings stored without
+// an eventId are missed until BackfillReadingEventIds has run
+func (mc *MongoClient) readingEventIds(q bson.M, limit int) ([]bson.ObjectId, error) {
+	s := mc.getScanSessionCopy()
+	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "readingEventIds", q)
+
+	match := bson.M{"eventId": bson.M{"$exists": true}}
+	for k, v := range q {
+		match[k] = v
+	}
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$group": bson.M{"_id": "$eventId", "created": bson.M{"$max": "$created"}}},
+		{"$sort": bson.D{{Name: "created", Value: -1}, {Name: "_id", Value: -1}}},
+		{"$limit": limit},
+	}
+
+	var docs []struct {
+		Id bson.ObjectId `bson:"_id"`
+	}
+	err := s.DB(mc.Database.Name).C(READINGS_COLLECTION).Pipe(pipeline).AllowDiskUse().All(&docs)
+	if err != nil {
+		return nil, queryError(err)
+	}
+
+	ids := make([]bson.ObjectId, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.Id
+	}
+	return ids, nil
 }
 
 // Get readings from the database
 func (mc *MongoClient) getReadings(q bson.M) ([]models.Reading, error) {
 	s := mc.getSessionCopy()
 	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "getReadings", q)
 
 	readings := []models.Reading{}
-	err := s.DB(mc.Database.Name).C(READINGS_COLLECTION).Find(q).All(&readings)
-	return readings, err
+	err := s.DB(mc.Database.Name).C(READINGS_COLLECTION).Find(q).Sort(newestFirst...).All(&readings)
+	return readings, queryError(err)
 }
 
 // Get a reading from the database with the passed query
 func (mc *MongoClient) getReading(q bson.M) (models.Reading, error) {
 	s := mc.getSessionCopy()
 	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "getReading", q)
 
 	var res models.Reading
 	err := s.DB(mc.Database.Name).C(READINGS_COLLECTION).Find(q).One(&res)
 	if err == mgo.ErrNotFound {
 		return res, ErrNotFound
 	}
-	return res, err
+	return res, queryError(err)
 }
 
 // ************************* VALUE DESCRIPTORS *****************************
@@ -451,11 +2093,13 @@ func (mc *MongoClient) getReading(q bson.M) (models.Reading, error) {
 // 503 - Unexpected
 // TODO: Check for valid printf formatting
 func (mc *MongoClient) AddValueDescriptor(v models.ValueDescriptor) (bson.ObjectId, error) {
+	defer mc.ClearValueDescriptorCache()
 	s := mc.getSessionCopy()
 	defer s.Close()
 
 	// Created/Modified now
 	v.Created = time.Now().UnixNano() / int64(time.Millisecond)
+	v.Modified = v.Created
 
 	// See if the name is unique and add the value descriptors
 	info, err := s.DB(mc.Database.Name).C(VALUE_DESCRIPTOR_COLLECTION).Upsert(bson.M{"name": v.Name}, v)
@@ -485,6 +2129,7 @@ func (mc *MongoClient) ValueDescriptors() ([]models.ValueDescriptor, error) {
 // TODO: Check for the valid printf formatting
 // 404 not found if the value descriptor cannot be found by the identifiers
 func (mc *MongoClient) UpdateValueDescriptor(v models.ValueDescriptor) error {
+	defer mc.ClearValueDescriptorCache()
 	s := mc.getSessionCopy()
 	defer s.Close()
 
@@ -510,21 +2155,189 @@ func (mc *MongoClient) UpdateValueDescriptor(v models.ValueDescriptor) error {
 	return err
 }
 
+// Rename a value descriptor and the readings that use its name
+// The readings are renamed before the value descriptor so a failed rename can simply be retried,
+// then once more afterwards to pick up readings added under the old name in the meantime
+// 404 - no value descriptor is named oldName
+// 409 - a value descriptor is already named newName
+func (mc *MongoClient) RenameValueDescriptor(oldName, newName string) error {
+	defer mc.ClearValueDescriptorCache()
+	vd, err := mc.getValueDescriptor(bson.M{"name": oldName})
+	if err != nil {
+		return queryError(err)
+	}
+	if oldName == newName {
+		return nil
+	}
+
+	// See if the new name is unique
+	_, err = mc.getValueDescriptor(bson.M{"name": newName})
+	if err != ErrNotFound {
+		if err != nil {
+			return queryError(err)
+		}
+		return ErrNotUnique
+	}
+
+	s := mc.getScanSessionCopy()
+	defer s.Close()
+
+	// The readings are modified too so ReadingsModifiedSince picks up the rename
+	modified := time.Now().UnixNano() / int64(time.Millisecond)
+	readings := s.DB(mc.Database.Name).C(READINGS_COLLECTION)
+	rename := bson.M{"$set": bson.M{"name": newName, "modified": modified}}
+	if _, err = readings.UpdateAll(bson.M{"name": oldName}, rename); err != nil {
+		return queryError(err)
+	}
+
+	err = s.DB(mc.Database.Name).C(VALUE_DESCRIPTOR_COLLECTION).UpdateId(vd.Id, bson.M{"$set": bson.M{"name": newName, "modified": modified}})
+	if err == mgo.ErrNotFound {
+		return ErrNotFound
+	}
+	if err != nil {
+		return queryError(err)
+	}
+
+	_, err = readings.UpdateAll(bson.M{"name": oldName}, rename)
+	return queryError(err)
+}
+
 // Delete the value descriptor based on the id
 // Not found error if there isn't a value descriptor for the ID
 // ValueDescriptorStillInUse if the value descriptor is still referenced by readings
 func (mc *MongoClient) DeleteValueDescriptorById(id string) error {
+	defer mc.ClearValueDescriptorCache()
 	if !bson.IsObjectIdHex(id) {
 		return ErrInvalidObjectId
 	}
 	return mc.deleteById(id, VALUE_DESCRIPTOR_COLLECTION)
 }
 
+// Delete the value descriptors based on their ids with a single remove
+// The value descriptors still referenced by readings are kept and listed in ErrValueDescriptorsInUse,
+// the ids without a value descriptor are skipped. Nothing is removed when an id is invalid
+// Return the number of value descriptors removed
+func (mc *MongoClient) DeleteValueDescriptorsByIds(ids []string) (int, error) {
+	defer mc.ClearValueDescriptorCache()
+	objectIds := make([]bson.ObjectId, len(ids))
+	for i, id := range ids {
+		if !bson.IsObjectIdHex(id) {
+			return 0, ErrInvalidObjectId
+		}
+		objectIds[i] = bson.ObjectIdHex(id)
+	}
+	if len(objectIds) == 0 {
+		return 0, nil
+	}
+
+	s := mc.getScanSessionCopy()
+	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "DeleteValueDescriptorsByIds", ids)
+
+	var vds []struct {
+		Id   bson.ObjectId `bson:"_id"`
+		Name string        `bson:"name"`
+	}
+	c := s.DB(mc.Database.Name).C(VALUE_DESCRIPTOR_COLLECTION)
+	if err := c.Find(bson.M{"_id": bson.M{"$in": objectIds}}).Select(bson.M{"name": 1}).All(&vds); err != nil {
+		return 0, queryError(err)
+	}
+	names := make([]string, len(vds))
+	for i, v := range vds {
+		names[i] = v.Name
+	}
+
+	// One query finds all of the names still in use
+	var used []string
+	err := s.DB(mc.Database.Name).C(READINGS_COLLECTION).Find(bson.M{"name": bson.M{"$in": names}}).Distinct("name", &used)
+	if err != nil {
+		return 0, queryError(err)
+	}
+	inUse := map[string]bool{}
+	for _, name := range used {
+		inUse[name] = true
+	}
+
+	var safe []bson.ObjectId
+	var kept []string
+	for _, v := range vds {
+		if inUse[v.Name] {
+			kept = append(kept, v.Id.Hex())
+		} else {
+			safe = append(safe, v.Id)
+		}
+	}
+
+	removed := 0
+	if len(safe) > 0 {
+		info, err := c.RemoveAll(bson.M{"_id": bson.M{"$in": safe}})
+		if err != nil {
+			return 0, queryError(err)
+		}
+		removed = info.Removed
+	}
+
+	if len(kept) > 0 {
+		return removed, ErrValueDescriptorsInUse{Ids: kept}
+	}
+	return removed, nil
+}
+
+// Delete the value descriptor based on the name
+// Not found error if there isn't a value descriptor for the name
+// ErrValueDescriptorInUse if the value descriptor is still referenced by readings
+// The lookup, the check of the readings and the removal are separate queries, not atomic: a
+// reading added with the name in between is left without its value descriptor
+func (mc *MongoClient) DeleteValueDescriptorByName(name string) error {
+	defer mc.ClearValueDescriptorCache()
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	c := s.DB(mc.Database.Name).C(VALUE_DESCRIPTOR_COLLECTION)
+	found, err := c.Find(bson.M{"name": name}).Count()
+	if err != nil {
+		return queryError(err)
+	}
+	if found == 0 {
+		return ErrNotFound
+	}
+
+	count, err := mc.ReadingCountByValueDescriptor(name)
+	if err != nil {
+		return queryError(err)
+	}
+	if count > 0 {
+		return ErrValueDescriptorInUse
+	}
+
+	err = c.Remove(bson.M{"name": name})
+	if err == mgo.ErrNotFound {
+		return ErrNotFound
+	}
+	return queryError(err)
+}
+
 // Return a value descriptor based on the name
 // Can return null if no value descriptor is found
+// The value descriptor is cached when the cache is enabled
 func (mc *MongoClient) ValueDescriptorByName(name string) (models.ValueDescriptor, error) {
+	if v, ok := mc.vdCache.get(name); ok {
+		return v, nil
+	}
+
 	query := bson.M{"name": name}
-	return mc.getValueDescriptor(query)
+	v, err := mc.getValueDescriptor(query)
+	if err == nil {
+		mc.vdCache.put(v)
+	}
+	return v, err
+}
+
+// Empty the value descriptor cache
+// It is emptied whenever a value descriptor is changed through the client, call it after changing
+// value descriptors directly in the database
+func (mc *MongoClient) ClearValueDescriptorCache() {
+	mc.vdCache.clear()
 }
 
 // Return all of the value descriptors based on the names
@@ -561,6 +2374,17 @@ func (mc *MongoClient) ValueDescriptorsByUomLabel(uomLabel string) ([]models.Val
 	return mc.getValueDescriptors(query)
 }
 
+// Return the value descriptors whose unit of measure label is in the list
+// An empty list returns no value descriptors without querying the database
+func (mc *MongoClient) ValueDescriptorsByUomLabels(labels []string) ([]models.ValueDescriptor, error) {
+	if len(labels) == 0 {
+		return []models.ValueDescriptor{}, nil
+	}
+
+	query := bson.M{"uomLabel": bson.M{"$in": labels}}
+	return mc.getValueDescriptors(query)
+}
+
 // Return value descriptors based on if it has the label
 func (mc *MongoClient) ValueDescriptorsByLabel(label string) ([]models.ValueDescriptor, error) {
 	query := bson.M{"labels": label}
@@ -573,12 +2397,52 @@ func (mc *MongoClient) ValueDescriptorsByType(t string) ([]models.ValueDescripto
 	return mc.getValueDescriptors(query)
 }
 
+// Return the value descriptors whose creation time is between start and end
+func (mc *MongoClient) ValueDescriptorsByCreationTime(start, end int64) ([]models.ValueDescriptor, error) {
+	if err := validateTimeRange(start, end); err != nil {
+		return []models.ValueDescriptor{}, err
+	}
+
+	query := bson.M{"created": bson.M{
+		"$gte": start,
+		"$lte": end,
+	}}
+	return mc.getValueDescriptors(query)
+}
+
+// Return the value descriptors added or modified after since
+func (mc *MongoClient) ValueDescriptorsModifiedSince(since int64) ([]models.ValueDescriptor, error) {
+	query := bson.M{"modified": bson.M{"$gt": since}}
+	return mc.getValueDescriptors(query)
+}
+
+// Return the distinct UOM labels used by the value descriptors
+func (mc *MongoClient) DistinctUomLabels() ([]string, error) {
+	return mc.distinctValueDescriptorField("uomLabel")
+}
+
+// Return the distinct types used by the value descriptors
+func (mc *MongoClient) DistinctValueDescriptorTypes() ([]string, error) {
+	return mc.distinctValueDescriptorField("type")
+}
+
+// Get the number of value descriptors in Mongo
+func (mc *MongoClient) ValueDescriptorCount() (int, error) {
+	s := mc.countsFromSecondary(mc.getSessionCopy())
+	defer s.Close()
+
+	count, err := s.DB(mc.Database.Name).C(VALUE_DESCRIPTOR_COLLECTION).Find(nil).Count()
+	return count, queryError(err)
+}
+
 // Delete all of the value descriptors
 func (mc *MongoClient) ScrubAllValueDescriptors() error {
-	s := mc.getSessionCopy()
-	defer s.Close()
+	if !mc.config.AllowScrub {
+		return ErrScrubNotAllowed
+	}
+	defer mc.ClearValueDescriptorCache()
 
-	_, err := s.DB(mc.Database.Name).C(VALUE_DESCRIPTOR_COLLECTION).RemoveAll(nil)
+	_, err := mc.removeInBatches(VALUE_DESCRIPTOR_COLLECTION, nil)
 	if err != nil {
 		return err
 	}
@@ -590,28 +2454,50 @@ func (mc *MongoClient) ScrubAllValueDescriptors() error {
 func (mc *MongoClient) getValueDescriptors(q bson.M) ([]models.ValueDescriptor, error) {
 	s := mc.getSessionCopy()
 	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "getValueDescriptors", q)
 
 	v := []models.ValueDescriptor{}
 	err := s.DB(mc.Database.Name).C(VALUE_DESCRIPTOR_COLLECTION).Find(q).All(&v)
 
-	return v, err
+	return v, queryError(err)
 }
 
 // Get value descriptors with a limit based on the query
 func (mc *MongoClient) getValueDescriptorsLimit(q bson.M, limit int) ([]models.ValueDescriptor, error) {
 	s := mc.getSessionCopy()
 	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "getValueDescriptorsLimit", q)
 
 	v := []models.ValueDescriptor{}
+	if err := validateLimit(limit); err != nil {
+		return v, err
+	}
 	err := s.DB(mc.Database.Name).C(VALUE_DESCRIPTOR_COLLECTION).Find(q).Limit(limit).All(&v)
 
-	return v, err
+	return v, queryError(err)
+}
+
+// Get the distinct values of a value descriptor field
+// Return an empty slice if there are no value descriptors
+func (mc *MongoClient) distinctValueDescriptorField(field string) ([]string, error) {
+	s := mc.getScanSessionCopy()
+	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "distinctValueDescriptorField", field)
+
+	values := []string{}
+	err := s.DB(mc.Database.Name).C(VALUE_DESCRIPTOR_COLLECTION).Find(nil).Distinct(field, &values)
+	if values == nil {
+		values = []string{}
+	}
+
+	return values, queryError(err)
 }
 
 // Get a value descriptor based on the query
 func (mc *MongoClient) getValueDescriptor(q bson.M) (models.ValueDescriptor, error) {
 	s := mc.getSessionCopy()
 	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "getValueDescriptor", q)
 
 	var v models.ValueDescriptor
 	err := s.DB(mc.Database.Name).C(VALUE_DESCRIPTOR_COLLECTION).Find(q).One(&v)
@@ -619,7 +2505,75 @@ func (mc *MongoClient) getValueDescriptor(q bson.M) (models.ValueDescriptor, err
 		return v, ErrNotFound
 	}
 
-	return v, err
+	return v, queryError(err)
+}
+
+// Number of documents to handle per round trip in the bulk deletes and migrations
+func (mc *MongoClient) batchDeleteSize() int {
+	if mc.config.BatchDeleteSize <= 0 {
+		return DefaultBatchDeleteSize
+	}
+	return mc.config.BatchDeleteSize
+}
+
+// Remove the documents matching the query from the collection in bounded batches
+// Each batch is a separate remove so other operations aren't locked out for the whole delete
+// Return the number of documents removed
+func (mc *MongoClient) removeInBatches(col string, q bson.M) (int, error) {
+	s := mc.getScanSessionCopy()
+	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "removeInBatches", q)
+
+	c := s.DB(mc.Database.Name).C(col)
+	removed := 0
+	for {
+		var batch []struct {
+			Id bson.ObjectId `bson:"_id"`
+		}
+		err := c.Find(q).Select(bson.M{"_id": 1}).Limit(mc.batchDeleteSize()).All(&batch)
+		if err != nil {
+			return removed, queryError(err)
+		}
+		if len(batch) == 0 {
+			return removed, nil
+		}
+
+		ids := make([]bson.ObjectId, len(batch))
+		for i, doc := range batch {
+			ids[i] = doc.Id
+		}
+
+		info, err := c.RemoveAll(bson.M{"_id": bson.M{"$in": ids}})
+		if err != nil {
+			return removed, queryError(err)
+		}
+		removed += info.Removed
+	}
+}
+
+// Get the document count kept in the collection's metadata
+func (mc *MongoClient) estimatedCount(col string) (int, error) {
+	s := mc.countsFromSecondary(mc.getSessionCopy())
+	defer s.Close()
+
+	var stats struct {
+		Count int `bson:"count"`
+	}
+	err := s.DB(mc.Database.Name).Run(bson.D{{Name: "collStats", Value: col}}, &stats)
+	return stats.Count, queryError(err)
+}
+
+// Return whether a document exists in the collection for the id
+func (mc *MongoClient) exists(col string, id string) (bool, error) {
+	if !bson.IsObjectIdHex(id) {
+		return false, ErrInvalidObjectId
+	}
+
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	count, err := s.DB(mc.Database.Name).C(col).FindId(bson.ObjectIdHex(id)).Limit(1).Count()
+	return count > 0, queryError(err)
 }
 
 // Delete from the collection based on ID
@@ -13,7 +13,18 @@
 package clients
 
 import (
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/support/logging-client"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
 )
 
 func TestMongoDB(t *testing.T) {
@@ -26,6 +37,7 @@ func TestMongoDB(t *testing.T) {
 		Port:         27017,
 		DatabaseName: "coredata",
 		Timeout:      1000,
+		AllowScrub:   true,
 	}
 
 	mongo, err := newMongoClient(config)
@@ -46,7 +58,2142 @@ func BenchmarkMongoDB(b *testing.B) {
 		Port:         27017,
 		DatabaseName: "coredata",
 		Timeout:      1000,
+		AllowScrub:   true,
 	}
 
 	benchmarkDB(b, config)
 }
+
+func connectTestMongo(t *testing.T) *MongoClient {
+	config := DBConfiguration{
+		DbType:       MONGO,
+		Host:         "0.0.0.0",
+		Port:         27017,
+		DatabaseName: "coredata",
+		Timeout:      1000,
+		AllowScrub:   true,
+	}
+
+	mongo, err := newMongoClient(config)
+	if err != nil {
+		t.Fatalf("Could not connect with mongodb: %v", err)
+	}
+	return mongo
+}
+
+func TestMongoReadingCounts(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	err := mongo.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all readings")
+	}
+
+	// name0..name9 once, plus name0..name4 a second time
+	_, err = populateDbReadings(mongo, 10)
+	if err != nil {
+		t.Fatalf("Error populating db: %v\n", err)
+	}
+	_, err = populateDbReadings(mongo, 5)
+	if err != nil {
+		t.Fatalf("Error populating db: %v\n", err)
+	}
+
+	count, err := mongo.ReadingCountByValueDescriptor("name1")
+	if err != nil {
+		t.Fatalf("Error getting ReadingCountByValueDescriptor: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("There should be 2 readings instead of %d", count)
+	}
+	count, err = mongo.ReadingCountByValueDescriptor("name")
+	if err != nil {
+		t.Fatalf("Error getting ReadingCountByValueDescriptor: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("There should be 0 readings instead of %d", count)
+	}
+
+	counts, err := mongo.ReadingCountsGrouped()
+	if err != nil {
+		t.Fatalf("Error getting ReadingCountsGrouped: %v", err)
+	}
+	if len(counts) != 10 {
+		t.Fatalf("There should be 10 groups instead of %d", len(counts))
+	}
+	if counts["name4"] != 2 || counts["name5"] != 1 {
+		t.Fatalf("Unexpected grouped counts: %v", counts)
+	}
+}
+
+func TestMongoSeedDemoData(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	resetDemoData(t, mongo)
+
+	count, err := mongo.EventCountByDeviceId(seedDeviceName)
+	if err != nil {
+		t.Fatalf("Error getting events count: %v", err)
+	}
+	if count != seedEventCount {
+		t.Fatalf("There should be %d events instead of %d", seedEventCount, count)
+	}
+
+	count, err = mongo.ReadingCount()
+	if err != nil {
+		t.Fatalf("Error getting readings count: %v", err)
+	}
+	if count != seedEventCount*len(seedValueDescriptors) {
+		t.Fatalf("There should be %d readings instead of %d", seedEventCount*len(seedValueDescriptors), count)
+	}
+
+	values, err := mongo.ValueDescriptors()
+	if err != nil {
+		t.Fatalf("Error getting value descriptors: %v", err)
+	}
+	if len(values) != len(seedValueDescriptors) {
+		t.Fatalf("There should be %d value descriptors instead of %d", len(seedValueDescriptors), len(values))
+	}
+}
+
+func TestMongoDeleteEventsOlderThanAge(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+	mongo.config.BatchDeleteSize = 7
+
+	err := mongo.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events")
+	}
+
+	for i := 0; i < 20; i++ {
+		e := models.Event{Device: "device"}
+		e.Readings = []models.Reading{{Name: "reading1"}, {Name: "reading2"}}
+		if _, err = mongo.AddEvent(&e); err != nil {
+			t.Fatalf("Error adding event: %v", err)
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	removed, err := mongo.DeleteEventsOlderThanAge(1000000)
+	if err != nil {
+		t.Fatalf("Error deleting events: %v", err)
+	}
+	if removed != 0 {
+		t.Fatalf("There should be 0 events removed instead of %d", removed)
+	}
+
+	removed, err = mongo.DeleteEventsOlderThanAge(0)
+	if err != nil {
+		t.Fatalf("Error deleting events: %v", err)
+	}
+	if removed != 20 {
+		t.Fatalf("There should be 20 events removed instead of %d", removed)
+	}
+
+	count, err := mongo.ReadingCount()
+	if err != nil {
+		t.Fatalf("Error getting readings count: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("There should be 0 readings instead of %d", count)
+	}
+}
+
+func TestMongoDistinctValueDescriptorFields(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	err := mongo.ScrubAllValueDescriptors()
+	if err != nil {
+		t.Fatalf("Error removing all value descriptors")
+	}
+
+	labels, err := mongo.DistinctUomLabels()
+	if err != nil {
+		t.Fatalf("Error getting DistinctUomLabels: %v", err)
+	}
+	if labels == nil || len(labels) != 0 {
+		t.Fatalf("There should be an empty slice of labels instead of %v", labels)
+	}
+
+	_, err = populateDbValues(mongo, 10)
+	if err != nil {
+		t.Fatalf("Error populating db: %v\n", err)
+	}
+
+	labels, err = mongo.DistinctUomLabels()
+	if err != nil {
+		t.Fatalf("Error getting DistinctUomLabels: %v", err)
+	}
+	if len(labels) != 10 {
+		t.Fatalf("There should be 10 labels instead of %d", len(labels))
+	}
+
+	types, err := mongo.DistinctValueDescriptorTypes()
+	if err != nil {
+		t.Fatalf("Error getting DistinctValueDescriptorTypes: %v", err)
+	}
+	if len(types) != 10 {
+		t.Fatalf("There should be 10 types instead of %d", len(types))
+	}
+}
+
+func TestMongoReadingsAfter(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	err := mongo.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all readings")
+	}
+	_, err = populateDbReadings(mongo, 25)
+	if err != nil {
+		t.Fatalf("Error populating db: %v\n", err)
+	}
+
+	// Walk all of the readings a page at a time
+	seen := map[string]bool{}
+	var created int64
+	var id string
+	for {
+		var readings []models.Reading
+		readings, created, id, err = mongo.ReadingsAfter(created, id, 10)
+		if err != nil {
+			t.Fatalf("Error getting ReadingsAfter: %v", err)
+		}
+		if len(readings) == 0 {
+			break
+		}
+		for _, r := range readings {
+			if seen[r.Id.Hex()] {
+				t.Fatalf("Reading %s returned twice", r.Id.Hex())
+			}
+			seen[r.Id.Hex()] = true
+		}
+	}
+	if len(seen) != 25 {
+		t.Fatalf("There should be 25 readings instead of %d", len(seen))
+	}
+
+	_, _, _, err = mongo.ReadingsAfter(0, "INVALID", 10)
+	if err != ErrInvalidObjectId {
+		t.Fatalf("Invalid ID should return ErrInvalidObjectId, not %v", err)
+	}
+}
+
+func TestMongoReadingsAfterCursor(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	err := mongo.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all readings")
+	}
+	_, err = populateDbReadings(mongo, 25)
+	if err != nil {
+		t.Fatalf("Error populating db: %v\n", err)
+	}
+
+	// Walk all of the readings a page at a time, until the next cursor is empty
+	seen := map[string]bool{}
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("The cursor should end after 3 pages")
+		}
+		var readings []models.Reading
+		readings, cursor, err = mongo.ReadingsAfterCursor(cursor, 10)
+		if err != nil {
+			t.Fatalf("Error getting ReadingsAfterCursor: %v", err)
+		}
+		for _, r := range readings {
+			if seen[r.Id.Hex()] {
+				t.Fatalf("Reading %s returned twice", r.Id.Hex())
+			}
+			seen[r.Id.Hex()] = true
+		}
+		if cursor == "" {
+			break
+		}
+	}
+	if len(seen) != 25 {
+		t.Fatalf("There should be 25 readings instead of %d", len(seen))
+	}
+
+	_, _, err = mongo.ReadingsAfterCursor("not a cursor", 10)
+	if err != ErrInvalidCursor {
+		t.Fatalf("Invalid cursor should return ErrInvalidCursor, not %v", err)
+	}
+}
+
+func TestMongoEventByIdMetadata(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	err := mongo.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events")
+	}
+
+	e := models.Event{Device: "device"}
+	e.Readings = []models.Reading{{Name: "reading1"}, {Name: "reading2"}}
+	id, err := mongo.AddEvent(&e)
+	if err != nil {
+		t.Fatalf("Error adding event: %v", err)
+	}
+
+	full, err := mongo.EventById(id.Hex())
+	if err != nil {
+		t.Fatalf("Error getting event by id: %v", err)
+	}
+	if len(full.Readings) != 2 {
+		t.Fatalf("There should be 2 readings instead of %d", len(full.Readings))
+	}
+
+	meta, err := mongo.EventByIdMetadata(id.Hex())
+	if err != nil {
+		t.Fatalf("Error getting event metadata by id: %v", err)
+	}
+	if meta.ID != id || meta.Device != "device" {
+		t.Fatalf("Event metadata does not match: %v", meta)
+	}
+	if meta.Readings == nil || len(meta.Readings) != 0 {
+		t.Fatalf("There should be an empty list of readings instead of %v", meta.Readings)
+	}
+
+	_, err = mongo.EventByIdMetadata(bson.NewObjectId().Hex())
+	if err != ErrNotFound {
+		t.Fatalf("Missing event should return ErrNotFound, not %v", err)
+	}
+}
+
+func TestMongoValueDescriptorsByTime(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	err := mongo.ScrubAllValueDescriptors()
+	if err != nil {
+		t.Fatalf("Error removing all value descriptors")
+	}
+
+	beforeTime := time.Now().UnixNano() / int64(time.Millisecond)
+	id, err := populateDbValues(mongo, 10)
+	if err != nil {
+		t.Fatalf("Error populating db: %v\n", err)
+	}
+	afterTime := time.Now().UnixNano() / int64(time.Millisecond)
+
+	values, err := mongo.ValueDescriptorsByCreationTime(beforeTime, afterTime)
+	if err != nil {
+		t.Fatalf("Error getting ValueDescriptorsByCreationTime: %v", err)
+	}
+	if len(values) != 10 {
+		t.Fatalf("There should be 10 value descriptors instead of %d", len(values))
+	}
+	_, err = mongo.ValueDescriptorsByCreationTime(afterTime+10, beforeTime)
+	if err != ErrInvalidTimeRange {
+		t.Fatalf("Start after end should return ErrInvalidTimeRange, not %v", err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	since := time.Now().UnixNano() / int64(time.Millisecond)
+	values, err = mongo.ValueDescriptorsModifiedSince(since)
+	if err != nil {
+		t.Fatalf("Error getting ValueDescriptorsModifiedSince: %v", err)
+	}
+	if len(values) != 0 {
+		t.Fatalf("There should be 0 value descriptors instead of %d", len(values))
+	}
+
+	v, err := mongo.ValueDescriptorById(id.Hex())
+	if err != nil {
+		t.Fatalf("Error getting value descriptor by id: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if err = mongo.UpdateValueDescriptor(v); err != nil {
+		t.Fatalf("Error updating value descriptor: %v", err)
+	}
+	values, err = mongo.ValueDescriptorsModifiedSince(since)
+	if err != nil {
+		t.Fatalf("Error getting ValueDescriptorsModifiedSince: %v", err)
+	}
+	if len(values) != 1 {
+		t.Fatalf("There should be 1 value descriptor instead of %d", len(values))
+	}
+}
+
+func TestMongoEventsByIds(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	err := mongo.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events")
+	}
+	id, err := populateDbEvents(mongo, 10, 0)
+	if err != nil {
+		t.Fatalf("Error populating db: %v\n", err)
+	}
+
+	for _, ids := range [][]string{nil, {}} {
+		events, err := mongo.EventsByIds(ids)
+		if err != nil {
+			t.Fatalf("Error getting EventsByIds: %v", err)
+		}
+		if events == nil || len(events) != 0 {
+			t.Fatalf("There should be an empty list of events, not %v", events)
+		}
+	}
+
+	events, err := mongo.EventsByIds([]string{id.Hex(), bson.NewObjectId().Hex()})
+	if err != nil {
+		t.Fatalf("Error getting EventsByIds: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("There should be 1 event, not %d", len(events))
+	}
+
+	_, err = mongo.EventsByIds([]string{"INVALID"})
+	if err != ErrInvalidObjectId {
+		t.Fatalf("Invalid ID should return ErrInvalidObjectId, not %v", err)
+	}
+}
+
+func TestMongoPingWhileIdle(t *testing.T) {
+	config := DBConfiguration{
+		DbType:          MONGO,
+		Host:            "0.0.0.0",
+		Port:            27017,
+		DatabaseName:    "coredata",
+		Timeout:         1000,
+		SocketKeepAlive: 1000,
+		MaxIdleTime:     10,
+	}
+
+	mongo, err := newMongoClient(config)
+	if err != nil {
+		t.Fatalf("Could not connect with mongodb: %v", err)
+	}
+
+	// Let the pinger run a few times
+	time.Sleep(50 * time.Millisecond)
+	if _, err = mongo.EventCount(); err != nil {
+		t.Fatalf("Error getting events count: %v", err)
+	}
+
+	// Stopping the pinger twice must not panic
+	mongo.CloseSession()
+	mongo.CloseSession()
+}
+
+func TestMongoDryRuns(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	err := mongo.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events")
+	}
+	for i := 0; i < 5; i++ {
+		e := models.Event{Device: "device"}
+		e.Readings = []models.Reading{{Name: "reading1"}, {Name: "reading2"}}
+		if _, err = mongo.AddEvent(&e); err != nil {
+			t.Fatalf("Error adding event: %v", err)
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	events, readings, err := mongo.ScrubAllEventsDryRun()
+	if err != nil {
+		t.Fatalf("Error getting ScrubAllEventsDryRun: %v", err)
+	}
+	if events != 5 || readings != 10 {
+		t.Fatalf("Scrub should remove 5 events and 10 readings, not %d and %d", events, readings)
+	}
+
+	count, err := mongo.DeleteEventsOlderThanAgeDryRun(0)
+	if err != nil {
+		t.Fatalf("Error getting DeleteEventsOlderThanAgeDryRun: %v", err)
+	}
+	if count != 5 {
+		t.Fatalf("There should be 5 events to remove instead of %d", count)
+	}
+
+	count, err = mongo.DeleteReadingsByDeviceDryRun("device")
+	if err != nil {
+		t.Fatalf("Error getting DeleteReadingsByDeviceDryRun: %v", err)
+	}
+	if count != 10 {
+		t.Fatalf("There should be 10 readings to remove instead of %d", count)
+	}
+
+	// Nothing was removed by the dry runs
+	count, err = mongo.DeleteReadingsByDevice("device")
+	if err != nil {
+		t.Fatalf("Error deleting readings by device: %v", err)
+	}
+	if count != 10 {
+		t.Fatalf("There should be 10 readings removed instead of %d", count)
+	}
+	eventList, err := mongo.EventsForDevice("device")
+	if err != nil {
+		t.Fatalf("Error getting events for device: %v", err)
+	}
+	if len(eventList) != 5 {
+		t.Fatalf("There should be 5 events instead of %d", len(eventList))
+	}
+}
+
+func TestMongoEventsFilteredByValueDescriptor(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	err := mongo.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events")
+	}
+	var newest string
+	for i := 0; i < 4; i++ {
+		e := models.Event{Device: "device"}
+		e.Readings = []models.Reading{{Name: "humidity"}}
+		// Only every other event has a temperature
+		if i%2 == 0 {
+			e.Readings = append(e.Readings, models.Reading{Name: "temperature"})
+		}
+		id, err := mongo.AddEvent(&e)
+		if err != nil {
+			t.Fatalf("Error adding event: %v", err)
+		}
+		if i%2 == 0 {
+			newest = id.Hex()
+		}
+	}
+
+	events, err := mongo.EventsFilteredByValueDescriptor("temperature", 10)
+	if err != nil {
+		t.Fatalf("Error getting EventsFilteredByValueDescriptor: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("There should be 2 events instead of %d", len(events))
+	}
+	for _, e := range events {
+		if len(e.Readings) != 1 || e.Readings[0].Name != "temperature" {
+			t.Fatalf("Readings were not filtered: %v", e.Readings)
+		}
+	}
+
+	events, err = mongo.EventsFilteredByValueDescriptor("temperature", 1)
+	if err != nil {
+		t.Fatalf("Error getting EventsFilteredByValueDescriptor: %v", err)
+	}
+	if len(events) != 1 || events[0].ID.Hex() != newest {
+		t.Fatalf("The newest event with a temperature should be returned: %v", events)
+	}
+
+	events, err = mongo.EventsFilteredByValueDescriptor("pressure", 10)
+	if err != nil {
+		t.Fatalf("Error getting EventsFilteredByValueDescriptor: %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("There should be 0 events instead of %d", len(events))
+	}
+}
+
+func TestMongoPages(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	resetDemoData(t, mongo)
+
+	events, err := mongo.EventsPage(bson.M{"device": seedDeviceName}, 2, 2)
+	if err != nil {
+		t.Fatalf("Error getting EventsPage: %v", err)
+	}
+	if events.Total != seedEventCount || len(events.Items) != 2 {
+		t.Fatalf("Expected 2 of %d events, got %d of %d", seedEventCount, len(events.Items), events.Total)
+	}
+	if events.Skip != 2 || events.Limit != 2 {
+		t.Fatalf("Page bounds not set: %v", events)
+	}
+
+	readings, err := mongo.ReadingsPage(bson.M{"name": "temperature"}, 4, 10)
+	if err != nil {
+		t.Fatalf("Error getting ReadingsPage: %v", err)
+	}
+	if readings.Total != seedEventCount || len(readings.Items) != 1 {
+		t.Fatalf("Expected 1 of %d readings, got %d of %d", seedEventCount, len(readings.Items), readings.Total)
+	}
+
+	vds, err := mongo.ValueDescriptorsPage(nil, 0, 0)
+	if err != nil {
+		t.Fatalf("Error getting ValueDescriptorsPage: %v", err)
+	}
+	if vds.Total != 3 || len(vds.Items) != 0 {
+		t.Fatalf("Expected 0 of 3 value descriptors, got %d of %d", len(vds.Items), vds.Total)
+	}
+
+	_, err = mongo.EventsPage(nil, -1, 10)
+	if err != ErrInvalidPage {
+		t.Fatalf("Expected ErrInvalidPage for a negative skip, got %v", err)
+	}
+}
+
+func TestMongoNewestFirstSort(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	err := mongo.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events")
+	}
+	// Added in a tight loop so many events share the same created millisecond
+	for i := 0; i < 50; i++ {
+		e := models.Event{Device: "device", Readings: []models.Reading{{Name: "name"}}}
+		if _, err = mongo.AddEvent(&e); err != nil {
+			t.Fatalf("Error adding event: %v", err)
+		}
+	}
+
+	events, err := mongo.EventsForDevice("device")
+	if err != nil {
+		t.Fatalf("Error getting EventsForDevice: %v", err)
+	}
+	for i := 1; i < len(events); i++ {
+		prev, cur := events[i-1], events[i]
+		if cur.Created > prev.Created || (cur.Created == prev.Created && cur.ID > prev.ID) {
+			t.Fatalf("Events are not sorted newest first at %d", i)
+		}
+	}
+
+	seen := map[bson.ObjectId]bool{}
+	for skip := 0; skip < 50; skip += 7 {
+		page, err := mongo.EventsPage(bson.M{"device": "device"}, skip, 7)
+		if err != nil {
+			t.Fatalf("Error getting EventsPage: %v", err)
+		}
+		for _, e := range page.Items {
+			if seen[e.ID] {
+				t.Fatalf("Event %s returned on more than one page", e.ID.Hex())
+			}
+			seen[e.ID] = true
+		}
+	}
+	if len(seen) != 50 {
+		t.Fatalf("Paging returned %d of the 50 events", len(seen))
+	}
+}
+
+func TestMongoRenameValueDescriptor(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	resetDemoData(t, mongo)
+
+	err := mongo.RenameValueDescriptor("temperature", "humidity")
+	if err != ErrNotUnique {
+		t.Fatalf("Expected ErrNotUnique, got %v", err)
+	}
+	err = mongo.RenameValueDescriptor("missing", "other")
+	if err != ErrNotFound {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+
+	err = mongo.RenameValueDescriptor("temperature", "ambient")
+	if err != nil {
+		t.Fatalf("Error renaming value descriptor: %v", err)
+	}
+	if _, err = mongo.ValueDescriptorByName("ambient"); err != nil {
+		t.Fatalf("Error getting the renamed value descriptor: %v", err)
+	}
+	if _, err = mongo.ValueDescriptorByName("temperature"); err != ErrNotFound {
+		t.Fatalf("The old name should be gone, got %v", err)
+	}
+
+	count, err := mongo.ReadingCountByValueDescriptor("ambient")
+	if err != nil {
+		t.Fatalf("Error getting ReadingCountByValueDescriptor: %v", err)
+	}
+	if count != seedEventCount {
+		t.Fatalf("There should be %d renamed readings, not %d", seedEventCount, count)
+	}
+	count, err = mongo.ReadingCountByValueDescriptor("temperature")
+	if err != nil {
+		t.Fatalf("Error getting ReadingCountByValueDescriptor: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("There should be no readings left under the old name, not %d", count)
+	}
+}
+
+func TestMongoAddEventReturning(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	e := models.Event{Device: "device", Readings: []models.Reading{{Name: "name1"}, {Name: "name2"}}}
+	added, err := mongo.AddEventReturning(&e)
+	if err != nil {
+		t.Fatalf("Error adding event: %v", err)
+	}
+	if !added.ID.Valid() || added.Created == 0 || added.Modified != added.Created {
+		t.Fatalf("Event was not populated: %v", added)
+	}
+	for _, r := range added.Readings {
+		if !r.Id.Valid() || r.Created != added.Created || r.Device != "device" {
+			t.Fatalf("Reading was not populated: %v", r)
+		}
+	}
+
+	stored, err := mongo.EventById(added.ID.Hex())
+	if err != nil {
+		t.Fatalf("Error getting event: %v", err)
+	}
+	if stored.Created != added.Created || len(stored.Readings) != len(added.Readings) {
+		t.Fatalf("Returned event %v does not match the stored one %v", added, stored)
+	}
+}
+
+func TestMongoQueryTimeout(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	resetDemoData(t, mongo)
+
+	mongo.config.QueryTimeout = 50
+	defer func() { mongo.config.QueryTimeout = 0 }()
+
+	// Each document sleeps longer than the query timeout
+	_, err := mongo.getEvents(bson.M{"$where": "sleep(100) || true"})
+	if err != ErrQueryTimeout {
+		t.Fatalf("Expected ErrQueryTimeout, got %v", err)
+	}
+
+	// Scans keep their own timeout
+	if _, err = mongo.ReadingCountsGrouped(); err != nil {
+		t.Fatalf("Error getting ReadingCountsGrouped: %v", err)
+	}
+}
+
+func TestMongoExists(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	e := models.Event{Device: "device", Readings: []models.Reading{{Name: "name"}}}
+	id, err := mongo.AddEvent(&e)
+	if err != nil {
+		t.Fatalf("Error adding event: %v", err)
+	}
+
+	exists, err := mongo.EventExists(id.Hex())
+	if err != nil || !exists {
+		t.Fatalf("Event should exist: %v %v", exists, err)
+	}
+	exists, err = mongo.ReadingExists(e.Readings[0].Id.Hex())
+	if err != nil || !exists {
+		t.Fatalf("Reading should exist: %v %v", exists, err)
+	}
+
+	exists, err = mongo.EventExists(bson.NewObjectId().Hex())
+	if err != nil || exists {
+		t.Fatalf("Event should not exist: %v %v", exists, err)
+	}
+	exists, err = mongo.ReadingExists(bson.NewObjectId().Hex())
+	if err != nil || exists {
+		t.Fatalf("Reading should not exist: %v %v", exists, err)
+	}
+
+	if _, err = mongo.EventExists("invalid"); err != ErrInvalidObjectId {
+		t.Fatalf("Expected ErrInvalidObjectId, got %v", err)
+	}
+	if _, err = mongo.ReadingExists("invalid"); err != ErrInvalidObjectId {
+		t.Fatalf("Expected ErrInvalidObjectId, got %v", err)
+	}
+}
+
+func TestMongoEventsByDevicesAndCreationTime(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	err := mongo.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events")
+	}
+	start := time.Now().UnixNano() / int64(time.Millisecond)
+	for _, device := range []string{"device1", "device2", "device3", "device1"} {
+		e := models.Event{Device: device}
+		if _, err = mongo.AddEvent(&e); err != nil {
+			t.Fatalf("Error adding event: %v", err)
+		}
+	}
+	end := time.Now().UnixNano() / int64(time.Millisecond)
+
+	events, err := mongo.EventsByDevicesAndCreationTime([]string{"device1", "device2"}, start, end, 10)
+	if err != nil {
+		t.Fatalf("Error getting EventsByDevicesAndCreationTime: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("There should be 3 events, not %d", len(events))
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i].Created > events[i-1].Created {
+			t.Fatalf("Events are not sorted newest first")
+		}
+	}
+
+	events, err = mongo.EventsByDevicesAndCreationTime([]string{"device1", "device2"}, start, end, 1)
+	if err != nil {
+		t.Fatalf("Error getting EventsByDevicesAndCreationTime: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("There should be 1 event, not %d", len(events))
+	}
+
+	events, err = mongo.EventsByDevicesAndCreationTime([]string{"device3"}, end+1, end+10, 10)
+	if err != nil {
+		t.Fatalf("Error getting EventsByDevicesAndCreationTime: %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("There should be 0 events, not %d", len(events))
+	}
+
+	events, err = mongo.EventsByDevicesAndCreationTime(nil, start, end, 10)
+	if err != nil || len(events) != 0 {
+		t.Fatalf("Expected no events for no devices: %v %v", events, err)
+	}
+	if _, err = mongo.EventsByDevicesAndCreationTime([]string{"device1"}, end, start-1, 10); err != ErrInvalidTimeRange {
+		t.Fatalf("Expected ErrInvalidTimeRange, got %v", err)
+	}
+}
+
+func TestMongoReconnect(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	// Queries running while the session is swapped must not fail
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				if _, err := mongo.EventCount(); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	for i := 0; i < 3; i++ {
+		if err := mongo.Reconnect(); err != nil {
+			t.Fatalf("Error reconnecting: %v", err)
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("Error querying during reconnect: %v", err)
+	}
+
+	if err := mongo.session().Ping(); err != nil {
+		t.Fatalf("Error pinging the new session: %v", err)
+	}
+}
+
+func TestMongoDBStats(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	resetDemoData(t, mongo)
+
+	count, err := mongo.ValueDescriptorCount()
+	if err != nil {
+		t.Fatalf("Error getting ValueDescriptorCount: %v", err)
+	}
+	if count != 3 {
+		t.Fatalf("There should be 3 value descriptors, not %d", count)
+	}
+
+	events, readings, vds, err := mongo.DBStats()
+	if err != nil {
+		t.Fatalf("Error getting DBStats: %v", err)
+	}
+	if events != seedEventCount || readings != 3*seedEventCount || vds != 3 {
+		t.Fatalf("Unexpected stats: %d events, %d readings, %d value descriptors", events, readings, vds)
+	}
+}
+
+func TestMongoReadingsAboveThreshold(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	err := mongo.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events")
+	}
+	e := models.Event{Device: "device"}
+	for _, value := range []string{"10", "20.5", "30", "not a number", "", "-5"} {
+		e.Readings = append(e.Readings, models.Reading{Name: "temperature", Value: value})
+	}
+	e.Readings = append(e.Readings, models.Reading{Name: "humidity", Value: "90"})
+	if _, err = mongo.AddEvent(&e); err != nil {
+		t.Fatalf("Error adding event: %v", err)
+	}
+
+	readings, err := mongo.ReadingsByValueDescriptorAboveThreshold("temperature", 15, 10)
+	if err != nil {
+		t.Fatalf("Error getting ReadingsByValueDescriptorAboveThreshold: %v", err)
+	}
+	if len(readings) != 2 {
+		t.Fatalf("There should be 2 readings, not %d", len(readings))
+	}
+	for _, r := range readings {
+		if r.Name != "temperature" || (r.Value != "20.5" && r.Value != "30") {
+			t.Fatalf("Unexpected reading %v", r)
+		}
+	}
+
+	readings, err = mongo.ReadingsByValueDescriptorAboveThreshold("temperature", 15, 1)
+	if err != nil {
+		t.Fatalf("Error getting ReadingsByValueDescriptorAboveThreshold: %v", err)
+	}
+	if len(readings) != 1 {
+		t.Fatalf("There should be 1 reading, not %d", len(readings))
+	}
+}
+
+func TestMongoEstimatedEventCount(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	resetDemoData(t, mongo)
+
+	// Nothing is being written so the metadata count is exact
+	count, err := mongo.EstimatedEventCount()
+	if err != nil {
+		t.Fatalf("Error getting EstimatedEventCount: %v", err)
+	}
+	if count != seedEventCount {
+		t.Fatalf("There should be %d events, not %d", seedEventCount, count)
+	}
+}
+
+func TestMongoAddEvents(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	err := mongo.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events")
+	}
+
+	// The middle event is over the 16MB document limit
+	events := []models.Event{
+		{Device: "device", Readings: []models.Reading{{Name: "name", Value: "1"}}},
+		{Device: "device", Readings: []models.Reading{{Name: "name", Value: strings.Repeat("x", 17*1024*1024)}}},
+		{Device: "device", Readings: []models.Reading{{Name: "name", Value: "3"}}},
+	}
+	results, err := mongo.AddEvents(events)
+	if err != nil {
+		t.Fatalf("Error adding events: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("There should be 3 results, not %d", len(results))
+	}
+	for i, r := range results {
+		if r.Index != i {
+			t.Fatalf("Result %d has index %d", i, r.Index)
+		}
+	}
+	if results[0].Err != nil || results[2].Err != nil || !results[0].ID.Valid() || !results[2].ID.Valid() {
+		t.Fatalf("The valid events should be added: %v", results)
+	}
+	if results[1].Err == nil || results[1].ID != "" {
+		t.Fatalf("The oversized event should be reported: %v", results[1])
+	}
+
+	count, err := mongo.EventCount()
+	if err != nil {
+		t.Fatalf("Error getting EventCount: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("There should be 2 events, not %d", count)
+	}
+}
+
+func TestMongoReadingsByDeviceSince(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	resetDemoData(t, mongo)
+
+	readings, err := mongo.ReadingsByDeviceSince(seedDeviceName, 0, 100)
+	if err != nil {
+		t.Fatalf("Error getting ReadingsByDeviceSince: %v", err)
+	}
+	if len(readings) != 3*seedEventCount {
+		t.Fatalf("There should be %d readings, not %d", 3*seedEventCount, len(readings))
+	}
+
+	// Nothing is newer than the newest reading
+	readings, err = mongo.ReadingsByDeviceSince(seedDeviceName, readings[0].Created, 100)
+	if err != nil {
+		t.Fatalf("Error getting ReadingsByDeviceSince: %v", err)
+	}
+	if len(readings) != 0 {
+		t.Fatalf("There should be 0 readings, not %d", len(readings))
+	}
+
+	if _, err = mongo.ReadingsByDeviceSince("", 0, 100); err != ErrEmptyDeviceId {
+		t.Fatalf("Expected ErrEmptyDeviceId, got %v", err)
+	}
+}
+
+func TestMongoCappedReadings(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	mongo.config.CappedReadings = true
+	mongo.config.ReadingsCapSize = 0
+	if err := mongo.ensureCappedReadings(); err != ErrInvalidCapSize {
+		t.Fatalf("Expected ErrInvalidCapSize, got %v", err)
+	}
+
+	s := mongo.getSessionCopy()
+	defer s.Close()
+	if err := s.DB(mongo.Database.Name).C(READINGS_COLLECTION).DropCollection(); err != nil {
+		t.Fatalf("Error dropping the readings collection: %v", err)
+	}
+	// Leave an uncapped collection for the other tests
+	defer s.DB(mongo.Database.Name).C(READINGS_COLLECTION).DropCollection()
+
+	mongo.config.ReadingsCapSize = 4096
+	mongo.config.ReadingsCapDocs = 5
+	if err := mongo.ensureCappedReadings(); err != nil {
+		t.Fatalf("Error creating the capped readings collection: %v", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		if _, err := mongo.AddReading(models.Reading{Name: "name"}); err != nil {
+			t.Fatalf("Error adding reading: %v", err)
+		}
+	}
+	count, err := mongo.ReadingCount()
+	if err != nil {
+		t.Fatalf("Error getting ReadingCount: %v", err)
+	}
+	if count != 5 {
+		t.Fatalf("The oldest readings should be evicted, there are %d readings", count)
+	}
+
+	// Calling it again keeps the capped collection
+	if err = mongo.ensureCappedReadings(); err != nil {
+		t.Fatalf("Error checking the capped readings collection: %v", err)
+	}
+}
+
+func TestMongoEventsWithReadings(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	resetDemoData(t, mongo)
+
+	expected, err := mongo.EventsForDeviceLimit(seedDeviceName, 3)
+	if err != nil {
+		t.Fatalf("Error getting EventsForDeviceLimit: %v", err)
+	}
+	events, err := mongo.EventsWithReadings(bson.M{"device": seedDeviceName}, 3)
+	if err != nil {
+		t.Fatalf("Error getting EventsWithReadings: %v", err)
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("There should be %d events, not %d", len(expected), len(events))
+	}
+	for i := range events {
+		if events[i].ID != expected[i].ID || len(events[i].Readings) != len(expected[i].Readings) {
+			t.Fatalf("Event %d doesn't match the de-referenced one: %v %v", i, events[i], expected[i])
+		}
+		for j := range events[i].Readings {
+			if !reflect.DeepEqual(events[i].Readings[j], expected[i].Readings[j]) {
+				t.Fatalf("Reading %d of event %d doesn't match: %v %v", j, i, events[i].Readings[j], expected[i].Readings[j])
+			}
+		}
+	}
+
+	events, err = mongo.EventsWithReadings(bson.M{"device": seedDeviceName}, 0)
+	if err != nil || len(events) != 0 {
+		t.Fatalf("A limit of 0 should return no events: %v %v", events, err)
+	}
+}
+
+// Keeps the warnings so tests can check what was logged
+type warnRecorder struct {
+	logger.MockLogger
+	mutex    sync.Mutex
+	warnings []string
+}
+
+func (r *warnRecorder) Warn(msg string, labels ...string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.warnings = append(r.warnings, msg)
+	return nil
+}
+
+func TestMongoSlowQueryLog(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	resetDemoData(t, mongo)
+
+	recorder := &warnRecorder{}
+	previous := loggingClient
+	loggingClient = recorder
+	defer func() { loggingClient = previous }()
+
+	// Disabled by default
+	if _, err := mongo.getEvents(bson.M{"$where": "sleep(10) || true"}); err != nil {
+		t.Fatalf("Error getting events: %v", err)
+	}
+	if len(recorder.warnings) != 0 {
+		t.Fatalf("Nothing should be logged when disabled: %v", recorder.warnings)
+	}
+
+	mongo.config.SlowQuery = 5
+	defer func() { mongo.config.SlowQuery = 0 }()
+	if _, err := mongo.getEvents(bson.M{"$where": "sleep(10) || true"}); err != nil {
+		t.Fatalf("Error getting events: %v", err)
+	}
+	if len(recorder.warnings) != 1 || !strings.Contains(recorder.warnings[0], "getEvents") {
+		t.Fatalf("The slow query should be logged: %v", recorder.warnings)
+	}
+}
+
+func TestMongoValueDescriptorCache(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	resetDemoData(t, mongo)
+	mongo.vdCache = newValueDescriptorCache(time.Minute)
+	defer func() { mongo.vdCache = nil }()
+
+	v, err := mongo.ValueDescriptorByName("temperature")
+	if err != nil {
+		t.Fatalf("Error getting ValueDescriptorByName: %v", err)
+	}
+	if _, ok := mongo.vdCache.get("temperature"); !ok {
+		t.Fatalf("The value descriptor should be cached")
+	}
+
+	v.UomLabel = "degreesC"
+	if err = mongo.UpdateValueDescriptor(v); err != nil {
+		t.Fatalf("Error updating value descriptor: %v", err)
+	}
+	v, err = mongo.ValueDescriptorByName("temperature")
+	if err != nil {
+		t.Fatalf("Error getting ValueDescriptorByName: %v", err)
+	}
+	if v.UomLabel != "degreesC" {
+		t.Fatalf("The update should invalidate the cache, got %s", v.UomLabel)
+	}
+
+	if err = mongo.ScrubAllEvents(); err != nil {
+		t.Fatalf("Error removing all events: %v", err)
+	}
+	if err = mongo.DeleteValueDescriptorByName("temperature"); err != nil {
+		t.Fatalf("Error deleting value descriptor: %v", err)
+	}
+	if _, err = mongo.ValueDescriptorByName("temperature"); err != ErrNotFound {
+		t.Fatalf("The delete should invalidate the cache, got %v", err)
+	}
+
+	if _, err = mongo.AddReading(models.Reading{Name: "undescribed"}); err != nil {
+		t.Fatalf("Error adding reading: %v", err)
+	}
+	if err = mongo.DeleteValueDescriptorByName("undescribed"); err != ErrNotFound {
+		t.Fatalf("A missing value descriptor should be ErrNotFound even when readings use the name, got %v", err)
+	}
+}
+
+func TestMongoEventCountByReadingValueDescriptor(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	err := mongo.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events")
+	}
+	for i := 0; i < 3; i++ {
+		// Two temperatures in the same event only count the event once
+		e := models.Event{Device: "device", Readings: []models.Reading{{Name: "temperature"}, {Name: "temperature"}}}
+		if i == 0 {
+			e.Readings = []models.Reading{{Name: "humidity"}}
+		}
+		if _, err = mongo.AddEvent(&e); err != nil {
+			t.Fatalf("Error adding event: %v", err)
+		}
+	}
+
+	count, err := mongo.EventCountByReadingValueDescriptor("temperature")
+	if err != nil {
+		t.Fatalf("Error getting EventCountByReadingValueDescriptor: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("There should be 2 events, not %d", count)
+	}
+
+	count, err = mongo.EventCountByReadingValueDescriptor("pressure")
+	if err != nil {
+		t.Fatalf("Error getting EventCountByReadingValueDescriptor: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("There should be 0 events, not %d", count)
+	}
+}
+
+func TestMongoTrimReadingsForEventsOlderThan(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	resetDemoData(t, mongo)
+	mongo.config.BatchDeleteSize = 2
+	defer func() { mongo.config.BatchDeleteSize = 0 }()
+
+	time.Sleep(10 * time.Millisecond)
+	e := models.Event{Device: "recent", Readings: []models.Reading{{Name: "temperature"}}}
+	if _, err := mongo.AddEvent(&e); err != nil {
+		t.Fatalf("Error adding event: %v", err)
+	}
+
+	removed, err := mongo.TrimReadingsForEventsOlderThan(5)
+	if err != nil {
+		t.Fatalf("Error trimming readings: %v", err)
+	}
+	if removed != 3*seedEventCount {
+		t.Fatalf("There should be %d readings removed, not %d", 3*seedEventCount, removed)
+	}
+
+	events, err := mongo.EventsForDevice(seedDeviceName)
+	if err != nil {
+		t.Fatalf("Error getting EventsForDevice: %v", err)
+	}
+	if len(events) != seedEventCount {
+		t.Fatalf("The events should be kept, there are %d", len(events))
+	}
+	for _, e := range events {
+		if len(e.Readings) != 0 {
+			t.Fatalf("The readings of the old events should be removed: %v", e.Readings)
+		}
+	}
+
+	count, err := mongo.ReadingCount()
+	if err != nil {
+		t.Fatalf("Error getting ReadingCount: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("Only the recent reading should be left, there are %d", count)
+	}
+}
+
+func TestMongoEventsForDevicePagedSorted(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	resetDemoData(t, mongo)
+
+	events, err := mongo.EventsForDevicePagedSorted(seedDeviceName, "origin", true, 1, 3)
+	if err != nil {
+		t.Fatalf("Error getting EventsForDevicePagedSorted: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("There should be 3 events, not %d", len(events))
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i].Origin < events[i-1].Origin {
+			t.Fatalf("Events are not sorted by ascending origin")
+		}
+	}
+
+	events, err = mongo.EventsForDevicePagedSorted(seedDeviceName, "origin", false, 0, 10)
+	if err != nil {
+		t.Fatalf("Error getting EventsForDevicePagedSorted: %v", err)
+	}
+	if len(events) != seedEventCount {
+		t.Fatalf("There should be %d events, not %d", seedEventCount, len(events))
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i].Origin > events[i-1].Origin {
+			t.Fatalf("Events are not sorted by descending origin")
+		}
+	}
+
+	for _, field := range []string{"device", "$where", "-created", ""} {
+		if _, err = mongo.EventsForDevicePagedSorted(seedDeviceName, field, true, 0, 10); err != ErrInvalidSortField {
+			t.Fatalf("Expected ErrInvalidSortField for %q, got %v", field, err)
+		}
+	}
+}
+
+func TestMongoScrubNotAllowed(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	resetDemoData(t, mongo)
+
+	mongo.config.AllowScrub = false
+	if err := mongo.ScrubAllEvents(); err != ErrScrubNotAllowed {
+		t.Fatalf("Expected ErrScrubNotAllowed, got %v", err)
+	}
+	if err := mongo.ScrubAllValueDescriptors(); err != ErrScrubNotAllowed {
+		t.Fatalf("Expected ErrScrubNotAllowed, got %v", err)
+	}
+
+	events, readings, vds, err := mongo.DBStats()
+	if err != nil {
+		t.Fatalf("Error getting DBStats: %v", err)
+	}
+	if events != seedEventCount || readings != 3*seedEventCount || vds != 3 {
+		t.Fatalf("Nothing should be removed: %d events, %d readings, %d value descriptors", events, readings, vds)
+	}
+}
+
+func TestMongoConvertReading(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	resetDemoData(t, mongo)
+
+	r := models.Reading{Name: "temperature", Value: "212"}
+	converted, err := mongo.ConvertReading(r, "degreesC")
+	if err != nil {
+		t.Fatalf("Error converting reading: %v", err)
+	}
+	if converted.Value != "100" || r.Value != "212" {
+		t.Fatalf("The copy should be converted and the reading kept: %v %v", converted, r)
+	}
+
+	if _, err = mongo.ConvertReading(models.Reading{Name: "humidity", Value: "50"}, "C"); err != ErrUnsupportedConversion {
+		t.Fatalf("Expected ErrUnsupportedConversion, got %v", err)
+	}
+	if _, err = mongo.ConvertReading(models.Reading{Name: "missing", Value: "50"}, "C"); err != ErrNotFound {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestMongoEventsPushedBetween(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	err := mongo.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events")
+	}
+	for _, pushed := range []int64{0, 300, 100, 200, 400} {
+		e := models.Event{Device: "device", Pushed: pushed}
+		if _, err = mongo.AddEvent(&e); err != nil {
+			t.Fatalf("Error adding event: %v", err)
+		}
+	}
+
+	events, err := mongo.EventsPushedBetween(100, 300, 10)
+	if err != nil {
+		t.Fatalf("Error getting EventsPushedBetween: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("There should be 3 events, not %d", len(events))
+	}
+	for i, pushed := range []int64{100, 200, 300} {
+		if events[i].Pushed != pushed {
+			t.Fatalf("Events are not sorted by pushed: %d at %d", events[i].Pushed, i)
+		}
+	}
+
+	events, err = mongo.EventsPushedBetween(100, 300, 2)
+	if err != nil {
+		t.Fatalf("Error getting EventsPushedBetween: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("There should be 2 events, not %d", len(events))
+	}
+
+	if _, err = mongo.EventsPushedBetween(300, 100, 10); err != ErrInvalidTimeRange {
+		t.Fatalf("Expected ErrInvalidTimeRange, got %v", err)
+	}
+}
+
+func TestMongoEnsureIndexes(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	s := mongo.getSessionCopy()
+	defer s.Close()
+	if err := s.DB(mongo.Database.Name).C(EVENTS_COLLECTION).DropCollection(); err != nil {
+		t.Fatalf("Error dropping the events collection: %v", err)
+	}
+	// The driver skips the indexes it remembers ensuring
+	s.ResetIndexCache()
+
+	results, err := mongo.ensureIndexes()
+	if err != nil {
+		t.Fatalf("Error ensuring the indexes: %v", err)
+	}
+	if len(results) != len(mongoIndexes) {
+		t.Fatalf("There should be %d index results, not %d", len(mongoIndexes), len(results))
+	}
+	for _, r := range results {
+		if r.Collection == EVENTS_COLLECTION && !r.Created {
+			t.Fatalf("Index %s on the dropped events collection should be created", r.Name)
+		}
+	}
+
+	// Running it again finds every index in place
+	results, err = mongo.ensureIndexes()
+	if err != nil {
+		t.Fatalf("Error ensuring the indexes: %v", err)
+	}
+	for _, r := range results {
+		if r.Created {
+			t.Fatalf("Index %s on %s already existed", r.Name, r.Collection)
+		}
+	}
+}
+
+func TestMongoSkipIndexes(t *testing.T) {
+	mongo := connectTestMongo(t)
+	s := mongo.getSessionCopy()
+	if err := s.DB(mongo.Database.Name).C(EVENTS_COLLECTION).DropCollection(); err != nil {
+		t.Fatalf("Error dropping the events collection: %v", err)
+	}
+	s.ResetIndexCache()
+	s.Close()
+	mongo.CloseSession()
+
+	skipping, err := newMongoClient(DBConfiguration{
+		DbType:       MONGO,
+		Host:         "0.0.0.0",
+		Port:         27017,
+		DatabaseName: "coredata",
+		Timeout:      1000,
+		SkipIndexes:  true,
+	})
+	if err != nil {
+		t.Fatalf("Could not connect with mongodb: %v", err)
+	}
+	defer skipping.CloseSession()
+
+	if _, err = skipping.AddEvent(&models.Event{Device: "device"}); err != nil {
+		t.Fatalf("Error adding an event: %v", err)
+	}
+	s = skipping.getSessionCopy()
+	defer s.Close()
+	indexes, err := s.DB(skipping.Database.Name).C(EVENTS_COLLECTION).Indexes()
+	if err != nil {
+		t.Fatalf("Error listing the indexes: %v", err)
+	}
+	if len(indexes) != 1 {
+		t.Fatalf("Only the _id index should exist, not %v", indexes)
+	}
+}
+
+func TestMongoDeleteReadingsByQuery(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	err := mongo.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events")
+	}
+	e := models.Event{Device: "device", Readings: []models.Reading{
+		{Device: "device", Name: "name1"},
+		{Device: "device", Name: "name1"},
+		{Device: "device", Name: "name2"},
+	}}
+	id, err := mongo.AddEvent(&e)
+	if err != nil {
+		t.Fatalf("Error adding event: %v", err)
+	}
+
+	filter := ReadingFilter{Device: "device", Name: "name1"}
+	if _, err = mongo.DeleteReadingsByQuery(filter, 1); err != ErrDeleteLimitExceeded {
+		t.Fatalf("Expected ErrDeleteLimitExceeded, got %v", err)
+	}
+	count, err := mongo.ReadingCount()
+	if err != nil {
+		t.Fatalf("Error getting ReadingCount: %v", err)
+	}
+	if count != 3 {
+		t.Fatalf("No reading should be removed over the limit, there are %d readings", count)
+	}
+
+	removed, err := mongo.DeleteReadingsByQuery(filter, 2)
+	if err != nil {
+		t.Fatalf("Error deleting readings: %v", err)
+	}
+	if removed != 2 {
+		t.Fatalf("There should be 2 readings removed, not %d", removed)
+	}
+
+	// The event no longer references the removed readings
+	e, err = mongo.EventById(id.Hex())
+	if err != nil {
+		t.Fatalf("Error getting the event: %v", err)
+	}
+	if len(e.Readings) != 1 || e.Readings[0].Name != "name2" {
+		t.Fatalf("The event should keep only the name2 reading: %v", e.Readings)
+	}
+
+	if _, err = mongo.DeleteReadingsByQuery(ReadingFilter{Start: 200, End: 100}, 10); err != ErrInvalidTimeRange {
+		t.Fatalf("Expected ErrInvalidTimeRange, got %v", err)
+	}
+}
+
+// Run with -race to catch the unguarded shared state
+func TestMongoConcurrentClients(t *testing.T) {
+	config := DBConfiguration{
+		DbType:       MONGO,
+		Host:         "0.0.0.0",
+		Port:         27017,
+		DatabaseName: "coredata",
+		Timeout:      1000,
+		CacheVDs:     true,
+		VDCacheTTL:   60000,
+	}
+
+	const count = 8
+	clients := make([]*MongoClient, count)
+	errs := make([]error, count)
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clients[i], errs[i] = newMongoClient(config)
+			if errs[i] == nil {
+				_, errs[i] = getCurrentMongoClient()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < count; i++ {
+		if errs[i] != nil {
+			t.Fatalf("Error creating client %d: %v", i, errs[i])
+		}
+		defer clients[i].CloseSession()
+		if err := clients[i].session().Ping(); err != nil {
+			t.Fatalf("Client %d can't reach the database: %v", i, err)
+		}
+	}
+
+	current, err := getCurrentMongoClient()
+	if err != nil {
+		t.Fatalf("Error getting the current client: %v", err)
+	}
+	found := false
+	for _, c := range clients {
+		found = found || c == current
+	}
+	if !found {
+		t.Fatalf("The current client should be one of the clients created")
+	}
+}
+
+func TestMongoReadingsByDeviceGrouped(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	resetDemoData(t, mongo)
+
+	groups, err := mongo.ReadingsByDeviceGrouped(seedDeviceName, 2)
+	if err != nil {
+		t.Fatalf("Error getting ReadingsByDeviceGrouped: %v", err)
+	}
+	if len(groups) != 3 {
+		t.Fatalf("There should be 3 groups, not %d", len(groups))
+	}
+	for name, readings := range groups {
+		if len(readings) != 2 {
+			t.Fatalf("Group %s should have 2 readings, not %d", name, len(readings))
+		}
+		for _, r := range readings {
+			if r.Name != name {
+				t.Fatalf("Reading %s is in group %s", r.Name, name)
+			}
+		}
+	}
+	// The newest seeded temperature comes first
+	if groups["temperature"][0].Value != "72" {
+		t.Fatalf("The newest temperature should be 72, not %s", groups["temperature"][0].Value)
+	}
+
+	groups, err = mongo.ReadingsByDeviceGrouped("unknown", 2)
+	if err != nil {
+		t.Fatalf("Error getting ReadingsByDeviceGrouped: %v", err)
+	}
+	if len(groups) != 0 {
+		t.Fatalf("There should be no groups, not %d", len(groups))
+	}
+
+	if _, err = mongo.ReadingsByDeviceGrouped("", 2); err != ErrEmptyDeviceId {
+		t.Fatalf("Expected ErrEmptyDeviceId, got %v", err)
+	}
+}
+
+func TestMongoReadingsProjected(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	resetDemoData(t, mongo)
+
+	fields := []string{"device", "name", "value", "created"}
+	readings, err := mongo.ReadingsProjected(bson.M{"name": "temperature"}, fields, 2)
+	if err != nil {
+		t.Fatalf("Error getting ReadingsProjected: %v", err)
+	}
+	if len(readings) != 2 {
+		t.Fatalf("There should be 2 readings, not %d", len(readings))
+	}
+	for _, r := range readings {
+		if len(r) != len(fields) {
+			t.Fatalf("Only the projected fields should be returned: %v", r)
+		}
+		if r["name"] != "temperature" || r["device"] != seedDeviceName {
+			t.Fatalf("Unexpected reading: %v", r)
+		}
+	}
+
+	if _, err = mongo.ReadingsProjected(bson.M{}, []string{"name", "$where"}, 2); err != ErrInvalidProjection {
+		t.Fatalf("Expected ErrInvalidProjection, got %v", err)
+	}
+	if _, err = mongo.ReadingsProjected(bson.M{}, nil, 2); err != ErrInvalidProjection {
+		t.Fatalf("Expected ErrInvalidProjection, got %v", err)
+	}
+}
+
+func TestMongoBackfillReadingEventIds(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	err := mongo.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events")
+	}
+
+	s := mongo.getSessionCopy()
+	defer s.Close()
+	readings := s.DB(mongo.Database.Name).C(READINGS_COLLECTION)
+
+	// Events stored before the back-reference was written
+	for i := 0; i < 3; i++ {
+		e := models.Event{ID: bson.NewObjectId(), Device: "device", Created: int64(i)}
+		for j := 0; j < 2; j++ {
+			r := models.Reading{Id: bson.NewObjectId(), Device: "device", Name: "name", Created: int64(i)}
+			if err = readings.Insert(r); err != nil {
+				t.Fatalf("Error adding reading: %v", err)
+			}
+			e.Readings = append(e.Readings, r)
+		}
+		if err = s.DB(mongo.Database.Name).C(EVENTS_COLLECTION).Insert(MongoEvent{Event: e}); err != nil {
+			t.Fatalf("Error adding event: %v", err)
+		}
+	}
+	// AddEvent writes the back-reference itself
+	e := models.Event{Device: "device", Readings: []models.Reading{{Name: "name"}}}
+	id, err := mongo.AddEvent(&e)
+	if err != nil {
+		t.Fatalf("Error adding event: %v", err)
+	}
+	count, err := readings.Find(bson.M{"eventId": id}).Count()
+	if err != nil || count != 1 {
+		t.Fatalf("AddEvent should set the event id of its reading: %d, %v", count, err)
+	}
+
+	updated, err := mongo.BackfillReadingEventIds()
+	if err != nil {
+		t.Fatalf("Error backfilling the event ids: %v", err)
+	}
+	if updated != 6 {
+		t.Fatalf("There should be 6 readings updated, not %d", updated)
+	}
+	if count, _ = readings.Find(bson.M{"eventId": bson.M{"$exists": false}}).Count(); count != 0 {
+		t.Fatalf("There are %d readings without an event id", count)
+	}
+
+	// Re-running finds nothing left to do
+	if updated, err = mongo.BackfillReadingEventIds(); err != nil || updated != 0 {
+		t.Fatalf("Re-running should update no readings: %d, %v", updated, err)
+	}
+}
+
+func TestMongoEventsByReadingLabel(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	resetDemoData(t, mongo)
+	e := models.Event{Device: "other", Readings: []models.Reading{{Name: "unlabeled"}}}
+	if _, err := mongo.AddEvent(&e); err != nil {
+		t.Fatalf("Error adding event: %v", err)
+	}
+
+	events, err := mongo.EventsByReadingLabel("humidity", 100)
+	if err != nil {
+		t.Fatalf("Error getting EventsByReadingLabel: %v", err)
+	}
+	if len(events) != seedEventCount {
+		t.Fatalf("There should be %d events, not %d", seedEventCount, len(events))
+	}
+	for _, e := range events {
+		if e.Device != seedDeviceName {
+			t.Fatalf("Event of device %s has no labeled reading", e.Device)
+		}
+	}
+
+	events, err = mongo.EventsByReadingLabel("environment", 2)
+	if err != nil {
+		t.Fatalf("Error getting EventsByReadingLabel: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("There should be 2 events, not %d", len(events))
+	}
+
+	events, err = mongo.EventsByReadingLabel("critical", 100)
+	if err != nil {
+		t.Fatalf("Error getting EventsByReadingLabel: %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("There should be no events, not %d", len(events))
+	}
+}
+
+func TestMongoEventTooLarge(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	err := mongo.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events")
+	}
+
+	mongo.config.MaxReadings = 2
+	e := models.Event{Device: "device", Readings: []models.Reading{{Name: "name"}, {Name: "name"}, {Name: "name"}}}
+	if _, err = mongo.AddEvent(&e); err != ErrEventTooLarge {
+		t.Fatalf("Expected ErrEventTooLarge, got %v", err)
+	}
+
+	e = models.Event{Device: "device", Readings: []models.Reading{{Name: "name", Value: strings.Repeat("x", maxEventDocumentSize)}}}
+	if _, err = mongo.AddEvent(&e); err != ErrEventTooLarge {
+		t.Fatalf("Expected ErrEventTooLarge, got %v", err)
+	}
+
+	// Nothing is inserted for the rejected events
+	count, err := mongo.ReadingCount()
+	if err != nil {
+		t.Fatalf("Error getting ReadingCount: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("There should be no readings, not %d", count)
+	}
+
+	e = models.Event{Device: "device", Readings: []models.Reading{{Name: "name"}, {Name: "name"}}}
+	if _, err = mongo.AddEvent(&e); err != nil {
+		t.Fatalf("Error adding event: %v", err)
+	}
+}
+
+func TestMongoLatest(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	resetDemoData(t, mongo)
+
+	events, err := mongo.LatestEvents(2)
+	if err != nil {
+		t.Fatalf("Error getting LatestEvents: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("There should be 2 events, not %d", len(events))
+	}
+	if events[0].Created < events[1].Created {
+		t.Fatalf("Events are not sorted newest first")
+	}
+
+	readings, err := mongo.LatestReadings(4)
+	if err != nil {
+		t.Fatalf("Error getting LatestReadings: %v", err)
+	}
+	if len(readings) != 4 {
+		t.Fatalf("There should be 4 readings, not %d", len(readings))
+	}
+	for i := 1; i < len(readings); i++ {
+		if readings[i-1].Created < readings[i].Created {
+			t.Fatalf("Readings are not sorted newest first")
+		}
+	}
+
+	if events, err = mongo.LatestEvents(0); err != nil || len(events) != 0 {
+		t.Fatalf("A limit of 0 should return no events: %d, %v", len(events), err)
+	}
+	if readings, err = mongo.LatestReadings(0); err != nil || len(readings) != 0 {
+		t.Fatalf("A limit of 0 should return no readings: %d, %v", len(readings), err)
+	}
+}
+
+func TestMongoMatching(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	resetDemoData(t, mongo)
+
+	events, err := mongo.EventsMatching(EventQuery{}.Device(seedDeviceName).Pushed(false).Limit(2))
+	if err != nil {
+		t.Fatalf("Error getting EventsMatching: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("There should be 2 events, not %d", len(events))
+	}
+
+	events, err = mongo.EventsMatching(EventQuery{}.Pushed(true))
+	if err != nil {
+		t.Fatalf("Error getting EventsMatching: %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("There should be no pushed events, not %d", len(events))
+	}
+
+	readings, err := mongo.ReadingsMatching(ReadingQuery{}.Device(seedDeviceName).Name("humidity"))
+	if err != nil {
+		t.Fatalf("Error getting ReadingsMatching: %v", err)
+	}
+	if len(readings) != seedEventCount {
+		t.Fatalf("There should be %d readings, not %d", seedEventCount, len(readings))
+	}
+
+	if _, err = mongo.ReadingsMatching(ReadingQuery{}.Between(200, 100)); err != ErrInvalidTimeRange {
+		t.Fatalf("Expected ErrInvalidTimeRange, got %v", err)
+	}
+}
+
+func TestMongoCorruptEvent(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	err := mongo.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events")
+	}
+
+	s := mongo.getSessionCopy()
+	defer s.Close()
+	events := s.DB(mongo.Database.Name).C(EVENTS_COLLECTION)
+
+	// The reading the event references was never stored
+	missing := models.Event{ID: bson.NewObjectId(), Device: "device", Readings: []models.Reading{{Id: bson.NewObjectId()}}}
+	if err = events.Insert(MongoEvent{Event: missing}); err != nil {
+		t.Fatalf("Error adding event: %v", err)
+	}
+	_, err = mongo.EventById(missing.ID.Hex())
+	if corrupt, ok := err.(ErrCorruptEvent); !ok || corrupt.Id != missing.ID.Hex() {
+		t.Fatalf("Expected ErrCorruptEvent for %s, got %v", missing.ID.Hex(), err)
+	}
+
+	// Evicted by the capped readings collection
+	mongo.config.CappedReadings = true
+	evicted, err := mongo.EventById(missing.ID.Hex())
+	mongo.config.CappedReadings = false
+	if err != nil || len(evicted.Readings) != 0 {
+		t.Fatalf("The evicted readings should be skipped, got %v, %v", evicted.Readings, err)
+	}
+
+	// The readings can't be decoded as references
+	malformed := bson.NewObjectId()
+	if err = events.Insert(bson.M{"_id": malformed, "device": "device", "readings": "malformed"}); err != nil {
+		t.Fatalf("Error adding event: %v", err)
+	}
+	_, err = mongo.EventById(malformed.Hex())
+	if corrupt, ok := err.(ErrCorruptEvent); !ok || corrupt.Id != malformed.Hex() {
+		t.Fatalf("Expected ErrCorruptEvent for %s, got %v", malformed.Hex(), err)
+	}
+
+	if _, err = mongo.EventById(bson.NewObjectId().Hex()); err != ErrNotFound {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestMongoCountsUseSecondary(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	s := mongo.countsFromSecondary(mongo.getSessionCopy())
+	if s.Mode() != mgo.Strong {
+		t.Fatalf("The counts should read from the primary by default, mode %v", s.Mode())
+	}
+	s.Close()
+
+	mongo.config.CountsSecondary = true
+	s = mongo.countsFromSecondary(mongo.getSessionCopy())
+	if s.Mode() != mgo.SecondaryPreferred {
+		t.Fatalf("The counts should prefer a secondary, mode %v", s.Mode())
+	}
+	s.Close()
+
+	// Without a secondary the counts fall back to the primary
+	resetDemoData(t, mongo)
+	count, err := mongo.EventCount()
+	if err != nil {
+		t.Fatalf("Error getting EventCount: %v", err)
+	}
+	if count != seedEventCount {
+		t.Fatalf("There should be %d events, not %d", seedEventCount, count)
+	}
+}
+
+func TestMongoDeleteValueDescriptorsByIds(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	resetDemoData(t, mongo)
+	unused, err := mongo.AddValueDescriptor(models.ValueDescriptor{Name: "unused"})
+	if err != nil {
+		t.Fatalf("Error adding value descriptor: %v", err)
+	}
+	temperature, err := mongo.ValueDescriptorByName("temperature")
+	if err != nil {
+		t.Fatalf("Error getting the value descriptor: %v", err)
+	}
+
+	if _, err = mongo.DeleteValueDescriptorsByIds([]string{unused.Hex(), "invalid"}); err != ErrInvalidObjectId {
+		t.Fatalf("Expected ErrInvalidObjectId, got %v", err)
+	}
+
+	ids := []string{unused.Hex(), temperature.Id.Hex(), bson.NewObjectId().Hex()}
+	removed, err := mongo.DeleteValueDescriptorsByIds(ids)
+	inUse, ok := err.(ErrValueDescriptorsInUse)
+	if !ok || len(inUse.Ids) != 1 || inUse.Ids[0] != temperature.Id.Hex() {
+		t.Fatalf("Expected ErrValueDescriptorsInUse for the temperature, got %v", err)
+	}
+	if removed != 1 {
+		t.Fatalf("There should be 1 value descriptor removed, not %d", removed)
+	}
+
+	if _, err = mongo.ValueDescriptorById(unused.Hex()); err != ErrNotFound {
+		t.Fatalf("The unused value descriptor should be removed, got %v", err)
+	}
+	if _, err = mongo.ValueDescriptorById(temperature.Id.Hex()); err != nil {
+		t.Fatalf("The temperature value descriptor should be kept, got %v", err)
+	}
+}
+
+func TestMongoEventsWithClockSkew(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	err := mongo.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events")
+	}
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	for _, origin := range []int64{0, now, now - 60000, now + 60000} {
+		e := models.Event{Device: "device", Origin: origin}
+		if _, err = mongo.AddEvent(&e); err != nil {
+			t.Fatalf("Error adding event: %v", err)
+		}
+	}
+
+	events, err := mongo.EventsWithClockSkew(30000, 10)
+	if err != nil {
+		t.Fatalf("Error getting EventsWithClockSkew: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("There should be 2 skewed events, not %d", len(events))
+	}
+	for _, e := range events {
+		if e.Origin == 0 || e.Origin == now {
+			t.Fatalf("Event with origin %d isn't skewed", e.Origin)
+		}
+	}
+
+	events, err = mongo.EventsWithClockSkew(30000, 1)
+	if err != nil {
+		t.Fatalf("Error getting EventsWithClockSkew: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("There should be 1 event, not %d", len(events))
+	}
+}
+
+func TestMongoReadingsModifiedSince(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	err := mongo.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events")
+	}
+	start := time.Now().UnixNano()/int64(time.Millisecond) - 1
+	var ids []bson.ObjectId
+	for i := 0; i < 3; i++ {
+		id, err := mongo.AddReading(models.Reading{Name: "name", Value: strconv.Itoa(i)})
+		if err != nil {
+			t.Fatalf("Error adding reading: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	readings, err := mongo.ReadingsModifiedSince(start, 10)
+	if err != nil {
+		t.Fatalf("Error getting ReadingsModifiedSince: %v", err)
+	}
+	if len(readings) != 3 {
+		t.Fatalf("There should be 3 readings, not %d", len(readings))
+	}
+	last := readings[len(readings)-1].Modified
+
+	// Only the updated reading changed since the last poll
+	time.Sleep(2 * time.Millisecond)
+	r, err := mongo.ReadingById(ids[0].Hex())
+	if err != nil {
+		t.Fatalf("Error getting the reading: %v", err)
+	}
+	r.Value = "updated"
+	if err = mongo.UpdateReading(r); err != nil {
+		t.Fatalf("Error updating the reading: %v", err)
+	}
+	readings, err = mongo.ReadingsModifiedSince(last, 10)
+	if err != nil {
+		t.Fatalf("Error getting ReadingsModifiedSince: %v", err)
+	}
+	if len(readings) != 1 || readings[0].Id != ids[0] {
+		t.Fatalf("Only the updated reading should be returned: %v", readings)
+	}
+
+	if readings, err = mongo.ReadingsModifiedSince(start, 0); err != nil || len(readings) != 0 {
+		t.Fatalf("A limit of 0 should return no readings: %d, %v", len(readings), err)
+	}
+}
+
+func TestMongoLimits(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	resetDemoData(t, mongo)
+
+	tests := []struct {
+		name     string
+		limit    int
+		expected int
+		err      error
+	}{
+		{"negative", -1, 0, ErrInvalidLimit},
+		{"zero", 0, 0, nil},
+		{"positive", 2, 2, nil},
+		{"over the count", 100, seedEventCount, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := mongo.EventsForDeviceLimit(seedDeviceName, tt.limit)
+			if err != tt.err {
+				t.Fatalf("Expected %v from EventsForDeviceLimit, got %v", tt.err, err)
+			}
+			if len(events) != tt.expected {
+				t.Fatalf("There should be %d events, not %d", tt.expected, len(events))
+			}
+
+			readings, err := mongo.ReadingsByValueDescriptor("temperature", tt.limit)
+			if err != tt.err {
+				t.Fatalf("Expected %v from ReadingsByValueDescriptor, got %v", tt.err, err)
+			}
+			if len(readings) != tt.expected {
+				t.Fatalf("There should be %d readings, not %d", tt.expected, len(readings))
+			}
+		})
+	}
+}
+
+func TestMongoValueDescriptorsByUomLabels(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+
+	resetDemoData(t, mongo)
+
+	vds, err := mongo.ValueDescriptorsByUomLabels([]string{"degreesF", "kPa", "unknown"})
+	if err != nil {
+		t.Fatalf("Error getting ValueDescriptorsByUomLabels: %v", err)
+	}
+	if len(vds) != 2 {
+		t.Fatalf("There should be 2 value descriptors, not %d", len(vds))
+	}
+	for _, v := range vds {
+		if v.UomLabel != "degreesF" && v.UomLabel != "kPa" {
+			t.Fatalf("Unexpected UOM label %s", v.UomLabel)
+		}
+	}
+
+	vds, err = mongo.ValueDescriptorsByUomLabels(nil)
+	if err != nil {
+		t.Fatalf("Error getting ValueDescriptorsByUomLabels: %v", err)
+	}
+	if vds == nil || len(vds) != 0 {
+		t.Fatalf("An empty list of labels should return an empty list: %v", vds)
+	}
+}
+
+func TestMongoSessionLeak(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+	mongo.config.TrackSessions = true
+
+	recorder := &warnRecorder{}
+	previous := loggingClient
+	loggingClient = recorder
+	defer func() { loggingClient = previous }()
+
+	// Closed copies aren't reported
+	mongo.getSessionCopy().Close()
+	// The copy is dropped without being closed
+	mongo.getSessionCopy()
+
+	for i := 0; i < 10; i++ {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+		recorder.mutex.Lock()
+		count := len(recorder.warnings)
+		recorder.mutex.Unlock()
+		if count > 0 {
+			break
+		}
+	}
+
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	if len(recorder.warnings) != 1 || !strings.Contains(recorder.warnings[0], "TestMongoSessionLeak") {
+		t.Fatalf("The leaked copy should be reported with its stack: %v", recorder.warnings)
+	}
+}
+
+func TestMongoSessionLeakTimeout(t *testing.T) {
+	mongo := connectTestMongo(t)
+	defer mongo.CloseSession()
+	mongo.config.TrackSessions = true
+	mongo.config.LeakTimeout = 20
+
+	recorder := &warnRecorder{}
+	previous := loggingClient
+	loggingClient = recorder
+	defer func() { loggingClient = previous }()
+
+	// Closed in time, the timer is stopped
+	mongo.getSessionCopy().Close()
+	// Still open after the timeout, it's reported while its owner holds it
+	s := mongo.getSessionCopy()
+	defer s.Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	if len(recorder.warnings) != 1 || !strings.Contains(recorder.warnings[0], "open for more than") ||
+		!strings.Contains(recorder.warnings[0], "TestMongoSessionLeakTimeout") {
+		t.Fatalf("The copy open after the timeout should be reported with its stack: %v", recorder.warnings)
+	}
+}
@@ -0,0 +1,218 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package clients
+
+import (
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// A page of events along with the total number of events matching the query
+type PagedEvents struct {
+	Items []models.Event `json:"items"`
+	Total int            `json:"total"`
+	Skip  int            `json:"skip"`
+	Limit int            `json:"limit"`
+}
+
+// A page of readings along with the total number of readings matching the query
+type PagedReadings struct {
+	Items []models.Reading `json:"items"`
+	Total int              `json:"total"`
+	Skip  int              `json:"skip"`
+	Limit int              `json:"limit"`
+}
+
+// A page of value descriptors along with the total number of value descriptors matching the query
+type PagedValueDescriptors struct {
+	Items []models.ValueDescriptor `json:"items"`
+	Total int                      `json:"total"`
+	Skip  int                      `json:"skip"`
+	Limit int                      `json:"limit"`
+}
+
+// Return a page of the events matching the query, newest first, and the total number of matches
+// A limit of 0 only counts the events
+func (mc *MongoClient) EventsPage(q bson.M, skip, limit int) (PagedEvents, error) {
+	s := mc.getSessionCopy()
+	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "EventsPage", q)
+
+	page := PagedEvents{Items: []models.Event{}, Skip: skip, Limit: limit}
+	var me []MongoEvent
+	total, err := findPage(s.DB(mc.Database.Name).C(EVENTS_COLLECTION), q, skip, limit, &me)
+	if err != nil {
+		return page, err
+	}
+
+	page.Total = total
+	for _, e := range me {
+		page.Items = append(page.Items, e.Event)
+	}
+	return page, nil
+}
+
+// Return a page of the readings matching the query, newest first, and the total number of matches
+// A limit of 0 only counts the readings
+func (mc *MongoClient) ReadingsPage(q bson.M, skip, limit int) (PagedReadings, error) {
+	s := mc.getSessionCopy()
+	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "ReadingsPage", q)
+
+	page := PagedReadings{Items: []models.Reading{}, Skip: skip, Limit: limit}
+	total, err := findPage(s.DB(mc.Database.Name).C(READINGS_COLLECTION), q, skip, limit, &page.Items)
+	page.Total = total
+	return page, err
+}
+
+// Return a page of the value descriptors matching the query, newest first, and the total number of matches
+// A limit of 0 only counts the value descriptors
+func (mc *MongoClient) ValueDescriptorsPage(q bson.M, skip, limit int) (PagedValueDescriptors, error) {
+	s := mc.getSessionCopy()
+	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "ValueDescriptorsPage", q)
+
+	page := PagedValueDescriptors{Items: []models.ValueDescriptor{}, Skip: skip, Limit: limit}
+	total, err := findPage(s.DB(mc.Database.Name).C(VALUE_DESCRIPTOR_COLLECTION), q, skip, limit, &page.Items)
+	page.Total = total
+	return page, err
+}
+
+// Count the documents matching the query and load the requested page of them into result
+// Both queries run on the collection's session so the caller only copies the session once
+func findPage(c *mgo.Collection, q bson.M, skip, limit int, result interface{}) (int, error) {
+	if skip < 0 {
+		return 0, ErrInvalidPage
+	}
+	if err := validateLimit(limit); err != nil {
+		return 0, err
+	}
+
+	total, err := c.Find(q).Count()
+	if err != nil || limit == 0 || skip >= total {
+		return total, queryError(err)
+	}
+
+	err = c.Find(q).Sort(newestFirst...).Skip(skip).Limit(limit).All(result)
+	return total, queryError(err)
+}
+
+// Return a page of the events oldest first, skipping the first offset of them
+// Unlike EventsPage it doesn't count the events, and pages in the order they were created
+func (mc *MongoClient) EventsPagedAscending(offset, limit int) ([]models.Event, error) {
+	events := []models.Event{}
+	if err := validatePage(offset, limit); err != nil || limit == 0 {
+		return events, err
+	}
+
+	s := mc.getScanSessionCopy()
+	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "EventsPagedAscending", bson.M{"skip": offset})
+
+	var me []MongoEvent
+	err := s.DB(mc.Database.Name).C(EVENTS_COLLECTION).Find(nil).Sort(oldestFirst...).Skip(offset).Limit(limit).All(&me)
+	if err != nil {
+		return events, queryError(err)
+	}
+	for _, e := range me {
+		events = append(events, e.Event)
+	}
+	return events, nil
+}
+
+// Return at most limit events oldest first, following the cursor, and the cursor of the next page
+func (mc *MongoClient) EventsAfterCursor(cursor string, limit int) ([]models.Event, string, error) {
+	events := []models.Event{}
+	p, err := decodeCursor(cursor)
+	if err != nil {
+		return events, cursor, err
+	}
+	if err = validateLimit(limit); err != nil || limit == 0 {
+		return events, cursor, err
+	}
+
+	s := mc.getSessionCopy()
+	defer s.Close()
+	q := cursorQuery(p)
+	defer mc.logIfSlow(time.Now(), "EventsAfterCursor", q)
+
+	var me []MongoEvent
+	err = s.DB(mc.Database.Name).C(EVENTS_COLLECTION).Find(q).Sort(oldestFirst...).Limit(limit).All(&me)
+	if err != nil {
+		return events, cursor, queryError(err)
+	}
+	if len(me) == 0 {
+		return events, "", nil
+	}
+	for _, e := range me {
+		events = append(events, e.Event)
+	}
+	last := events[len(events)-1]
+	return events, nextCursor(len(events), limit, last.Created, last.ID), nil
+}
+
+// Return a page of the readings oldest first, skipping the first offset of them
+// Unlike ReadingsPage it doesn't count the readings, and pages in the order they were created
+func (mc *MongoClient) ReadingsPagedAscending(offset, limit int) ([]models.Reading, error) {
+	readings := []models.Reading{}
+	if err := validatePage(offset, limit); err != nil || limit == 0 {
+		return readings, err
+	}
+
+	s := mc.getScanSessionCopy()
+	defer s.Close()
+	defer mc.logIfSlow(time.Now(), "ReadingsPagedAscending", bson.M{"skip": offset})
+
+	err := s.DB(mc.Database.Name).C(READINGS_COLLECTION).Find(nil).Sort(oldestFirst...).Skip(offset).Limit(limit).All(&readings)
+	return readings, queryError(err)
+}
+
+// Return at most limit readings oldest first, following the cursor, and the cursor of the next page
+// The cursor is the opaque form of the position ReadingsAfter resumes from
+func (mc *MongoClient) ReadingsAfterCursor(cursor string, limit int) ([]models.Reading, string, error) {
+	p, err := decodeCursor(cursor)
+	if err != nil {
+		return []models.Reading{}, cursor, err
+	}
+	if err = validateLimit(limit); err != nil || limit == 0 {
+		return []models.Reading{}, cursor, err
+	}
+
+	afterId := ""
+	if p.id != "" {
+		afterId = p.id.Hex()
+	}
+	readings, created, id, err := mc.ReadingsAfter(p.created, afterId, limit)
+	if err != nil {
+		return readings, cursor, queryError(err)
+	}
+	if len(readings) == 0 {
+		return readings, "", nil
+	}
+	return readings, nextCursor(len(readings), limit, created, bson.ObjectIdHex(id)), nil
+}
+
+// Match the documents following the cursor position, on the created time and then the id
+func cursorQuery(p cursorPosition) bson.M {
+	if p.id == "" {
+		return bson.M{}
+	}
+	return bson.M{"$or": []bson.M{
+		{"created": bson.M{"$gt": p.created}},
+		{"created": p.created, "_id": bson.M{"$gt": p.id}},
+	}}
+}
@@ -0,0 +1,180 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package clients
+
+import (
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Conditions shared by the event and reading queries
+// The setters keep the first invalid argument so the query fails when it's run
+type queryFilter struct {
+	device  *string
+	start   int64
+	end     int64
+	between bool
+	pushed  *bool
+	limit   int
+	limited bool
+	err     error
+}
+
+func (f *queryFilter) setDevice(id string) {
+	if err := validateDeviceId(id); err != nil && f.err == nil {
+		f.err = err
+	}
+	f.device = &id
+}
+
+func (f *queryFilter) setBetween(start, end int64) {
+	if err := validateTimeRange(start, end); err != nil && f.err == nil {
+		f.err = err
+	}
+	f.start, f.end, f.between = start, end, true
+}
+
+func (f *queryFilter) setLimit(n int) {
+	f.limit, f.limited = n, true
+}
+
+// Build the query from the conditions set
+func (f queryFilter) bson() (bson.M, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	q := bson.M{}
+	if f.device != nil {
+		q["device"] = *f.device
+	}
+	if f.between {
+		q["created"] = bson.M{"$gte": f.start, "$lte": f.end}
+	}
+	if f.pushed != nil {
+		if *f.pushed {
+			q["pushed"] = bson.M{"$gt": int64(0)}
+		} else {
+			q["pushed"] = int64(0)
+		}
+	}
+	return q, nil
+}
+
+// Typed query of EventsMatching, built by chaining the methods from EventQuery{}
+// Each method returns a copy, so a query can be the base of several others
+// The zero value matches every event without a limit
+type EventQuery struct {
+	filter queryFilter
+}
+
+// Only match the events of the device (id or name)
+func (q EventQuery) Device(id string) EventQuery {
+	q.filter.setDevice(id)
+	return q
+}
+
+// Only match the events created between start and end, inclusive
+func (q EventQuery) Between(start, end int64) EventQuery {
+	q.filter.setBetween(start, end)
+	return q
+}
+
+// Only match the events that have (true) or haven't (false) been pushed
+func (q EventQuery) Pushed(pushed bool) EventQuery {
+	q.filter.pushed = &pushed
+	return q
+}
+
+// Return at most n events
+func (q EventQuery) Limit(n int) EventQuery {
+	q.filter.setLimit(n)
+	return q
+}
+
+// Typed query of ReadingsMatching, built with the chained methods like EventQuery
+// The zero value matches every reading without a limit
+type ReadingQuery struct {
+	filter queryFilter
+	name   *string
+}
+
+// Only match the readings of the device (id or name)
+func (q ReadingQuery) Device(id string) ReadingQuery {
+	q.filter.setDevice(id)
+	return q
+}
+
+// Only match the readings of the value descriptor
+func (q ReadingQuery) Name(name string) ReadingQuery {
+	q.name = &name
+	return q
+}
+
+// Only match the readings created between start and end, inclusive
+func (q ReadingQuery) Between(start, end int64) ReadingQuery {
+	q.filter.setBetween(start, end)
+	return q
+}
+
+// Only match the readings that have (true) or haven't (false) been pushed
+func (q ReadingQuery) Pushed(pushed bool) ReadingQuery {
+	q.filter.pushed = &pushed
+	return q
+}
+
+// Return at most n readings
+func (q ReadingQuery) Limit(n int) ReadingQuery {
+	q.filter.setLimit(n)
+	return q
+}
+
+func (q ReadingQuery) bson() (bson.M, error) {
+	query, err := q.filter.bson()
+	if err != nil {
+		return nil, err
+	}
+	if q.name != nil {
+		query["name"] = *q.name
+	}
+	return query, nil
+}
+
+// Return the events matching the query, newest first
+// ErrEmptyDeviceId - the device id of the query is empty
+// ErrInvalidTimeRange - a time of the query is negative or the start is after the end
+func (mc *MongoClient) EventsMatching(q EventQuery) ([]models.Event, error) {
+	query, err := q.filter.bson()
+	if err != nil {
+		return []models.Event{}, err
+	}
+	if !q.filter.limited {
+		return mc.getEvents(query)
+	}
+	return mc.getEventsLimit(query, q.filter.limit)
+}
+
+// Return the readings matching the query, newest first
+// ErrEmptyDeviceId - the device id of the query is empty
+// ErrInvalidTimeRange - a time of the query is negative or the start is after the end
+func (mc *MongoClient) ReadingsMatching(q ReadingQuery) ([]models.Reading, error) {
+	query, err := q.bson()
+	if err != nil {
+		return []models.Reading{}, err
+	}
+	if !q.filter.limited {
+		return mc.getReadings(query)
+	}
+	return mc.getReadingsLimit(query, q.filter.limit)
+}
@@ -0,0 +1,71 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package clients
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestEventQuery(t *testing.T) {
+	q := EventQuery{}.Device("device").Between(100, 200).Pushed(false).Limit(10)
+	query, err := q.filter.bson()
+	if err != nil {
+		t.Fatalf("Error building the query: %v", err)
+	}
+	expected := bson.M{
+		"device":  "device",
+		"created": bson.M{"$gte": int64(100), "$lte": int64(200)},
+		"pushed":  int64(0),
+	}
+	if !reflect.DeepEqual(query, expected) {
+		t.Fatalf("Expected %v, got %v", expected, query)
+	}
+	if !q.filter.limited || q.filter.limit != 10 {
+		t.Fatalf("The limit should be 10")
+	}
+
+	if query, _ = (EventQuery{}).filter.bson(); len(query) != 0 {
+		t.Fatalf("The empty query should match everything: %v", query)
+	}
+	if query, _ = (EventQuery{}).Pushed(true).filter.bson(); !reflect.DeepEqual(query, bson.M{"pushed": bson.M{"$gt": int64(0)}}) {
+		t.Fatalf("Unexpected pushed query: %v", query)
+	}
+
+	if _, err = (EventQuery{}).Device("").filter.bson(); err != ErrEmptyDeviceId {
+		t.Fatalf("Expected ErrEmptyDeviceId, got %v", err)
+	}
+	if _, err = (EventQuery{}).Between(200, 100).Device("device").filter.bson(); err != ErrInvalidTimeRange {
+		t.Fatalf("Expected ErrInvalidTimeRange, got %v", err)
+	}
+}
+
+func TestReadingQuery(t *testing.T) {
+	base := ReadingQuery{}.Device("device")
+	query, err := base.Name("temperature").bson()
+	if err != nil {
+		t.Fatalf("Error building the query: %v", err)
+	}
+	expected := bson.M{"device": "device", "name": "temperature"}
+	if !reflect.DeepEqual(query, expected) {
+		t.Fatalf("Expected %v, got %v", expected, query)
+	}
+
+	// Chaining doesn't change the query it starts from
+	if query, _ = base.bson(); !reflect.DeepEqual(query, bson.M{"device": "device"}) {
+		t.Fatalf("The base query was changed: %v", query)
+	}
+}
@@ -0,0 +1,56 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package clients
+
+import (
+	"strconv"
+
+	"gopkg.in/mgo.v2"
+)
+
+// Read preferences of the sessions
+const (
+	ReadPrimary            = "primary"
+	ReadPrimaryPreferred   = "primaryPreferred"
+	ReadSecondary          = "secondary"
+	ReadSecondaryPreferred = "secondaryPreferred"
+	ReadNearest            = "nearest"
+)
+
+// Return the addresses to dial, the seed hosts when there are some, else Host and Port
+func mongoAddrs(config DBConfiguration) []string {
+	if len(config.Hosts) != 0 {
+		return config.Hosts
+	}
+	return []string{config.Host + ":" + strconv.Itoa(config.Port)}
+}
+
+// Return the session mode of the read preference
+// The primary is read in the Strong mode the driver defaults to, so a session keeps reading its own writes
+func readPreferenceMode(preference string) (mgo.Mode, error) {
+	switch preference {
+	case "", ReadPrimary:
+		return mgo.Strong, nil
+	case ReadPrimaryPreferred:
+		return mgo.PrimaryPreferred, nil
+	case ReadSecondary:
+		return mgo.Secondary, nil
+	case ReadSecondaryPreferred:
+		return mgo.SecondaryPreferred, nil
+	case ReadNearest:
+		return mgo.Nearest, nil
+	default:
+		return mgo.Strong, ErrInvalidReadPreference
+	}
+}
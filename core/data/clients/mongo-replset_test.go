@@ -0,0 +1,58 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package clients
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/mgo.v2"
+)
+
+func TestMongoAddrs(t *testing.T) {
+	addrs := mongoAddrs(DBConfiguration{Host: "localhost", Port: 27017})
+	if !reflect.DeepEqual(addrs, []string{"localhost:27017"}) {
+		t.Fatalf("Without seed hosts the host and port should be dialed: %v", addrs)
+	}
+
+	seeds := []string{"mongo1:27017", "mongo2:27017"}
+	addrs = mongoAddrs(DBConfiguration{Host: "localhost", Port: 27017, Hosts: seeds})
+	if !reflect.DeepEqual(addrs, seeds) {
+		t.Fatalf("The seed hosts should replace the host and port: %v", addrs)
+	}
+}
+
+func TestReadPreferenceMode(t *testing.T) {
+	tests := []struct {
+		preference string
+		mode       mgo.Mode
+	}{
+		{"", mgo.Strong},
+		{ReadPrimary, mgo.Strong},
+		{ReadPrimaryPreferred, mgo.PrimaryPreferred},
+		{ReadSecondary, mgo.Secondary},
+		{ReadSecondaryPreferred, mgo.SecondaryPreferred},
+		{ReadNearest, mgo.Nearest},
+	}
+	for _, tt := range tests {
+		mode, err := readPreferenceMode(tt.preference)
+		if err != nil || mode != tt.mode {
+			t.Errorf("%q should give mode %v, got %v %v", tt.preference, tt.mode, mode, err)
+		}
+	}
+
+	if _, err := readPreferenceMode("secondarypreferred"); err != ErrInvalidReadPreference {
+		t.Fatalf("Expected ErrInvalidReadPreference, got %v", err)
+	}
+}
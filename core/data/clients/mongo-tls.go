@@ -0,0 +1,89 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package clients
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"time"
+)
+
+// Build the TLS configuration of the mongo connections from the certificate files
+// Without a CA file the system roots verify the server, a client certificate needs both its
+// certificate and key files
+func mongoTLSConfig(config DBConfiguration) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.TLSSkipVerify}
+
+	if config.TLSCAFile != "" {
+		pem, err := ioutil.ReadFile(config.TLSCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, ErrInvalidTLSConfig
+		}
+	}
+
+	if (config.TLSCertFile == "") != (config.TLSKeyFile == "") {
+		return nil, ErrInvalidTLSConfig
+	}
+	if config.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.TLSCertFile, config.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// Dial the mongo servers, over TLS when tls is set
+type mongoDialer struct {
+	dialer  *net.Dialer
+	tls     *tls.Config
+	timeout time.Duration // Bound of the TLS handshake
+}
+
+// Dial the server at the host:port address
+// The server name is verified against the host, the handshake is done here so a bad certificate
+// fails the dial instead of the first query
+func (d *mongoDialer) dial(addr string) (net.Conn, error) {
+	conn, err := d.dialer.Dial("tcp", addr)
+	if err != nil || d.tls == nil {
+		return conn, err
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	tlsConfig := d.tls.Clone()
+	tlsConfig.ServerName = host
+
+	tlsConn := tls.Client(conn, tlsConfig)
+	if d.timeout > 0 {
+		tlsConn.SetDeadline(time.Now().Add(d.timeout))
+	}
+	if err = tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	tlsConn.SetDeadline(time.Time{})
+	return tlsConn, nil
+}
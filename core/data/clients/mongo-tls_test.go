@@ -0,0 +1,101 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package clients
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Write the certificate of the test server to a CA file
+func writeTestCAFile(t *testing.T, server *httptest.Server) string {
+	f, err := ioutil.TempFile("", "mongo-ca")
+	if err != nil {
+		t.Fatalf("Error creating the CA file: %v", err)
+	}
+	defer f.Close()
+
+	cert := server.TLS.Certificates[0].Certificate[0]
+	if err = pem.Encode(f, &pem.Block{Type: "CERTIFICATE", Bytes: cert}); err != nil {
+		t.Fatalf("Error writing the CA file: %v", err)
+	}
+	return f.Name()
+}
+
+func TestMongoTLSConfig(t *testing.T) {
+	if _, err := mongoTLSConfig(DBConfiguration{TLS: true, TLSCAFile: "/nonexistent/ca.pem"}); err == nil {
+		t.Fatalf("A missing CA file should be reported")
+	}
+	if _, err := mongoTLSConfig(DBConfiguration{TLS: true, TLSCertFile: "client.pem"}); err != ErrInvalidTLSConfig {
+		t.Fatalf("A client certificate without its key should return ErrInvalidTLSConfig, got %v", err)
+	}
+
+	notPEM, err := ioutil.TempFile("", "mongo-ca")
+	if err != nil {
+		t.Fatalf("Error creating the CA file: %v", err)
+	}
+	notPEM.WriteString("not a certificate")
+	notPEM.Close()
+	defer os.Remove(notPEM.Name())
+	if _, err = mongoTLSConfig(DBConfiguration{TLS: true, TLSCAFile: notPEM.Name()}); err != ErrInvalidTLSConfig {
+		t.Fatalf("A CA file without certificates should return ErrInvalidTLSConfig, got %v", err)
+	}
+
+	tlsConfig, err := mongoTLSConfig(DBConfiguration{TLS: true, TLSSkipVerify: true})
+	if err != nil || !tlsConfig.InsecureSkipVerify || tlsConfig.RootCAs != nil {
+		t.Fatalf("Without files the system roots should be used: %v %v", tlsConfig, err)
+	}
+}
+
+func TestMongoDialerTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	addr := strings.TrimPrefix(server.URL, "https://")
+
+	caFile := writeTestCAFile(t, server)
+	defer os.Remove(caFile)
+
+	tlsConfig, err := mongoTLSConfig(DBConfiguration{TLS: true, TLSCAFile: caFile})
+	if err != nil {
+		t.Fatalf("Error loading the TLS configuration: %v", err)
+	}
+	d := &mongoDialer{dialer: &net.Dialer{Timeout: time.Second}, tls: tlsConfig, timeout: time.Second}
+	conn, err := d.dial(addr)
+	if err != nil {
+		t.Fatalf("The server signed by the CA should be trusted: %v", err)
+	}
+	conn.Close()
+
+	// The system roots don't know the test certificate
+	tlsConfig, _ = mongoTLSConfig(DBConfiguration{TLS: true})
+	d.tls = tlsConfig
+	if conn, err = d.dial(addr); err == nil {
+		conn.Close()
+		t.Fatalf("An unknown server certificate should fail the dial")
+	}
+
+	tlsConfig, _ = mongoTLSConfig(DBConfiguration{TLS: true, TLSSkipVerify: true})
+	d.tls = tlsConfig
+	if conn, err = d.dial(addr); err != nil {
+		t.Fatalf("Skipping the verification should accept any certificate: %v", err)
+	}
+	conn.Close()
+}
@@ -0,0 +1,1292 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package clients
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/gomodule/redigo/redis"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Prefix of the keys when the configuration doesn't name the database
+const DefaultRedisKeyPrefix = "coredata"
+
+// Max number of idle connections kept in the pool
+const redisMaxIdle = 10
+
+// Number of keys read or removed per round trip
+const redisBatchSize = 1000
+
+// Kinds of the documents, stored as BSON under <prefix>:<kind>:<id>
+// The sorted sets indexing them are scored by the created time: events, events:device:<device>,
+// events:pushed, readings, readings:device:<device>, readings:name:<name> and vds
+// The hash vd:name maps the value descriptor names to their id and keeps them unique
+const (
+	redisEventKey           = "event"
+	redisReadingKey         = "reading"
+	redisValueDescriptorKey = "vd"
+)
+
+// Client of a redis database, the time ranges and the newest first limits are sorted set ranges
+type RedisClient struct {
+	pool   *redis.Pool
+	prefix string
+	config DBConfiguration
+}
+
+// Event document in redis, the readings are stored under their own keys and referenced by id
+type redisEvent struct {
+	ID       bson.ObjectId   `bson:"_id"`
+	Pushed   int64           `bson:"pushed"`
+	Device   string          `bson:"device"`
+	Created  int64           `bson:"created"`
+	Modified int64           `bson:"modified"`
+	Origin   int64           `bson:"origin"`
+	Schedule string          `bson:"schedule,omitempty"`
+	Event    string          `bson:"event,omitempty"`
+	Readings []bson.ObjectId `bson:"readings"`
+}
+
+// Reading document in redis, with the back-reference to its event like in mongo
+type redisReading struct {
+	models.Reading `bson:",inline"`
+	EventId        bson.ObjectId `bson:"eventId,omitempty"`
+}
+
+func newRedisEvent(e models.Event) redisEvent {
+	re := redisEvent{
+		ID:       e.ID,
+		Pushed:   e.Pushed,
+		Device:   e.Device,
+		Created:  e.Created,
+		Modified: e.Modified,
+		Origin:   e.Origin,
+		Schedule: e.Schedule,
+		Event:    e.Event,
+	}
+	for _, r := range e.Readings {
+		re.Readings = append(re.Readings, r.Id)
+	}
+	return re
+}
+
+func (re redisEvent) event(readings []models.Reading) models.Event {
+	return models.Event{
+		ID:       re.ID,
+		Pushed:   re.Pushed,
+		Device:   re.Device,
+		Created:  re.Created,
+		Modified: re.Modified,
+		Origin:   re.Origin,
+		Schedule: re.Schedule,
+		Event:    re.Event,
+		Readings: readings,
+	}
+}
+
+func init() {
+	RegisterDBClient(REDIS.String(), func(config DBConfiguration) (DBClient, error) {
+		// Keep the interface nil on errors, not holding a nil *RedisClient
+		rc, err := newRedisClient(config)
+		if err != nil {
+			return nil, err
+		}
+		return rc, nil
+	})
+}
+
+// Return a redis client, the keys are prefixed by the database name
+func newRedisClient(config DBConfiguration) (*RedisClient, error) {
+	if config.DatabaseName == "" {
+		config.DatabaseName = DefaultRedisKeyPrefix
+	}
+
+	address := net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+	options := []redis.DialOption{redis.DialConnectTimeout(time.Duration(config.Timeout) * time.Millisecond)}
+	if config.QueryTimeout > 0 {
+		timeout := time.Duration(config.QueryTimeout) * time.Millisecond
+		options = append(options, redis.DialReadTimeout(timeout), redis.DialWriteTimeout(timeout))
+	}
+	// Only redis 6 knows about the users, the older servers reject AUTH with a user name
+	if config.Username != "" {
+		options = append(options, redis.DialUsername(config.Username))
+	}
+	if config.Password != "" {
+		options = append(options, redis.DialPassword(config.Password))
+	}
+
+	pool := &redis.Pool{
+		MaxIdle: redisMaxIdle,
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", address, options...)
+		},
+	}
+	// Ping the connections idle for longer than configured before handing them out
+	if config.MaxIdleTime > 0 {
+		maxIdle := time.Duration(config.MaxIdleTime) * time.Millisecond
+		pool.TestOnBorrow = func(c redis.Conn, idleSince time.Time) error {
+			if time.Since(idleSince) < maxIdle {
+				return nil
+			}
+			_, err := c.Do("PING")
+			return err
+		}
+	}
+
+	// Fail like mongo does when the database can't be reached
+	conn := pool.Get()
+	_, err := conn.Do("PING")
+	conn.Close()
+	if err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	return &RedisClient{pool: pool, prefix: config.DatabaseName, config: config}, nil
+}
+
+func (rc *RedisClient) CloseSession() {
+	rc.pool.Close()
+}
+
+func (rc *RedisClient) key(parts ...string) string {
+	k := rc.prefix
+	for _, p := range parts {
+		k += ":" + p
+	}
+	return k
+}
+
+// ******************************* EVENTS **********************************
+
+func (rc *RedisClient) Events() ([]models.Event, error) {
+	return rc.eventsInRange(rc.key("events"), "-inf", "+inf")
+}
+
+func (rc *RedisClient) AddEvent(e *models.Event) (bson.ObjectId, error) {
+	if e == nil {
+		return "", ErrNilEvent
+	}
+	if rc.config.MaxReadings > 0 && len(e.Readings) > rc.config.MaxReadings {
+		return "", ErrEventTooLarge
+	}
+
+	readings := newRedisEventReadings(e, time.Now().UnixNano()/int64(time.Millisecond))
+
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	// The event and its readings are stored in a single transaction
+	conn.Send("MULTI")
+	if err := rc.sendAddEventReadings(conn, e, readings); err != nil {
+		conn.Do("DISCARD")
+		return e.ID, err
+	}
+	_, err := conn.Do("EXEC")
+	return e.ID, err
+}
+
+// Add a batch of events in a single transaction
+// Only the events with too many readings are refused on their own
+func (rc *RedisClient) AddEvents(events []models.Event) ([]AddEventResult, error) {
+	results := make([]AddEventResult, len(events))
+	created := time.Now().UnixNano() / int64(time.Millisecond)
+
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	conn.Send("MULTI")
+	for i := range events {
+		results[i].Index = i
+		e := &events[i]
+		if rc.config.MaxReadings > 0 && len(e.Readings) > rc.config.MaxReadings {
+			results[i].Err = ErrEventTooLarge
+			continue
+		}
+
+		if err := rc.sendAddEventReadings(conn, e, newRedisEventReadings(e, created)); err != nil {
+			conn.Do("DISCARD")
+			return failPending(results, err), err
+		}
+	}
+	if _, err := conn.Do("EXEC"); err != nil {
+		return failPending(results, err), err
+	}
+
+	for i := range events {
+		if results[i].Err == nil {
+			results[i].ID = events[i].ID
+		}
+	}
+	return results, nil
+}
+
+// Give the event and its readings their ids and timestamps, and return the readings to store
+func newRedisEventReadings(e *models.Event, created int64) []redisReading {
+	e.Created = created
+	e.Modified = e.Created
+	e.ID = bson.NewObjectId()
+
+	readings := make([]redisReading, len(e.Readings))
+	for i := range e.Readings {
+		e.Readings[i].Id = bson.NewObjectId()
+		e.Readings[i].Created = e.Created
+		e.Readings[i].Modified = e.Created
+		e.Readings[i].Device = e.Device
+		readings[i] = redisReading{Reading: e.Readings[i], EventId: e.ID}
+	}
+	return readings
+}
+
+// Queue the commands storing the event and its readings, between MULTI and EXEC
+func (rc *RedisClient) sendAddEventReadings(conn redis.Conn, e *models.Event, readings []redisReading) error {
+	for _, r := range readings {
+		if err := rc.sendAddReading(conn, r); err != nil {
+			return err
+		}
+	}
+	return rc.sendAddEvent(conn, newRedisEvent(*e))
+}
+
+func (rc *RedisClient) UpdateEvent(e models.Event) error {
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	var old redisEvent
+	if err := rc.getDocument(conn, redisEventKey, e.ID.Hex(), &old); err != nil {
+		return err
+	}
+
+	e.Modified = time.Now().UnixNano() / int64(time.Millisecond)
+
+	// The indexes move with the device, the pushed flag and the created time
+	conn.Send("MULTI")
+	rc.sendRemoveEvent(conn, old)
+	if err := rc.sendAddEvent(conn, newRedisEvent(e)); err != nil {
+		conn.Do("DISCARD")
+		return err
+	}
+	_, err := conn.Do("EXEC")
+	return err
+}
+
+func (rc *RedisClient) EventById(id string) (models.Event, error) {
+	if !bson.IsObjectIdHex(id) {
+		return models.Event{}, ErrInvalidObjectId
+	}
+
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	events, err := rc.getEvents(conn, []string{id})
+	if err != nil {
+		return models.Event{}, err
+	}
+	if len(events) == 0 {
+		return models.Event{}, ErrNotFound
+	}
+	return events[0], nil
+}
+
+func (rc *RedisClient) EventCount() (int, error) {
+	return rc.count(rc.key("events"))
+}
+
+func (rc *RedisClient) EventCountByDeviceId(id string) (int, error) {
+	if err := validateDeviceId(id); err != nil {
+		return 0, err
+	}
+	return rc.count(rc.key("events", "device", id))
+}
+
+// Delete the event and its readings
+func (rc *RedisClient) DeleteEventById(id string) error {
+	if !bson.IsObjectIdHex(id) {
+		return ErrInvalidObjectId
+	}
+
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	var re redisEvent
+	if err := rc.getDocument(conn, redisEventKey, id, &re); err != nil {
+		return err
+	}
+	readings, err := rc.getRedisReadings(conn, hexIds(re.Readings))
+	if err != nil {
+		return err
+	}
+
+	conn.Send("MULTI")
+	rc.sendRemoveEvent(conn, re)
+	for _, r := range readings {
+		rc.sendRemoveReading(conn, r)
+	}
+	_, err = conn.Do("EXEC")
+	return err
+}
+
+func (rc *RedisClient) EventsForDeviceLimit(id string, limit int) ([]models.Event, error) {
+	if err := validateDeviceId(id); err != nil {
+		return []models.Event{}, err
+	}
+	return rc.eventsLimit(rc.key("events", "device", id), "-inf", "+inf", limit)
+}
+
+func (rc *RedisClient) EventsForDevice(id string) ([]models.Event, error) {
+	if err := validateDeviceId(id); err != nil {
+		return []models.Event{}, err
+	}
+	return rc.eventsInRange(rc.key("events", "device", id), "-inf", "+inf")
+}
+
+func (rc *RedisClient) EventsByCreationTime(startTime, endTime int64, limit int) ([]models.Event, error) {
+	if err := validateTimeRange(startTime, endTime); err != nil {
+		return []models.Event{}, err
+	}
+	return rc.eventsLimit(rc.key("events"), score(startTime), score(endTime), limit)
+}
+
+func (rc *RedisClient) ReadingsByDeviceAndValueDescriptor(deviceId, valueDescriptor string, limit int) ([]models.Reading, error) {
+	readings := []models.Reading{}
+	if err := validateDeviceId(deviceId); err != nil {
+		return readings, err
+	}
+	if err := validateLimit(limit); err != nil {
+		return readings, err
+	}
+
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	// Walk the readings of the value descriptor from the newest, keeping the ones of the device
+	nameKey := rc.key("readings", "name", valueDescriptor)
+	deviceKey := rc.key("readings", "device", deviceId)
+	var ids []string
+	for start := 0; len(ids) < limit; start += redisBatchSize {
+		page, err := redis.Strings(conn.Do("ZREVRANGE", nameKey, start, start+redisBatchSize-1))
+		if err != nil {
+			return readings, err
+		}
+		if len(page) == 0 {
+			break
+		}
+
+		for _, id := range page {
+			conn.Send("ZSCORE", deviceKey, id)
+		}
+		if err = conn.Flush(); err != nil {
+			return readings, err
+		}
+		for _, id := range page {
+			s, err := conn.Receive()
+			if err != nil {
+				return readings, err
+			}
+			if s != nil && len(ids) < limit {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	return rc.getReadings(conn, ids)
+}
+
+func (rc *RedisClient) EventsOlderThanAge(age int64) ([]models.Event, error) {
+	expireDate := (time.Now().UnixNano() / int64(time.Millisecond)) - age
+	return rc.eventsInRange(rc.key("events"), "-inf", "("+score(expireDate))
+}
+
+func (rc *RedisClient) EventsPushed() ([]models.Event, error) {
+	return rc.eventsInRange(rc.key("events", "pushed"), "-inf", "+inf")
+}
+
+// Delete at most limit of the pushed events along with their readings, oldest first, in a single transaction
+func (rc *RedisClient) DeleteEventsPushed(limit int) (int, error) {
+	if err := validateLimit(limit); err != nil {
+		return 0, err
+	}
+	if limit == 0 {
+		return 0, nil
+	}
+
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	pushed := rc.key("events", "pushed")
+	ids, err := redis.Strings(conn.Do("ZRANGE", pushed, 0, limit-1))
+	if err != nil {
+		return 0, err
+	}
+	docs, err := rc.getDocuments(conn, redisEventKey, ids)
+	if err != nil {
+		return 0, err
+	}
+
+	var events []redisEvent
+	var readingIds []string
+	for i, doc := range docs {
+		if doc == nil {
+			// Drop the index entry of an event removed meanwhile so it isn't found again
+			conn.Do("ZREM", pushed, ids[i])
+			continue
+		}
+		var re redisEvent
+		if err := bson.Unmarshal(doc, &re); err != nil {
+			return 0, ErrCorruptEvent{Id: ids[i], Err: err}
+		}
+		events = append(events, re)
+		readingIds = append(readingIds, hexIds(re.Readings)...)
+	}
+	readings, err := rc.getRedisReadings(conn, readingIds)
+	if err != nil {
+		return 0, err
+	}
+
+	conn.Send("MULTI")
+	for _, re := range events {
+		rc.sendRemoveEvent(conn, re)
+	}
+	for _, r := range readings {
+		rc.sendRemoveReading(conn, r)
+	}
+	if _, err = conn.Do("EXEC"); err != nil {
+		return 0, err
+	}
+	return len(events), nil
+}
+
+func (rc *RedisClient) EventsPagedAscending(offset, limit int) ([]models.Event, error) {
+	if err := validatePage(offset, limit); err != nil || limit == 0 {
+		return []models.Event{}, err
+	}
+
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	ids, err := redis.Strings(conn.Do("ZRANGE", rc.key("events"), offset, offset+limit-1))
+	if err != nil {
+		return []models.Event{}, err
+	}
+	return rc.getEvents(conn, ids)
+}
+
+func (rc *RedisClient) EventsAfterCursor(cursor string, limit int) ([]models.Event, string, error) {
+	p, err := decodeCursor(cursor)
+	if err != nil {
+		return []models.Event{}, cursor, err
+	}
+	if err = validateLimit(limit); err != nil || limit == 0 {
+		return []models.Event{}, cursor, err
+	}
+
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	ids, err := rc.idsAfter(conn, rc.key("events"), p, limit)
+	if err != nil {
+		return []models.Event{}, cursor, err
+	}
+	events, err := rc.getEvents(conn, ids)
+	if err != nil || len(events) == 0 {
+		return events, "", err
+	}
+	last := events[len(events)-1]
+	return events, nextCursor(len(ids), limit, last.Created, last.ID), nil
+}
+
+func (rc *RedisClient) ScrubAllEvents() error {
+	if !rc.config.AllowScrub {
+		return ErrScrubNotAllowed
+	}
+	return rc.deleteMatching(rc.key(redisEventKey+"*"), rc.key(redisReadingKey+"*"))
+}
+
+// Return the events of the sorted set scored between min and max, oldest first
+func (rc *RedisClient) eventsInRange(key string, min, max string) ([]models.Event, error) {
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	ids, err := redis.Strings(conn.Do("ZRANGEBYSCORE", key, min, max))
+	if err != nil {
+		return []models.Event{}, err
+	}
+	return rc.getEvents(conn, ids)
+}
+
+// Return at most limit events of the sorted set scored between min and max, newest first
+func (rc *RedisClient) eventsLimit(key string, min, max string, limit int) ([]models.Event, error) {
+	events := []models.Event{}
+
+	// Check if limit is 0
+	if err := validateLimit(limit); err != nil {
+		return events, err
+	}
+	if limit == 0 {
+		return events, nil
+	}
+
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	ids, err := rc.newestIds(conn, []string{key}, min, max, limit)
+	if err != nil {
+		return events, err
+	}
+	return rc.getEvents(conn, ids)
+}
+
+// Return the events of the ids with their readings, skipping the ids without an event
+func (rc *RedisClient) getEvents(conn redis.Conn, ids []string) ([]models.Event, error) {
+	events := []models.Event{}
+
+	docs, err := rc.getDocuments(conn, redisEventKey, ids)
+	if err != nil {
+		return events, err
+	}
+
+	var decoded []redisEvent
+	var readingIds []string
+	for i, doc := range docs {
+		if doc == nil {
+			continue
+		}
+		var re redisEvent
+		if err := bson.Unmarshal(doc, &re); err != nil {
+			return events, ErrCorruptEvent{Id: ids[i], Err: err}
+		}
+		decoded = append(decoded, re)
+		readingIds = append(readingIds, hexIds(re.Readings)...)
+	}
+
+	readings, err := rc.getReadings(conn, readingIds)
+	if err != nil {
+		return events, err
+	}
+	byId := make(map[bson.ObjectId]models.Reading, len(readings))
+	for _, r := range readings {
+		byId[r.Id] = r
+	}
+
+	for _, re := range decoded {
+		var eventReadings []models.Reading
+		for _, id := range re.Readings {
+			r, ok := byId[id]
+			if !ok {
+				// Not the event missing, it references a reading that was removed
+				return events, ErrCorruptEvent{Id: re.ID.Hex(), Err: fmt.Errorf("reading %v doesn't exist", id.Hex())}
+			}
+			eventReadings = append(eventReadings, r)
+		}
+		events = append(events, re.event(eventReadings))
+	}
+
+	return events, nil
+}
+
+// Queue the commands storing the event and indexing it, between MULTI and EXEC
+func (rc *RedisClient) sendAddEvent(conn redis.Conn, re redisEvent) error {
+	doc, err := bson.Marshal(re)
+	if err != nil {
+		return err
+	}
+
+	id := re.ID.Hex()
+	conn.Send("SET", rc.key(redisEventKey, id), doc)
+	conn.Send("ZADD", rc.key("events"), re.Created, id)
+	conn.Send("ZADD", rc.key("events", "device", re.Device), re.Created, id)
+	if re.Pushed > 0 {
+		conn.Send("ZADD", rc.key("events", "pushed"), re.Created, id)
+	}
+	return nil
+}
+
+// Queue the commands removing the event from the store and the indexes, between MULTI and EXEC
+func (rc *RedisClient) sendRemoveEvent(conn redis.Conn, re redisEvent) {
+	id := re.ID.Hex()
+	conn.Send("DEL", rc.key(redisEventKey, id))
+	conn.Send("ZREM", rc.key("events"), id)
+	conn.Send("ZREM", rc.key("events", "device", re.Device), id)
+	conn.Send("ZREM", rc.key("events", "pushed"), id)
+}
+
+// ****************************** READINGS *********************************
+
+// Return all the readings, oldest first
+func (rc *RedisClient) Readings() ([]models.Reading, error) {
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	ids, err := redis.Strings(conn.Do("ZRANGE", rc.key("readings"), 0, -1))
+	if err != nil {
+		return []models.Reading{}, err
+	}
+	return rc.getReadings(conn, ids)
+}
+
+func (rc *RedisClient) AddReading(r models.Reading) (bson.ObjectId, error) {
+	if err := validateReading(r); err != nil {
+		return "", err
+	}
+
+	// Get the reading ready
+	r.Id = bson.NewObjectId()
+	r.Created = time.Now().UnixNano() / int64(time.Millisecond)
+	r.Modified = r.Created
+
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	conn.Send("MULTI")
+	if err := rc.sendAddReading(conn, redisReading{Reading: r}); err != nil {
+		conn.Do("DISCARD")
+		return r.Id, err
+	}
+	_, err := conn.Do("EXEC")
+	return r.Id, err
+}
+
+func (rc *RedisClient) UpdateReading(r models.Reading) error {
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	var old redisReading
+	if err := rc.getDocument(conn, redisReadingKey, r.Id.Hex(), &old); err != nil {
+		return err
+	}
+
+	r.Modified = time.Now().UnixNano() / int64(time.Millisecond)
+
+	// Keep the reference to the event, the model doesn't carry it
+	conn.Send("MULTI")
+	rc.sendRemoveReading(conn, old)
+	if err := rc.sendAddReading(conn, redisReading{Reading: r, EventId: old.EventId}); err != nil {
+		conn.Do("DISCARD")
+		return err
+	}
+	_, err := conn.Do("EXEC")
+	return err
+}
+
+func (rc *RedisClient) ReadingById(id string) (models.Reading, error) {
+	if !bson.IsObjectIdHex(id) {
+		return models.Reading{}, ErrInvalidObjectId
+	}
+
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	var r redisReading
+	err := rc.getDocument(conn, redisReadingKey, id, &r)
+	return r.Reading, err
+}
+
+func (rc *RedisClient) ReadingCount() (int, error) {
+	return rc.count(rc.key("readings"))
+}
+
+// Delete the reading and drop it from the readings of its event
+func (rc *RedisClient) DeleteReadingById(id string) error {
+	if !bson.IsObjectIdHex(id) {
+		return ErrInvalidObjectId
+	}
+
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	var r redisReading
+	if err := rc.getDocument(conn, redisReadingKey, id, &r); err != nil {
+		return err
+	}
+
+	var eventDoc []byte
+	if r.EventId != "" {
+		var re redisEvent
+		err := rc.getDocument(conn, redisEventKey, r.EventId.Hex(), &re)
+		if err != nil && err != ErrNotFound {
+			return err
+		}
+		if err == nil {
+			kept := []bson.ObjectId{}
+			for _, rId := range re.Readings {
+				if rId != r.Id {
+					kept = append(kept, rId)
+				}
+			}
+			re.Readings = kept
+			if eventDoc, err = bson.Marshal(re); err != nil {
+				return err
+			}
+		}
+	}
+
+	conn.Send("MULTI")
+	rc.sendRemoveReading(conn, r)
+	if eventDoc != nil {
+		conn.Send("SET", rc.key(redisEventKey, r.EventId.Hex()), eventDoc)
+	}
+	_, err := conn.Do("EXEC")
+	return err
+}
+
+func (rc *RedisClient) ReadingsByDevice(id string, limit int) ([]models.Reading, error) {
+	if err := validateDeviceId(id); err != nil {
+		return []models.Reading{}, err
+	}
+	return rc.readingsLimit([]string{rc.key("readings", "device", id)}, "-inf", "+inf", limit)
+}
+
+func (rc *RedisClient) ReadingsByValueDescriptor(name string, limit int) ([]models.Reading, error) {
+	return rc.readingsLimit([]string{rc.key("readings", "name", name)}, "-inf", "+inf", limit)
+}
+
+func (rc *RedisClient) ReadingsByValueDescriptorNames(names []string, limit int) ([]models.Reading, error) {
+	if len(names) == 0 {
+		return []models.Reading{}, nil
+	}
+
+	keys := make([]string, len(names))
+	for i, name := range names {
+		keys[i] = rc.key("readings", "name", name)
+	}
+	return rc.readingsLimit(keys, "-inf", "+inf", limit)
+}
+
+func (rc *RedisClient) ReadingsByCreationTime(start, end int64, limit int) ([]models.Reading, error) {
+	if err := validateTimeRange(start, end); err != nil {
+		return []models.Reading{}, err
+	}
+	return rc.readingsLimit([]string{rc.key("readings")}, score(start), score(end), limit)
+}
+
+func (rc *RedisClient) ReadingsPagedAscending(offset, limit int) ([]models.Reading, error) {
+	if err := validatePage(offset, limit); err != nil || limit == 0 {
+		return []models.Reading{}, err
+	}
+
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	ids, err := redis.Strings(conn.Do("ZRANGE", rc.key("readings"), offset, offset+limit-1))
+	if err != nil {
+		return []models.Reading{}, err
+	}
+	return rc.getReadings(conn, ids)
+}
+
+func (rc *RedisClient) ReadingsAfterCursor(cursor string, limit int) ([]models.Reading, string, error) {
+	p, err := decodeCursor(cursor)
+	if err != nil {
+		return []models.Reading{}, cursor, err
+	}
+	if err = validateLimit(limit); err != nil || limit == 0 {
+		return []models.Reading{}, cursor, err
+	}
+
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	ids, err := rc.idsAfter(conn, rc.key("readings"), p, limit)
+	if err != nil {
+		return []models.Reading{}, cursor, err
+	}
+	readings, err := rc.getReadings(conn, ids)
+	if err != nil || len(readings) == 0 {
+		return readings, "", err
+	}
+	last := readings[len(readings)-1]
+	return readings, nextCursor(len(ids), limit, last.Created, last.Id), nil
+}
+
+// Return at most limit readings of the sorted sets scored between min and max, newest first
+func (rc *RedisClient) readingsLimit(keys []string, min, max string, limit int) ([]models.Reading, error) {
+	readings := []models.Reading{}
+
+	// Check if limit is 0
+	if err := validateLimit(limit); err != nil {
+		return readings, err
+	}
+	if limit == 0 {
+		return readings, nil
+	}
+
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	ids, err := rc.newestIds(conn, keys, min, max, limit)
+	if err != nil {
+		return readings, err
+	}
+	return rc.getReadings(conn, ids)
+}
+
+// Return the readings of the ids, skipping the ids without a reading
+func (rc *RedisClient) getReadings(conn redis.Conn, ids []string) ([]models.Reading, error) {
+	readings := []models.Reading{}
+
+	stored, err := rc.getRedisReadings(conn, ids)
+	if err != nil {
+		return readings, err
+	}
+	for _, r := range stored {
+		readings = append(readings, r.Reading)
+	}
+	return readings, nil
+}
+
+func (rc *RedisClient) getRedisReadings(conn redis.Conn, ids []string) ([]redisReading, error) {
+	docs, err := rc.getDocuments(conn, redisReadingKey, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var readings []redisReading
+	for _, doc := range docs {
+		if doc == nil {
+			continue
+		}
+		var r redisReading
+		if err := bson.Unmarshal(doc, &r); err != nil {
+			return nil, err
+		}
+		readings = append(readings, r)
+	}
+	return readings, nil
+}
+
+// Queue the commands storing the reading and indexing it, between MULTI and EXEC
+func (rc *RedisClient) sendAddReading(conn redis.Conn, r redisReading) error {
+	doc, err := bson.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	id := r.Id.Hex()
+	conn.Send("SET", rc.key(redisReadingKey, id), doc)
+	conn.Send("ZADD", rc.key("readings"), r.Created, id)
+	conn.Send("ZADD", rc.key("readings", "device", r.Device), r.Created, id)
+	conn.Send("ZADD", rc.key("readings", "name", r.Name), r.Created, id)
+	return nil
+}
+
+// Queue the commands removing the reading from the store and the indexes, between MULTI and EXEC
+func (rc *RedisClient) sendRemoveReading(conn redis.Conn, r redisReading) {
+	id := r.Id.Hex()
+	conn.Send("DEL", rc.key(redisReadingKey, id))
+	conn.Send("ZREM", rc.key("readings"), id)
+	conn.Send("ZREM", rc.key("readings", "device", r.Device), id)
+	conn.Send("ZREM", rc.key("readings", "name", r.Name), id)
+}
+
+// ************************** VALUE DESCRIPTORS ****************************
+
+func (rc *RedisClient) AddValueDescriptor(v models.ValueDescriptor) (bson.ObjectId, error) {
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	// Created/Modified now
+	v.Created = time.Now().UnixNano() / int64(time.Millisecond)
+	v.Modified = v.Created
+	id := bson.NewObjectId()
+
+	// Claim the name first, it's what makes the value descriptor unique
+	added, err := redis.Bool(conn.Do("HSETNX", rc.key(redisValueDescriptorKey, "name"), v.Name, id.Hex()))
+	if err != nil {
+		return v.Id, err
+	}
+	if !added {
+		return v.Id, ErrNotUnique
+	}
+
+	v.Id = id
+	doc, err := bson.Marshal(v)
+	if err != nil {
+		conn.Do("HDEL", rc.key(redisValueDescriptorKey, "name"), v.Name)
+		return v.Id, err
+	}
+
+	conn.Send("MULTI")
+	conn.Send("SET", rc.key(redisValueDescriptorKey, id.Hex()), doc)
+	conn.Send("ZADD", rc.key("vds"), v.Created, id.Hex())
+	_, err = conn.Do("EXEC")
+	return v.Id, err
+}
+
+func (rc *RedisClient) ValueDescriptors() ([]models.ValueDescriptor, error) {
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	return rc.allValueDescriptors(conn)
+}
+
+func (rc *RedisClient) UpdateValueDescriptor(v models.ValueDescriptor) error {
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	var old models.ValueDescriptor
+	if err := rc.getDocument(conn, redisValueDescriptorKey, v.Id.Hex(), &old); err != nil {
+		return err
+	}
+
+	// See if the name is unique if it changed
+	nameKey := rc.key(redisValueDescriptorKey, "name")
+	if v.Name != old.Name {
+		added, err := redis.Bool(conn.Do("HSETNX", nameKey, v.Name, v.Id.Hex()))
+		if err != nil {
+			return err
+		}
+		if !added {
+			return ErrNotUnique
+		}
+	}
+
+	v.Modified = time.Now().UnixNano() / int64(time.Millisecond)
+	doc, err := bson.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	conn.Send("MULTI")
+	if v.Name != old.Name {
+		conn.Send("HDEL", nameKey, old.Name)
+	}
+	conn.Send("SET", rc.key(redisValueDescriptorKey, v.Id.Hex()), doc)
+	conn.Send("ZADD", rc.key("vds"), v.Created, v.Id.Hex())
+	_, err = conn.Do("EXEC")
+	return err
+}
+
+func (rc *RedisClient) DeleteValueDescriptorById(id string) error {
+	if !bson.IsObjectIdHex(id) {
+		return ErrInvalidObjectId
+	}
+
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	var v models.ValueDescriptor
+	if err := rc.getDocument(conn, redisValueDescriptorKey, id, &v); err != nil {
+		return err
+	}
+
+	conn.Send("MULTI")
+	conn.Send("DEL", rc.key(redisValueDescriptorKey, id))
+	conn.Send("ZREM", rc.key("vds"), id)
+	conn.Send("HDEL", rc.key(redisValueDescriptorKey, "name"), v.Name)
+	_, err := conn.Do("EXEC")
+	return err
+}
+
+func (rc *RedisClient) ValueDescriptorByName(name string) (models.ValueDescriptor, error) {
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	return rc.valueDescriptorByName(conn, name)
+}
+
+func (rc *RedisClient) ValueDescriptorsByName(names []string) ([]models.ValueDescriptor, error) {
+	vList := []models.ValueDescriptor{}
+
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	for _, name := range names {
+		v, err := rc.valueDescriptorByName(conn, name)
+		if err != nil && err != ErrNotFound {
+			return []models.ValueDescriptor{}, err
+		}
+		if err == nil {
+			vList = append(vList, v)
+		}
+	}
+
+	return vList, nil
+}
+
+func (rc *RedisClient) DeleteValueDescriptorByName(name string) error {
+	v, err := rc.ValueDescriptorByName(name)
+	if err != nil {
+		return err
+	}
+
+	count, err := rc.count(rc.key("readings", "name", name))
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrValueDescriptorInUse
+	}
+	return rc.DeleteValueDescriptorById(v.Id.Hex())
+}
+
+func (rc *RedisClient) ValueDescriptorById(id string) (models.ValueDescriptor, error) {
+	if !bson.IsObjectIdHex(id) {
+		return models.ValueDescriptor{}, ErrInvalidObjectId
+	}
+
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	var v models.ValueDescriptor
+	err := rc.getDocument(conn, redisValueDescriptorKey, id, &v)
+	return v, err
+}
+
+func (rc *RedisClient) ValueDescriptorsByUomLabel(uomLabel string) ([]models.ValueDescriptor, error) {
+	return rc.valueDescriptorsMatching(func(v models.ValueDescriptor) bool {
+		return v.UomLabel == uomLabel
+	})
+}
+
+func (rc *RedisClient) ValueDescriptorsByLabel(label string) ([]models.ValueDescriptor, error) {
+	return rc.valueDescriptorsMatching(func(v models.ValueDescriptor) bool {
+		return stringInSlice(label, v.Labels)
+	})
+}
+
+func (rc *RedisClient) ValueDescriptorsByType(t string) ([]models.ValueDescriptor, error) {
+	return rc.valueDescriptorsMatching(func(v models.ValueDescriptor) bool {
+		return v.Type == t
+	})
+}
+
+func (rc *RedisClient) ScrubAllValueDescriptors() error {
+	if !rc.config.AllowScrub {
+		return ErrScrubNotAllowed
+	}
+	return rc.deleteMatching(rc.key(redisValueDescriptorKey + "*"))
+}
+
+func (rc *RedisClient) valueDescriptorByName(conn redis.Conn, name string) (models.ValueDescriptor, error) {
+	var v models.ValueDescriptor
+
+	id, err := redis.String(conn.Do("HGET", rc.key(redisValueDescriptorKey, "name"), name))
+	if err == redis.ErrNil {
+		return v, ErrNotFound
+	}
+	if err != nil {
+		return v, err
+	}
+
+	err = rc.getDocument(conn, redisValueDescriptorKey, id, &v)
+	return v, err
+}
+
+// There are few value descriptors, so the lookups other than the id and the name filter them all
+func (rc *RedisClient) valueDescriptorsMatching(match func(models.ValueDescriptor) bool) ([]models.ValueDescriptor, error) {
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	vList := []models.ValueDescriptor{}
+	all, err := rc.allValueDescriptors(conn)
+	if err != nil {
+		return vList, err
+	}
+	for _, v := range all {
+		if match(v) {
+			vList = append(vList, v)
+		}
+	}
+	return vList, nil
+}
+
+func (rc *RedisClient) allValueDescriptors(conn redis.Conn) ([]models.ValueDescriptor, error) {
+	vList := []models.ValueDescriptor{}
+
+	ids, err := redis.Strings(conn.Do("ZRANGE", rc.key("vds"), 0, -1))
+	if err != nil {
+		return vList, err
+	}
+	docs, err := rc.getDocuments(conn, redisValueDescriptorKey, ids)
+	if err != nil {
+		return vList, err
+	}
+	for _, doc := range docs {
+		if doc == nil {
+			continue
+		}
+		var v models.ValueDescriptor
+		if err := bson.Unmarshal(doc, &v); err != nil {
+			return []models.ValueDescriptor{}, err
+		}
+		vList = append(vList, v)
+	}
+	return vList, nil
+}
+
+// ******************************* HELPERS *********************************
+
+// Score of a sorted set range boundary
+func score(t int64) string {
+	return strconv.FormatInt(t, 10)
+}
+
+func hexIds(ids []bson.ObjectId) []string {
+	hex := make([]string, len(ids))
+	for i, id := range ids {
+		hex[i] = id.Hex()
+	}
+	return hex
+}
+
+func (rc *RedisClient) count(key string) (int, error) {
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	return redis.Int(conn.Do("ZCARD", key))
+}
+
+// Decode the document of the id into out
+// ErrNotFound - there is no document with the id
+func (rc *RedisClient) getDocument(conn redis.Conn, kind string, id string, out interface{}) error {
+	doc, err := redis.Bytes(conn.Do("GET", rc.key(kind, id)))
+	if err == redis.ErrNil {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(doc, out)
+}
+
+// Return the documents of the ids in the same order, nil for the ids without a document
+func (rc *RedisClient) getDocuments(conn redis.Conn, kind string, ids []string) ([][]byte, error) {
+	docs := make([][]byte, 0, len(ids))
+	for start := 0; start < len(ids); start += redisBatchSize {
+		end := start + redisBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		keys := make([]interface{}, 0, end-start)
+		for _, id := range ids[start:end] {
+			keys = append(keys, rc.key(kind, id))
+		}
+		batch, err := redis.ByteSlices(conn.Do("MGET", keys...))
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, batch...)
+	}
+	return docs, nil
+}
+
+// Return at most limit ids of the sorted sets scored between min and max, newest first
+// The ids in several of the sets are only returned once
+func (rc *RedisClient) newestIds(conn redis.Conn, keys []string, min, max string, limit int) ([]string, error) {
+	type member struct {
+		id    string
+		score float64
+	}
+
+	var members []member
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		values, err := redis.Strings(conn.Do("ZREVRANGEBYSCORE", key, max, min, "WITHSCORES", "LIMIT", 0, limit))
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i+1 < len(values); i += 2 {
+			if seen[values[i]] {
+				continue
+			}
+			s, err := strconv.ParseFloat(values[i+1], 64)
+			if err != nil {
+				return nil, err
+			}
+			seen[values[i]] = true
+			members = append(members, member{id: values[i], score: s})
+		}
+	}
+
+	// Each set is already sorted, only the merge of several of them needs it
+	if len(keys) > 1 {
+		sort.SliceStable(members, func(i, j int) bool { return members[i].score > members[j].score })
+	}
+	if len(members) > limit {
+		members = members[:limit]
+	}
+
+	ids := make([]string, len(members))
+	for i, m := range members {
+		ids[i] = m.id
+	}
+	return ids, nil
+}
+
+// Return at most limit ids of the sorted set following the cursor position, oldest first
+// The members with the same score are sorted by the hex ids, so in the order of the ids
+func (rc *RedisClient) idsAfter(conn redis.Conn, key string, p cursorPosition, limit int) ([]string, error) {
+	if p.id == "" {
+		return redis.Strings(conn.Do("ZRANGE", key, 0, limit-1))
+	}
+
+	// Skip the members created at the same time up to the id of the cursor
+	same, err := redis.Strings(conn.Do("ZRANGEBYSCORE", key, score(p.created), score(p.created)))
+	if err != nil {
+		return nil, err
+	}
+	skip := 0
+	for _, id := range same {
+		if id <= p.id.Hex() {
+			skip++
+		}
+	}
+
+	return redis.Strings(conn.Do("ZRANGEBYSCORE", key, score(p.created), "+inf", "LIMIT", skip, limit))
+}
+
+// Delete the keys matching the patterns, in batches so the server isn't blocked
+func (rc *RedisClient) deleteMatching(patterns ...string) error {
+	conn := rc.pool.Get()
+	defer conn.Close()
+
+	for _, pattern := range patterns {
+		var cursor uint64
+		for {
+			reply, err := redis.Values(conn.Do("SCAN", cursor, "MATCH", pattern, "COUNT", redisBatchSize))
+			if err != nil {
+				return err
+			}
+			keys, err := redis.Strings(reply[1], nil)
+			if err != nil {
+				return err
+			}
+			if len(keys) > 0 {
+				args := make([]interface{}, len(keys))
+				for i, k := range keys {
+					args[i] = k
+				}
+				if _, err = conn.Do("DEL", args...); err != nil {
+					return err
+				}
+			}
+
+			cursor, err = redis.Uint64(reply[0], nil)
+			if err != nil {
+				return err
+			}
+			if cursor == 0 {
+				break
+			}
+		}
+	}
+	return nil
+}
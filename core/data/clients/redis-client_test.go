@@ -0,0 +1,119 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// +build redisRunning
+
+// This test will only be executed if the tag redisRunning is added when running
+// the tests with a command like:
+// go test -tags redisRunning
+
+package clients
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+)
+
+func connectTestRedis(t *testing.T) *RedisClient {
+	config := DBConfiguration{
+		DbType:       REDIS,
+		Host:         "0.0.0.0",
+		Port:         6379,
+		DatabaseName: "coredata-test",
+		Timeout:      1000,
+		AllowScrub:   true,
+	}
+
+	redis, err := newRedisClient(config)
+	if err != nil {
+		t.Fatalf("Could not connect with redis: %v", err)
+	}
+	return redis
+}
+
+func TestRedisDB(t *testing.T) {
+
+	t.Log("This test needs to have a running redis on localhost")
+
+	testDB(t, connectTestRedis(t))
+}
+
+func BenchmarkRedisDB(b *testing.B) {
+
+	b.Log("This benchmark needs to have a running redis on localhost")
+
+	config := DBConfiguration{
+		DbType:       REDIS,
+		Host:         "0.0.0.0",
+		Port:         6379,
+		DatabaseName: "coredata-test",
+		Timeout:      1000,
+		AllowScrub:   true,
+	}
+
+	benchmarkDB(b, config)
+}
+
+func TestRedisEventReadings(t *testing.T) {
+	redis := connectTestRedis(t)
+	defer redis.CloseSession()
+
+	if err := redis.ScrubAllEvents(); err != nil {
+		t.Fatalf("Error removing all events: %v", err)
+	}
+
+	e := models.Event{Device: "device1", Readings: []models.Reading{{Name: "temperature"}, {Name: "humidity"}}}
+	id, err := redis.AddEvent(&e)
+	if err != nil {
+		t.Fatalf("Error adding the event: %v", err)
+	}
+
+	stored, err := redis.EventById(id.Hex())
+	if err != nil {
+		t.Fatalf("Error getting the event: %v", err)
+	}
+	if len(stored.Readings) != 2 || stored.Readings[0].Device != "device1" {
+		t.Fatalf("The event should come back with its readings: %v", stored.Readings)
+	}
+
+	// Deleting a reading drops it from its event
+	if err = redis.DeleteReadingById(e.Readings[0].Id.Hex()); err != nil {
+		t.Fatalf("Error deleting the reading: %v", err)
+	}
+	stored, err = redis.EventById(id.Hex())
+	if err != nil {
+		t.Fatalf("Error getting the event: %v", err)
+	}
+	if len(stored.Readings) != 1 || stored.Readings[0].Name != "humidity" {
+		t.Fatalf("The event should only keep the humidity reading: %v", stored.Readings)
+	}
+
+	// Deleting the event deletes the readings left
+	if err = redis.DeleteEventById(id.Hex()); err != nil {
+		t.Fatalf("Error deleting the event: %v", err)
+	}
+	count, err := redis.ReadingCount()
+	if err != nil {
+		t.Fatalf("Error counting the readings: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("There should be no readings left, not %d", count)
+	}
+}
+
+func TestRedisScrubNotAllowed(t *testing.T) {
+	redis := connectTestRedis(t)
+	defer redis.CloseSession()
+	redis.config.AllowScrub = false
+
+	if err := redis.ScrubAllEvents(); err != ErrScrubNotAllowed {
+		t.Fatalf("Expected ErrScrubNotAllowed, got %v", err)
+	}
+	if err := redis.ScrubAllValueDescriptors(); err != ErrScrubNotAllowed {
+		t.Fatalf("Expected ErrScrubNotAllowed, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+// +build mongoRunning
+
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package clients
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+)
+
+const (
+	seedDeviceName = "demo-device"
+	seedEventCount = 5
+)
+
+// Value descriptors loaded by seedDemoData
+var seedValueDescriptors = []models.ValueDescriptor{
+	{
+		Name:        "temperature",
+		Description: "ambient temperature",
+		Type:        "F",
+		UomLabel:    "degreesF",
+		Min:         "-40",
+		Max:         "140",
+		Labels:      []string{"environment", "temperature"},
+	},
+	{
+		Name:        "humidity",
+		Description: "relative humidity",
+		Type:        "F",
+		UomLabel:    "%RH",
+		Min:         "0",
+		Max:         "100",
+		Labels:      []string{"environment", "humidity"},
+	},
+	{
+		Name:        "pressure",
+		Description: "barometric pressure",
+		Type:        "F",
+		UomLabel:    "kPa",
+		Min:         "80",
+		Max:         "110",
+		Labels:      []string{"environment", "pressure"},
+	},
+}
+
+// Load a small known data set for the integration tests, after the data already stored
+// The events belong to seedDeviceName, are one minute apart by origin and have a reading per value descriptor
+func seedDemoData(mc *MongoClient) error {
+	for _, v := range seedValueDescriptors {
+		if _, err := mc.AddValueDescriptor(v); err != nil {
+			return err
+		}
+	}
+
+	origin := time.Now().Add(-seedEventCount*time.Minute).UnixNano() / int64(time.Millisecond)
+	for i := 0; i < seedEventCount; i++ {
+		e := models.Event{Device: seedDeviceName, Origin: origin}
+		e.Readings = []models.Reading{
+			{Name: "temperature", Value: strconv.Itoa(68 + i), Origin: origin},
+			{Name: "humidity", Value: strconv.Itoa(40 + i), Origin: origin},
+			{Name: "pressure", Value: strconv.Itoa(100 + i), Origin: origin},
+		}
+		if _, err := mc.AddEvent(&e); err != nil {
+			return err
+		}
+		origin += int64(time.Minute / time.Millisecond)
+	}
+
+	return nil
+}
+
+// Replace the events, readings and value descriptors of the test database with the demo data
+func resetDemoData(t *testing.T, mc *MongoClient) {
+	if err := mc.ScrubAllEvents(); err != nil {
+		t.Fatalf("Error removing all events: %v", err)
+	}
+	if err := mc.ScrubAllValueDescriptors(); err != nil {
+		t.Fatalf("Error removing all value descriptors: %v", err)
+	}
+	if err := seedDemoData(mc); err != nil {
+		t.Fatalf("Error seeding data: %v", err)
+	}
+}
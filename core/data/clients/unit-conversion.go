@@ -0,0 +1,99 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package clients
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+)
+
+// A unit is converted to the base unit of its quantity as value*scale + offset
+type unit struct {
+	quantity string
+	scale    float64
+	offset   float64
+}
+
+// Units known by ConvertReading, by lower case unit of measure label
+var units = map[string]unit{
+	// Temperature, base unit kelvin
+	"k":          {"temperature", 1, 0},
+	"kelvin":     {"temperature", 1, 0},
+	"c":          {"temperature", 1, 273.15},
+	"°c":         {"temperature", 1, 273.15},
+	"degreesc":   {"temperature", 1, 273.15},
+	"celsius":    {"temperature", 1, 273.15},
+	"f":          {"temperature", 5.0 / 9, 273.15 - 32*5.0/9},
+	"°f":         {"temperature", 5.0 / 9, 273.15 - 32*5.0/9},
+	"degreesf":   {"temperature", 5.0 / 9, 273.15 - 32*5.0/9},
+	"fahrenheit": {"temperature", 5.0 / 9, 273.15 - 32*5.0/9},
+
+	// Pressure, base unit pascal
+	"pa":  {"pressure", 1, 0},
+	"hpa": {"pressure", 100, 0},
+	"kpa": {"pressure", 1000, 0},
+	"bar": {"pressure", 100000, 0},
+	"psi": {"pressure", 6894.757293168, 0},
+
+	// Length, base unit meter
+	"m":  {"length", 1, 0},
+	"cm": {"length", 0.01, 0},
+	"mm": {"length", 0.001, 0},
+	"km": {"length", 1000, 0},
+	"in": {"length", 0.0254, 0},
+	"ft": {"length", 0.3048, 0},
+}
+
+// Return a copy of the reading with its value converted from the unit of its value descriptor to targetUom
+// Readings don't carry a unit so the converted value is in targetUom from then on
+// ErrNotFound - the reading's value descriptor doesn't exist
+// ErrUnsupportedConversion - a unit is unknown or the units measure different quantities
+func (mc *MongoClient) ConvertReading(r models.Reading, targetUom string) (models.Reading, error) {
+	vd, err := mc.ValueDescriptorByName(r.Name)
+	if err != nil {
+		return r, err
+	}
+
+	value, err := convertValue(r.Value, vd.UomLabel, targetUom)
+	if err != nil {
+		return r, err
+	}
+	r.Value = value
+	return r, nil
+}
+
+// Convert the numeric value between the units of measure
+func convertValue(value string, fromUom string, toUom string) (string, error) {
+	from, ok := units[strings.ToLower(strings.TrimSpace(fromUom))]
+	if !ok {
+		return value, ErrUnsupportedConversion
+	}
+	to, ok := units[strings.ToLower(strings.TrimSpace(toUom))]
+	if !ok || to.quantity != from.quantity {
+		return value, ErrUnsupportedConversion
+	}
+
+	v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return value, err
+	}
+
+	// Rounded to hide the floating point noise of the offsets, e.g. 212 instead of 211.99999999999997
+	base := v*from.scale + from.offset
+	converted := math.Round((base-to.offset)/to.scale*1e9) / 1e9
+	return strconv.FormatFloat(converted, 'f', -1, 64), nil
+}
@@ -0,0 +1,66 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package clients
+
+import (
+	"testing"
+)
+
+func TestConvertValue(t *testing.T) {
+	tests := []struct {
+		value    string
+		from     string
+		to       string
+		expected string
+	}{
+		{"100", "C", "F", "212"},
+		{"32", "degreesF", "degreesC", "0"},
+		{"-40", "F", "C", "-40"},
+		{"0", "C", "K", "273.15"},
+		{"101.325", "kPa", "psi", "14.695948776"},
+		{"1", "bar", "hPa", "1000"},
+		{"12", "in", "ft", "1"},
+		{"21.5", "C", "c", "21.5"},
+	}
+	for _, test := range tests {
+		converted, err := convertValue(test.value, test.from, test.to)
+		if err != nil {
+			t.Errorf("Error converting %s %s to %s: %v", test.value, test.from, test.to, err)
+			continue
+		}
+		if converted != test.expected {
+			t.Errorf("%s %s should be %s %s, not %s", test.value, test.from, test.expected, test.to, converted)
+		}
+	}
+}
+
+func TestConvertValueUnsupported(t *testing.T) {
+	tests := []struct {
+		from string
+		to   string
+	}{
+		{"C", "kPa"},
+		{"%RH", "C"},
+		{"C", "unknown"},
+	}
+	for _, test := range tests {
+		if _, err := convertValue("1", test.from, test.to); err != ErrUnsupportedConversion {
+			t.Errorf("Expected ErrUnsupportedConversion from %s to %s, got %v", test.from, test.to, err)
+		}
+	}
+
+	if _, err := convertValue("warm", "C", "F"); err == nil {
+		t.Errorf("A value that isn't a number should not be converted")
+	}
+}
@@ -0,0 +1,79 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package clients
+
+import (
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+)
+
+// Cache of the value descriptors found by name
+// A nil cache is disabled, it never finds anything and ignores what is put in it
+type valueDescriptorCache struct {
+	mutex   sync.RWMutex
+	ttl     time.Duration
+	entries map[string]cachedValueDescriptor
+}
+
+type cachedValueDescriptor struct {
+	vd      models.ValueDescriptor
+	expires time.Time
+}
+
+// Create a cache keeping the value descriptors for ttl
+func newValueDescriptorCache(ttl time.Duration) *valueDescriptorCache {
+	return &valueDescriptorCache{ttl: ttl, entries: make(map[string]cachedValueDescriptor)}
+}
+
+// Get the value descriptor cached for the name if it hasn't expired
+func (c *valueDescriptorCache) get(name string) (models.ValueDescriptor, bool) {
+	if c == nil {
+		return models.ValueDescriptor{}, false
+	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	entry, ok := c.entries[name]
+	if !ok || time.Now().After(entry.expires) {
+		return models.ValueDescriptor{}, false
+	}
+	return entry.vd, true
+}
+
+// Cache the value descriptor by its name
+func (c *valueDescriptorCache) put(vd models.ValueDescriptor) {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries[vd.Name] = cachedValueDescriptor{vd: vd, expires: time.Now().Add(c.ttl)}
+}
+
+// Remove all of the cached value descriptors
+func (c *valueDescriptorCache) clear() {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries = make(map[string]cachedValueDescriptor)
+}
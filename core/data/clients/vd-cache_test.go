@@ -0,0 +1,60 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package clients
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+)
+
+func TestValueDescriptorCache(t *testing.T) {
+	c := newValueDescriptorCache(time.Minute)
+
+	if _, ok := c.get("temperature"); ok {
+		t.Fatalf("An empty cache should not find anything")
+	}
+
+	c.put(models.ValueDescriptor{Name: "temperature", UomLabel: "C"})
+	v, ok := c.get("temperature")
+	if !ok || v.UomLabel != "C" {
+		t.Fatalf("The cached value descriptor should be found: %v %v", v, ok)
+	}
+
+	c.clear()
+	if _, ok = c.get("temperature"); ok {
+		t.Fatalf("A cleared cache should not find anything")
+	}
+}
+
+func TestValueDescriptorCacheExpiry(t *testing.T) {
+	c := newValueDescriptorCache(time.Millisecond)
+
+	c.put(models.ValueDescriptor{Name: "temperature"})
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := c.get("temperature"); ok {
+		t.Fatalf("An expired value descriptor should not be found")
+	}
+}
+
+func TestValueDescriptorCacheDisabled(t *testing.T) {
+	var c *valueDescriptorCache
+
+	c.put(models.ValueDescriptor{Name: "temperature"})
+	if _, ok := c.get("temperature"); ok {
+		t.Fatalf("A disabled cache should not find anything")
+	}
+	c.clear()
+}
@@ -18,6 +18,9 @@ type ConfigurationStruct struct {
 	ReadMaxLimit               int
 	MetaDataCheck              bool
 	ValidateCheck              bool
+	CheckModelTags             bool
+	AllowScrub                 bool
+	MaxReadingsPerEvent        int
 	AddToEventQueue            bool
 	PersistData                bool
 	HeartBeatTime              int
@@ -28,8 +31,24 @@ type ConfigurationStruct struct {
 	ServicePort                int
 	ServiceTimeout             int
 	ServiceAddress             string
+	GRPCPort                   int // Port of the gRPC API (0 - disabled)
 	DeviceUpdateLastConnected  bool
 	ServiceUpdateLastConnected bool
+	RetentionMaxAge            int64
+	RetentionInterval          int
+	ArchiveEnabled             bool
+	ArchiveEndpoint            string
+	ArchiveRegion              string
+	ArchiveBucket              string
+	ArchiveAccessKey           string
+	ArchiveSecretKey           string
+	ArchiveObjectPattern       string
+	ArchiveBatchSize           int
+	ArchiveTimeout             int
+	ScrubPushedInterval        int
+	ScrubPushedBatchSize       int
+	ScrubPushedBatchPause      int
+	DBType                     string
 	MongoDBUserName            string
 	MongoDBPassword            string
 	MongoDatabaseName          string
@@ -38,6 +57,36 @@ type ConfigurationStruct struct {
 	MongoDBConnectTimeout      int
 	MongoDBMaxWaitTime         int
 	MongoDBKeepAlive           bool
+	MongoDBBatchDeleteSize     int
+	MongoDBAppName             string
+	MongoDBSocketKeepAlive     int
+	MongoDBMaxIdleTime         int
+	MongoDBQueryTimeout        int
+	MongoDBScanTimeout         int
+	MongoDBCappedReadings      bool
+	MongoDBReadingsCapSize     int
+	MongoDBReadingsCapDocs     int
+	MongoDBSlowQueryThreshold  int
+	MongoDBCacheDescriptors    bool
+	MongoDBDescriptorCacheTTL  int
+	MongoDBCountsUseSecondary  bool
+	MongoDBCompressionEnabled  bool
+	MongoDBTrackSessionLeaks   bool
+	MongoDBSessionLeakTimeout  int
+	MongoDBEnsureIndexes       bool
+	MongoDBTLSEnabled          bool
+	MongoDBTLSCAFile           string
+	MongoDBTLSCertFile         string
+	MongoDBTLSKeyFile          string
+	MongoDBTLSSkipVerify       bool
+	MongoDBHosts               string
+	MongoDBReplicaSet          string
+	MongoDBReadPreference      string
+	RedisHost                  string
+	RedisPort                  int
+	RedisPassword              string
+	RedisConnectTimeout        int
+	RedisKeyPrefix             string
 	ConsulHost                 string
 	ConsulCheckAddress         string
 	ConsulPort                 int
@@ -67,6 +116,25 @@ type ConfigurationStruct struct {
 	MetaPingPath               string
 	ActiveMQBroker             string
 	ZeroMQAddressPort          string
+	MQTTBroker                 string
+	MQTTClientId               string
+	MQTTUsername               string
+	MQTTPassword               string
+	MQTTTopic                  string
+	MQTTQoS                    int
+	MQTTRetained               bool
+	MQTTTLSCAFile              string
+	MQTTTLSCertFile            string
+	MQTTTLSKeyFile             string
+	MQTTTLSSkipVerify          bool
+	MQTTTimeout                int
+	NATSURL                    string
+	NATSSubject                string
+	NATSStream                 string
+	NATSStreamMaxAge           int
+	NATSUsername               string
+	NATSPassword               string
+	NATSTimeout                int
 }
 
 var configuration ConfigurationStruct = ConfigurationStruct{} //  Needs to be initialized before used
@@ -23,24 +23,21 @@ import (
 
 	"github.com/edgexfoundry/edgex-go/core/clients/types"
 	"github.com/edgexfoundry/edgex-go/core/data/clients"
+	"github.com/edgexfoundry/edgex-go/core/data/errors"
 	"github.com/edgexfoundry/edgex-go/core/domain/models"
 	"github.com/gorilla/mux"
 )
 
 // Check metadata if the device exists
 func checkDevice(device string, w http.ResponseWriter) bool {
-	if configuration.MetaDataCheck {
-		_, err := mdc.CheckForDevice(device)
-		if err != nil {
-			loggingClient.Error(fmt.Sprintf("error checking device %s %v", device, err))
-			switch err := err.(type) {
-			case types.ErrNotFound:
-				http.Error(w, err.Error(), http.StatusNotFound)
-				return false
-			default: //return an error on everything else.
-				http.Error(w, err.Error(), http.StatusServiceUnavailable)
-				return false
-			}
+	if err := checkEventDevice(device); err != nil {
+		switch err := err.(type) {
+		case types.ErrNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return false
+		default: //return an error on everything else.
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return false
 		}
 	}
 
@@ -50,10 +47,10 @@ func checkDevice(device string, w http.ResponseWriter) bool {
 // Put event on the message queue to be processed by the rules engine
 func putEventOnQueue(e models.Event) {
 	loggingClient.Info("Putting event on message queue", "")
-	//	Have multiple implementations (start with ZeroMQ)
+	// Sent with ZeroMQ to export-distro and/or to the MQTT broker, per MsgPubType
 	err := ep.SendEventMessage(e)
 	if err != nil {
-		loggingClient.Error("Unable to send message for event: " + e.String())
+		loggingClient.Error("Unable to send message for event: " + e.String() + ": " + err.Error())
 	}
 }
 
@@ -149,7 +146,11 @@ func scrubAllHandler(w http.ResponseWriter, r *http.Request) {
 
 		err := dbc.ScrubAllEvents()
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			if err == clients.ErrScrubNotAllowed {
+				http.Error(w, err.Error(), http.StatusForbidden)
+			} else {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			}
 			loggingClient.Error("Error scrubbing all events/readings: " + err.Error())
 			return
 		}
@@ -171,8 +172,19 @@ func eventHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	switch r.Method {
-	// Get all events
+	// Get all events, or a page of them with ?offset=&limit= or ?cursor=&limit=
 	case http.MethodGet:
+		page, paged, err := parsePageRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			loggingClient.Error(err.Error())
+			return
+		}
+		if paged {
+			getEventsPage(w, r, page)
+			return
+		}
+
 		events, err := dbc.Events()
 		if err != nil {
 			loggingClient.Error(err.Error())
@@ -187,13 +199,12 @@ func eventHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		encode(events, w)
+		encodeEvents(r, events, w)
 		break
 	// Post a new event
 	case http.MethodPost:
 		var e models.Event
-		dec := json.NewDecoder(r.Body)
-		err := dec.Decode(&e)
+		err := decodeEvent(r, &e)
 
 		// Problem Decoding Event
 		if err != nil {
@@ -204,54 +215,19 @@ func eventHandler(w http.ResponseWriter, r *http.Request) {
 
 		loggingClient.Info("Posting Event: " + e.String())
 
-		// Check device
-		if checkDevice(e.Device, w) == false {
+		id, err := addNewEvent(e)
+		if err != nil {
+			http.Error(w, err.Error(), addEventErrorStatus(err))
 			return
 		}
 
-		if configuration.ValidateCheck {
-			loggingClient.Debug("Validation enabled, parsing events")
-			for reading := range e.Readings {
-				// Check value descriptor
-				vd, err := dbc.ValueDescriptorByName(e.Readings[reading].Name)
-				if err != nil {
-					if err == clients.ErrNotFound {
-						http.Error(w, "Value descriptor for a reading not found", http.StatusNotFound)
-					} else {
-						http.Error(w, err.Error(), http.StatusServiceUnavailable)
-					}
-					loggingClient.Error(err.Error())
-					return
-				}
-
-				valid, err := isValidValueDescriptor(vd, e.Readings[reading])
-				if !valid {
-					http.Error(w, "Validation failed", http.StatusConflict)
-					loggingClient.Error("Validation failed")
-					return
-				}
-			}
-		}
-
-		// Add the event and readings to the database
 		if configuration.PersistData {
-			id, err := dbc.AddEvent(&e)
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusServiceUnavailable)
-				loggingClient.Error(err.Error())
-				return
-			}
-
 			w.WriteHeader(http.StatusOK)
 			w.Write([]byte(id.Hex()))
 		} else {
 			encode("unsaved", w)
 		}
 
-		putEventOnQueue(e)                                 // Push the aux struct to export service (It has the actual readings)
-		updateDeviceLastReportedConnected(e.Device)        // update last reported connected (device)
-		updateDeviceServiceLastReportedConnected(e.Device) // update last reported connected (device service)
-
 		break
 	// Do not update the readings
 	case http.MethodPut:
@@ -309,6 +285,88 @@ func eventHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Write the page of events requested, with the cursor of the next page in the X-Next-Cursor header
+func getEventsPage(w http.ResponseWriter, r *http.Request, page pageRequest) {
+	if page.limit > configuration.ReadMaxLimit {
+		http.Error(w, maxExceededString, http.StatusRequestEntityTooLarge)
+		loggingClient.Error(maxExceededString)
+		return
+	}
+
+	var events []models.Event
+	var next string
+	var err error
+	if page.byCursor {
+		events, next, err = dbc.EventsAfterCursor(page.cursor, page.limit)
+	} else {
+		events, err = dbc.EventsPagedAscending(page.offset, page.limit)
+	}
+	if err != nil {
+		if isPageError(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		}
+		loggingClient.Error(err.Error())
+		return
+	}
+
+	if next != "" {
+		w.Header().Set(nextCursorHeader, next)
+	}
+	encodeEvents(r, events, w)
+}
+
+// Result of one event of a batch posted to /event/batch
+type batchEventResult struct {
+	Index int    `json:"index"`
+	Id    string `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+/*
+Handler for adding a batch of events
+The events are checked like a single posted event, the valid ones are added together and the others
+are reported in their result
+Status code 400 - the body isn't a list of events
+Status code 503 - unanticipated issues
+api/v1/event/batch
+*/
+func eventBatchHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var events []models.Event
+	if err := decodeEvents(r, &events); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		loggingClient.Error("Error decoding the batch of events: " + err.Error())
+		return
+	}
+
+	loggingClient.Info(fmt.Sprintf("Posting a batch of %d events", len(events)))
+
+	results, err := addNewEvents(events)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	encode(results, w)
+}
+
+// HTTP status of a failure to add an event
+func addEventErrorStatus(err error) int {
+	switch err.(type) {
+	case types.ErrNotFound, errors.NoValueDescriptor:
+		return http.StatusNotFound
+	case errors.ValidationFailed:
+		return http.StatusConflict
+	}
+	if err == clients.ErrEventTooLarge {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusServiceUnavailable
+}
+
 //GET
 //Return the event specified by the event ID
 ///api/v1/event/{id}
@@ -337,7 +395,7 @@ func getEventByIdHandler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Return the result
-		encode(e, w)
+		encodeEvents(r, e, w)
 	}
 }
 
@@ -517,7 +575,7 @@ func getEventByDeviceHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		encode(eventList, w)
+		encodeEvents(r, eventList, w)
 	}
 }
 
@@ -613,12 +671,16 @@ func eventByCreationTimeHandler(w http.ResponseWriter, r *http.Request) {
 
 		e, err := dbc.EventsByCreationTime(start, end, limit)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			if err == clients.ErrInvalidTimeRange {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			} else {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			}
 			loggingClient.Error(err.Error())
 			return
 		}
 
-		encode(e, w)
+		encodeEvents(r, e, w)
 	}
 }
 
@@ -20,10 +20,12 @@ import (
 	"net/http/httptest"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/edgexfoundry/edgex-go/core/data/clients"
 	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/cbor"
 	"github.com/edgexfoundry/edgex-go/support/logging-client"
 	"github.com/gorilla/mux"
 )
@@ -99,6 +101,174 @@ func TestGetEventByIdHandler(t *testing.T) {
 	testEventWithoutReadings(event, t)
 }
 
+func TestGetEventByIdHandlerCBOR(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/event/"+testEvent.ID.Hex(), nil)
+	req.Header.Set("Accept", "application/json;q=0.5, application/cbor")
+	w := httptest.NewRecorder()
+
+	testRoutes.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatal("value expected, status code " + strconv.Itoa(w.Code) + " " + req.Method + " " + req.URL.Path)
+	}
+	if w.Header().Get("Content-Type") != cbor.ContentType {
+		t.Fatalf("The event should be encoded in CBOR, not %s", w.Header().Get("Content-Type"))
+	}
+
+	v, err := cbor.Unmarshal(w.Body.Bytes())
+	if err != nil {
+		t.Fatalf("The response should be valid CBOR: %v", err)
+	}
+	event, err := eventFromCBOR(v)
+	if err != nil {
+		t.Fatalf("The response should be an event: %v", err)
+	}
+	testEventWithoutReadings(event, t)
+}
+
+func TestGetEventHandlerPaged(t *testing.T) {
+	configuration.ReadMaxLimit = 10
+	defer func() { configuration.ReadMaxLimit = 0 }()
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/event?offset=0&limit=1", nil)
+	w := httptest.NewRecorder()
+	testRoutes.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("value expected, status code %d %s", w.Code, req.URL)
+	}
+	events := []models.Event{}
+	json.Unmarshal(w.Body.Bytes(), &events)
+	if len(events) != 1 {
+		t.Fatalf("The page should have 1 event, not %d", len(events))
+	}
+	testEventWithoutReadings(events[0], t)
+
+	// A full page has a cursor to the next one, the empty page after the last event doesn't
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/event?cursor=&limit=1", nil)
+	w = httptest.NewRecorder()
+	testRoutes.ServeHTTP(w, req)
+
+	next := w.Header().Get(nextCursorHeader)
+	if w.Code != 200 || next == "" {
+		t.Fatalf("The first page should have a next cursor, status code %d cursor %q", w.Code, next)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/event?limit=10&cursor="+next, nil)
+	w = httptest.NewRecorder()
+	testRoutes.ServeHTTP(w, req)
+
+	events = []models.Event{}
+	json.Unmarshal(w.Body.Bytes(), &events)
+	if w.Code != 200 || len(events) != 0 || w.Header().Get(nextCursorHeader) != "" {
+		t.Fatalf("The page after the last event should be empty without a cursor, status code %d events %d", w.Code, len(events))
+	}
+}
+
+func TestGetEventHandlerPagedInvalid(t *testing.T) {
+	configuration.ReadMaxLimit = 10
+	defer func() { configuration.ReadMaxLimit = 0 }()
+
+	tests := []struct {
+		query string
+		code  int
+	}{
+		{"offset=-1", http.StatusBadRequest},
+		{"offset=first", http.StatusBadRequest},
+		{"limit=-1", http.StatusBadRequest},
+		{"cursor=invalid!", http.StatusBadRequest},
+		{"offset=0&cursor=", http.StatusBadRequest},
+		{"limit=11", http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/event?"+tt.query, nil)
+		w := httptest.NewRecorder()
+		testRoutes.ServeHTTP(w, req)
+
+		if w.Code != tt.code {
+			t.Errorf("%s should return %d, not %d", tt.query, tt.code, w.Code)
+		}
+	}
+}
+
+func TestEventBatchHandlerInvalid(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/event/batch", strings.NewReader(`{"device":"not a list"}`))
+	w := httptest.NewRecorder()
+	testRoutes.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("A body that isn't a list of events should return 400, not %d", w.Code)
+	}
+}
+
+func TestEventBatchHandlerValidation(t *testing.T) {
+	configuration.ValidateCheck = true
+	defer func() { configuration.ValidateCheck = false }()
+
+	// No value descriptor exists, so every event fails the validation and none is added
+	body := `[{"device":"test device","readings":[{"name":"temperature","value":"1"}]},` +
+		`{"device":"test device","readings":[{"name":"humidity","value":"2"}]}]`
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/event/batch", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	testRoutes.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("The batch should be answered with the results, status code %d", w.Code)
+	}
+	results := []batchEventResult{}
+	json.Unmarshal(w.Body.Bytes(), &results)
+	if len(results) != 2 {
+		t.Fatalf("There should be 2 results, not %d", len(results))
+	}
+	for i, r := range results {
+		if r.Index != i || r.Id != "" || r.Error == "" {
+			t.Errorf("Event %d should be reported as invalid: %v", i, r)
+		}
+	}
+
+	count, _ := dbc.EventCount()
+	if count != 1 {
+		t.Fatalf("The invalid events shouldn't be added, there are %d events", count)
+	}
+}
+
+func TestEventBatchHandlerCBOR(t *testing.T) {
+	configuration.ValidateCheck = true
+	defer func() { configuration.ValidateCheck = false }()
+
+	body, _ := cbor.Marshal([]interface{}{
+		map[string]interface{}{
+			"device": "test device",
+			"readings": []interface{}{
+				map[string]interface{}{"name": "image", "binaryValue": []byte{0xff, 0xd8, 0xff}},
+			},
+		},
+	})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/event/batch", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", cbor.ContentType)
+	w := httptest.NewRecorder()
+	testRoutes.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("The CBOR batch should be decoded and answered with the results, status code %d", w.Code)
+	}
+	results := []batchEventResult{}
+	json.Unmarshal(w.Body.Bytes(), &results)
+	if len(results) != 1 || results[0].Error == "" {
+		t.Fatalf("The event without a value descriptor should be reported as invalid: %v", results)
+	}
+
+	// A JSON body sent as CBOR isn't a list of events
+	req, _ = http.NewRequest(http.MethodPost, "/api/v1/event/batch", strings.NewReader(`[{"device":"test device"}]`))
+	req.Header.Set("Content-Type", cbor.ContentType)
+	w = httptest.NewRecorder()
+	testRoutes.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("An invalid CBOR body should return 400, not %d", w.Code)
+	}
+}
+
 func testEventWithoutReadings(event models.Event, t *testing.T) {
 	if event.ID.Hex() != testEvent.ID.Hex() {
 		t.Error("eventId mismatch. expected " + testEvent.ID.Hex() + " received " + event.ID.Hex())
@@ -0,0 +1,419 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/edgexfoundry/edgex-go/core/clients/types"
+	"github.com/edgexfoundry/edgex-go/core/data/clients"
+	"github.com/edgexfoundry/edgex-go/core/data/errors"
+	"github.com/edgexfoundry/edgex-go/core/data/pb"
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Create the gRPC server of the core data API, the REST API and it share the same operations
+func NewGrpcServer() *grpc.Server {
+	s := grpc.NewServer()
+	pb.RegisterCoreDataServer(s, grpcServer{})
+	return s
+}
+
+type grpcServer struct{}
+
+func (grpcServer) AddEvent(ctx context.Context, e *pb.Event) (*pb.AddReply, error) {
+	event, err := eventFromProto(e)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	loggingClient.Info("Posting Event: " + event.String())
+
+	id, err := addNewEvent(event)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.AddReply{Id: objectIdHex(id)}, nil
+}
+
+func (grpcServer) StreamEvents(stream pb.CoreData_StreamEventsServer) error {
+	for index := int64(0); ; index++ {
+		e, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		reply := &pb.StreamEventReply{Index: index}
+		event, err := eventFromProto(e)
+		if err == nil {
+			var id bson.ObjectId
+			id, err = addNewEvent(event)
+			reply.Id = objectIdHex(id)
+		}
+		if err != nil {
+			reply.Error = err.Error()
+		}
+		if err = stream.Send(reply); err != nil {
+			return err
+		}
+	}
+}
+
+func (grpcServer) GetEvent(ctx context.Context, req *pb.IdRequest) (*pb.Event, error) {
+	e, err := dbc.EventById(req.Id)
+	if err != nil {
+		loggingClient.Error(err.Error())
+		return nil, grpcError(err)
+	}
+	return eventToProto(e), nil
+}
+
+func (grpcServer) EventsForDevice(req *pb.DeviceRequest, stream pb.CoreData_EventsForDeviceServer) error {
+	if err := checkGrpcLimit(req.Limit); err != nil {
+		return err
+	}
+	if err := checkEventDevice(req.Device); err != nil {
+		return grpcError(err)
+	}
+
+	events, err := dbc.EventsForDeviceLimit(req.Device, int(req.Limit))
+	if err != nil {
+		loggingClient.Error(err.Error())
+		return grpcError(err)
+	}
+	for _, e := range events {
+		if err = stream.Send(eventToProto(e)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (grpcServer) DeleteEvent(ctx context.Context, req *pb.IdRequest) (*pb.Empty, error) {
+	e, err := dbc.EventById(req.Id)
+	if err != nil {
+		loggingClient.Error(err.Error())
+		return nil, grpcError(err)
+	}
+
+	loggingClient.Info("Deleting event: " + e.ID.Hex())
+	if err = deleteEvent(e); err != nil {
+		loggingClient.Error(err.Error())
+		return nil, grpcError(err)
+	}
+	return &pb.Empty{}, nil
+}
+
+func (grpcServer) AddReading(ctx context.Context, r *pb.Reading) (*pb.AddReply, error) {
+	reading, err := readingFromProto(r)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	id, err := addNewReading(reading)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.AddReply{Id: objectIdHex(id)}, nil
+}
+
+func (grpcServer) GetReading(ctx context.Context, req *pb.IdRequest) (*pb.Reading, error) {
+	r, err := dbc.ReadingById(req.Id)
+	if err != nil {
+		loggingClient.Error(err.Error())
+		return nil, grpcError(err)
+	}
+	return readingToProto(r), nil
+}
+
+func (grpcServer) ReadingsForDevice(req *pb.DeviceRequest, stream pb.CoreData_ReadingsForDeviceServer) error {
+	if err := checkGrpcLimit(req.Limit); err != nil {
+		return err
+	}
+	if err := checkEventDevice(req.Device); err != nil {
+		return grpcError(err)
+	}
+
+	readings, err := dbc.ReadingsByDevice(req.Device, int(req.Limit))
+	if err != nil {
+		loggingClient.Error(err.Error())
+		return grpcError(err)
+	}
+	return sendReadings(readings, stream)
+}
+
+func (grpcServer) ReadingsForValueDescriptor(req *pb.NameRequest, stream pb.CoreData_ReadingsForValueDescriptorServer) error {
+	if err := checkGrpcLimit(req.Limit); err != nil {
+		return err
+	}
+	if configuration.ValidateCheck {
+		if _, err := dbc.ValueDescriptorByName(req.Name); err != nil {
+			loggingClient.Error(err.Error())
+			return grpcError(err)
+		}
+	}
+
+	readings, err := dbc.ReadingsByValueDescriptor(req.Name, int(req.Limit))
+	if err != nil {
+		loggingClient.Error(err.Error())
+		return grpcError(err)
+	}
+	return sendReadings(readings, stream)
+}
+
+func sendReadings(readings []models.Reading, stream interface{ Send(*pb.Reading) error }) error {
+	for _, r := range readings {
+		if err := stream.Send(readingToProto(r)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (grpcServer) AddValueDescriptor(ctx context.Context, v *pb.ValueDescriptor) (*pb.AddReply, error) {
+	vd, err := valueDescriptorFromProto(v)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	id, err := addNewValueDescriptor(vd)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.AddReply{Id: id.Hex()}, nil
+}
+
+func (grpcServer) GetValueDescriptor(ctx context.Context, req *pb.NameRequest) (*pb.ValueDescriptor, error) {
+	v, err := dbc.ValueDescriptorByName(req.Name)
+	if err != nil {
+		loggingClient.Error(err.Error())
+		return nil, grpcError(err)
+	}
+	return valueDescriptorToProto(v), nil
+}
+
+func (grpcServer) ValueDescriptors(req *pb.Empty, stream pb.CoreData_ValueDescriptorsServer) error {
+	vList, err := dbc.ValueDescriptors()
+	if err != nil {
+		loggingClient.Error(err.Error())
+		return grpcError(err)
+	}
+	if len(vList) > configuration.ReadMaxLimit {
+		loggingClient.Error(maxExceededString)
+		return status.Error(codes.ResourceExhausted, maxExceededString)
+	}
+
+	for _, v := range vList {
+		if err = stream.Send(valueDescriptorToProto(v)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (grpcServer) DeleteValueDescriptor(ctx context.Context, req *pb.NameRequest) (*pb.Empty, error) {
+	vd, err := dbc.ValueDescriptorByName(req.Name)
+	if err != nil {
+		loggingClient.Error(err.Error())
+		return nil, grpcError(err)
+	}
+	if err = removeValueDescriptor(vd); err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.Empty{}, nil
+}
+
+// Refuse the limits over the read max limit like the REST API
+func checkGrpcLimit(limit int32) error {
+	if limit < 0 {
+		return status.Error(codes.InvalidArgument, clients.ErrInvalidLimit.Error())
+	}
+	if int(limit) > configuration.ReadMaxLimit {
+		loggingClient.Error(maxExceededString)
+		return status.Error(codes.ResourceExhausted, maxExceededString)
+	}
+	return nil
+}
+
+// gRPC status of an error of the shared operations, the counterpart of their HTTP status codes
+func grpcError(err error) error {
+	switch err.(type) {
+	case types.ErrNotFound, errors.NoValueDescriptor:
+		return status.Error(codes.NotFound, err.Error())
+	case errors.ValidationFailed, errors.BadValueDescriptor, errors.ValueDescriptorStillInUse:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	switch err {
+	case clients.ErrNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case clients.ErrInvalidObjectId, clients.ErrInvalidReading:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case clients.ErrNotUnique:
+		return status.Error(codes.AlreadyExists, err.Error())
+	case clients.ErrEventTooLarge:
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return status.Error(codes.Unavailable, err.Error())
+}
+
+func eventToProto(e models.Event) *pb.Event {
+	event := &pb.Event{
+		Id:       objectIdHex(e.ID),
+		Pushed:   e.Pushed,
+		Device:   e.Device,
+		Created:  e.Created,
+		Modified: e.Modified,
+		Origin:   e.Origin,
+		Schedule: e.Schedule,
+		Event:    e.Event,
+	}
+	for _, r := range e.Readings {
+		event.Readings = append(event.Readings, readingToProto(r))
+	}
+	return event
+}
+
+func eventFromProto(e *pb.Event) (models.Event, error) {
+	id, err := objectIdFromHex(e.Id)
+	if err != nil {
+		return models.Event{}, err
+	}
+	event := models.Event{
+		ID:       id,
+		Pushed:   e.Pushed,
+		Device:   e.Device,
+		Created:  e.Created,
+		Modified: e.Modified,
+		Origin:   e.Origin,
+		Schedule: e.Schedule,
+		Event:    e.Event,
+	}
+	for _, r := range e.Readings {
+		reading, err := readingFromProto(r)
+		if err != nil {
+			return event, err
+		}
+		event.Readings = append(event.Readings, reading)
+	}
+	return event, nil
+}
+
+func readingToProto(r models.Reading) *pb.Reading {
+	return &pb.Reading{
+		Id:          objectIdHex(r.Id),
+		Pushed:      r.Pushed,
+		Created:     r.Created,
+		Origin:      r.Origin,
+		Modified:    r.Modified,
+		Device:      r.Device,
+		Name:        r.Name,
+		Value:       r.Value,
+		BinaryValue: r.BinaryValue,
+	}
+}
+
+func readingFromProto(r *pb.Reading) (models.Reading, error) {
+	id, err := objectIdFromHex(r.Id)
+	if err != nil {
+		return models.Reading{}, err
+	}
+	return models.Reading{
+		Id:          id,
+		Pushed:      r.Pushed,
+		Created:     r.Created,
+		Origin:      r.Origin,
+		Modified:    r.Modified,
+		Device:      r.Device,
+		Name:        r.Name,
+		Value:       r.Value,
+		BinaryValue: r.BinaryValue,
+	}, nil
+}
+
+func valueDescriptorToProto(v models.ValueDescriptor) *pb.ValueDescriptor {
+	return &pb.ValueDescriptor{
+		Id:           objectIdHex(v.Id),
+		Created:      v.Created,
+		Description:  v.Description,
+		Modified:     v.Modified,
+		Origin:       v.Origin,
+		Name:         v.Name,
+		Min:          limitString(v.Min),
+		Max:          limitString(v.Max),
+		DefaultValue: limitString(v.DefaultValue),
+		Type:         v.Type,
+		UomLabel:     v.UomLabel,
+		Formatting:   v.Formatting,
+		Labels:       v.Labels,
+	}
+}
+
+func valueDescriptorFromProto(v *pb.ValueDescriptor) (models.ValueDescriptor, error) {
+	id, err := objectIdFromHex(v.Id)
+	if err != nil {
+		return models.ValueDescriptor{}, err
+	}
+	vd := models.ValueDescriptor{
+		Id:          id,
+		Created:     v.Created,
+		Description: v.Description,
+		Modified:    v.Modified,
+		Origin:      v.Origin,
+		Name:        v.Name,
+		Type:        v.Type,
+		UomLabel:    v.UomLabel,
+		Formatting:  v.Formatting,
+		Labels:      v.Labels,
+	}
+	// The REST API stores the limits as strings, an empty one isn't set
+	if v.Min != "" {
+		vd.Min = v.Min
+	}
+	if v.Max != "" {
+		vd.Max = v.Max
+	}
+	if v.DefaultValue != "" {
+		vd.DefaultValue = v.DefaultValue
+	}
+	return vd, nil
+}
+
+func limitString(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+func objectIdFromHex(id string) (bson.ObjectId, error) {
+	if id == "" {
+		return "", nil
+	}
+	if !bson.IsObjectIdHex(id) {
+		return "", clients.ErrInvalidObjectId
+	}
+	return bson.ObjectIdHex(id), nil
+}
@@ -0,0 +1,193 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/edgexfoundry/edgex-go/core/data/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Serve the gRPC API on a local port and return a client of it
+func newTestGrpcClient(t *testing.T) (pb.CoreDataClient, func()) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error listening: %v", err)
+	}
+	s := NewGrpcServer()
+	go s.Serve(l)
+
+	conn, err := grpc.Dial(l.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		s.Stop()
+		t.Fatalf("Error dialing the gRPC server: %v", err)
+	}
+	return pb.NewCoreDataClient(conn), func() {
+		conn.Close()
+		s.Stop()
+	}
+}
+
+func TestGrpcGetEvent(t *testing.T) {
+	client, stop := newTestGrpcClient(t)
+	defer stop()
+
+	e, err := client.GetEvent(context.Background(), &pb.IdRequest{Id: testEvent.ID.Hex()})
+	if err != nil {
+		t.Fatalf("Error getting the event: %v", err)
+	}
+	if e.Id != testEvent.ID.Hex() || e.Device != testEvent.Device || e.Origin != testEvent.Origin {
+		t.Fatalf("Event mismatch, expected %v, received %v", testEvent, e)
+	}
+
+	_, err = client.GetEvent(context.Background(), &pb.IdRequest{Id: "57ba04a1189b95b8afcdafd7"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("An unknown event should be NotFound, not %v", err)
+	}
+}
+
+func TestGrpcAddEventValidation(t *testing.T) {
+	configuration.ValidateCheck = true
+	defer func() { configuration.ValidateCheck = false }()
+	client, stop := newTestGrpcClient(t)
+	defer stop()
+
+	// No value descriptor exists, so the event fails the validation like over REST
+	_, err := client.AddEvent(context.Background(), &pb.Event{
+		Device:   "test device",
+		Readings: []*pb.Reading{{Name: "temperature", Value: "1"}},
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("A reading without a value descriptor should be NotFound, not %v", err)
+	}
+
+	_, err = client.AddEvent(context.Background(), &pb.Event{Id: "not an id"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("An invalid id should be InvalidArgument, not %v", err)
+	}
+}
+
+func TestGrpcStreamEvents(t *testing.T) {
+	configuration.ValidateCheck = true
+	defer func() { configuration.ValidateCheck = false }()
+	client, stop := newTestGrpcClient(t)
+	defer stop()
+
+	stream, err := client.StreamEvents(context.Background())
+	if err != nil {
+		t.Fatalf("Error opening the stream: %v", err)
+	}
+	events := []*pb.Event{
+		{Id: "not an id"},
+		{Device: "test device", Readings: []*pb.Reading{{Name: "temperature", Value: "1"}}},
+	}
+	for _, e := range events {
+		if err = stream.Send(e); err != nil {
+			t.Fatalf("Error sending an event: %v", err)
+		}
+	}
+	stream.CloseSend()
+
+	// A refused event is answered and doesn't end the stream
+	for i := range events {
+		reply, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Error receiving the reply %d: %v", i, err)
+		}
+		if reply.Index != int64(i) || reply.Id != "" || reply.Error == "" {
+			t.Errorf("Event %d should be reported as refused: %v", i, reply)
+		}
+	}
+	if _, err = stream.Recv(); err != io.EOF {
+		t.Fatalf("The stream should end after the replies, not %v", err)
+	}
+
+	count, _ := dbc.EventCount()
+	if count != 1 {
+		t.Fatalf("The refused events shouldn't be added, there are %d events", count)
+	}
+}
+
+func TestGrpcValueDescriptors(t *testing.T) {
+	configuration.ReadMaxLimit = 10
+	defer func() { configuration.ReadMaxLimit = 0 }()
+	client, stop := newTestGrpcClient(t)
+	defer stop()
+	ctx := context.Background()
+
+	added, err := client.AddValueDescriptor(ctx, &pb.ValueDescriptor{Name: "grpc", Type: "F", Min: "-20", Max: "20"})
+	if err != nil {
+		t.Fatalf("Error adding the value descriptor: %v", err)
+	}
+	_, err = client.AddValueDescriptor(ctx, &pb.ValueDescriptor{Name: "grpc", Type: "F"})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Fatalf("A second value descriptor with the name should be AlreadyExists, not %v", err)
+	}
+
+	v, err := client.GetValueDescriptor(ctx, &pb.NameRequest{Name: "grpc"})
+	if err != nil {
+		t.Fatalf("Error getting the value descriptor: %v", err)
+	}
+	if v.Id != added.Id || v.Type != "F" || v.Min != "-20" || v.Max != "20" || v.DefaultValue != "" {
+		t.Fatalf("Value descriptor mismatch: %v", v)
+	}
+
+	stream, err := client.ValueDescriptors(ctx, &pb.Empty{})
+	if err != nil {
+		t.Fatalf("Error listing the value descriptors: %v", err)
+	}
+	found := false
+	for {
+		v, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Error receiving the value descriptors: %v", err)
+		}
+		found = found || v.Name == "grpc"
+	}
+	if !found {
+		t.Fatalf("The value descriptor should be listed")
+	}
+
+	if _, err = client.DeleteValueDescriptor(ctx, &pb.NameRequest{Name: "grpc"}); err != nil {
+		t.Fatalf("Error deleting the value descriptor: %v", err)
+	}
+	_, err = client.GetValueDescriptor(ctx, &pb.NameRequest{Name: "grpc"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("The deleted value descriptor should be NotFound, not %v", err)
+	}
+}
+
+func TestGrpcLimit(t *testing.T) {
+	configuration.ReadMaxLimit = 10
+	defer func() { configuration.ReadMaxLimit = 0 }()
+	client, stop := newTestGrpcClient(t)
+	defer stop()
+
+	stream, err := client.ReadingsForDevice(context.Background(), &pb.DeviceRequest{Device: "test device", Limit: 11})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("A limit over the read max limit should be ResourceExhausted, not %v", err)
+	}
+}
@@ -19,9 +19,12 @@ import (
 
 	"github.com/edgexfoundry/edgex-go/core/clients/metadata"
 	"github.com/edgexfoundry/edgex-go/core/clients/types"
+	"github.com/edgexfoundry/edgex-go/core/data/archive"
 	"github.com/edgexfoundry/edgex-go/core/data/clients"
 	"github.com/edgexfoundry/edgex-go/core/data/messaging"
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
 	"github.com/edgexfoundry/edgex-go/internal"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/messagebus"
 	consulclient "github.com/edgexfoundry/edgex-go/support/consul-client"
 	"github.com/edgexfoundry/edgex-go/support/logging-client"
 )
@@ -33,6 +36,9 @@ var ep *messaging.EventPublisher
 var mdc metadata.DeviceClient
 var msc metadata.DeviceServiceClient
 
+// Stops the background workers, closed by Destruct
+var stopWorkers chan struct{}
+
 func ConnectToConsul(conf ConfigurationStruct) error {
 
 	// Initialize service on Consul
@@ -64,20 +70,100 @@ func Init(conf ConfigurationStruct, l logger.LoggingClient, useConsul bool) erro
 
 	var err error
 
+	// Catch models that would lose fields in the database or in the exports
+	if conf.CheckModelTags {
+		if err = models.ValidateModelTags(); err != nil {
+			return err
+		}
+	}
+
 	// Create a database client
-	dbc, err = clients.NewDBClient(clients.DBConfiguration{
-		DbType:       clients.MONGO,
-		Host:         conf.MongoDBHost,
-		Port:         conf.MongoDBPort,
-		Timeout:      conf.MongoDBConnectTimeout,
-		DatabaseName: conf.MongoDatabaseName,
-		Username:     conf.MongoDBUserName,
-		Password:     conf.MongoDBPassword,
-	})
+	dbConfig := clients.DBConfiguration{
+		Driver:          conf.DBType,
+		Host:            conf.MongoDBHost,
+		Port:            conf.MongoDBPort,
+		Timeout:         conf.MongoDBConnectTimeout,
+		DatabaseName:    conf.MongoDatabaseName,
+		Username:        conf.MongoDBUserName,
+		Password:        conf.MongoDBPassword,
+		BatchDeleteSize: conf.MongoDBBatchDeleteSize,
+		AppName:         conf.MongoDBAppName,
+		SocketKeepAlive: conf.MongoDBSocketKeepAlive,
+		MaxIdleTime:     conf.MongoDBMaxIdleTime,
+		QueryTimeout:    conf.MongoDBQueryTimeout,
+		ScanTimeout:     conf.MongoDBScanTimeout,
+		CappedReadings:  conf.MongoDBCappedReadings,
+		ReadingsCapSize: conf.MongoDBReadingsCapSize,
+		ReadingsCapDocs: conf.MongoDBReadingsCapDocs,
+		SlowQuery:       conf.MongoDBSlowQueryThreshold,
+		CacheVDs:        conf.MongoDBCacheDescriptors,
+		VDCacheTTL:      conf.MongoDBDescriptorCacheTTL,
+		AllowScrub:      conf.AllowScrub,
+		MaxReadings:     conf.MaxReadingsPerEvent,
+		CountsSecondary: conf.MongoDBCountsUseSecondary,
+		Compression:     conf.MongoDBCompressionEnabled,
+		TrackSessions:   conf.MongoDBTrackSessionLeaks,
+		LeakTimeout:     conf.MongoDBSessionLeakTimeout,
+		SkipIndexes:     !conf.MongoDBEnsureIndexes,
+		TLS:             conf.MongoDBTLSEnabled,
+		TLSCAFile:       conf.MongoDBTLSCAFile,
+		TLSCertFile:     conf.MongoDBTLSCertFile,
+		TLSKeyFile:      conf.MongoDBTLSKeyFile,
+		TLSSkipVerify:   conf.MongoDBTLSSkipVerify,
+		Hosts:           splitHosts(conf.MongoDBHosts),
+		ReplicaSet:      conf.MongoDBReplicaSet,
+		ReadPreference:  conf.MongoDBReadPreference,
+	}
+	// Redis has its own connection settings, the other mongo settings don't apply to it
+	if conf.DBType == clients.REDIS.String() {
+		dbConfig.Host = conf.RedisHost
+		dbConfig.Port = conf.RedisPort
+		dbConfig.Timeout = conf.RedisConnectTimeout
+		dbConfig.DatabaseName = conf.RedisKeyPrefix
+		dbConfig.Username = ""
+		dbConfig.Password = conf.RedisPassword
+	}
+	dbc, err = clients.NewDBClient(dbConfig)
+	if err == clients.ErrUnsupportedDatabase {
+		return fmt.Errorf("no database driver %s, the registered ones are %v", conf.DBType, clients.RegisteredDBClients())
+	}
 	if err != nil {
 		return fmt.Errorf("couldn't connect to database: %v", err.Error())
 	}
 
+	if conf.ArchiveEnabled {
+		s3, err := archive.NewS3Archiver(archive.S3Configuration{
+			Endpoint:      conf.ArchiveEndpoint,
+			Region:        conf.ArchiveRegion,
+			Bucket:        conf.ArchiveBucket,
+			AccessKey:     conf.ArchiveAccessKey,
+			SecretKey:     conf.ArchiveSecretKey,
+			ObjectPattern: conf.ArchiveObjectPattern,
+			Timeout:       conf.ArchiveTimeout,
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't set up the event archive: %v", err)
+		}
+		archiver = s3
+		archiveBatchSize = conf.ArchiveBatchSize
+	}
+
+	stopWorkers = make(chan struct{})
+	if conf.RetentionMaxAge > 0 {
+		if conf.RetentionInterval <= 0 {
+			return fmt.Errorf("RetentionInterval must be positive to expire the events older than RetentionMaxAge")
+		}
+		loggingClient.Info(fmt.Sprintf("Expiring the events and readings older than %d ms every %d ms", conf.RetentionMaxAge, conf.RetentionInterval))
+		startRetention(conf.RetentionMaxAge, conf.RetentionInterval, stopWorkers)
+	}
+	if conf.ScrubPushedInterval > 0 {
+		if conf.ScrubPushedBatchSize <= 0 {
+			return fmt.Errorf("ScrubPushedBatchSize must be positive to scrub the pushed events")
+		}
+		loggingClient.Info(fmt.Sprintf("Scrubbing the pushed events every %d ms, %d at a time", conf.ScrubPushedInterval, conf.ScrubPushedBatchSize))
+		startScrubber(conf.ScrubPushedInterval, conf.ScrubPushedBatchSize, conf.ScrubPushedBatchPause, stopWorkers)
+	}
+
 	// Create metadata clients
 	params := types.EndpointParams{
 						ServiceKey:internal.CoreMetaDataServiceKey,
@@ -91,13 +177,47 @@ func Init(conf ConfigurationStruct, l logger.LoggingClient, useConsul bool) erro
 	msc = metadata.NewDeviceServiceClient(params, types.Endpoint{})
 
 	// Create the event publisher
-	ep = messaging.NewZeroMQPublisher(messaging.ZeroMQConfiguration{
-		AddressPort: conf.ZeroMQAddressPort,
-	})
+	if conf.MQTTQoS < 0 || conf.MQTTQoS > 2 {
+		return fmt.Errorf("MQTTQoS must be 0, 1 or 2")
+	}
+	ep, err = messaging.NewEventPublisher(conf.MsgPubType,
+		messaging.ZeroMQConfiguration{
+			AddressPort: conf.ZeroMQAddressPort,
+		},
+		messaging.MQTTConfiguration{
+			Broker:        conf.MQTTBroker,
+			ClientId:      conf.MQTTClientId,
+			Username:      conf.MQTTUsername,
+			Password:      conf.MQTTPassword,
+			Topic:         conf.MQTTTopic,
+			QoS:           byte(conf.MQTTQoS),
+			Retained:      conf.MQTTRetained,
+			TLSCAFile:     conf.MQTTTLSCAFile,
+			TLSCertFile:   conf.MQTTTLSCertFile,
+			TLSKeyFile:    conf.MQTTTLSKeyFile,
+			TLSSkipVerify: conf.MQTTTLSSkipVerify,
+			Timeout:       conf.MQTTTimeout,
+		},
+		messagebus.Configuration{
+			URL:      conf.NATSURL,
+			Subject:  conf.NATSSubject,
+			Stream:   conf.NATSStream,
+			MaxAge:   conf.NATSStreamMaxAge,
+			Username: conf.NATSUsername,
+			Password: conf.NATSPassword,
+			Timeout:  conf.NATSTimeout,
+		})
+	if err != nil {
+		return fmt.Errorf("couldn't set up the %s event publisher: %v", conf.MsgPubType, err)
+	}
 
 	return nil
 }
 
 func Destruct() {
+	if stopWorkers != nil {
+		close(stopWorkers)
+		stopWorkers = nil
+	}
 	dbc.CloseSession()
 }
@@ -14,8 +14,13 @@
 package messaging
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
 	"github.com/edgexfoundry/edgex-go/core/data/errors"
 	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/messagebus"
 )
 
 // Types of messaging protocols
@@ -24,23 +29,90 @@ const (
 	MQTT
 )
 
-// Publisher to send events to northbound services
+// Names of the protocols in the publisher type configuration
+const (
+	ZeroMQType    = "zero"
+	MQTTType      = "mqtt"
+	NATSType      = messagebus.NATS
+	JetStreamType = messagebus.JetStream
+)
+
+// Publisher to send events to northbound services, with each of the configured protocols
 type EventPublisher struct {
-	protocol int
-	zmq      zeroMQEventPublisher
+	zmq  *zeroMQEventPublisher
+	mqtt *mqttEventPublisher
+	bus  messagebus.Publisher
 }
 
 func NewZeroMQPublisher(configuration ZeroMQConfiguration) *EventPublisher {
-	return &EventPublisher{protocol: ZEROMQ, zmq: newZeroMQEventPublisher(configuration)}
+	return &EventPublisher{zmq: newZeroMQEventPublisher(configuration)}
 }
 
-// Send the event
+// Create the publisher of the protocols in pubType, a comma separated list of zero, mqtt and one of
+// nats or jetstream, so the events can go to an MQTT broker instead of or alongside export-distro,
+// and reach export-distro over a message bus rather than ZeroMQ
+func NewEventPublisher(pubType string, zmqConfig ZeroMQConfiguration, mqttConfig MQTTConfiguration, busConfig messagebus.Configuration) (*EventPublisher, error) {
+	var busType string
+	ep := &EventPublisher{}
+	for _, t := range strings.Split(pubType, ",") {
+		t = strings.TrimSpace(t)
+		switch t {
+		case ZeroMQType:
+			if ep.zmq == nil {
+				ep.zmq = newZeroMQEventPublisher(zmqConfig)
+			}
+		case MQTTType:
+			if ep.mqtt == nil {
+				mqtt, err := newMQTTEventPublisher(mqttConfig)
+				if err != nil {
+					return nil, err
+				}
+				ep.mqtt = mqtt
+			}
+		case NATSType, JetStreamType:
+			if busType != "" && busType != t {
+				return nil, fmt.Errorf("only one of the %s and %s publishers can be used", busType, t)
+			}
+			busType = t
+		default:
+			return nil, errors.UnsupportedPublisher{}
+		}
+	}
+	if busType != "" {
+		busConfig.Type = busType
+		bus, err := messagebus.NewPublisher(busConfig)
+		if err != nil {
+			return nil, err
+		}
+		ep.bus = bus
+	}
+	return ep, nil
+}
+
+// Send the event with each protocol, a failure doesn't keep it from the others
+// The error is the first failure
 func (ep *EventPublisher) SendEventMessage(e models.Event) error {
-	// Switch based on the protocol you're using
-	switch ep.protocol {
-	case ZEROMQ:
-		return ep.zmq.SendEventMessage(e)
-	default:
-		return errors.UnsupportedPublisher{}
+	var err error
+	if ep.zmq != nil {
+		err = ep.zmq.SendEventMessage(e)
+	}
+	if ep.mqtt != nil {
+		if mqttErr := ep.mqtt.SendEventMessage(e); err == nil {
+			err = mqttErr
+		}
+	}
+	if ep.bus != nil {
+		if busErr := ep.sendBusMessage(e); err == nil {
+			err = busErr
+		}
+	}
+	return err
+}
+
+func (ep *EventPublisher) sendBusMessage(e models.Event) error {
+	s, err := json.Marshal(&e)
+	if err != nil {
+		return err
 	}
+	return ep.bus.Publish(s)
 }
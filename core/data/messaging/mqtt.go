@@ -0,0 +1,147 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package messaging
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+)
+
+// Configuration struct for MQTT
+type MQTTConfiguration struct {
+	Broker        string // tcp://, ssl:// or ws:// URL of the broker
+	ClientId      string
+	Username      string
+	Password      string
+	Topic         string // {device} is replaced by the device of the event
+	QoS           byte
+	Retained      bool
+	TLSCAFile     string
+	TLSCertFile   string
+	TLSKeyFile    string
+	TLSSkipVerify bool
+	Timeout       int // Milliseconds to connect and to publish
+}
+
+// MQTT implementation of the event publisher
+// The client connects when an event is sent rather than at startup, so core data comes up while
+// the broker is down, and reconnects on the next event after losing the connection
+type mqttEventPublisher struct {
+	client   paho.Client
+	topic    string
+	qos      byte
+	retained bool
+	timeout  time.Duration
+	mux      sync.Mutex
+}
+
+func newMQTTEventPublisher(config MQTTConfiguration) (*mqttEventPublisher, error) {
+	if config.QoS > 2 {
+		return nil, fmt.Errorf("invalid MQTT QoS %d, it must be 0, 1 or 2", config.QoS)
+	}
+	if config.Topic == "" {
+		return nil, fmt.Errorf("the MQTT topic is missing")
+	}
+	timeout := time.Duration(config.Timeout) * time.Millisecond
+
+	opts := paho.NewClientOptions()
+	opts.AddBroker(config.Broker)
+	opts.SetClientID(config.ClientId)
+	opts.SetUsername(config.Username)
+	opts.SetPassword(config.Password)
+	opts.SetAutoReconnect(false)
+	opts.SetConnectTimeout(timeout)
+	if config.TLSCAFile != "" || config.TLSCertFile != "" || config.TLSSkipVerify {
+		tlsConfig, err := mqttTLSConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		opts.SetTLSConfig(tlsConfig)
+	}
+
+	return &mqttEventPublisher{
+		client:   paho.NewClient(opts),
+		topic:    config.Topic,
+		qos:      config.QoS,
+		retained: config.Retained,
+		timeout:  timeout,
+	}, nil
+}
+
+func mqttTLSConfig(config MQTTConfiguration) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.TLSSkipVerify}
+	if config.TLSCAFile != "" {
+		ca, err := ioutil.ReadFile(config.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading the MQTT CA file: %v", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificate found in the MQTT CA file %s", config.TLSCAFile)
+		}
+	}
+	if config.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.TLSCertFile, config.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading the MQTT client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// Replace the MQTT separator and wildcards in the device name, so it stays a single topic level
+var topicLevelReplacer = strings.NewReplacer("/", "_", "+", "_", "#", "_")
+
+// Return the topic of the event from the template
+func mqttTopic(template string, e models.Event) string {
+	return strings.Replace(template, "{device}", topicLevelReplacer.Replace(e.Device), -1)
+}
+
+func (mep *mqttEventPublisher) connect() error {
+	mep.mux.Lock()
+	defer mep.mux.Unlock()
+	if mep.client.IsConnected() {
+		return nil
+	}
+	token := mep.client.Connect()
+	if !token.WaitTimeout(mep.timeout) {
+		return fmt.Errorf("timed out connecting to the MQTT broker")
+	}
+	return token.Error()
+}
+
+func (mep *mqttEventPublisher) SendEventMessage(e models.Event) error {
+	s, err := json.Marshal(&e)
+	if err != nil {
+		return err
+	}
+	if err = mep.connect(); err != nil {
+		return err
+	}
+	token := mep.client.Publish(mqttTopic(mep.topic, e), mep.qos, mep.retained, s)
+	if !token.WaitTimeout(mep.timeout) {
+		return fmt.Errorf("timed out publishing the event to the MQTT broker")
+	}
+	return token.Error()
+}
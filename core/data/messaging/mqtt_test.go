@@ -0,0 +1,87 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package messaging
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/edgex-go/core/data/errors"
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/messagebus"
+)
+
+func TestMQTTTopic(t *testing.T) {
+	tests := []struct {
+		template string
+		device   string
+		expected string
+	}{
+		{"edgex/events/{device}", "thermostat", "edgex/events/thermostat"},
+		{"edgex/events", "thermostat", "edgex/events"},
+		{"edgex/{device}/events", "floor/2", "edgex/floor_2/events"},
+		{"edgex/events/{device}", "a+b#", "edgex/events/a_b_"},
+	}
+	for _, tt := range tests {
+		topic := mqttTopic(tt.template, models.Event{Device: tt.device})
+		if topic != tt.expected {
+			t.Errorf("Topic of %s with %s should be %s, not %s", tt.template, tt.device, tt.expected, topic)
+		}
+	}
+}
+
+func TestNewEventPublisher(t *testing.T) {
+	config := MQTTConfiguration{Broker: "tcp://127.0.0.1:1", ClientId: "test", Topic: "edgex/events/{device}", Timeout: 1000}
+
+	ep, err := NewEventPublisher("mqtt", ZeroMQConfiguration{}, config, messagebus.Configuration{})
+	if err != nil {
+		t.Fatalf("Error creating the MQTT publisher: %v", err)
+	}
+	if ep.zmq != nil || ep.mqtt == nil {
+		t.Fatalf("Only the MQTT publisher should be created")
+	}
+	// Nothing listens on the broker port
+	if err = ep.SendEventMessage(models.Event{Device: "thermostat"}); err == nil {
+		t.Fatalf("Sending without a broker should fail")
+	}
+
+	if _, err = NewEventPublisher("mqtt,amqp", ZeroMQConfiguration{}, config, messagebus.Configuration{}); err != (errors.UnsupportedPublisher{}) {
+		t.Fatalf("An unknown publisher type should be unsupported, not %v", err)
+	}
+
+	if _, err = NewEventPublisher("nats,jetstream", ZeroMQConfiguration{}, config, messagebus.Configuration{}); err == nil {
+		t.Fatalf("Only one message bus should be allowed")
+	}
+
+	config.QoS = 3
+	if _, err = NewEventPublisher("mqtt", ZeroMQConfiguration{}, config, messagebus.Configuration{}); err == nil {
+		t.Fatalf("An invalid QoS should be refused")
+	}
+}
+
+func TestMQTTTLSConfig(t *testing.T) {
+	tlsConfig, err := mqttTLSConfig(MQTTConfiguration{TLSSkipVerify: true})
+	if err != nil {
+		t.Fatalf("Error creating the TLS configuration: %v", err)
+	}
+	if !tlsConfig.InsecureSkipVerify || tlsConfig.RootCAs != nil || len(tlsConfig.Certificates) != 0 {
+		t.Fatalf("Only the verification should be skipped: %v", tlsConfig)
+	}
+
+	if _, err = mqttTLSConfig(MQTTConfiguration{TLSCAFile: "missing-ca.pem"}); err == nil {
+		t.Fatalf("A missing CA file should be an error")
+	}
+	if _, err = mqttTLSConfig(MQTTConfiguration{TLSCertFile: "missing-cert.pem", TLSKeyFile: "missing-key.pem"}); err == nil {
+		t.Fatalf("A missing client certificate should be an error")
+	}
+}
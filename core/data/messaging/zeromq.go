@@ -32,11 +32,11 @@ type zeroMQEventPublisher struct {
 	mux       sync.Mutex
 }
 
-func newZeroMQEventPublisher(config ZeroMQConfiguration) zeroMQEventPublisher {
+func newZeroMQEventPublisher(config ZeroMQConfiguration) *zeroMQEventPublisher {
 	newPublisher, _ := zmq.NewSocket(zmq.PUB)
 	newPublisher.Bind(config.AddressPort)
 
-	return zeroMQEventPublisher{
+	return &zeroMQEventPublisher{
 		publisher: newPublisher,
 	}
 }
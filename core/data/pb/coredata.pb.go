@@ -0,0 +1,1357 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: coredata.proto
+
+package pb
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type Reading struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Pushed               int64    `protobuf:"varint,2,opt,name=pushed,proto3" json:"pushed,omitempty"`
+	Created              int64    `protobuf:"varint,3,opt,name=created,proto3" json:"created,omitempty"`
+	Origin               int64    `protobuf:"varint,4,opt,name=origin,proto3" json:"origin,omitempty"`
+	Modified             int64    `protobuf:"varint,5,opt,name=modified,proto3" json:"modified,omitempty"`
+	Device               string   `protobuf:"bytes,6,opt,name=device,proto3" json:"device,omitempty"`
+	Name                 string   `protobuf:"bytes,7,opt,name=name,proto3" json:"name,omitempty"`
+	Value                string   `protobuf:"bytes,8,opt,name=value,proto3" json:"value,omitempty"`
+	BinaryValue          []byte   `protobuf:"bytes,9,opt,name=binary_value,json=binaryValue,proto3" json:"binary_value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Reading) Reset()         { *m = Reading{} }
+func (m *Reading) String() string { return proto.CompactTextString(m) }
+func (*Reading) ProtoMessage()    {}
+func (*Reading) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33d9e4efa2354759, []int{0}
+}
+
+func (m *Reading) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Reading.Unmarshal(m, b)
+}
+func (m *Reading) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Reading.Marshal(b, m, deterministic)
+}
+func (m *Reading) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Reading.Merge(m, src)
+}
+func (m *Reading) XXX_Size() int {
+	return xxx_messageInfo_Reading.Size(m)
+}
+func (m *Reading) XXX_DiscardUnknown() {
+	xxx_messageInfo_Reading.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Reading proto.InternalMessageInfo
+
+func (m *Reading) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Reading) GetPushed() int64 {
+	if m != nil {
+		return m.Pushed
+	}
+	return 0
+}
+
+func (m *Reading) GetCreated() int64 {
+	if m != nil {
+		return m.Created
+	}
+	return 0
+}
+
+func (m *Reading) GetOrigin() int64 {
+	if m != nil {
+		return m.Origin
+	}
+	return 0
+}
+
+func (m *Reading) GetModified() int64 {
+	if m != nil {
+		return m.Modified
+	}
+	return 0
+}
+
+func (m *Reading) GetDevice() string {
+	if m != nil {
+		return m.Device
+	}
+	return ""
+}
+
+func (m *Reading) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Reading) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+func (m *Reading) GetBinaryValue() []byte {
+	if m != nil {
+		return m.BinaryValue
+	}
+	return nil
+}
+
+type Event struct {
+	Id                   string     `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Pushed               int64      `protobuf:"varint,2,opt,name=pushed,proto3" json:"pushed,omitempty"`
+	Device               string     `protobuf:"bytes,3,opt,name=device,proto3" json:"device,omitempty"`
+	Created              int64      `protobuf:"varint,4,opt,name=created,proto3" json:"created,omitempty"`
+	Modified             int64      `protobuf:"varint,5,opt,name=modified,proto3" json:"modified,omitempty"`
+	Origin               int64      `protobuf:"varint,6,opt,name=origin,proto3" json:"origin,omitempty"`
+	Schedule             string     `protobuf:"bytes,7,opt,name=schedule,proto3" json:"schedule,omitempty"`
+	Event                string     `protobuf:"bytes,8,opt,name=event,proto3" json:"event,omitempty"`
+	Readings             []*Reading `protobuf:"bytes,9,rep,name=readings,proto3" json:"readings,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *Event) Reset()         { *m = Event{} }
+func (m *Event) String() string { return proto.CompactTextString(m) }
+func (*Event) ProtoMessage()    {}
+func (*Event) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33d9e4efa2354759, []int{1}
+}
+
+func (m *Event) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Event.Unmarshal(m, b)
+}
+func (m *Event) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Event.Marshal(b, m, deterministic)
+}
+func (m *Event) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Event.Merge(m, src)
+}
+func (m *Event) XXX_Size() int {
+	return xxx_messageInfo_Event.Size(m)
+}
+func (m *Event) XXX_DiscardUnknown() {
+	xxx_messageInfo_Event.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Event proto.InternalMessageInfo
+
+func (m *Event) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Event) GetPushed() int64 {
+	if m != nil {
+		return m.Pushed
+	}
+	return 0
+}
+
+func (m *Event) GetDevice() string {
+	if m != nil {
+		return m.Device
+	}
+	return ""
+}
+
+func (m *Event) GetCreated() int64 {
+	if m != nil {
+		return m.Created
+	}
+	return 0
+}
+
+func (m *Event) GetModified() int64 {
+	if m != nil {
+		return m.Modified
+	}
+	return 0
+}
+
+func (m *Event) GetOrigin() int64 {
+	if m != nil {
+		return m.Origin
+	}
+	return 0
+}
+
+func (m *Event) GetSchedule() string {
+	if m != nil {
+		return m.Schedule
+	}
+	return ""
+}
+
+func (m *Event) GetEvent() string {
+	if m != nil {
+		return m.Event
+	}
+	return ""
+}
+
+func (m *Event) GetReadings() []*Reading {
+	if m != nil {
+		return m.Readings
+	}
+	return nil
+}
+
+type ValueDescriptor struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Created              int64    `protobuf:"varint,2,opt,name=created,proto3" json:"created,omitempty"`
+	Description          string   `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Modified             int64    `protobuf:"varint,4,opt,name=modified,proto3" json:"modified,omitempty"`
+	Origin               int64    `protobuf:"varint,5,opt,name=origin,proto3" json:"origin,omitempty"`
+	Name                 string   `protobuf:"bytes,6,opt,name=name,proto3" json:"name,omitempty"`
+	Min                  string   `protobuf:"bytes,7,opt,name=min,proto3" json:"min,omitempty"`
+	Max                  string   `protobuf:"bytes,8,opt,name=max,proto3" json:"max,omitempty"`
+	DefaultValue         string   `protobuf:"bytes,9,opt,name=default_value,json=defaultValue,proto3" json:"default_value,omitempty"`
+	Type                 string   `protobuf:"bytes,10,opt,name=type,proto3" json:"type,omitempty"`
+	UomLabel             string   `protobuf:"bytes,11,opt,name=uom_label,json=uomLabel,proto3" json:"uom_label,omitempty"`
+	Formatting           string   `protobuf:"bytes,12,opt,name=formatting,proto3" json:"formatting,omitempty"`
+	Labels               []string `protobuf:"bytes,13,rep,name=labels,proto3" json:"labels,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ValueDescriptor) Reset()         { *m = ValueDescriptor{} }
+func (m *ValueDescriptor) String() string { return proto.CompactTextString(m) }
+func (*ValueDescriptor) ProtoMessage()    {}
+func (*ValueDescriptor) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33d9e4efa2354759, []int{2}
+}
+
+func (m *ValueDescriptor) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ValueDescriptor.Unmarshal(m, b)
+}
+func (m *ValueDescriptor) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ValueDescriptor.Marshal(b, m, deterministic)
+}
+func (m *ValueDescriptor) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ValueDescriptor.Merge(m, src)
+}
+func (m *ValueDescriptor) XXX_Size() int {
+	return xxx_messageInfo_ValueDescriptor.Size(m)
+}
+func (m *ValueDescriptor) XXX_DiscardUnknown() {
+	xxx_messageInfo_ValueDescriptor.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ValueDescriptor proto.InternalMessageInfo
+
+func (m *ValueDescriptor) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *ValueDescriptor) GetCreated() int64 {
+	if m != nil {
+		return m.Created
+	}
+	return 0
+}
+
+func (m *ValueDescriptor) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *ValueDescriptor) GetModified() int64 {
+	if m != nil {
+		return m.Modified
+	}
+	return 0
+}
+
+func (m *ValueDescriptor) GetOrigin() int64 {
+	if m != nil {
+		return m.Origin
+	}
+	return 0
+}
+
+func (m *ValueDescriptor) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ValueDescriptor) GetMin() string {
+	if m != nil {
+		return m.Min
+	}
+	return ""
+}
+
+func (m *ValueDescriptor) GetMax() string {
+	if m != nil {
+		return m.Max
+	}
+	return ""
+}
+
+func (m *ValueDescriptor) GetDefaultValue() string {
+	if m != nil {
+		return m.DefaultValue
+	}
+	return ""
+}
+
+func (m *ValueDescriptor) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *ValueDescriptor) GetUomLabel() string {
+	if m != nil {
+		return m.UomLabel
+	}
+	return ""
+}
+
+func (m *ValueDescriptor) GetFormatting() string {
+	if m != nil {
+		return m.Formatting
+	}
+	return ""
+}
+
+func (m *ValueDescriptor) GetLabels() []string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+type IdRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *IdRequest) Reset()         { *m = IdRequest{} }
+func (m *IdRequest) String() string { return proto.CompactTextString(m) }
+func (*IdRequest) ProtoMessage()    {}
+func (*IdRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33d9e4efa2354759, []int{3}
+}
+
+func (m *IdRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IdRequest.Unmarshal(m, b)
+}
+func (m *IdRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IdRequest.Marshal(b, m, deterministic)
+}
+func (m *IdRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IdRequest.Merge(m, src)
+}
+func (m *IdRequest) XXX_Size() int {
+	return xxx_messageInfo_IdRequest.Size(m)
+}
+func (m *IdRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_IdRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IdRequest proto.InternalMessageInfo
+
+func (m *IdRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type NameRequest struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Max number of readings, at most the configured read max limit
+	Limit                int32    `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *NameRequest) Reset()         { *m = NameRequest{} }
+func (m *NameRequest) String() string { return proto.CompactTextString(m) }
+func (*NameRequest) ProtoMessage()    {}
+func (*NameRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33d9e4efa2354759, []int{4}
+}
+
+func (m *NameRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NameRequest.Unmarshal(m, b)
+}
+func (m *NameRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NameRequest.Marshal(b, m, deterministic)
+}
+func (m *NameRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NameRequest.Merge(m, src)
+}
+func (m *NameRequest) XXX_Size() int {
+	return xxx_messageInfo_NameRequest.Size(m)
+}
+func (m *NameRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_NameRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NameRequest proto.InternalMessageInfo
+
+func (m *NameRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *NameRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type DeviceRequest struct {
+	// Device name or id
+	Device string `protobuf:"bytes,1,opt,name=device,proto3" json:"device,omitempty"`
+	// Max number of items, at most the configured read max limit
+	Limit                int32    `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeviceRequest) Reset()         { *m = DeviceRequest{} }
+func (m *DeviceRequest) String() string { return proto.CompactTextString(m) }
+func (*DeviceRequest) ProtoMessage()    {}
+func (*DeviceRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33d9e4efa2354759, []int{5}
+}
+
+func (m *DeviceRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeviceRequest.Unmarshal(m, b)
+}
+func (m *DeviceRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeviceRequest.Marshal(b, m, deterministic)
+}
+func (m *DeviceRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeviceRequest.Merge(m, src)
+}
+func (m *DeviceRequest) XXX_Size() int {
+	return xxx_messageInfo_DeviceRequest.Size(m)
+}
+func (m *DeviceRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeviceRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeviceRequest proto.InternalMessageInfo
+
+func (m *DeviceRequest) GetDevice() string {
+	if m != nil {
+		return m.Device
+	}
+	return ""
+}
+
+func (m *DeviceRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type AddReply struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AddReply) Reset()         { *m = AddReply{} }
+func (m *AddReply) String() string { return proto.CompactTextString(m) }
+func (*AddReply) ProtoMessage()    {}
+func (*AddReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33d9e4efa2354759, []int{6}
+}
+
+func (m *AddReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AddReply.Unmarshal(m, b)
+}
+func (m *AddReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AddReply.Marshal(b, m, deterministic)
+}
+func (m *AddReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddReply.Merge(m, src)
+}
+func (m *AddReply) XXX_Size() int {
+	return xxx_messageInfo_AddReply.Size(m)
+}
+func (m *AddReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddReply proto.InternalMessageInfo
+
+func (m *AddReply) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type StreamEventReply struct {
+	// Position of the event in the stream, from 0
+	Index int64  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Id    string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	// Why the event wasn't added, empty when it was
+	Error                string   `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StreamEventReply) Reset()         { *m = StreamEventReply{} }
+func (m *StreamEventReply) String() string { return proto.CompactTextString(m) }
+func (*StreamEventReply) ProtoMessage()    {}
+func (*StreamEventReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33d9e4efa2354759, []int{7}
+}
+
+func (m *StreamEventReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamEventReply.Unmarshal(m, b)
+}
+func (m *StreamEventReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamEventReply.Marshal(b, m, deterministic)
+}
+func (m *StreamEventReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamEventReply.Merge(m, src)
+}
+func (m *StreamEventReply) XXX_Size() int {
+	return xxx_messageInfo_StreamEventReply.Size(m)
+}
+func (m *StreamEventReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamEventReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamEventReply proto.InternalMessageInfo
+
+func (m *StreamEventReply) GetIndex() int64 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *StreamEventReply) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *StreamEventReply) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type Empty struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Empty) Reset()         { *m = Empty{} }
+func (m *Empty) String() string { return proto.CompactTextString(m) }
+func (*Empty) ProtoMessage()    {}
+func (*Empty) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33d9e4efa2354759, []int{8}
+}
+
+func (m *Empty) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Empty.Unmarshal(m, b)
+}
+func (m *Empty) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Empty.Marshal(b, m, deterministic)
+}
+func (m *Empty) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Empty.Merge(m, src)
+}
+func (m *Empty) XXX_Size() int {
+	return xxx_messageInfo_Empty.Size(m)
+}
+func (m *Empty) XXX_DiscardUnknown() {
+	xxx_messageInfo_Empty.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Empty proto.InternalMessageInfo
+
+func init() {
+	proto.RegisterType((*Reading)(nil), "coredata.Reading")
+	proto.RegisterType((*Event)(nil), "coredata.Event")
+	proto.RegisterType((*ValueDescriptor)(nil), "coredata.ValueDescriptor")
+	proto.RegisterType((*IdRequest)(nil), "coredata.IdRequest")
+	proto.RegisterType((*NameRequest)(nil), "coredata.NameRequest")
+	proto.RegisterType((*DeviceRequest)(nil), "coredata.DeviceRequest")
+	proto.RegisterType((*AddReply)(nil), "coredata.AddReply")
+	proto.RegisterType((*StreamEventReply)(nil), "coredata.StreamEventReply")
+	proto.RegisterType((*Empty)(nil), "coredata.Empty")
+}
+
+func init() { proto.RegisterFile("coredata.proto", fileDescriptor_33d9e4efa2354759) }
+
+var fileDescriptor_33d9e4efa2354759 = []byte{
+	// 710 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x55, 0x4b, 0x6e, 0x1b, 0x39,
+	0x10, 0x45, 0xeb, 0xaf, 0x92, 0xfc, 0xe3, 0xd8, 0x33, 0x1c, 0x19, 0x18, 0x68, 0x94, 0x8d, 0x36,
+	0x71, 0x04, 0x3b, 0x40, 0x56, 0x86, 0xe3, 0x58, 0xb6, 0x13, 0x20, 0xf0, 0xa2, 0x03, 0x64, 0x91,
+	0x8d, 0x41, 0x89, 0x65, 0x9b, 0x40, 0x7f, 0x14, 0x36, 0x65, 0x58, 0x57, 0xca, 0x85, 0x72, 0x81,
+	0x9c, 0x21, 0xeb, 0x80, 0x64, 0xff, 0x5b, 0x4e, 0xb4, 0xe3, 0x2b, 0xd6, 0x2b, 0xd6, 0x7b, 0x55,
+	0x2d, 0xc1, 0xf6, 0x3c, 0x94, 0xc8, 0x99, 0x62, 0x47, 0x0b, 0x19, 0xaa, 0x90, 0x74, 0x12, 0x3c,
+	0xfa, 0xe1, 0x40, 0xdb, 0x45, 0xc6, 0x45, 0x70, 0x4f, 0xb6, 0xa1, 0x26, 0x38, 0x75, 0x86, 0xce,
+	0xb8, 0xeb, 0xd6, 0x04, 0x27, 0x7f, 0x43, 0x6b, 0xb1, 0x8c, 0x1e, 0x90, 0xd3, 0xda, 0xd0, 0x19,
+	0xd7, 0xdd, 0x18, 0x11, 0x0a, 0xed, 0xb9, 0x44, 0xa6, 0x90, 0xd3, 0xba, 0xb9, 0x48, 0xa0, 0x66,
+	0x84, 0x52, 0xdc, 0x8b, 0x80, 0x36, 0x2c, 0xc3, 0x22, 0x32, 0x80, 0x8e, 0x1f, 0x72, 0x71, 0x27,
+	0x90, 0xd3, 0xa6, 0xb9, 0x49, 0xb1, 0xe6, 0x70, 0x7c, 0x14, 0x73, 0xa4, 0x2d, 0xf3, 0x72, 0x8c,
+	0x08, 0x81, 0x46, 0xc0, 0x7c, 0xa4, 0x6d, 0x13, 0x35, 0x67, 0xb2, 0x0f, 0xcd, 0x47, 0xe6, 0x2d,
+	0x91, 0x76, 0x4c, 0xd0, 0x02, 0xf2, 0x3f, 0xf4, 0x67, 0x22, 0x60, 0x72, 0x75, 0x6b, 0x2f, 0xbb,
+	0x43, 0x67, 0xdc, 0x77, 0x7b, 0x36, 0xf6, 0x59, 0x87, 0x46, 0x3f, 0x1d, 0x68, 0x5e, 0x3e, 0x62,
+	0xa0, 0x36, 0x16, 0x99, 0xb5, 0x55, 0x2f, 0xb4, 0x95, 0x13, 0xdf, 0x28, 0x8a, 0xff, 0x83, 0xc8,
+	0xd8, 0x98, 0x56, 0xd9, 0x98, 0x68, 0xfe, 0x80, 0x7c, 0xe9, 0x25, 0x42, 0x53, 0xac, 0xc5, 0xa2,
+	0x6e, 0x39, 0x11, 0x6b, 0x00, 0x79, 0x09, 0x1d, 0x69, 0xe7, 0x15, 0xd1, 0xee, 0xb0, 0x3e, 0xee,
+	0x1d, 0xef, 0x1d, 0xa5, 0xd3, 0x8d, 0x27, 0xe9, 0xa6, 0x29, 0xa3, 0xef, 0x35, 0xd8, 0x31, 0x16,
+	0x4c, 0x31, 0x9a, 0x4b, 0xb1, 0x50, 0xa1, 0xac, 0x58, 0x90, 0x93, 0x54, 0x2b, 0x4a, 0x1a, 0x42,
+	0x8f, 0xc7, 0x3c, 0x11, 0x06, 0xb1, 0x13, 0xf9, 0x50, 0x41, 0x74, 0xe3, 0x59, 0xd1, 0xcd, 0x82,
+	0xe8, 0x64, 0xb2, 0xad, 0xdc, 0x64, 0x77, 0xa1, 0xee, 0x8b, 0x20, 0xf6, 0x40, 0x1f, 0x4d, 0x84,
+	0x3d, 0xc5, 0xe2, 0xf5, 0x91, 0xbc, 0x80, 0x2d, 0x8e, 0x77, 0x6c, 0xe9, 0xa9, 0xdc, 0xa0, 0xbb,
+	0x6e, 0x3f, 0x0e, 0x1a, 0x99, 0xba, 0xb8, 0x5a, 0x2d, 0x90, 0x82, 0x2d, 0xae, 0xcf, 0xe4, 0x10,
+	0xba, 0xcb, 0xd0, 0xbf, 0xf5, 0xd8, 0x0c, 0x3d, 0xda, 0xb3, 0x36, 0x2f, 0x43, 0xff, 0xa3, 0xc6,
+	0xe4, 0x3f, 0x80, 0xbb, 0x50, 0xfa, 0x4c, 0x29, 0x11, 0xdc, 0xd3, 0xbe, 0xb9, 0xcd, 0x45, 0xb4,
+	0x0a, 0x43, 0x8c, 0xe8, 0xd6, 0xb0, 0xae, 0x17, 0xc1, 0xa2, 0xd1, 0x21, 0x74, 0x3f, 0x70, 0x17,
+	0xbf, 0x2e, 0x31, 0xaa, 0x6c, 0xd5, 0xe8, 0x0d, 0xf4, 0x6e, 0x98, 0x8f, 0xc9, 0x75, 0xa2, 0xd8,
+	0x29, 0xee, 0xb2, 0x27, 0x7c, 0xa1, 0x8c, 0xe7, 0x4d, 0xd7, 0x82, 0xd1, 0x29, 0x6c, 0x4d, 0xcd,
+	0xa2, 0x25, 0xd4, 0x6c, 0x0f, 0x9d, 0xc2, 0x1e, 0xae, 0xa7, 0x0f, 0xa0, 0x73, 0xce, 0xb9, 0x8b,
+	0x0b, 0x6f, 0x55, 0xe9, 0xe9, 0x06, 0x76, 0x3f, 0x29, 0x89, 0xcc, 0x37, 0x1f, 0x82, 0xcd, 0xd9,
+	0x87, 0xa6, 0x08, 0x38, 0x3e, 0x99, 0xb4, 0xba, 0x6b, 0x41, 0xcc, 0xac, 0xa5, 0x0b, 0xa2, 0x37,
+	0x51, 0xca, 0x50, 0xc6, 0x0b, 0x60, 0xc1, 0xa8, 0x0d, 0xcd, 0x4b, 0x7f, 0xa1, 0x56, 0xc7, 0xdf,
+	0x5a, 0xd0, 0xb9, 0x08, 0x25, 0x4e, 0x99, 0x62, 0xe4, 0x95, 0xe9, 0xc0, 0x7e, 0x6b, 0x3b, 0xd9,
+	0x66, 0x9a, 0xc0, 0x80, 0x64, 0x81, 0xb4, 0xcd, 0x33, 0xe8, 0xe7, 0xda, 0x8a, 0xaa, 0xa4, 0x41,
+	0x16, 0x28, 0xf7, 0x3f, 0x76, 0x26, 0x0e, 0x99, 0x40, 0xe7, 0x1a, 0x95, 0x7d, 0xf1, 0xaf, 0x2c,
+	0x37, 0x1d, 0xce, 0xa0, 0x5c, 0x91, 0x9c, 0xc2, 0x8e, 0x7d, 0xec, 0x2a, 0x94, 0xd6, 0x6d, 0xf2,
+	0x4f, 0x96, 0x53, 0xf0, 0xbf, 0x42, 0x9e, 0x38, 0xe4, 0x04, 0x7a, 0x53, 0xf4, 0x50, 0xe1, 0x86,
+	0x6f, 0x6a, 0x93, 0xc8, 0x09, 0x80, 0x91, 0x6c, 0x7f, 0x6a, 0xab, 0xdf, 0xec, 0x5a, 0x6f, 0x5e,
+	0x03, 0x5c, 0xa3, 0x4a, 0x48, 0x6b, 0x1f, 0xaa, 0x56, 0x22, 0xe7, 0xb0, 0x17, 0x1f, 0x37, 0x11,
+	0x58, 0x2d, 0x30, 0x71, 0xc8, 0x7b, 0x18, 0xe4, 0x4a, 0x94, 0x7f, 0x40, 0x0e, 0x32, 0x4a, 0x6e,
+	0xcb, 0xd7, 0x57, 0xba, 0x00, 0x72, 0xce, 0x79, 0xb9, 0xc2, 0xbf, 0x59, 0x6a, 0xe9, 0x6a, 0xad,
+	0x0f, 0x57, 0x40, 0xae, 0x51, 0x6d, 0xd8, 0xc6, 0xf3, 0xb5, 0xc9, 0x5b, 0xd8, 0x2d, 0x85, 0x22,
+	0x52, 0x9e, 0xd4, 0x6f, 0xf8, 0x13, 0x87, 0x9c, 0xc1, 0x81, 0x9d, 0xfd, 0x86, 0xcd, 0x94, 0xab,
+	0xbf, 0x6b, 0x7c, 0xa9, 0x2d, 0x66, 0xb3, 0x96, 0xf9, 0x1f, 0x3e, 0xf9, 0x35, 0x00, 0x4d, 0x53,
+	0xe8, 0x95, 0x99, 0x07, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// CoreDataClient is the client API for CoreData service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type CoreDataClient interface {
+	// Add an event and its readings, the id is empty when the data isn't persisted
+	AddEvent(ctx context.Context, in *Event, opts ...grpc.CallOption) (*AddReply, error)
+	// Add each event sent on the stream, every event is answered in order and a refused event
+	// doesn't end the stream
+	StreamEvents(ctx context.Context, opts ...grpc.CallOption) (CoreData_StreamEventsClient, error)
+	GetEvent(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Event, error)
+	// The events of the device, newest first
+	EventsForDevice(ctx context.Context, in *DeviceRequest, opts ...grpc.CallOption) (CoreData_EventsForDeviceClient, error)
+	// Delete the event and its readings
+	DeleteEvent(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Empty, error)
+	AddReading(ctx context.Context, in *Reading, opts ...grpc.CallOption) (*AddReply, error)
+	GetReading(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Reading, error)
+	ReadingsForDevice(ctx context.Context, in *DeviceRequest, opts ...grpc.CallOption) (CoreData_ReadingsForDeviceClient, error)
+	ReadingsForValueDescriptor(ctx context.Context, in *NameRequest, opts ...grpc.CallOption) (CoreData_ReadingsForValueDescriptorClient, error)
+	AddValueDescriptor(ctx context.Context, in *ValueDescriptor, opts ...grpc.CallOption) (*AddReply, error)
+	GetValueDescriptor(ctx context.Context, in *NameRequest, opts ...grpc.CallOption) (*ValueDescriptor, error)
+	ValueDescriptors(ctx context.Context, in *Empty, opts ...grpc.CallOption) (CoreData_ValueDescriptorsClient, error)
+	// Delete the value descriptor, refused while readings reference it
+	DeleteValueDescriptor(ctx context.Context, in *NameRequest, opts ...grpc.CallOption) (*Empty, error)
+}
+
+type coreDataClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewCoreDataClient(cc *grpc.ClientConn) CoreDataClient {
+	return &coreDataClient{cc}
+}
+
+func (c *coreDataClient) AddEvent(ctx context.Context, in *Event, opts ...grpc.CallOption) (*AddReply, error) {
+	out := new(AddReply)
+	err := c.cc.Invoke(ctx, "/coredata.CoreData/AddEvent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreDataClient) StreamEvents(ctx context.Context, opts ...grpc.CallOption) (CoreData_StreamEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_CoreData_serviceDesc.Streams[0], "/coredata.CoreData/StreamEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &coreDataStreamEventsClient{stream}
+	return x, nil
+}
+
+type CoreData_StreamEventsClient interface {
+	Send(*Event) error
+	Recv() (*StreamEventReply, error)
+	grpc.ClientStream
+}
+
+type coreDataStreamEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *coreDataStreamEventsClient) Send(m *Event) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *coreDataStreamEventsClient) Recv() (*StreamEventReply, error) {
+	m := new(StreamEventReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *coreDataClient) GetEvent(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Event, error) {
+	out := new(Event)
+	err := c.cc.Invoke(ctx, "/coredata.CoreData/GetEvent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreDataClient) EventsForDevice(ctx context.Context, in *DeviceRequest, opts ...grpc.CallOption) (CoreData_EventsForDeviceClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_CoreData_serviceDesc.Streams[1], "/coredata.CoreData/EventsForDevice", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &coreDataEventsForDeviceClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CoreData_EventsForDeviceClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type coreDataEventsForDeviceClient struct {
+	grpc.ClientStream
+}
+
+func (x *coreDataEventsForDeviceClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *coreDataClient) DeleteEvent(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/coredata.CoreData/DeleteEvent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreDataClient) AddReading(ctx context.Context, in *Reading, opts ...grpc.CallOption) (*AddReply, error) {
+	out := new(AddReply)
+	err := c.cc.Invoke(ctx, "/coredata.CoreData/AddReading", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreDataClient) GetReading(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Reading, error) {
+	out := new(Reading)
+	err := c.cc.Invoke(ctx, "/coredata.CoreData/GetReading", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreDataClient) ReadingsForDevice(ctx context.Context, in *DeviceRequest, opts ...grpc.CallOption) (CoreData_ReadingsForDeviceClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_CoreData_serviceDesc.Streams[2], "/coredata.CoreData/ReadingsForDevice", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &coreDataReadingsForDeviceClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CoreData_ReadingsForDeviceClient interface {
+	Recv() (*Reading, error)
+	grpc.ClientStream
+}
+
+type coreDataReadingsForDeviceClient struct {
+	grpc.ClientStream
+}
+
+func (x *coreDataReadingsForDeviceClient) Recv() (*Reading, error) {
+	m := new(Reading)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *coreDataClient) ReadingsForValueDescriptor(ctx context.Context, in *NameRequest, opts ...grpc.CallOption) (CoreData_ReadingsForValueDescriptorClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_CoreData_serviceDesc.Streams[3], "/coredata.CoreData/ReadingsForValueDescriptor", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &coreDataReadingsForValueDescriptorClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CoreData_ReadingsForValueDescriptorClient interface {
+	Recv() (*Reading, error)
+	grpc.ClientStream
+}
+
+type coreDataReadingsForValueDescriptorClient struct {
+	grpc.ClientStream
+}
+
+func (x *coreDataReadingsForValueDescriptorClient) Recv() (*Reading, error) {
+	m := new(Reading)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *coreDataClient) AddValueDescriptor(ctx context.Context, in *ValueDescriptor, opts ...grpc.CallOption) (*AddReply, error) {
+	out := new(AddReply)
+	err := c.cc.Invoke(ctx, "/coredata.CoreData/AddValueDescriptor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreDataClient) GetValueDescriptor(ctx context.Context, in *NameRequest, opts ...grpc.CallOption) (*ValueDescriptor, error) {
+	out := new(ValueDescriptor)
+	err := c.cc.Invoke(ctx, "/coredata.CoreData/GetValueDescriptor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreDataClient) ValueDescriptors(ctx context.Context, in *Empty, opts ...grpc.CallOption) (CoreData_ValueDescriptorsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_CoreData_serviceDesc.Streams[4], "/coredata.CoreData/ValueDescriptors", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &coreDataValueDescriptorsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CoreData_ValueDescriptorsClient interface {
+	Recv() (*ValueDescriptor, error)
+	grpc.ClientStream
+}
+
+type coreDataValueDescriptorsClient struct {
+	grpc.ClientStream
+}
+
+func (x *coreDataValueDescriptorsClient) Recv() (*ValueDescriptor, error) {
+	m := new(ValueDescriptor)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *coreDataClient) DeleteValueDescriptor(ctx context.Context, in *NameRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/coredata.CoreData/DeleteValueDescriptor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CoreDataServer is the server API for CoreData service.
+type CoreDataServer interface {
+	// Add an event and its readings, the id is empty when the data isn't persisted
+	AddEvent(context.Context, *Event) (*AddReply, error)
+	// Add each event sent on the stream, every event is answered in order and a refused event
+	// doesn't end the stream
+	StreamEvents(CoreData_StreamEventsServer) error
+	GetEvent(context.Context, *IdRequest) (*Event, error)
+	// The events of the device, newest first
+	EventsForDevice(*DeviceRequest, CoreData_EventsForDeviceServer) error
+	// Delete the event and its readings
+	DeleteEvent(context.Context, *IdRequest) (*Empty, error)
+	AddReading(context.Context, *Reading) (*AddReply, error)
+	GetReading(context.Context, *IdRequest) (*Reading, error)
+	ReadingsForDevice(*DeviceRequest, CoreData_ReadingsForDeviceServer) error
+	ReadingsForValueDescriptor(*NameRequest, CoreData_ReadingsForValueDescriptorServer) error
+	AddValueDescriptor(context.Context, *ValueDescriptor) (*AddReply, error)
+	GetValueDescriptor(context.Context, *NameRequest) (*ValueDescriptor, error)
+	ValueDescriptors(*Empty, CoreData_ValueDescriptorsServer) error
+	// Delete the value descriptor, refused while readings reference it
+	DeleteValueDescriptor(context.Context, *NameRequest) (*Empty, error)
+}
+
+// UnimplementedCoreDataServer can be embedded to have forward compatible implementations.
+type UnimplementedCoreDataServer struct {
+}
+
+func (*UnimplementedCoreDataServer) AddEvent(ctx context.Context, req *Event) (*AddReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddEvent not implemented")
+}
+func (*UnimplementedCoreDataServer) StreamEvents(srv CoreData_StreamEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamEvents not implemented")
+}
+func (*UnimplementedCoreDataServer) GetEvent(ctx context.Context, req *IdRequest) (*Event, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEvent not implemented")
+}
+func (*UnimplementedCoreDataServer) EventsForDevice(req *DeviceRequest, srv CoreData_EventsForDeviceServer) error {
+	return status.Errorf(codes.Unimplemented, "method EventsForDevice not implemented")
+}
+func (*UnimplementedCoreDataServer) DeleteEvent(ctx context.Context, req *IdRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteEvent not implemented")
+}
+func (*UnimplementedCoreDataServer) AddReading(ctx context.Context, req *Reading) (*AddReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddReading not implemented")
+}
+func (*UnimplementedCoreDataServer) GetReading(ctx context.Context, req *IdRequest) (*Reading, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReading not implemented")
+}
+func (*UnimplementedCoreDataServer) ReadingsForDevice(req *DeviceRequest, srv CoreData_ReadingsForDeviceServer) error {
+	return status.Errorf(codes.Unimplemented, "method ReadingsForDevice not implemented")
+}
+func (*UnimplementedCoreDataServer) ReadingsForValueDescriptor(req *NameRequest, srv CoreData_ReadingsForValueDescriptorServer) error {
+	return status.Errorf(codes.Unimplemented, "method ReadingsForValueDescriptor not implemented")
+}
+func (*UnimplementedCoreDataServer) AddValueDescriptor(ctx context.Context, req *ValueDescriptor) (*AddReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddValueDescriptor not implemented")
+}
+func (*UnimplementedCoreDataServer) GetValueDescriptor(ctx context.Context, req *NameRequest) (*ValueDescriptor, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetValueDescriptor not implemented")
+}
+func (*UnimplementedCoreDataServer) ValueDescriptors(req *Empty, srv CoreData_ValueDescriptorsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ValueDescriptors not implemented")
+}
+func (*UnimplementedCoreDataServer) DeleteValueDescriptor(ctx context.Context, req *NameRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteValueDescriptor not implemented")
+}
+
+func RegisterCoreDataServer(s *grpc.Server, srv CoreDataServer) {
+	s.RegisterService(&_CoreData_serviceDesc, srv)
+}
+
+func _CoreData_AddEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Event)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreDataServer).AddEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/coredata.CoreData/AddEvent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreDataServer).AddEvent(ctx, req.(*Event))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreData_StreamEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CoreDataServer).StreamEvents(&coreDataStreamEventsServer{stream})
+}
+
+type CoreData_StreamEventsServer interface {
+	Send(*StreamEventReply) error
+	Recv() (*Event, error)
+	grpc.ServerStream
+}
+
+type coreDataStreamEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *coreDataStreamEventsServer) Send(m *StreamEventReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *coreDataStreamEventsServer) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _CoreData_GetEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreDataServer).GetEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/coredata.CoreData/GetEvent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreDataServer).GetEvent(ctx, req.(*IdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreData_EventsForDevice_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DeviceRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CoreDataServer).EventsForDevice(m, &coreDataEventsForDeviceServer{stream})
+}
+
+type CoreData_EventsForDeviceServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type coreDataEventsForDeviceServer struct {
+	grpc.ServerStream
+}
+
+func (x *coreDataEventsForDeviceServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _CoreData_DeleteEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreDataServer).DeleteEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/coredata.CoreData/DeleteEvent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreDataServer).DeleteEvent(ctx, req.(*IdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreData_AddReading_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Reading)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreDataServer).AddReading(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/coredata.CoreData/AddReading",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreDataServer).AddReading(ctx, req.(*Reading))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreData_GetReading_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreDataServer).GetReading(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/coredata.CoreData/GetReading",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreDataServer).GetReading(ctx, req.(*IdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreData_ReadingsForDevice_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DeviceRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CoreDataServer).ReadingsForDevice(m, &coreDataReadingsForDeviceServer{stream})
+}
+
+type CoreData_ReadingsForDeviceServer interface {
+	Send(*Reading) error
+	grpc.ServerStream
+}
+
+type coreDataReadingsForDeviceServer struct {
+	grpc.ServerStream
+}
+
+func (x *coreDataReadingsForDeviceServer) Send(m *Reading) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _CoreData_ReadingsForValueDescriptor_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(NameRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CoreDataServer).ReadingsForValueDescriptor(m, &coreDataReadingsForValueDescriptorServer{stream})
+}
+
+type CoreData_ReadingsForValueDescriptorServer interface {
+	Send(*Reading) error
+	grpc.ServerStream
+}
+
+type coreDataReadingsForValueDescriptorServer struct {
+	grpc.ServerStream
+}
+
+func (x *coreDataReadingsForValueDescriptorServer) Send(m *Reading) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _CoreData_AddValueDescriptor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValueDescriptor)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreDataServer).AddValueDescriptor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/coredata.CoreData/AddValueDescriptor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreDataServer).AddValueDescriptor(ctx, req.(*ValueDescriptor))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreData_GetValueDescriptor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreDataServer).GetValueDescriptor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/coredata.CoreData/GetValueDescriptor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreDataServer).GetValueDescriptor(ctx, req.(*NameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreData_ValueDescriptors_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CoreDataServer).ValueDescriptors(m, &coreDataValueDescriptorsServer{stream})
+}
+
+type CoreData_ValueDescriptorsServer interface {
+	Send(*ValueDescriptor) error
+	grpc.ServerStream
+}
+
+type coreDataValueDescriptorsServer struct {
+	grpc.ServerStream
+}
+
+func (x *coreDataValueDescriptorsServer) Send(m *ValueDescriptor) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _CoreData_DeleteValueDescriptor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreDataServer).DeleteValueDescriptor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/coredata.CoreData/DeleteValueDescriptor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreDataServer).DeleteValueDescriptor(ctx, req.(*NameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _CoreData_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "coredata.CoreData",
+	HandlerType: (*CoreDataServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddEvent",
+			Handler:    _CoreData_AddEvent_Handler,
+		},
+		{
+			MethodName: "GetEvent",
+			Handler:    _CoreData_GetEvent_Handler,
+		},
+		{
+			MethodName: "DeleteEvent",
+			Handler:    _CoreData_DeleteEvent_Handler,
+		},
+		{
+			MethodName: "AddReading",
+			Handler:    _CoreData_AddReading_Handler,
+		},
+		{
+			MethodName: "GetReading",
+			Handler:    _CoreData_GetReading_Handler,
+		},
+		{
+			MethodName: "AddValueDescriptor",
+			Handler:    _CoreData_AddValueDescriptor_Handler,
+		},
+		{
+			MethodName: "GetValueDescriptor",
+			Handler:    _CoreData_GetValueDescriptor_Handler,
+		},
+		{
+			MethodName: "DeleteValueDescriptor",
+			Handler:    _CoreData_DeleteValueDescriptor_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamEvents",
+			Handler:       _CoreData_StreamEvents_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "EventsForDevice",
+			Handler:       _CoreData_EventsForDevice_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ReadingsForDevice",
+			Handler:       _CoreData_ReadingsForDevice_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ReadingsForValueDescriptor",
+			Handler:       _CoreData_ReadingsForValueDescriptor_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ValueDescriptors",
+			Handler:       _CoreData_ValueDescriptors_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "coredata.proto",
+}
@@ -20,7 +20,9 @@ import (
 	"net/url"
 	"strconv"
 
+	"github.com/edgexfoundry/edgex-go/core/clients/types"
 	"github.com/edgexfoundry/edgex-go/core/data/clients"
+	"github.com/edgexfoundry/edgex-go/core/data/errors"
 	"github.com/edgexfoundry/edgex-go/core/domain/models"
 	"github.com/gorilla/mux"
 )
@@ -31,7 +33,19 @@ func readingHandler(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
 	switch r.Method {
+	// Get all readings, or a page of them with ?offset=&limit= or ?cursor=&limit=
 	case http.MethodGet:
+		page, paged, err := parsePageRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			loggingClient.Error(err.Error())
+			return
+		}
+		if paged {
+			getReadingsPage(w, page)
+			return
+		}
+
 		r, err := dbc.Readings()
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusServiceUnavailable)
@@ -59,42 +73,24 @@ func readingHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		if configuration.ValidateCheck {
-			// Check the value descriptor
-			vd, err := dbc.ValueDescriptorByName(reading.Name)
-			if err != nil {
-				if err == clients.ErrNotFound {
-					http.Error(w, "Value descriptor not found for reading", http.StatusConflict)
+		id, err := addNewReading(reading)
+		if err != nil {
+			switch err.(type) {
+			case types.ErrNotFound:
+				http.Error(w, err.Error(), http.StatusNotFound)
+			case errors.NoValueDescriptor, errors.ValidationFailed:
+				http.Error(w, err.Error(), http.StatusConflict)
+			default:
+				if err == clients.ErrInvalidReading {
+					http.Error(w, err.Error(), http.StatusBadRequest)
 				} else {
 					http.Error(w, err.Error(), http.StatusServiceUnavailable)
 				}
-				loggingClient.Error(err.Error())
-				return
-			}
-
-			valid, err := isValidValueDescriptor(vd, reading)
-			if !valid {
-				http.Error(w, "Validation failed", http.StatusConflict)
-				loggingClient.Error("Validation failed")
-				return
-			}
-		}
-
-		// Check device
-		if reading.Device != "" {
-			if checkDevice(reading.Device, w) == false {
-				return
 			}
+			return
 		}
 
 		if configuration.PersistData {
-			id, err := dbc.AddReading(reading)
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusServiceUnavailable)
-				loggingClient.Error(err.Error())
-				return
-			}
-
 			w.WriteHeader(http.StatusOK)
 			w.Write([]byte(id.Hex()))
 		} else {
@@ -174,6 +170,38 @@ func readingHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Write the page of readings requested, with the cursor of the next page in the X-Next-Cursor header
+func getReadingsPage(w http.ResponseWriter, page pageRequest) {
+	if page.limit > configuration.ReadMaxLimit {
+		http.Error(w, maxExceededString, http.StatusRequestEntityTooLarge)
+		loggingClient.Error(maxExceededString)
+		return
+	}
+
+	var readings []models.Reading
+	var next string
+	var err error
+	if page.byCursor {
+		readings, next, err = dbc.ReadingsAfterCursor(page.cursor, page.limit)
+	} else {
+		readings, err = dbc.ReadingsPagedAscending(page.offset, page.limit)
+	}
+	if err != nil {
+		if isPageError(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		}
+		loggingClient.Error(err.Error())
+		return
+	}
+
+	if next != "" {
+		w.Header().Set(nextCursorHeader, next)
+	}
+	encode(readings, w)
+}
+
 // Get a reading by id
 // HTTP 404 not found if the reading can't be found by the ID
 // api/v1/reading/{id}
@@ -547,7 +575,11 @@ func readingByCreationTimeHandler(w http.ResponseWriter, r *http.Request) {
 
 		readings, err := dbc.ReadingsByCreationTime(s, e, l)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			if err == clients.ErrInvalidTimeRange {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			} else {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			}
 			loggingClient.Error(err.Error())
 			return
 		}
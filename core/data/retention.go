@@ -0,0 +1,131 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/data/archive"
+)
+
+// Number of readings looked up per round when expiring the readings without an event
+const retentionBatchSize = 1000
+
+// Keeps a copy of the expired events before they're removed, nil when the events aren't archived
+var archiver archive.EventArchiver
+
+// Number of events per archived object, and read at a time (0 - retentionBatchSize)
+var archiveBatchSize int
+
+// The databases that remove the old events and their readings in batches on their own
+type ageDeleter interface {
+	DeleteEventsOlderThanAge(age int64) (int, error)
+}
+
+// Remove the events and readings older than maxAge milliseconds now and then every interval milliseconds
+// The loop runs until stop is closed
+func startRetention(maxAge int64, interval int, stop chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(time.Duration(interval) * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			events, readings, err := expireOlderThan(maxAge)
+			if err != nil {
+				loggingClient.Error("Error expiring the old events and readings: " + err.Error())
+			} else if events > 0 || readings > 0 {
+				loggingClient.Info(fmt.Sprintf("Expired %d events and %d readings older than %d ms", events, readings, maxAge))
+			}
+
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Remove the events older than the age with their readings, then the readings posted on their own
+// Return the number of events and of readings without an event removed
+func expireOlderThan(age int64) (int, int, error) {
+	events, err := expireEvents(age)
+	if err != nil {
+		return events, 0, err
+	}
+
+	cutoff := time.Now().UnixNano()/int64(time.Millisecond) - age
+	readings := 0
+	for {
+		batch, err := dbc.ReadingsByCreationTime(0, cutoff, retentionBatchSize)
+		if err != nil || len(batch) == 0 {
+			return events, readings, err
+		}
+		for _, r := range batch {
+			if err = dbc.DeleteReadingById(r.Id.Hex()); err != nil {
+				return events, readings, err
+			}
+			readings++
+		}
+	}
+}
+
+// Remove the events older than the age and their readings, return the number of events removed
+// The events are read oldest first a page at a time, each page archived when there's an archive,
+// and removed before the next one is read, so the expired events are never all in memory
+func expireEvents(age int64) (int, error) {
+	if d, ok := dbc.(ageDeleter); ok && archiver == nil {
+		return d.DeleteEventsOlderThanAge(age)
+	}
+
+	pageSize := retentionBatchSize
+	if archiver != nil && archiveBatchSize > 0 {
+		pageSize = archiveBatchSize
+	}
+	cutoff := time.Now().UnixNano()/int64(time.Millisecond) - age
+	removed := 0
+	for {
+		// The events removed make way for the next ones
+		page, err := dbc.EventsPagedAscending(0, pageSize)
+		if err != nil {
+			return removed, err
+		}
+		expired := page
+		for i, e := range page {
+			if e.Created >= cutoff {
+				expired = page[:i]
+				break
+			}
+		}
+		if len(expired) == 0 {
+			return removed, nil
+		}
+
+		if archiver != nil {
+			if err = archiver.ArchiveEvents(expired); err != nil {
+				return removed, fmt.Errorf("archiving the events before removing them: %v", err)
+			}
+		}
+		for _, e := range expired {
+			if err = deleteEvent(e); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+		if len(expired) < pageSize {
+			return removed, nil
+		}
+	}
+}
@@ -0,0 +1,106 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/data/clients"
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+)
+
+func TestExpireOlderThan(t *testing.T) {
+	saved := dbc
+	defer func() { dbc = saved }()
+	dbc, _ = clients.NewDBClient(clients.DBConfiguration{DbType: clients.MEMORY})
+
+	old := models.Event{Device: "device", Readings: []models.Reading{{Name: "temperature", Value: "1"}}}
+	dbc.AddEvent(&old)
+	dbc.AddReading(models.Reading{Device: "device", Name: "humidity", Value: "2"})
+	time.Sleep(20 * time.Millisecond)
+	fresh := models.Event{Device: "device", Readings: []models.Reading{{Name: "temperature", Value: "3"}}}
+	dbc.AddEvent(&fresh)
+
+	events, readings, err := expireOlderThan(10)
+	if err != nil {
+		t.Fatalf("Error expiring the old events: %v", err)
+	}
+	if events != 1 || readings != 1 {
+		t.Fatalf("The old event and the old reading without an event should expire, not %d events and %d readings", events, readings)
+	}
+
+	if _, err = dbc.EventById(fresh.ID.Hex()); err != nil {
+		t.Fatalf("The fresh event should be kept: %v", err)
+	}
+	if count, _ := dbc.EventCount(); count != 1 {
+		t.Fatalf("Only the fresh event should be left, not %d events", count)
+	}
+	if count, _ := dbc.ReadingCount(); count != 1 {
+		t.Fatalf("Only the reading of the fresh event should be left, not %d readings", count)
+	}
+}
+
+// Records the batches archived, or refuses them with err
+type testArchiver struct {
+	batches [][]models.Event
+	err     error
+}
+
+func (a *testArchiver) ArchiveEvents(events []models.Event) error {
+	if a.err != nil {
+		return a.err
+	}
+	a.batches = append(a.batches, events)
+	return nil
+}
+
+func TestExpireEventsArchived(t *testing.T) {
+	saved := dbc
+	defer func() { dbc, archiver, archiveBatchSize = saved, nil, 0 }()
+	dbc, _ = clients.NewDBClient(clients.DBConfiguration{DbType: clients.MEMORY})
+
+	for i := 0; i < 3; i++ {
+		dbc.AddEvent(&models.Event{Device: "device", Readings: []models.Reading{{Name: "temperature", Value: "1"}}})
+	}
+	time.Sleep(20 * time.Millisecond)
+	fresh := models.Event{Device: "device", Readings: []models.Reading{{Name: "temperature", Value: "2"}}}
+	dbc.AddEvent(&fresh)
+
+	// Nothing is removed when the archive refuses the events
+	archiver = &testArchiver{err: errors.New("unreachable")}
+	if removed, err := expireEvents(10); err == nil || removed != 0 {
+		t.Fatalf("The events should be kept when they can't be archived: %d %v", removed, err)
+	}
+	if count, _ := dbc.EventCount(); count != 4 {
+		t.Fatalf("The events should be kept when they can't be archived, there are %d events", count)
+	}
+
+	recorder := &testArchiver{}
+	archiver, archiveBatchSize = recorder, 2
+	removed, err := expireEvents(10)
+	if err != nil || removed != 3 {
+		t.Fatalf("The 3 events should be archived and removed: %d %v", removed, err)
+	}
+	if len(recorder.batches) != 2 || len(recorder.batches[0]) != 2 || len(recorder.batches[1]) != 1 {
+		t.Fatalf("The events should be archived 2 at a time: %v", recorder.batches)
+	}
+	if len(recorder.batches[0][0].Readings) != 1 {
+		t.Fatalf("The events should be archived with their readings: %v", recorder.batches[0][0])
+	}
+	if _, err = dbc.EventById(fresh.ID.Hex()); err != nil {
+		t.Fatalf("The fresh event should be kept: %v", err)
+	}
+}
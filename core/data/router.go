@@ -27,6 +27,8 @@ func LoadRestRoutes() *mux.Router {
 	// /api/v1/event
 	b.HandleFunc("/event", eventHandler).Methods(http.MethodGet, http.MethodPut, http.MethodPost)
 	e := b.PathPrefix("/event").Subrouter()
+	e.HandleFunc("/batch", eventBatchHandler).Methods(http.MethodPost)
+	e.HandleFunc("/stream", eventStreamHandler).Methods(http.MethodGet)
 	e.HandleFunc("/scrub", scrubHandler).Methods(http.MethodDelete)
 	e.HandleFunc("/scruball", scrubAllHandler).Methods(http.MethodDelete)
 	e.HandleFunc("/count", eventCountHandler).Methods(http.MethodGet)
@@ -44,6 +46,7 @@ func LoadRestRoutes() *mux.Router {
 	b.HandleFunc("/reading", readingHandler).Methods(http.MethodGet, http.MethodPut, http.MethodPost)
 	rd := b.PathPrefix("/reading").Subrouter()
 	rd.HandleFunc("/count", readingCountHandler).Methods(http.MethodGet)
+	rd.HandleFunc("/subscribe", readingSubscribeHandler).Methods(http.MethodGet)
 	rd.HandleFunc("/id/{id}", deleteReadingByIdHandler).Methods(http.MethodDelete)
 	rd.HandleFunc("/{id}", getReadingByIdHandler).Methods(http.MethodGet)
 	rd.HandleFunc("/device/{deviceId}/{limit:[0-9]+}", readingByDeviceHandler).Methods(http.MethodGet)
@@ -0,0 +1,70 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"fmt"
+	"time"
+)
+
+// Delete the pushed events and their readings every interval milliseconds, batchSize events at a time
+// with a pause of pause milliseconds between the batches so the database isn't saturated
+// The loop runs until stop is closed
+func startScrubber(interval, batchSize, pause int, stop chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(time.Duration(interval) * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+
+			removed, err := scrubPushed(batchSize, time.Duration(pause)*time.Millisecond, stop)
+			if err != nil {
+				loggingClient.Error("Error scrubbing the pushed events: " + err.Error())
+			}
+			if removed > 0 {
+				loggingClient.Info(fmt.Sprintf("Scrubbed %d pushed events", removed))
+			}
+		}
+	}()
+}
+
+// Delete the pushed events in batches until there are none left or stop is closed
+// Return the number of events removed
+func scrubPushed(batchSize int, pause time.Duration, stop chan struct{}) (int, error) {
+	removed := 0
+	for {
+		n, err := dbc.DeleteEventsPushed(batchSize)
+		removed += n
+		if err != nil || n < batchSize {
+			return removed, err
+		}
+
+		// Both cases can be ready with no pause, so check stop first
+		select {
+		case <-stop:
+			return removed, nil
+		default:
+		}
+		select {
+		case <-stop:
+			return removed, nil
+		case <-time.After(pause):
+		}
+	}
+}
@@ -0,0 +1,54 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/edgex-go/core/data/clients"
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+)
+
+func TestScrubPushed(t *testing.T) {
+	saved := dbc
+	defer func() { dbc = saved }()
+	dbc, _ = clients.NewDBClient(clients.DBConfiguration{DbType: clients.MEMORY})
+
+	for i := 0; i < 5; i++ {
+		e := models.Event{Device: "device", Pushed: int64(i % 2)}
+		dbc.AddEvent(&e)
+	}
+
+	// The 3 events not pushed stay, the 2 pushed ones are removed over 2 batches
+	removed, err := scrubPushed(1, 0, make(chan struct{}))
+	if err != nil {
+		t.Fatalf("Error scrubbing the pushed events: %v", err)
+	}
+	if removed != 2 {
+		t.Fatalf("There should be 2 events scrubbed, not %d", removed)
+	}
+	if count, _ := dbc.EventCount(); count != 3 {
+		t.Fatalf("The events not pushed should be kept, there are %d events", count)
+	}
+
+	// A closed stop ends the scrub after the first batch
+	for i := 0; i < 2; i++ {
+		dbc.AddEvent(&models.Event{Device: "device", Pushed: 1})
+	}
+	stop := make(chan struct{})
+	close(stop)
+	if removed, _ = scrubPushed(1, 0, stop); removed != 1 {
+		t.Fatalf("Only the first batch should be scrubbed once stopped, not %d events", removed)
+	}
+}
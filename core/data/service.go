@@ -0,0 +1,223 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"fmt"
+
+	"github.com/edgexfoundry/edgex-go/core/data/clients"
+	"github.com/edgexfoundry/edgex-go/core/data/errors"
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// The operations shared by the REST and the gRPC APIs
+// They return the database errors, types.ErrNotFound for an unknown device and the errors of the
+// errors package, each API maps them to its own status codes
+
+// Check the device of the event and its readings, then add it and send it to the export services
+// The id is empty when the data isn't persisted
+func addNewEvent(e models.Event) (bson.ObjectId, error) {
+	if err := checkEventDevice(e.Device); err != nil {
+		return "", err
+	}
+	if err := validateEvent(e); err != nil {
+		loggingClient.Error(err.Error())
+		return "", err
+	}
+
+	var id bson.ObjectId
+	if configuration.PersistData {
+		var err error
+		if id, err = dbc.AddEvent(&e); err != nil {
+			loggingClient.Error(err.Error())
+			return "", err
+		}
+	}
+
+	putEventOnQueue(e)                                 // Push the aux struct to export service (It has the actual readings)
+	publishEventReadings(e)                            // Stream the readings to their subscribers
+	eventStreams.publish(e)                            // Stream the event to the SSE clients
+	updateDeviceLastReportedConnected(e.Device)        // update last reported connected (device)
+	updateDeviceServiceLastReportedConnected(e.Device) // update last reported connected (device service)
+	return id, nil
+}
+
+// Check and add the events together, the results tell which ones were added and why the others weren't
+// The error is only set when the whole batch failed
+func addNewEvents(events []models.Event) ([]batchEventResult, error) {
+	results := make([]batchEventResult, len(events))
+	var valid []models.Event
+	var positions []int // Position in the batch of each valid event
+	devices := map[string]error{}
+	for i, e := range events {
+		results[i].Index = i
+		deviceErr, checked := devices[e.Device]
+		if !checked {
+			deviceErr = checkEventDevice(e.Device)
+			devices[e.Device] = deviceErr
+		}
+		if deviceErr != nil {
+			results[i].Error = deviceErr.Error()
+			continue
+		}
+		if err := validateEvent(e); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		valid = append(valid, e)
+		positions = append(positions, i)
+	}
+
+	// Add the valid events and readings to the database
+	added := valid
+	if configuration.PersistData && len(valid) != 0 {
+		addResults, err := dbc.AddEvents(valid)
+		if err != nil {
+			loggingClient.Error(err.Error())
+			return nil, err
+		}
+
+		added = nil
+		for _, ar := range addResults {
+			result := &results[positions[ar.Index]]
+			if ar.Err != nil {
+				result.Error = ar.Err.Error()
+				continue
+			}
+			result.Id = ar.ID.Hex()
+			added = append(added, valid[ar.Index])
+		}
+	}
+
+	reported := map[string]bool{}
+	for _, e := range added {
+		putEventOnQueue(e)
+		publishEventReadings(e)
+		eventStreams.publish(e)
+		if !reported[e.Device] {
+			reported[e.Device] = true
+			updateDeviceLastReportedConnected(e.Device)
+			updateDeviceServiceLastReportedConnected(e.Device)
+		}
+	}
+	return results, nil
+}
+
+// Check metadata if the device of an event exists
+func checkEventDevice(device string) error {
+	if !configuration.MetaDataCheck {
+		return nil
+	}
+	if _, err := mdc.CheckForDevice(device); err != nil {
+		loggingClient.Error(fmt.Sprintf("error checking device %s %v", device, err))
+		return err
+	}
+	return nil
+}
+
+// Check the readings of an event against their value descriptors when validation is enabled
+func validateEvent(e models.Event) error {
+	for _, reading := range e.Readings {
+		if err := validateReading(reading); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check the reading against its value descriptor when validation is enabled
+func validateReading(reading models.Reading) error {
+	if !configuration.ValidateCheck {
+		return nil
+	}
+	vd, err := dbc.ValueDescriptorByName(reading.Name)
+	if err != nil {
+		if err == clients.ErrNotFound {
+			return errors.NoValueDescriptor{Id: reading.Name}
+		}
+		return err
+	}
+	if valid, _ := isValidValueDescriptor(vd, reading); !valid {
+		return errors.ValidationFailed{Name: reading.Name}
+	}
+	return nil
+}
+
+// Check the reading and its device, then add it
+// The id is empty when the data isn't persisted
+func addNewReading(reading models.Reading) (bson.ObjectId, error) {
+	if err := validateReading(reading); err != nil {
+		loggingClient.Error(err.Error())
+		return "", err
+	}
+	if reading.Device != "" {
+		if err := checkEventDevice(reading.Device); err != nil {
+			return "", err
+		}
+	}
+
+	var id bson.ObjectId
+	if configuration.PersistData {
+		var err error
+		if id, err = dbc.AddReading(reading); err != nil {
+			loggingClient.Error(err.Error())
+			return "", err
+		}
+		reading.Id = id
+	}
+
+	readingSubscriptions.publish([]models.Reading{reading})
+	return id, nil
+}
+
+// Check the formatting of the value descriptor, then add it
+func addNewValueDescriptor(v models.ValueDescriptor) (bson.ObjectId, error) {
+	match, err := validateFormatString(v)
+	if err != nil {
+		loggingClient.Error("Error checking for format string for POSTed value descriptor")
+		return "", err
+	}
+	if !match {
+		err = errors.BadValueDescriptor{VName: v.Name}
+		loggingClient.Error(err.Error())
+		return "", err
+	}
+
+	id, err := dbc.AddValueDescriptor(v)
+	if err != nil {
+		loggingClient.Error(err.Error())
+	}
+	return id, err
+}
+
+// Delete the value descriptor unless readings still reference it
+func removeValueDescriptor(vd models.ValueDescriptor) error {
+	readings, err := dbc.ReadingsByValueDescriptor(vd.Name, 10)
+	if err != nil {
+		loggingClient.Error(err.Error())
+		return err
+	}
+	if len(readings) > 0 {
+		err = errors.ValueDescriptorStillInUse{}
+		loggingClient.Error(err.Error())
+		return err
+	}
+
+	if err = dbc.DeleteValueDescriptorById(vd.Id.Hex()); err != nil {
+		loggingClient.Error(err.Error())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+)
+
+const (
+	streamReplaySize = 1000             // Recent events kept to resume the streams from their Last-Event-ID
+	streamBufferSize = 256              // Events queued per stream before it's dropped as too slow
+	streamHeartbeat  = 15 * time.Second // Comment sent on the idle streams so the proxies keep them open
+)
+
+// The streams of the new events
+var eventStreams = newEventHub(time.Now().UnixNano() / int64(time.Millisecond))
+
+// An event with its position in the streams
+type streamedEvent struct {
+	seq   uint64
+	event models.Event
+}
+
+// A client of the event stream, the events channel is closed when it's dropped
+type eventStreamer struct {
+	devices map[string]bool // Empty - every device
+	events  chan streamedEvent
+}
+
+func (s *eventStreamer) matches(e models.Event) bool {
+	return len(s.devices) == 0 || s.devices[e.Device]
+}
+
+// Send the new events to the streams, and keep the recent ones to resume the streams that reconnect
+// The stream ids are the start of the hub and the sequence of the event, so the ids handed out
+// before a restart are known to be stale
+type eventHub struct {
+	mutex   sync.Mutex
+	epoch   int64
+	seq     uint64
+	recent  []streamedEvent // Ring of the last streamReplaySize events
+	streams map[*eventStreamer]bool
+}
+
+func newEventHub(epoch int64) *eventHub {
+	return &eventHub{epoch: epoch, streams: map[*eventStreamer]bool{}}
+}
+
+// Return the stream id of the event
+func (h *eventHub) id(seq uint64) string {
+	return strconv.FormatInt(h.epoch, 10) + "-" + strconv.FormatUint(seq, 10)
+}
+
+// Return the sequence of the event with the stream id, false when the id isn't one of this hub
+func (h *eventHub) parseId(id string) (uint64, bool) {
+	parts := strings.SplitN(id, "-", 2)
+	if len(parts) != 2 || parts[0] != strconv.FormatInt(h.epoch, 10) {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(parts[1], 10, 64)
+	return seq, err == nil
+}
+
+// Open a stream of the events of the devices
+// With the id of the last event received, the events the stream missed since are returned to be
+// sent first, resumed is false when they're no longer known
+func (h *eventHub) subscribe(devices []string, lastId string) (s *eventStreamer, missed []streamedEvent, resumed bool) {
+	s = &eventStreamer{devices: stringSet(devices), events: make(chan streamedEvent, streamBufferSize)}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.streams[s] = true
+	if lastId == "" {
+		return s, nil, true
+	}
+
+	last, ok := h.parseId(lastId)
+	if !ok || last > h.seq {
+		return s, nil, false
+	}
+	// The ring holds the events from oldest + 1 on, anything older is lost
+	if oldest := h.seq - uint64(len(h.recent)); last < oldest {
+		return s, nil, false
+	}
+	for _, se := range h.recent {
+		if se.seq > last && s.matches(se.event) {
+			missed = append(missed, se)
+		}
+	}
+	return s, missed, true
+}
+
+func (h *eventHub) unsubscribe(s *eventStreamer) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.remove(s)
+}
+
+// Remove the stream and close its channel, the mutex must be held
+func (h *eventHub) remove(s *eventStreamer) {
+	if h.streams[s] {
+		delete(h.streams, s)
+		close(s.events)
+	}
+}
+
+// Keep the event and queue it for its streams without blocking, a stream too slow to keep up is
+// dropped rather than holding up the ingestion, it resumes from the recent events when it reconnects
+func (h *eventHub) publish(e models.Event) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.seq++
+	se := streamedEvent{seq: h.seq, event: e}
+	if len(h.recent) < streamReplaySize {
+		h.recent = append(h.recent, se)
+	} else {
+		copy(h.recent, h.recent[1:])
+		h.recent[len(h.recent)-1] = se
+	}
+
+	for s := range h.streams {
+		if !s.matches(e) {
+			continue
+		}
+		select {
+		case s.events <- se:
+		default:
+			loggingClient.Warn("Dropping an event stream too slow to keep up")
+			h.remove(s)
+		}
+	}
+}
+
+/*
+Stream the new events as Server-Sent Events, for the clients that can't use the WebSockets
+Each event is a message with the event in JSON, idle streams get a heartbeat comment
+A reconnecting client sending the Last-Event-ID header gets the events it missed first, or a reset
+event when they're no longer known and it should reload them through the REST API
+api/v1/event/stream?device={device}
+*/
+func eventStreamHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		loggingClient.Error("Streaming unsupported by the response writer")
+		return
+	}
+
+	s, missed, resumed := eventStreams.subscribe(queryList(r, "device"), r.Header.Get("Last-Event-ID"))
+	defer eventStreams.unsubscribe(s)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // Don't let nginx buffer the stream
+	w.WriteHeader(http.StatusOK)
+
+	if !resumed {
+		fmt.Fprint(w, "event: reset\ndata: the missed events are no longer known\n\n")
+	}
+	for _, se := range missed {
+		if err := writeStreamedEvent(w, se); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case se, ok := <-s.events:
+			if !ok {
+				return
+			}
+			if err := writeStreamedEvent(w, se); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+func writeStreamedEvent(w http.ResponseWriter, se streamedEvent) error {
+	data, err := json.Marshal(se.event)
+	if err != nil {
+		loggingClient.Error("Error encoding a streamed event: " + err.Error())
+		return nil
+	}
+	_, err = fmt.Fprintf(w, "id: %s\ndata: %s\n\n", eventStreams.id(se.seq), data)
+	return err
+}
@@ -0,0 +1,109 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+)
+
+func TestEventHubResume(t *testing.T) {
+	hub := newEventHub(42)
+	for _, device := range []string{"camera", "thermostat", "camera"} {
+		hub.publish(models.Event{Device: device})
+	}
+
+	s, missed, resumed := hub.subscribe([]string{"camera"}, hub.id(1))
+	if !resumed || len(missed) != 1 || missed[0].seq != 3 {
+		t.Fatalf("The camera event after the first one should be missed, not %v", missed)
+	}
+	hub.unsubscribe(s)
+
+	for _, id := range []string{"41-1", "42-4", "42-x", "garbage"} {
+		s, missed, resumed = hub.subscribe(nil, id)
+		if resumed || len(missed) != 0 {
+			t.Errorf("The stream can't be resumed from %s", id)
+		}
+		hub.unsubscribe(s)
+	}
+
+	// Once the ring is full the oldest events are forgotten
+	for i := 0; i < streamReplaySize; i++ {
+		hub.publish(models.Event{Device: "camera"})
+	}
+	if s, _, resumed = hub.subscribe(nil, hub.id(2)); resumed {
+		t.Errorf("The stream can't be resumed from a forgotten event")
+	}
+	hub.unsubscribe(s)
+	s, missed, resumed = hub.subscribe(nil, hub.id(3))
+	if !resumed || len(missed) != streamReplaySize {
+		t.Errorf("The stream should be resumed from the oldest event kept, %d events missed", len(missed))
+	}
+	hub.unsubscribe(s)
+}
+
+func TestEventStreamHandler(t *testing.T) {
+	server := httptest.NewServer(testRoutes)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/v1/event/stream?device=camera")
+	if err != nil {
+		t.Fatalf("Error opening the stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("The stream should be text/event-stream, not %s", resp.Header.Get("Content-Type"))
+	}
+
+	eventStreams.publish(models.Event{Device: "thermostat", Origin: 1})
+	eventStreams.publish(models.Event{Device: "camera", Origin: 2})
+
+	lines := bufio.NewReader(resp.Body)
+	id, _ := lines.ReadString('\n')
+	data, _ := lines.ReadString('\n')
+	if !strings.HasPrefix(id, "id: ") || !strings.HasPrefix(data, "data: ") {
+		t.Fatalf("The event should be sent with its id, not %q %q", id, data)
+	}
+	var e models.Event
+	if err = json.Unmarshal([]byte(strings.TrimPrefix(data, "data: ")), &e); err != nil {
+		t.Fatalf("Error decoding the streamed event: %v", err)
+	}
+	if e.Device != "camera" || e.Origin != 2 {
+		t.Fatalf("Only the camera event should be streamed, not %v", e)
+	}
+}
+
+func TestEventStreamHandlerReset(t *testing.T) {
+	server := httptest.NewServer(testRoutes)
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/v1/event/stream", nil)
+	req.Header.Set("Last-Event-ID", "0-1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Error opening the stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	line, _ := bufio.NewReader(resp.Body).ReadString('\n')
+	if line != "event: reset\n" {
+		t.Fatalf("A stream resumed from an unknown id should be reset, not %q", line)
+	}
+}
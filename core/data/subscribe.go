@@ -0,0 +1,196 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	subscriberBufferSize = 256              // Readings queued per subscriber before it's dropped as too slow
+	subscribeWriteWait   = 10 * time.Second // Bound of a write to a subscriber
+	subscribePingPeriod  = 30 * time.Second // Keep alive of the idle subscriptions
+)
+
+// The subscriptions to the new readings
+var readingSubscriptions = newReadingHub()
+
+// A client of the live readings, the readings channel is closed when it's dropped
+type readingSubscriber struct {
+	devices  map[string]bool // Empty - every device
+	names    map[string]bool // Empty - every value descriptor
+	readings chan models.Reading
+}
+
+func (s *readingSubscriber) matches(r models.Reading) bool {
+	return (len(s.devices) == 0 || s.devices[r.Device]) && (len(s.names) == 0 || s.names[r.Name])
+}
+
+// Send the new readings to the subscribers whose filters they match
+type readingHub struct {
+	mutex       sync.Mutex
+	subscribers map[*readingSubscriber]bool
+}
+
+func newReadingHub() *readingHub {
+	return &readingHub{subscribers: map[*readingSubscriber]bool{}}
+}
+
+func (h *readingHub) subscribe(devices, names []string) *readingSubscriber {
+	s := &readingSubscriber{
+		devices:  stringSet(devices),
+		names:    stringSet(names),
+		readings: make(chan models.Reading, subscriberBufferSize),
+	}
+	h.mutex.Lock()
+	h.subscribers[s] = true
+	h.mutex.Unlock()
+	return s
+}
+
+func (h *readingHub) unsubscribe(s *readingSubscriber) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.remove(s)
+}
+
+// Remove the subscriber and close its channel, the mutex must be held
+func (h *readingHub) remove(s *readingSubscriber) {
+	if h.subscribers[s] {
+		delete(h.subscribers, s)
+		close(s.readings)
+	}
+}
+
+// Queue the readings for their subscribers without blocking, a subscriber too slow to keep up is
+// dropped rather than holding up the ingestion
+func (h *readingHub) publish(readings []models.Reading) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for s := range h.subscribers {
+		for _, r := range readings {
+			if !s.matches(r) {
+				continue
+			}
+			select {
+			case s.readings <- r:
+			default:
+				loggingClient.Warn("Dropping a reading subscriber too slow to keep up")
+				h.remove(s)
+			}
+			if !h.subscribers[s] {
+				break
+			}
+		}
+	}
+}
+
+// Publish the readings of the added event, they get the device of the event
+func publishEventReadings(e models.Event) {
+	readings := make([]models.Reading, len(e.Readings))
+	for i, r := range e.Readings {
+		if r.Device == "" {
+			r.Device = e.Device
+		}
+		readings[i] = r
+	}
+	readingSubscriptions.publish(readings)
+}
+
+func stringSet(list []string) map[string]bool {
+	set := map[string]bool{}
+	for _, s := range list {
+		set[s] = true
+	}
+	return set
+}
+
+// Split the comma separated values of the query parameter, it can also be repeated
+func queryList(r *http.Request, key string) []string {
+	var list []string
+	for _, v := range r.URL.Query()[key] {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				list = append(list, s)
+			}
+		}
+	}
+	return list
+}
+
+var upgrader = websocket.Upgrader{
+	// The dashboards are served from other origins, and like the REST API the socket has no
+	// cookie based authentication to protect
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+/*
+Stream the new readings over a WebSocket as they're added, as JSON text messages
+The readings can be filtered by device and by value descriptor name with comma separated lists,
+a subscriber that can't keep up is disconnected
+api/v1/reading/subscribe?device={device}&name={name}
+*/
+func readingSubscribeHandler(w http.ResponseWriter, r *http.Request) {
+	// Subscribe before the handshake so no reading added after it's answered is missed
+	s := readingSubscriptions.subscribe(queryList(r, "device"), queryList(r, "name"))
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		readingSubscriptions.unsubscribe(s)
+		loggingClient.Error("Error opening the reading subscription: " + err.Error())
+		return
+	}
+	defer conn.Close()
+	defer readingSubscriptions.unsubscribe(s)
+
+	// The client doesn't send anything, reading is needed to handle the close and the pongs
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(subscribePingPeriod)
+	defer ping.Stop()
+	for {
+		select {
+		case reading, ok := <-s.readings:
+			conn.SetWriteDeadline(time.Now().Add(subscribeWriteWait))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "too slow to keep up"))
+				return
+			}
+			if err := conn.WriteJSON(reading); err != nil {
+				return
+			}
+		case <-ping.C:
+			conn.SetWriteDeadline(time.Now().Add(subscribeWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
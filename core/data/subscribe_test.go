@@ -0,0 +1,91 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/gorilla/websocket"
+)
+
+func TestReadingSubscribeHandler(t *testing.T) {
+	configuration.PersistData = true
+	defer func() { configuration.PersistData = false }()
+	server := httptest.NewServer(testRoutes)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/reading/subscribe?device=camera&name=image,temperature"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Error subscribing: %v", err)
+	}
+	defer conn.Close()
+
+	// Only the last reading matches both filters
+	for _, body := range []string{
+		`{"device":"thermostat","name":"temperature","value":"20"}`,
+		`{"device":"camera","name":"humidity","value":"50"}`,
+		`{"device":"camera","name":"temperature","value":"21"}`,
+	} {
+		resp, err := http.Post(server.URL+"/api/v1/reading", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("Error posting a reading: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("The reading should be added, status code %d", resp.StatusCode)
+		}
+	}
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var r models.Reading
+	if err = conn.ReadJSON(&r); err != nil {
+		t.Fatalf("Error receiving the reading: %v", err)
+	}
+	if r.Device != "camera" || r.Name != "temperature" || r.Value != "21" || !r.Id.Valid() {
+		t.Fatalf("The matching reading should be streamed, not %v", r)
+	}
+}
+
+func TestReadingHubDropsSlowSubscribers(t *testing.T) {
+	hub := newReadingHub()
+	slow := hub.subscribe(nil, nil)
+	filtered := hub.subscribe([]string{"other"}, nil)
+
+	readings := make([]models.Reading, subscriberBufferSize+1)
+	for i := range readings {
+		readings[i].Device = "camera"
+	}
+	hub.publish(readings)
+
+	for range readings[1:] {
+		<-slow.readings
+	}
+	if _, ok := <-slow.readings; ok {
+		t.Fatalf("The subscriber that can't keep up should be dropped")
+	}
+	if !hub.subscribers[filtered] {
+		t.Fatalf("The subscriber whose filter doesn't match should be kept")
+	}
+	hub.unsubscribe(slow)
+	hub.unsubscribe(filtered)
+	if len(hub.subscribers) != 0 {
+		t.Fatalf("There should be no subscribers left, not %d", len(hub.subscribers))
+	}
+}
@@ -15,11 +15,61 @@ package data
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/edgexfoundry/edgex-go/core/data/clients"
 )
 
+// Response header holding the cursor of the next page, absent on the last page
+const nextCursorHeader = "X-Next-Cursor"
+
+// Page requested with the offset, limit and cursor query parameters
+type pageRequest struct {
+	offset   int
+	limit    int
+	cursor   string
+	byCursor bool // The cursor parameter was given, an empty cursor starts from the oldest item
+}
+
+// Return the page requested by the query parameters and whether one was requested at all
+// The limit defaults to the max limit of the reads
+func parsePageRequest(r *http.Request) (pageRequest, bool, error) {
+	q := r.URL.Query()
+	_, hasOffset := q["offset"]
+	_, hasLimit := q["limit"]
+	_, hasCursor := q["cursor"]
+	p := pageRequest{limit: configuration.ReadMaxLimit, cursor: q.Get("cursor"), byCursor: hasCursor}
+	if !hasOffset && !hasLimit && !hasCursor {
+		return p, false, nil
+	}
+	if hasOffset && hasCursor {
+		return p, true, errors.New("offset and cursor can't be used together")
+	}
+
+	var err error
+	if hasOffset {
+		if p.offset, err = strconv.Atoi(q.Get("offset")); err != nil {
+			return p, true, clients.ErrInvalidPage
+		}
+	}
+	if hasLimit {
+		if p.limit, err = strconv.Atoi(q.Get("limit")); err != nil {
+			return p, true, clients.ErrInvalidLimit
+		}
+	}
+	return p, true, nil
+}
+
+// Whether a paging error is the client's fault
+func isPageError(err error) bool {
+	return err == clients.ErrInvalidPage || err == clients.ErrInvalidLimit || err == clients.ErrInvalidCursor
+}
+
 // Helper function for encoding things for returning from REST calls
 func encode(i interface{}, w http.ResponseWriter) {
 	w.Header().Add("Content-Type", "application/json")
@@ -34,6 +84,17 @@ func encode(i interface{}, w http.ResponseWriter) {
 	}
 }
 
+// Split a comma separated list of host:port addresses, the empty list gives no hosts
+func splitHosts(list string) []string {
+	hosts := []string{}
+	for _, host := range strings.Split(list, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
 // Printing function purely for debugging purposes
 // Print the body of a request to the console
 func printBody(r io.ReadCloser) {
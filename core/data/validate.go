@@ -27,6 +27,8 @@ func isValidValueDescriptor(vd models.ValueDescriptor, reading models.Reading) (
 		return validString(reading)
 	case "J": // JSON data
 		return validJSON(reading)
+	case "R": // raw binary data
+		return validBinary(reading)
 	default:
 		return false, fmt.Errorf("Unknown type")
 	}
@@ -118,3 +120,11 @@ func validJSON(reading models.Reading) (bool, error) {
 	}
 	return true, nil
 }
+
+func validBinary(reading models.Reading) (bool, error) {
+	if len(reading.BinaryValue) == 0 {
+		return false, fmt.Errorf("Binary value is empty")
+	}
+
+	return true, nil
+}
@@ -215,6 +215,35 @@ func TestValidJson(t *testing.T) {
 	}
 }
 
+func TestValidBinary(t *testing.T) {
+
+	var tests = []struct {
+		name   string
+		value  []byte
+		err    bool
+		result bool
+	}{
+		{"empty", nil, true, true},
+		{"valid", []byte{0xff, 0xd8, 0xff}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reading = models.Reading{BinaryValue: tt.value}
+			val, err := validBinary(reading)
+			if err == nil {
+				if tt.result != val {
+					t.Errorf("expecting %v, returned %v", tt.result, val)
+				}
+			} else {
+				if !tt.err {
+					t.Errorf("There should not be an error: %v", err)
+				}
+			}
+		})
+
+	}
+}
+
 func TestIsValidValueDescriptor_private(t *testing.T) {
 
 	var tests = []struct {
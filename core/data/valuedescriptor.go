@@ -15,16 +15,16 @@ package data
 
 import (
 	"encoding/json"
-	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"regexp"
 
 	"github.com/edgexfoundry/edgex-go/core/clients/types"
 	"github.com/edgexfoundry/edgex-go/core/data/clients"
+	"github.com/edgexfoundry/edgex-go/core/data/errors"
 	"github.com/edgexfoundry/edgex-go/core/domain/models"
 	"github.com/gorilla/mux"
-	"fmt"
 )
 
 const (
@@ -75,28 +75,18 @@ func valueDescriptorHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		// Check the formatting
-		match, err := validateFormatString(v)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusServiceUnavailable)
-			loggingClient.Error("Error checking for format string for POSTed value descriptor")
-			return
-		}
-		if !match {
-			err := errors.New("Error posting value descriptor. Format is not a valid printf format.")
-			http.Error(w, err.Error(), http.StatusConflict)
-			loggingClient.Error(err.Error())
-			return
-		}
-
-		id, err := dbc.AddValueDescriptor(v)
+		id, err := addNewValueDescriptor(v)
 		if err != nil {
-			if err == clients.ErrNotUnique {
-				http.Error(w, "Value Descriptor already exists", http.StatusConflict)
-			} else {
-				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			switch err.(type) {
+			case errors.BadValueDescriptor:
+				http.Error(w, err.Error(), http.StatusConflict)
+			default:
+				if err == clients.ErrNotUnique {
+					http.Error(w, "Value Descriptor already exists", http.StatusConflict)
+				} else {
+					http.Error(w, err.Error(), http.StatusServiceUnavailable)
+				}
 			}
-			loggingClient.Error(err.Error())
 			return
 		}
 
@@ -286,28 +276,16 @@ func valueDescriptorByNameHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func deleteValueDescriptor(vd models.ValueDescriptor, w http.ResponseWriter) error {
-	// Check if the value descriptor is still in use by readings
-	readings, err := dbc.ReadingsByValueDescriptor(vd.Name, 10)
+	err := removeValueDescriptor(vd)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusServiceUnavailable)
-		loggingClient.Error(err.Error())
-		return err
-	}
-	if len(readings) > 0 {
-		err = errors.New("Data integrity issue.  Value Descriptor is still referenced by existing readings.")
-		http.Error(w, err.Error(), http.StatusConflict)
-		loggingClient.Error(err.Error())
-		return err
-	}
-
-	// Delete the value descriptor
-	if err = dbc.DeleteValueDescriptorById(vd.Id.Hex()); err != nil {
-		http.Error(w, err.Error(), http.StatusServiceUnavailable)
-		loggingClient.Error(err.Error())
-		return err
+		switch err.(type) {
+		case errors.ValueDescriptorStillInUse:
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		}
 	}
-
-	return nil
+	return err
 }
 
 // Get a value descriptor based on the ID
@@ -0,0 +1,56 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+/*
+ * Check that the models stored in the database and exported as JSON tag each field for both, a field
+ * with only one of the tags is silently dropped by the other encoding
+ */
+func ValidateModelTags() error {
+	return checkTags(reflect.TypeOf(Event{}), reflect.TypeOf(Reading{}), reflect.TypeOf(ValueDescriptor{}))
+}
+
+// Return an error listing the fields that have a bson tag but no json tag or the other way around
+func checkTags(types ...reflect.Type) error {
+	var mismatches []string
+	for _, t := range types {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			// Unexported fields are skipped by both encodings
+			if f.PkgPath != "" {
+				continue
+			}
+
+			_, hasBson := f.Tag.Lookup("bson")
+			_, hasJson := f.Tag.Lookup("json")
+			if hasBson && !hasJson {
+				mismatches = append(mismatches, t.Name()+"."+f.Name+" has a bson tag but no json tag")
+			} else if hasJson && !hasBson {
+				mismatches = append(mismatches, t.Name()+"."+f.Name+" has a json tag but no bson tag")
+			}
+		}
+	}
+
+	if len(mismatches) > 0 {
+		return fmt.Errorf("model tags mismatch: %s", strings.Join(mismatches, ", "))
+	}
+	return nil
+}
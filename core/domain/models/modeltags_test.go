@@ -0,0 +1,52 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateModelTags(t *testing.T) {
+	if err := ValidateModelTags(); err != nil {
+		t.Fatalf("The models should have matching tags: %v", err)
+	}
+}
+
+func TestCheckTagsMismatch(t *testing.T) {
+	type drifted struct {
+		Matching string `bson:"matching" json:"matching"`
+		BsonOnly string `bson:"bsonOnly"`
+		JsonOnly string `json:"jsonOnly"`
+		Untagged string
+		hidden   string `bson:"hidden"`
+	}
+
+	err := checkTags(reflect.TypeOf(drifted{}))
+	if err == nil {
+		t.Fatal("The mismatched tags should be reported")
+	}
+	for _, field := range []string{"BsonOnly", "JsonOnly"} {
+		if !strings.Contains(err.Error(), "drifted."+field) {
+			t.Errorf("%s should be reported: %v", field, err)
+		}
+	}
+	for _, field := range []string{"Matching", "Untagged", "hidden"} {
+		if strings.Contains(err.Error(), "drifted."+field) {
+			t.Errorf("%s should not be reported: %v", field, err)
+		}
+	}
+}
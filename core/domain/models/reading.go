@@ -27,7 +27,7 @@ import (
  * Struct for the Reading object in EdgeX
  */
 type Reading struct {
-	Id       bson.ObjectId `bson:"_id,omitempty"`
+	Id       bson.ObjectId `bson:"_id,omitempty" json:"id"`
 	Pushed   int64         `bson:"pushed" json:"pushed"`   // When the data was pushed out of EdgeX (0 - not pushed yet)
 	Created  int64         `bson:"created" json:"created"` // When the reading was created
 	Origin   int64         `bson:"origin" json:"origin"`
@@ -35,6 +35,8 @@ type Reading struct {
 	Device   string        `bson:"device" json:"device"`
 	Name     string        `bson:"name" json:"name"`
 	Value    string        `bson:"value" json:"value"` // Device sensor data value
+	// Binary sensor data, like an image or a vibration capture, instead of Value
+	BinaryValue []byte `bson:"binaryValue,omitempty" json:"binaryValue,omitempty"`
 }
 
 // Custom marshaling to make empty strings null
@@ -48,12 +50,15 @@ func (r Reading) MarshalJSON() ([]byte, error) {
 		Device   *string       `json:"device"`
 		Name     *string       `json:"name"`
 		Value    *string       `json:"value"` // Device sensor data value
+		// Binary sensor data, base64 encoded
+		BinaryValue []byte `json:"binaryValue,omitempty"`
 	}{
-		Id:       r.Id,
-		Pushed:   r.Pushed,
-		Created:  r.Created,
-		Origin:   r.Origin,
-		Modified: r.Modified,
+		Id:          r.Id,
+		Pushed:      r.Pushed,
+		Created:     r.Created,
+		Origin:      r.Origin,
+		Modified:    r.Modified,
+		BinaryValue: r.BinaryValue,
 	}
 
 	// Empty strings are null
@@ -0,0 +1,53 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package export
+
+import (
+	"fmt"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+)
+
+// AMQP exchange types
+const (
+	AMQPExchangeDirect  = "direct"
+	AMQPExchangeTopic   = "topic"
+	AMQPExchangeFanout  = "fanout"
+	AMQPExchangeHeaders = "headers"
+)
+
+// AMQPDetails - Provides the exchange and routing of an AMQP destination
+// The address, port, user and password are those of the addressable, its path is the
+// virtual host, and a TLS/SSL/AMQPS protocol connects with TLS
+type AMQPDetails struct {
+	// Exchange the events are published to, the default exchange when empty
+	Exchange string `json:"exchange,omitempty"`
+	// Declared as a durable exchange of this type when set, used as it is otherwise
+	ExchangeType string `json:"exchangeType,omitempty"`
+	// {device} and {id} are replaced by those of the event, the topic of the addressable when empty
+	RoutingKey    string `json:"routingKey,omitempty"`
+	TLSSkipVerify bool   `json:"tlsSkipVerify,omitempty"`
+}
+
+func (a AMQPDetails) validate(addr models.Addressable) error {
+	if addr.Address == "" {
+		return fmt.Errorf("AMQP address is required")
+	}
+	if a.RoutingKey == "" && addr.Topic == "" && a.Exchange == "" {
+		return fmt.Errorf("AMQP routing key is required with the default exchange")
+	}
+
+	switch a.ExchangeType {
+	case "", AMQPExchangeDirect, AMQPExchangeTopic, AMQPExchangeFanout, AMQPExchangeHeaders:
+	default:
+		return fmt.Errorf("AMQP exchange type invalid: %s", a.ExchangeType)
+	}
+	if a.ExchangeType != "" && a.Exchange == "" {
+		return fmt.Errorf("AMQP exchange type requires an exchange")
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package export
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+)
+
+// AWSIoTDetails - Provides the credentials of an AWS IoT Core destination
+// The address and port of the addressable are the endpoint of the account, 8883 when 0, its
+// publisher is the client id, usually the thing name, and its topic the topic template,
+// {device} and {id} being replaced by those of the event
+type AWSIoTDetails struct {
+	// PEM client certificate registered for the thing, and its key
+	Certificate string `json:"certificate,omitempty"`
+	PrivateKey  string `json:"privateKey,omitempty"`
+	// PEM CA of the endpoint, the system CAs when empty
+	CACertificate string `json:"caCertificate,omitempty"`
+	// 0 or 1, AWS IoT doesn't support 2
+	QoS int `json:"qos,omitempty"`
+}
+
+// TLSConfig - mutual TLS configuration of the endpoint
+func (a AWSIoTDetails) TLSConfig(serverName string) (*tls.Config, error) {
+	cert, err := tls.X509KeyPair([]byte(a.Certificate), []byte(a.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("AWS IoT client certificate invalid: %v", err)
+	}
+	tlsConfig := &tls.Config{
+		ServerName:   serverName,
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if a.CACertificate != "" {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM([]byte(a.CACertificate)) {
+			return nil, fmt.Errorf("AWS IoT CA certificate invalid")
+		}
+	}
+	return tlsConfig, nil
+}
+
+func (a AWSIoTDetails) validate(addr models.Addressable) error {
+	if addr.Address == "" {
+		return fmt.Errorf("AWS IoT endpoint is required")
+	}
+	if addr.Publisher == "" {
+		return fmt.Errorf("AWS IoT client id is required")
+	}
+	if addr.Topic == "" {
+		return fmt.Errorf("AWS IoT topic is required")
+	}
+	if a.QoS != 0 && a.QoS != 1 {
+		return fmt.Errorf("AWS IoT QoS invalid: %d", a.QoS)
+	}
+	_, err := a.TLSConfig(addr.Address)
+	return err
+}
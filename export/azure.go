@@ -0,0 +1,67 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package export
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// AzureDetails - Provides the device of an Azure IoT Hub destination
+type AzureDetails struct {
+	// HostName=<hub>.azure-devices.net;DeviceId=<device>;SharedAccessKey=<key>
+	ConnectionString string `json:"connectionString,omitempty"`
+	// Seconds a SAS token is valid, it's renewed before it expires. An hour when 0
+	TokenTTL int `json:"tokenTTL,omitempty"`
+}
+
+// AzureConnection - Device of an Azure IoT Hub connection string
+type AzureConnection struct {
+	HostName        string
+	DeviceID        string
+	SharedAccessKey []byte
+}
+
+// ParseAzureConnectionString - parse the connection string of an Azure IoT Hub device
+func ParseAzureConnectionString(s string) (AzureConnection, error) {
+	var conn AzureConnection
+	for _, part := range strings.Split(s, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return conn, fmt.Errorf("Azure connection string invalid: %s", part)
+		}
+		switch strings.TrimSpace(kv[0]) {
+		case "HostName":
+			conn.HostName = kv[1]
+		case "DeviceId":
+			conn.DeviceID = kv[1]
+		case "SharedAccessKey":
+			key, err := base64.StdEncoding.DecodeString(kv[1])
+			if err != nil {
+				return conn, fmt.Errorf("Azure shared access key invalid: %v", err)
+			}
+			conn.SharedAccessKey = key
+		}
+	}
+
+	if conn.HostName == "" || conn.DeviceID == "" || len(conn.SharedAccessKey) == 0 {
+		return conn, fmt.Errorf("Azure connection string requires HostName, DeviceId and SharedAccessKey")
+	}
+	return conn, nil
+}
+
+func (a AzureDetails) validate() error {
+	if a.TokenTTL < 0 {
+		return fmt.Errorf("Azure token TTL invalid: %d", a.TokenTTL)
+	}
+	_, err := ParseAzureConnectionString(a.ConnectionString)
+	return err
+}
@@ -0,0 +1,47 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package export
+
+import (
+	"fmt"
+)
+
+// BatchDetails - Events of a registration accumulated and sent in a single payload, once
+// there are Size of them or Interval passed since the last payload. The events are sent one
+// by one when both are 0
+type BatchDetails struct {
+	// Events of a payload, 100 when 0
+	Size int `json:"size,omitempty"`
+	// Milliseconds the events wait for a full payload, 10000 when 0
+	Interval int `json:"interval,omitempty"`
+}
+
+// Enabled - whether the events are batched
+func (b BatchDetails) Enabled() bool {
+	return b.Size != 0 || b.Interval != 0
+}
+
+func (b BatchDetails) validate(format string, destination string) error {
+	if b.Size < 0 {
+		return fmt.Errorf("Batch size invalid: %d", b.Size)
+	}
+	if b.Interval < 0 {
+		return fmt.Errorf("Batch interval invalid: %d", b.Interval)
+	}
+	if !b.Enabled() {
+		return nil
+	}
+	// The destinations writing the events one by one batch them on their own
+	if destination == DestInfluxDB || destination == DestPostgres {
+		return fmt.Errorf("Destination %s can't batch the events", destination)
+	}
+	if format != FormatJSON && format != FormatXML && format != FormatCSV &&
+		format != FormatThingsBoardJSON && format != FormatProtobuf {
+		return fmt.Errorf("Format %s can't batch the events", format)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package export
+
+import (
+	"fmt"
+)
+
+// BufferDetails - Payloads of a registration its destination didn't take, stored on the disk
+// of export distro and forwarded again, in order, once the destination is reachable. The
+// payloads are dropped when MaxSize and MaxAge are both 0
+type BufferDetails struct {
+	// Bytes of payloads stored, the oldest are dropped beyond it, 100 MiB when 0
+	MaxSize int64 `json:"maxSize,omitempty"`
+	// Milliseconds the payloads are stored, kept until MaxSize drops them when 0
+	MaxAge int64 `json:"maxAge,omitempty"`
+	// Milliseconds between the attempts to forward the stored payloads, 10000 when 0
+	Interval int `json:"interval,omitempty"`
+}
+
+// Enabled - whether the undelivered payloads are stored and forwarded
+func (b BufferDetails) Enabled() bool {
+	return b.MaxSize != 0 || b.MaxAge != 0
+}
+
+func (b BufferDetails) validate(destination string) error {
+	if b.MaxSize < 0 {
+		return fmt.Errorf("Buffer max size invalid: %d", b.MaxSize)
+	}
+	if b.MaxAge < 0 {
+		return fmt.Errorf("Buffer max age invalid: %d", b.MaxAge)
+	}
+	if b.Interval < 0 {
+		return fmt.Errorf("Buffer interval invalid: %d", b.Interval)
+	}
+	if !b.Enabled() {
+		return nil
+	}
+	// The payloads are forwarded without the event keying them on the other destinations
+	if destination != DestMQTT && destination != DestAzureMQTT && destination != DestRest {
+		return fmt.Errorf("Destination %s can't buffer the payloads", destination)
+	}
+	return nil
+}
@@ -91,6 +91,29 @@ type DBClient interface {
 	// UnexpectedError - problem getting in database
 	// NotFound - no registration with the ID was found
 	DeleteRegistrationByName(name string) error
+
+	// ********************** DEAD LETTER FUNCTIONS *****************************
+	// Return all the dead letters, oldest first
+	// UnexpectedError - failed to retrieve dead letters from the database
+	DeadLetters() ([]export.DeadLetter, error)
+
+	// Return the dead letters of a registration, oldest first
+	// UnexpectedError - failed to retrieve dead letters from the database
+	DeadLettersByRegistration(name string) ([]export.DeadLetter, error)
+
+	// Add a new dead letter
+	// UnexpectedError - failed to add to database
+	AddDeadLetter(letter *export.DeadLetter) (bson.ObjectId, error)
+
+	// Get a dead letter by ID
+	// UnexpectedError - problem getting in database
+	// NotFound - no dead letter with the ID was found
+	DeadLetterById(id string) (export.DeadLetter, error)
+
+	// Delete a dead letter by ID
+	// UnexpectedError - problem getting in database
+	// NotFound - no dead letter with the ID was found
+	DeleteDeadLetterById(id string) error
 }
 
 type DBConfiguration struct {
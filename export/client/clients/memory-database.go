@@ -23,7 +23,8 @@ import (
 )
 
 type memDB struct {
-	regs []export.Registration
+	regs    []export.Registration
+	letters []export.DeadLetter
 }
 
 func (mc *memDB) Registrations() ([]export.Registration, error) {
@@ -93,3 +94,46 @@ func (mc *memDB) DeleteRegistrationByName(name string) error {
 	}
 	return ErrNotFound
 }
+
+func (mc *memDB) DeadLetters() ([]export.DeadLetter, error) {
+	// A copy, the dead letters are deleted while they're replayed
+	return append([]export.DeadLetter{}, mc.letters...), nil
+}
+
+func (mc *memDB) DeadLettersByRegistration(name string) ([]export.DeadLetter, error) {
+	letters := []export.DeadLetter{}
+	for _, letter := range mc.letters {
+		if letter.Registration == name {
+			letters = append(letters, letter)
+		}
+	}
+	return letters, nil
+}
+
+func (mc *memDB) AddDeadLetter(letter *export.DeadLetter) (bson.ObjectId, error) {
+	letter.ID = bson.NewObjectId()
+
+	mc.letters = append(mc.letters, *letter)
+
+	return letter.ID, nil
+}
+
+func (mc *memDB) DeadLetterById(id string) (export.DeadLetter, error) {
+	for _, letter := range mc.letters {
+		if letter.ID.Hex() == id {
+			return letter, nil
+		}
+	}
+
+	return export.DeadLetter{}, ErrNotFound
+}
+
+func (mc *memDB) DeleteDeadLetterById(id string) error {
+	for i, letter := range mc.letters {
+		if letter.ID.Hex() == id {
+			mc.letters = append(mc.letters[:i], mc.letters[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
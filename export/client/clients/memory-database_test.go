@@ -91,7 +91,50 @@ func testDB(t *testing.T, db DBClient) {
 
 }
 
+func testDeadLetters(t *testing.T, db DBClient) {
+	first := export.DeadLetter{Registration: "reg", Payload: []byte("data"), Created: 1}
+	id, err := db.AddDeadLetter(&first)
+	if err != nil {
+		t.Fatalf("Error adding dead letter: %v", err)
+	}
+	second := export.DeadLetter{Registration: "other", Payload: []byte("data"), Created: 2}
+	if _, err = db.AddDeadLetter(&second); err != nil {
+		t.Fatalf("Error adding dead letter: %v", err)
+	}
+
+	letters, err := db.DeadLetters()
+	if err != nil || len(letters) != 2 || letters[0].ID != id {
+		t.Fatalf("There should be two dead letters, oldest first: %v, %v", letters, err)
+	}
+	letters, err = db.DeadLettersByRegistration("reg")
+	if err != nil || len(letters) != 1 || letters[0].ID != id {
+		t.Fatalf("There should be one dead letter of the registration: %v, %v", letters, err)
+	}
+
+	letter, err := db.DeadLetterById(id.Hex())
+	if err != nil || string(letter.Payload) != "data" {
+		t.Fatalf("Error getting dead letter by id: %v, %v", letter, err)
+	}
+	if _, err = db.DeadLetterById("INVALID"); err == nil {
+		t.Fatalf("Dead letter should not be found")
+	}
+
+	if err = db.DeleteDeadLetterById("INVALID"); err == nil {
+		t.Fatalf("Dead letter should not be deleted")
+	}
+	if err = db.DeleteDeadLetterById(id.Hex()); err != nil {
+		t.Fatalf("Dead letter should be deleted: %v", err)
+	}
+	if err = db.DeleteDeadLetterById(second.ID.Hex()); err != nil {
+		t.Fatalf("Dead letter should be deleted: %v", err)
+	}
+	if letters, _ = db.DeadLetters(); len(letters) != 0 {
+		t.Fatalf("There should be no dead letter: %v", letters)
+	}
+}
+
 func TestMemoryDB(t *testing.T) {
 	memory := &memDB{}
 	testDB(t, memory)
+	testDeadLetters(t, memory)
 }
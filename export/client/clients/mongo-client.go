@@ -24,7 +24,8 @@ import (
 )
 
 const (
-	EXPORT_COLLECTION = "exportConfiguration"
+	EXPORT_COLLECTION     = "exportConfiguration"
+	DEADLETTER_COLLECTION = "exportDeadLetter"
 )
 
 /*
@@ -182,3 +183,76 @@ func (mc *MongoClient) deleteRegistration(q bson.M) error {
 	}
 	return err
 }
+
+// ****************************** DEAD LETTERS ********************************
+
+// Return all the dead letters, oldest first
+// UnexpectedError - failed to retrieve dead letters from the database
+func (mc *MongoClient) DeadLetters() ([]export.DeadLetter, error) {
+	return mc.getDeadLetters(bson.M{})
+}
+
+// Return the dead letters of a registration, oldest first
+// UnexpectedError - failed to retrieve dead letters from the database
+func (mc *MongoClient) DeadLettersByRegistration(name string) ([]export.DeadLetter, error) {
+	return mc.getDeadLetters(bson.M{"registration": name})
+}
+
+// Add a new dead letter
+// UnexpectedError - failed to add to database
+func (mc *MongoClient) AddDeadLetter(letter *export.DeadLetter) (bson.ObjectId, error) {
+	s := mc.GetSessionCopy()
+	defer s.Close()
+
+	letter.ID = bson.NewObjectId()
+
+	err := s.DB(mc.Database.Name).C(DEADLETTER_COLLECTION).Insert(letter)
+	return letter.ID, err
+}
+
+// Get a dead letter by ID
+// UnexpectedError - problem getting in database
+// NotFound - no dead letter with the ID was found
+func (mc *MongoClient) DeadLetterById(id string) (export.DeadLetter, error) {
+	if !bson.IsObjectIdHex(id) {
+		return export.DeadLetter{}, ErrInvalidObjectId
+	}
+
+	s := mc.GetSessionCopy()
+	defer s.Close()
+
+	var letter export.DeadLetter
+	err := s.DB(mc.Database.Name).C(DEADLETTER_COLLECTION).FindId(bson.ObjectIdHex(id)).One(&letter)
+	if err == mgo.ErrNotFound {
+		return letter, ErrNotFound
+	}
+	return letter, err
+}
+
+// Delete a dead letter by ID
+// UnexpectedError - problem getting in database
+// NotFound - no dead letter with the ID was found
+func (mc *MongoClient) DeleteDeadLetterById(id string) error {
+	if !bson.IsObjectIdHex(id) {
+		return ErrInvalidObjectId
+	}
+
+	s := mc.GetSessionCopy()
+	defer s.Close()
+
+	err := s.DB(mc.Database.Name).C(DEADLETTER_COLLECTION).RemoveId(bson.ObjectIdHex(id))
+	if err == mgo.ErrNotFound {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Get dead letters for the passed query, oldest first
+func (mc *MongoClient) getDeadLetters(q bson.M) ([]export.DeadLetter, error) {
+	s := mc.GetSessionCopy()
+	defer s.Close()
+
+	letters := []export.DeadLetter{}
+	err := s.DB(mc.Database.Name).C(DEADLETTER_COLLECTION).Find(q).Sort("created").All(&letters)
+	return letters, err
+}
@@ -34,4 +34,5 @@ func TestMongoDB(t *testing.T) {
 	}
 
 	testDB(t, mongo)
+	testDeadLetters(t, mongo)
 }
@@ -23,6 +23,7 @@ type ConfigurationStruct struct {
 	ConsulProfilesActive string
 	DistroHost           string
 	DistroPort           int
+	DistroNotifyTimeout  int
 }
 
 var configuration ConfigurationStruct = ConfigurationStruct{} // Needs to be initialized before used
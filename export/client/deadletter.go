@@ -0,0 +1,159 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/edgexfoundry/edgex-go/export"
+	"github.com/go-zoo/bone"
+	"go.uber.org/zap"
+)
+
+// Result of replaying the dead letters
+type replayResult struct {
+	Replayed int `json:"replayed"`
+	Failed   int `json:"failed"`
+}
+
+// Dead letters of the registration query parameter, all of them without it
+func queryDeadLetters(r *http.Request) ([]export.DeadLetter, error) {
+	if name := r.URL.Query().Get("registration"); name != "" {
+		return dbc.DeadLettersByRegistration(name)
+	}
+	return dbc.DeadLetters()
+}
+
+func getAllDeadLetters(w http.ResponseWriter, r *http.Request) {
+	letters, err := queryDeadLetters(r)
+	if err != nil {
+		logger.Error("Failed to query all dead letters", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if letters == nil {
+		letters = []export.DeadLetter{}
+	}
+
+	w.Header().Set("Content-Type", applicationJson)
+	json.NewEncoder(w).Encode(&letters)
+}
+
+func getDeadLetterByID(w http.ResponseWriter, r *http.Request) {
+	id := bone.GetValue(r, "id")
+
+	letter, err := dbc.DeadLetterById(id)
+	if err != nil {
+		logger.Error("Failed to query dead letter by id", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", applicationJson)
+	json.NewEncoder(w).Encode(&letter)
+}
+
+// Add the dead letter distro couldn't deliver
+func addDeadLetter(w http.ResponseWriter, r *http.Request) {
+	letter := export.DeadLetter{}
+	if err := json.NewDecoder(r.Body).Decode(&letter); err != nil {
+		logger.Error("Failed to parse dead letter", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if letter.Registration == "" || len(letter.Payload) == 0 {
+		logger.Error("Dead letter without registration or payload")
+		http.Error(w, "Registration and payload are required", http.StatusBadRequest)
+		return
+	}
+
+	id, err := dbc.AddDeadLetter(&letter)
+	if err != nil {
+		logger.Error("Failed to add dead letter", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(id.Hex()))
+}
+
+func delDeadLetterByID(w http.ResponseWriter, r *http.Request) {
+	id := bone.GetValue(r, "id")
+
+	if err := dbc.DeleteDeadLetterById(id); err != nil {
+		logger.Error("Failed to delete dead letter by id", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", applicationJson)
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("true"))
+}
+
+// Hand the dead letter to distro, and delete it once distro has it
+func replayDeadLetter(letter export.DeadLetter) error {
+	if err := notifier.ReplayDeadLetter(letter); err != nil {
+		return err
+	}
+	if err := dbc.DeleteDeadLetterById(letter.ID.Hex()); err != nil {
+		logger.Warn("Failed to delete replayed dead letter", zap.Error(err))
+	}
+	return nil
+}
+
+func replayDeadLetterByID(w http.ResponseWriter, r *http.Request) {
+	id := bone.GetValue(r, "id")
+
+	letter, err := dbc.DeadLetterById(id)
+	if err != nil {
+		logger.Error("Failed to query dead letter by id", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if notifier == nil {
+		http.Error(w, "Distro not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := replayDeadLetter(letter); err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", applicationJson)
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("true"))
+}
+
+// Replay the dead letters of the registration query parameter, all of them without it
+func replayDeadLetters(w http.ResponseWriter, r *http.Request) {
+	letters, err := queryDeadLetters(r)
+	if err != nil {
+		logger.Error("Failed to query dead letters", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if notifier == nil {
+		http.Error(w, "Distro not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	result := replayResult{}
+	for _, letter := range letters {
+		if err := replayDeadLetter(letter); err != nil {
+			result.Failed++
+		} else {
+			result.Replayed++
+		}
+	}
+
+	w.Header().Set("Content-Type", applicationJson)
+	json.NewEncoder(w).Encode(&result)
+}
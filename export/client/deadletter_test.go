@@ -0,0 +1,143 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package client
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/edgexfoundry/edgex-go/export"
+)
+
+func addTestDeadLetter(t *testing.T, serverUrl string, registration string) string {
+	response, err := http.Post(serverUrl+apiV1DeadLetter, "application/json",
+		strings.NewReader(`{"registration":"`+registration+`","destination":"REST_ENDPOINT","payload":"ZGF0YQ==","error":"refused","attempts":3,"created":1}`))
+	if err != nil {
+		t.Fatalf("Error adding dead letter: %v", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Returned status %d, should be %d", response.StatusCode, http.StatusOK)
+	}
+	id, _ := ioutil.ReadAll(response.Body)
+	return string(id)
+}
+
+func getTestDeadLetters(t *testing.T, url string) []export.DeadLetter {
+	response, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("Error getting dead letters: %v", err)
+	}
+	defer response.Body.Close()
+	letters := []export.DeadLetter{}
+	if err := json.NewDecoder(response.Body).Decode(&letters); err != nil {
+		t.Fatalf("Error parsing dead letters: %v", err)
+	}
+	return letters
+}
+
+func TestDeadLetters(t *testing.T) {
+	ts := prepareTest(t)
+	defer ts.Close()
+
+	for _, data := range []string{"{", `{"payload":"ZGF0YQ=="}`, `{"registration":"reg"}`} {
+		response, _ := http.Post(ts.URL+apiV1DeadLetter, "application/json", strings.NewReader(data))
+		response.Body.Close()
+		if response.StatusCode != http.StatusBadRequest {
+			t.Errorf("Invalid dead letter %s returned status %d", data, response.StatusCode)
+		}
+	}
+
+	id := addTestDeadLetter(t, ts.URL, "reg")
+	addTestDeadLetter(t, ts.URL, "other")
+
+	if letters := getTestDeadLetters(t, ts.URL+apiV1DeadLetter); len(letters) != 2 {
+		t.Fatalf("There should be two dead letters: %v", letters)
+	}
+	letters := getTestDeadLetters(t, ts.URL+apiV1DeadLetter+"?registration=reg")
+	if len(letters) != 1 || letters[0].ID.Hex() != id || string(letters[0].Payload) != "data" ||
+		letters[0].Error != "refused" || letters[0].Attempts != 3 {
+		t.Fatalf("There should be the dead letter of the registration: %v", letters)
+	}
+
+	response, _ := http.Get(ts.URL + apiV1DeadLetter + "/" + id)
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Errorf("Returned status %d, should be %d", response.StatusCode, http.StatusOK)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+apiV1DeadLetter+"/"+id, nil)
+	response, _ = http.DefaultClient.Do(req)
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Errorf("Returned status %d, should be %d", response.StatusCode, http.StatusOK)
+	}
+	response, _ = http.Get(ts.URL + apiV1DeadLetter + "/" + id)
+	response.Body.Close()
+	if response.StatusCode != http.StatusNotFound {
+		t.Errorf("A deleted dead letter returned status %d", response.StatusCode)
+	}
+}
+
+func TestDeadLetterReplay(t *testing.T) {
+	ts := prepareTest(t)
+	defer ts.Close()
+
+	// Distro accepts the dead letters of running registrations
+	var replayed []export.DeadLetter
+	distro := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		letter := export.DeadLetter{}
+		json.NewDecoder(r.Body).Decode(&letter)
+		if r.URL.Path != distroReplayPath || letter.Registration != "reg" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		replayed = append(replayed, letter)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer distro.Close()
+	notifier = newTestNotifier(t, distro.URL)
+	defer func() { notifier = nil }()
+
+	id := addTestDeadLetter(t, ts.URL, "reg")
+	response, _ := http.Post(ts.URL+apiV1DeadLetter+"/"+id+"/replay", "application/json", nil)
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK || len(replayed) != 1 || string(replayed[0].Payload) != "data" {
+		t.Fatalf("The dead letter should be replayed, status %d: %v", response.StatusCode, replayed)
+	}
+	if letters := getTestDeadLetters(t, ts.URL+apiV1DeadLetter); len(letters) != 0 {
+		t.Fatalf("The replayed dead letter should be deleted: %v", letters)
+	}
+
+	addTestDeadLetter(t, ts.URL, "reg")
+	addTestDeadLetter(t, ts.URL, "reg")
+	removed := addTestDeadLetter(t, ts.URL, "removed")
+
+	response, _ = http.Post(ts.URL+apiV1DeadLetter+"/"+removed+"/replay", "application/json", nil)
+	response.Body.Close()
+	if response.StatusCode != http.StatusBadGateway {
+		t.Errorf("A dead letter distro refuses returned status %d", response.StatusCode)
+	}
+
+	response, err := http.Post(ts.URL+apiV1DeadLetter+"/replay", "application/json", nil)
+	if err != nil {
+		t.Fatalf("Error replaying dead letters: %v", err)
+	}
+	var result replayResult
+	json.NewDecoder(response.Body).Decode(&result)
+	response.Body.Close()
+	if result.Replayed != 2 || result.Failed != 1 {
+		t.Fatalf("Two dead letters should be replayed and one fail: %v", result)
+	}
+	if letters := getTestDeadLetters(t, ts.URL+apiV1DeadLetter); len(letters) != 1 || letters[0].Registration != "removed" {
+		t.Fatalf("Only the failed dead letter should be kept: %v", letters)
+	}
+}
@@ -24,6 +24,7 @@ const (
 // Global variables
 var dbc clients.DBClient
 var logger *zap.Logger
+var notifier *DistroNotifier
 
 func ConnectToConsul(conf ConfigurationStruct) error {
 	// Initialize service on Consul
@@ -68,6 +69,8 @@ func Init(conf ConfigurationStruct, l *zap.Logger) error {
 		return fmt.Errorf("couldn't connect to database: %v", err.Error())
 	}
 
+	notifier = NewDistroNotifier(conf.DistroHost, conf.DistroPort, conf.DistroNotifyTimeout)
+
 	return nil
 }
 
@@ -0,0 +1,180 @@
+//
+// Copyright (c) 2018 Cavium
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/export"
+	"go.uber.org/zap"
+)
+
+const (
+	distroNotifyPath           = "/api/v1/notify/registrations"
+	distroReplayPath           = "/api/v1/replay"
+	distroStatsPath            = "/api/v1/stats/"
+	distroCheckPath            = "/api/v1/check"
+	defaultDistroNotifyTimeout = 5000
+
+	// Connecting to the destination of a registration may take its own timeouts
+	distroCheckTimeout = time.Minute
+)
+
+// DistroNotifier tells export-distro that a registration changed so it
+// reloads it
+type DistroNotifier struct {
+	url         string
+	replayURL   string
+	statsURL    string
+	checkURL    string
+	client      *http.Client
+	checkClient *http.Client
+}
+
+// NewDistroNotifier creates a notifier for the distro at host:port. The
+// timeout is in milliseconds, a default is used when it is not positive
+func NewDistroNotifier(host string, port int, timeout int) *DistroNotifier {
+	if timeout <= 0 {
+		timeout = defaultDistroNotifyTimeout
+	}
+	base := "http://" + host + ":" + strconv.Itoa(port)
+	return &DistroNotifier{
+		url:         base + distroNotifyPath,
+		replayURL:   base + distroReplayPath,
+		statsURL:    base + distroStatsPath,
+		checkURL:    base + distroCheckPath,
+		client:      &http.Client{Timeout: time.Duration(timeout) * time.Millisecond},
+		checkClient: &http.Client{Timeout: distroCheckTimeout},
+	}
+}
+
+// NotifyRegistrationChanged sends the action (add, update or delete) done
+// on the named registration to distro. Failures are logged and returned,
+// the registration change itself is already done so callers can ignore them
+func (n *DistroNotifier) NotifyRegistrationChanged(action string, name string) error {
+	data, err := json.Marshal(export.NotifyUpdate{Name: name, Operation: action})
+	if err != nil {
+		logger.Error("Error generating update json", zap.Error(err))
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPut, n.url, bytes.NewBuffer(data))
+	if err != nil {
+		logger.Error("Error creating http request", zap.Error(err))
+		return err
+	}
+	req.Header.Set("Content-Type", applicationJson)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		logger.Error("Error notifying updated registrations to distro",
+			zap.String("url", n.url), zap.Error(err))
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("distro returned status %d", resp.StatusCode)
+		logger.Error("Error notifying updated registrations to distro",
+			zap.String("url", n.url), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ReplayDeadLetter hands the dead letter to distro, which sends its payload
+// again with its registration. Distro dead letters it again if that fails
+func (n *DistroNotifier) ReplayDeadLetter(letter export.DeadLetter) error {
+	data, err := json.Marshal(letter)
+	if err != nil {
+		logger.Error("Error generating dead letter json", zap.Error(err))
+		return err
+	}
+
+	resp, err := n.client.Post(n.replayURL, applicationJson, bytes.NewBuffer(data))
+	if err != nil {
+		logger.Error("Error replaying dead letter to distro",
+			zap.String("url", n.replayURL), zap.Error(err))
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		err = fmt.Errorf("distro returned status %d", resp.StatusCode)
+		logger.Error("Error replaying dead letter to distro",
+			zap.String("url", n.replayURL), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// RegistrationStats asks distro for the delivery counters of the named
+// registration. A registration distro doesn't run has no counters
+func (n *DistroNotifier) RegistrationStats(name string) (*export.RegistrationStats, error) {
+	url := n.statsURL + neturl.PathEscape(name)
+	resp, err := n.client.Get(url)
+	if err != nil {
+		logger.Error("Error getting registration stats from distro",
+			zap.String("url", url), zap.Error(err))
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		stats := &export.RegistrationStats{}
+		if err := json.NewDecoder(resp.Body).Decode(stats); err != nil {
+			logger.Error("Error parsing registration stats", zap.Error(err))
+			return nil, err
+		}
+		return stats, nil
+	case http.StatusNotFound:
+		return &export.RegistrationStats{Name: name}, nil
+	default:
+		err = fmt.Errorf("distro returned status %d", resp.StatusCode)
+		logger.Error("Error getting registration stats from distro",
+			zap.String("url", url), zap.Error(err))
+		return nil, err
+	}
+}
+
+// CheckRegistration asks distro to send a test event with the registration,
+// which doesn't need to be stored, and returns why it failed
+func (n *DistroNotifier) CheckRegistration(reg export.Registration) (*export.RegistrationCheck, error) {
+	data, err := json.Marshal(reg)
+	if err != nil {
+		logger.Error("Error generating registration json", zap.Error(err))
+		return nil, err
+	}
+
+	resp, err := n.checkClient.Post(n.checkURL, applicationJson, bytes.NewBuffer(data))
+	if err != nil {
+		logger.Error("Error checking registration with distro",
+			zap.String("url", n.checkURL), zap.Error(err))
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("distro returned status %d", resp.StatusCode)
+		logger.Error("Error checking registration with distro",
+			zap.String("url", n.checkURL), zap.Error(err))
+		return nil, err
+	}
+	check := &export.RegistrationCheck{}
+	if err := json.NewDecoder(resp.Body).Decode(check); err != nil {
+		logger.Error("Error parsing registration check", zap.Error(err))
+		return nil, err
+	}
+	return check, nil
+}
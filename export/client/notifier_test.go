@@ -0,0 +1,91 @@
+//
+// Copyright (c) 2018 Cavium
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/edgexfoundry/edgex-go/export"
+	"go.uber.org/zap"
+)
+
+func newTestNotifier(t *testing.T, serverUrl string) *DistroNotifier {
+	u, err := url.Parse(serverUrl)
+	if err != nil {
+		t.Fatalf("Error parsing the server url: %v", err)
+	}
+	port, _ := strconv.Atoi(u.Port())
+	return NewDistroNotifier(u.Hostname(), port, 1000)
+}
+
+func TestNotifyRegistrationChanged(t *testing.T) {
+	logger = zap.NewNop()
+
+	var update export.NotifyUpdate
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != distroNotifyPath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	n := newTestNotifier(t, ts.URL)
+	if err := n.NotifyRegistrationChanged(export.NotifyUpdateAdd, "reg"); err != nil {
+		t.Fatalf("Error notifying distro: %v", err)
+	}
+	if update.Name != "reg" || update.Operation != export.NotifyUpdateAdd {
+		t.Fatalf("Distro received the wrong update: %v", update)
+	}
+}
+
+func TestNotifyRegistrationChangedFailure(t *testing.T) {
+	logger = zap.NewNop()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+
+	n := newTestNotifier(t, ts.URL)
+	if err := n.NotifyRegistrationChanged(export.NotifyUpdateDelete, "reg"); err == nil {
+		t.Fatalf("A bad status from distro should return an error")
+	}
+
+	// Distro not running
+	ts.Close()
+	if err := n.NotifyRegistrationChanged(export.NotifyUpdateDelete, "reg"); err == nil {
+		t.Fatalf("An unreachable distro should return an error")
+	}
+}
+
+func TestRegistrationStatsNotRunning(t *testing.T) {
+	logger = zap.NewNop()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	n := newTestNotifier(t, ts.URL)
+	stats, err := n.RegistrationStats("reg")
+	if err != nil {
+		t.Fatalf("Error getting registration stats: %v", err)
+	}
+	if stats.Name != "reg" || stats.Running || stats.Sent != 0 {
+		t.Errorf("A registration distro doesn't run has no counters: %+v", stats)
+	}
+}
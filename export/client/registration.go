@@ -9,11 +9,9 @@
 package client
 
 import (
-	"bytes"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
-	"strconv"
 
 	"github.com/edgexfoundry/edgex-go/export"
 	"github.com/edgexfoundry/edgex-go/export/client/clients"
@@ -48,6 +46,73 @@ func getRegByID(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(&reg)
 }
 
+// Delivery counters of the registration, from distro
+func getRegStats(w http.ResponseWriter, r *http.Request) {
+	id := bone.GetValue(r, "id")
+
+	reg, err := dbc.RegistrationById(id)
+	if err != nil {
+		logger.Error("Failed to query by id", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if notifier == nil {
+		http.Error(w, "Distro not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	stats, err := notifier.RegistrationStats(reg.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", applicationJson)
+	json.NewEncoder(w).Encode(stats)
+}
+
+// Validate the registration, then have distro send it a test event. The reasons it failed
+// are in the returned check
+func checkReg(w http.ResponseWriter, reg export.Registration) {
+	check := &export.RegistrationCheck{}
+	if valid, err := reg.Validate(); !valid {
+		check.Error = err.Error()
+	} else if notifier == nil {
+		http.Error(w, "Distro not configured", http.StatusServiceUnavailable)
+		return
+	} else if check, err = notifier.CheckRegistration(reg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", applicationJson)
+	json.NewEncoder(w).Encode(check)
+}
+
+// Check the registration of the body before it's added
+func validateReg(w http.ResponseWriter, r *http.Request) {
+	reg := export.Registration{}
+	if err := json.NewDecoder(r.Body).Decode(&reg); err != nil {
+		logger.Error("Failed to parse registration", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	checkReg(w, reg)
+}
+
+// Check the stored registration
+func testReg(w http.ResponseWriter, r *http.Request) {
+	id := bone.GetValue(r, "id")
+
+	reg, err := dbc.RegistrationById(id)
+	if err != nil {
+		logger.Error("Failed to query by id", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	checkReg(w, reg)
+}
+
 func getRegList(w http.ResponseWriter, r *http.Request) {
 	t := bone.GetValue(r, "type")
 
@@ -57,16 +122,31 @@ func getRegList(w http.ResponseWriter, r *http.Request) {
 	case typeAlgorithms:
 		list = append(list, export.EncNone)
 		list = append(list, export.EncAes)
+		list = append(list, export.EncAesGcm)
+		list = append(list, export.EncRsaEnvelope)
 	case typeCompressions:
 		list = append(list, export.CompNone)
 		list = append(list, export.CompGzip)
 		list = append(list, export.CompZip)
+		list = append(list, export.CompZstd)
 	case typeFormats:
 		list = append(list, export.FormatJSON)
 		list = append(list, export.FormatXML)
+		list = append(list, export.FormatCSV)
+		list = append(list, export.FormatProtobuf)
+		list = append(list, export.FormatTemplate)
+		list = append(list, export.FormatInfluxDBLine)
+		list = append(list, export.FormatThingsBoardJSON)
 	case typeDestinations:
 		list = append(list, export.DestMQTT)
 		list = append(list, export.DestRest)
+		list = append(list, export.DestKafka)
+		list = append(list, export.DestAMQP)
+		list = append(list, export.DestAzureMQTT)
+		list = append(list, export.DestAWSIoT)
+		list = append(list, export.DestPubSub)
+		list = append(list, export.DestInfluxDB)
+		list = append(list, export.DestPostgres)
 	default:
 		logger.Error("Unknown type: " + t)
 		http.Error(w, "Unknown type: "+t, http.StatusBadRequest)
@@ -200,6 +280,9 @@ func updateReg(w http.ResponseWriter, r *http.Request) {
 	if fromReg.Filter.ValueDescriptorIDs != nil {
 		toReg.Filter.ValueDescriptorIDs = fromReg.Filter.ValueDescriptorIDs
 	}
+	if fromReg.Filter.ValueFilters != nil {
+		toReg.Filter.ValueFilters = fromReg.Filter.ValueFilters
+	}
 	if fromReg.Encryption.Algo != "" {
 		toReg.Encryption = fromReg.Encryption
 	}
@@ -277,25 +360,8 @@ func delRegByName(w http.ResponseWriter, r *http.Request) {
 }
 
 func notifyUpdatedRegistrations(update export.NotifyUpdate) {
-	go func() {
-		client := &http.Client{}
-		url := "http://" + configuration.DistroHost + ":" + strconv.Itoa(configuration.DistroPort) +
-			"/api/v1/notify/registrations"
-
-		data, err := json.Marshal(update)
-		if err != nil {
-			logger.Error("Error generating update json", zap.Error(err))
-			return
-		}
-
-		req, err := http.NewRequest(http.MethodPut, url, bytes.NewBuffer([]byte(data)))
-		if err != nil {
-			logger.Error("Error creating http request")
-			return
-		}
-		_, err = client.Do(req)
-		if err != nil {
-			logger.Error("Error notifying updated registrations to distro", zap.String("url", url))
-		}
-	}()
+	if notifier == nil {
+		return
+	}
+	go notifier.NotifyRegistrationChanged(update.Operation, update.Name)
 }
@@ -17,6 +17,7 @@ import (
 
 const (
 	apiV1Registration = "/api/v1/registration"
+	apiV1DeadLetter   = "/api/v1/deadletter"
 	apiV1Ping         = "/api/v1/ping"
 )
 
@@ -38,11 +39,22 @@ func httpServer() http.Handler {
 	mux.Get(apiV1Registration+"/reference/:type", http.HandlerFunc(getRegList))
 	mux.Get(apiV1Registration, http.HandlerFunc(getAllReg))
 	mux.Get(apiV1Registration+"/name/:name", http.HandlerFunc(getRegByName))
+	mux.Get(apiV1Registration+"/:id/stats", http.HandlerFunc(getRegStats))
 	mux.Post(apiV1Registration, http.HandlerFunc(addReg))
+	mux.Post(apiV1Registration+"/validate", http.HandlerFunc(validateReg))
+	mux.Post(apiV1Registration+"/:id/test", http.HandlerFunc(testReg))
 	mux.Put(apiV1Registration, http.HandlerFunc(updateReg))
 	mux.Delete(apiV1Registration+"/id/:id", http.HandlerFunc(delRegByID))
 	mux.Delete(apiV1Registration+"/name/:name", http.HandlerFunc(delRegByName))
 
+	// Dead letters
+	mux.Get(apiV1DeadLetter, http.HandlerFunc(getAllDeadLetters))
+	mux.Get(apiV1DeadLetter+"/:id", http.HandlerFunc(getDeadLetterByID))
+	mux.Post(apiV1DeadLetter, http.HandlerFunc(addDeadLetter))
+	mux.Post(apiV1DeadLetter+"/replay", http.HandlerFunc(replayDeadLetters))
+	mux.Post(apiV1DeadLetter+"/:id/replay", http.HandlerFunc(replayDeadLetterByID))
+	mux.Delete(apiV1DeadLetter+"/:id", http.HandlerFunc(delDeadLetterByID))
+
 	return mux
 }
 
@@ -323,6 +323,31 @@ func TestRegistrationGetList(t *testing.T) {
 	}
 }
 
+func TestRegistrationGetListFormats(t *testing.T) {
+	ts := prepareTest(t)
+	defer ts.Close()
+
+	response, err := http.Get(ts.URL + apiV1Registration + "/reference/" + typeFormats)
+	if err != nil {
+		t.Fatalf("Error getting reference type: %v", err)
+	}
+	defer response.Body.Close()
+	var formats []string
+	if err := json.NewDecoder(response.Body).Decode(&formats); err != nil {
+		t.Fatalf("Formats could not be parsed: %v", err)
+	}
+
+	listed := map[string]bool{}
+	for _, f := range formats {
+		listed[f] = true
+	}
+	for _, f := range []string{export.FormatInfluxDBLine, export.FormatThingsBoardJSON} {
+		if !listed[f] {
+			t.Errorf("The format %s should be listed: %v", f, formats)
+		}
+	}
+}
+
 func getRegistrations(t *testing.T, serverUrl string) []export.Registration {
 	response, err := http.Get(serverUrl + apiV1Registration)
 	if err != nil {
@@ -359,3 +384,149 @@ func TestRegistrationGetAll(t *testing.T) {
 		t.Errorf("There should be only one registrations: %v", regs)
 	}
 }
+
+func TestRegistrationGetStats(t *testing.T) {
+	ts := prepareTest(t)
+	defer ts.Close()
+
+	id := createRegistration(t, ts.URL)
+	url := ts.URL + apiV1Registration + "/" + id + "/stats"
+
+	response, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("Error getting registration stats: %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Returned status %d without distro, should be %d", response.StatusCode, http.StatusServiceUnavailable)
+	}
+
+	distro := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != distroStatsPath+"OSIClient" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(export.RegistrationStats{Name: "OSIClient", Running: true, Sent: 5, Failed: 1})
+	}))
+	notifier = newTestNotifier(t, distro.URL)
+	defer func() { notifier = nil }()
+
+	response, err = http.Get(url)
+	if err != nil {
+		t.Fatalf("Error getting registration stats: %v", err)
+	}
+	stats := export.RegistrationStats{}
+	json.NewDecoder(response.Body).Decode(&stats)
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK || !stats.Running || stats.Sent != 5 || stats.Failed != 1 {
+		t.Errorf("Returned status %d with stats %+v", response.StatusCode, stats)
+	}
+
+	response, err = http.Get(ts.URL + apiV1Registration + "/invalid/stats")
+	if err != nil {
+		t.Fatalf("Error getting registration stats: %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusNotFound {
+		t.Errorf("Returned status %d, should be %d", response.StatusCode, http.StatusNotFound)
+	}
+
+	distro.Close()
+	response, err = http.Get(url)
+	if err != nil {
+		t.Fatalf("Error getting registration stats: %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusBadGateway {
+		t.Errorf("Returned status %d with distro down, should be %d", response.StatusCode, http.StatusBadGateway)
+	}
+}
+
+func TestRegistrationValidate(t *testing.T) {
+	ts := prepareTest(t)
+	defer ts.Close()
+
+	validate := func(body string) (int, export.RegistrationCheck) {
+		response, err := http.Post(ts.URL+apiV1Registration+"/validate", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("Error validating registration: %v", err)
+		}
+		defer response.Body.Close()
+		check := export.RegistrationCheck{}
+		json.NewDecoder(response.Body).Decode(&check)
+		return response.StatusCode, check
+	}
+
+	if status, _ := validate("{"); status != http.StatusBadRequest {
+		t.Errorf("Returned status %d, should be %d", status, http.StatusBadRequest)
+	}
+	// The invalid fields are returned without distro
+	status, check := validate(strings.Replace(regJson, `"format":"JSON"`, `"format":"YAML"`, 1))
+	if status != http.StatusOK || check.Valid || check.Error != "Format invalid: YAML" {
+		t.Errorf("Returned status %d with %+v", status, check)
+	}
+	if status, _ := validate(regJson); status != http.StatusServiceUnavailable {
+		t.Errorf("Returned status %d without distro, should be %d", status, http.StatusServiceUnavailable)
+	}
+
+	var checked export.Registration
+	distro := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != distroCheckPath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&checked)
+		json.NewEncoder(w).Encode(export.RegistrationCheck{Valid: true, Error: "connection refused"})
+	}))
+	notifier = newTestNotifier(t, distro.URL)
+	defer func() { notifier = nil }()
+
+	status, check = validate(regJson)
+	if status != http.StatusOK || !check.Valid || check.Sent || check.Error != "connection refused" {
+		t.Errorf("Returned status %d with %+v", status, check)
+	}
+	if checked.Name != "OSIClient" {
+		t.Errorf("Distro should check the registration: %+v", checked)
+	}
+	if regs, _ := dbc.Registrations(); len(regs) != 0 {
+		t.Errorf("A validated registration should not be added: %v", regs)
+	}
+
+	distro.Close()
+	if status, _ := validate(regJson); status != http.StatusBadGateway {
+		t.Errorf("Returned status %d with distro down, should be %d", status, http.StatusBadGateway)
+	}
+}
+
+func TestRegistrationTest(t *testing.T) {
+	ts := prepareTest(t)
+	defer ts.Close()
+
+	id := createRegistration(t, ts.URL)
+	distro := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(export.RegistrationCheck{Valid: true, Sent: true})
+	}))
+	defer distro.Close()
+	notifier = newTestNotifier(t, distro.URL)
+	defer func() { notifier = nil }()
+
+	response, err := http.Post(ts.URL+apiV1Registration+"/"+id+"/test", "application/json", nil)
+	if err != nil {
+		t.Fatalf("Error testing registration: %v", err)
+	}
+	check := export.RegistrationCheck{}
+	json.NewDecoder(response.Body).Decode(&check)
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK || !check.Sent {
+		t.Errorf("Returned status %d with %+v", response.StatusCode, check)
+	}
+
+	response, err = http.Post(ts.URL+apiV1Registration+"/invalid/test", "application/json", nil)
+	if err != nil {
+		t.Fatalf("Error testing registration: %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusNotFound {
+		t.Errorf("Returned status %d, should be %d", response.StatusCode, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,52 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package export
+
+import (
+	"fmt"
+)
+
+// Columns of the CSV rows, one per reading
+const (
+	CSVColumnID       = "id"
+	CSVColumnDevice   = "device"
+	CSVColumnName     = "name"
+	CSVColumnValue    = "value"
+	CSVColumnCreated  = "created"
+	CSVColumnModified = "modified"
+	CSVColumnOrigin   = "origin"
+	CSVColumnPushed   = "pushed"
+)
+
+// CSVDetails - Columns of the rows of the CSV format, in order. The rows have the device,
+// name, value, created and origin of the readings when there are none
+type CSVDetails struct {
+	Columns []string `json:"columns,omitempty"`
+}
+
+func (c CSVDetails) validate(format string) error {
+	if len(c.Columns) == 0 {
+		return nil
+	}
+	if format != FormatCSV {
+		return fmt.Errorf("CSV columns require the %s format", FormatCSV)
+	}
+	columns := make(map[string]bool)
+	for _, column := range c.Columns {
+		switch column {
+		case CSVColumnID, CSVColumnDevice, CSVColumnName, CSVColumnValue,
+			CSVColumnCreated, CSVColumnModified, CSVColumnOrigin, CSVColumnPushed:
+		default:
+			return fmt.Errorf("CSV column invalid: %s", column)
+		}
+		if columns[column] {
+			return fmt.Errorf("CSV column not unique: %s", column)
+		}
+		columns[column] = true
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package export
+
+import (
+	"gopkg.in/mgo.v2/bson"
+)
+
+// DeadLetter - Payload a registration couldn't deliver once its retries were exhausted
+// The payload is formatted, compressed and encrypted as it was sent, and is sent again as
+// is when replayed
+type DeadLetter struct {
+	ID           bson.ObjectId `bson:"_id,omitempty" json:"id,omitempty"`
+	Registration string        `json:"registration"`
+	Destination  string        `json:"destination"`
+	Payload      []byte        `json:"payload"`
+	Error        string        `json:"error"`
+	Attempts     int           `json:"attempts"`
+	Created      int64         `json:"created"` // When the last attempt failed, in milliseconds
+}
@@ -0,0 +1,151 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+)
+
+// Values of the readings of a device and value descriptor in the window
+type aggregate struct {
+	device string
+	name   string
+	count  int
+	sum    float64
+	min    float64
+	max    float64
+}
+
+func (a *aggregate) add(value float64) {
+	if a.count == 0 || value < a.min {
+		a.min = value
+	}
+	if a.count == 0 || value > a.max {
+		a.max = value
+	}
+	a.count++
+	a.sum += value
+}
+
+func (a *aggregate) value(function string) float64 {
+	switch function {
+	case export.AggregateAvg:
+		return a.sum / float64(a.count)
+	case export.AggregateMin:
+		return a.min
+	case export.AggregateMax:
+		return a.max
+	default:
+		return float64(a.count)
+	}
+}
+
+// Aggregates the numeric readings of the events it takes, and makes the aggregated events
+// once the window ends. The readings that aren't numbers are dropped
+type aggregateStage struct {
+	window     time.Duration
+	functions  []string
+	ticker     *time.Ticker          // Nil until the registration is configured
+	aggregates map[string]*aggregate // By device and value descriptor
+}
+
+func newAggregateStage(details export.AggregationDetails) *aggregateStage {
+	functions := details.Functions
+	if len(functions) == 0 {
+		functions = []string{export.AggregateAvg, export.AggregateMin, export.AggregateMax, export.AggregateCount}
+	}
+	return &aggregateStage{
+		window:     time.Duration(details.Window) * time.Millisecond,
+		functions:  functions,
+		aggregates: make(map[string]*aggregate),
+	}
+}
+
+// Filter takes the event, the next stages get the aggregated events
+func (stage *aggregateStage) Filter(event *models.Event) (bool, *models.Event) {
+	if event == nil {
+		return false, nil
+	}
+	for _, reading := range event.Readings {
+		value, err := strconv.ParseFloat(reading.Value, 64)
+		if err != nil {
+			continue
+		}
+		device := reading.Device
+		if device == "" {
+			device = event.Device
+		}
+		key := device + "/" + reading.Name
+		a, ok := stage.aggregates[key]
+		if !ok {
+			a = &aggregate{device: device, name: reading.Name}
+			stage.aggregates[key] = a
+		}
+		a.add(value)
+	}
+	return false, event
+}
+
+// Events of the window ending, one per device, and start the next window
+func (stage *aggregateStage) take() []*models.Event {
+	if len(stage.aggregates) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(stage.aggregates))
+	for k := range stage.aggregates {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	var events []*models.Event
+	devices := make(map[string]*models.Event)
+	for _, k := range keys {
+		a := stage.aggregates[k]
+		event, ok := devices[a.device]
+		if !ok {
+			event = &models.Event{Device: a.device, Origin: now}
+			devices[a.device] = event
+			events = append(events, event)
+		}
+		for _, f := range stage.functions {
+			event.Readings = append(event.Readings, models.Reading{
+				Device: a.device,
+				Name:   a.name + "_" + strings.ToLower(f),
+				Value:  strconv.FormatFloat(a.value(f), 'f', -1, 64),
+				Origin: now,
+			})
+		}
+	}
+	stage.aggregates = make(map[string]*aggregate)
+	return events
+}
+
+// Start the first window
+func (stage *aggregateStage) start() {
+	stage.ticker = time.NewTicker(stage.window)
+}
+
+// Ticks when the window ends, never without an aggregation
+func (stage *aggregateStage) tick() <-chan time.Time {
+	if stage == nil || stage.ticker == nil {
+		return nil
+	}
+	return stage.ticker.C
+}
+
+func (stage *aggregateStage) stop() {
+	if stage != nil && stage.ticker != nil {
+		stage.ticker.Stop()
+	}
+}
@@ -0,0 +1,118 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+	"go.uber.org/zap"
+)
+
+func TestAggregateStage(t *testing.T) {
+	stage := newAggregateStage(export.AggregationDetails{Window: 1000})
+	if events := stage.take(); events != nil {
+		t.Fatalf("An empty window should make no event: %v", events)
+	}
+
+	for _, value := range []string{"20", "23", "17", "n/a"} {
+		if ok, _ := stage.Filter(&models.Event{Device: "dev", Readings: []models.Reading{{Name: "temperature", Value: value}}}); ok {
+			t.Fatal("The event should be aggregated")
+		}
+	}
+	stage.Filter(&models.Event{Device: "other", Readings: []models.Reading{{Name: "temperature", Value: "5"}}})
+
+	events := stage.take()
+	if len(events) != 2 || events[0].Device != "dev" || events[1].Device != "other" {
+		t.Fatalf("There should be an event per device: %v", events)
+	}
+	expected := map[string]string{
+		"temperature_avg":   "20",
+		"temperature_min":   "17",
+		"temperature_max":   "23",
+		"temperature_count": "3",
+	}
+	if len(events[0].Readings) != len(expected) {
+		t.Fatalf("Unexpected readings: %v", events[0].Readings)
+	}
+	for _, r := range events[0].Readings {
+		if expected[r.Name] != r.Value || r.Device != "dev" || r.Origin != events[0].Origin {
+			t.Errorf("Unexpected reading: %+v", r)
+		}
+	}
+	if events := stage.take(); events != nil {
+		t.Errorf("The next window should start empty: %v", events)
+	}
+}
+
+func TestAggregateStageFunctions(t *testing.T) {
+	stage := newAggregateStage(export.AggregationDetails{Window: 1000, Functions: []string{export.AggregateMax}})
+	stage.Filter(&models.Event{Device: "dev", Readings: []models.Reading{
+		{Name: "pressure", Value: "1.5"},
+		{Name: "pressure", Value: "-2"},
+	}})
+	events := stage.take()
+	if len(events) != 1 || len(events[0].Readings) != 1 || events[0].Readings[0].Name != "pressure_max" || events[0].Readings[0].Value != "1.5" {
+		t.Errorf("Only the max should be computed: %v", events)
+	}
+}
+
+func TestRegistrationInfoAggregate(t *testing.T) {
+	logger = zap.NewNop()
+
+	run := func(window int, events ...*models.Event) [][]byte {
+		r := validRegistration()
+		r.Filter = export.Filter{}
+		r.Pipeline = []export.TransformStage{
+			{Name: "window", Type: export.StageAggregate, Aggregation: &export.AggregationDetails{Window: window, Functions: []string{export.AggregateAvg}}},
+			{Name: "site", Type: export.StageEnrich, Readings: map[string]string{"site": "plant-1"}},
+		}
+		reg := newRegistrationInfo()
+		if err := reg.configure(r); err != nil {
+			t.Fatalf("Error configuring the registration: %v", err)
+		}
+		sender := &batchSender{}
+		reg.sender = sender
+
+		done := make(chan struct{})
+		go func() {
+			registrationLoop(reg)
+			close(done)
+		}()
+		for _, event := range events {
+			reg.chEvent <- event
+		}
+		time.Sleep(50 * time.Millisecond)
+		reg.chRegistration <- nil
+		<-done
+		return sender.payloads
+	}
+
+	// The window ends when the registration is removed
+	payloads := run(60000,
+		&models.Event{Device: "dev", Readings: []models.Reading{{Name: "temperature", Value: "20"}}},
+		&models.Event{Device: "dev", Readings: []models.Reading{{Name: "temperature", Value: "22"}}})
+	if len(payloads) != 1 {
+		t.Fatalf("The window should make a single payload: %q", payloads)
+	}
+	event := models.Event{}
+	if err := json.Unmarshal(payloads[0], &event); err != nil {
+		t.Fatalf("Error parsing the payload: %v", err)
+	}
+	if len(event.Readings) != 2 || event.Readings[0].Name != "temperature_avg" || event.Readings[0].Value != "21" || event.Readings[1].Name != "site" {
+		t.Errorf("The stages after the aggregation should get the aggregated event: %+v", event.Readings)
+	}
+
+	// The window ends on time
+	payloads = run(10, &models.Event{Device: "dev", Readings: []models.Reading{{Name: "temperature", Value: "20"}}})
+	if len(payloads) != 1 {
+		t.Errorf("The window should be sent once it ends: %q", payloads)
+	}
+}
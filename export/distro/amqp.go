@@ -0,0 +1,181 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+const amqpConfirmTimeout = 10 * time.Second
+
+type amqpSender struct {
+	senderStats
+	uri          string
+	tlsConfig    *tls.Config // nil - without TLS
+	exchange     string
+	exchangeType string
+	routingKey   string
+	conn         *amqp.Connection
+	channel      *amqp.Channel
+}
+
+// NewAMQPSender - create new amqp sender
+// The events are published persistent and mandatory, and each one waits for the confirm of
+// the broker
+func NewAMQPSender(addr models.Addressable, details export.AMQPDetails) Sender {
+	uri := amqp.URI{
+		Scheme:   "amqp",
+		Host:     addr.Address,
+		Port:     addr.Port,
+		Username: addr.User,
+		Password: addr.Password,
+		Vhost:    strings.TrimPrefix(addr.Path, "/"),
+	}
+	if uri.Vhost == "" {
+		uri.Vhost = "/"
+	}
+
+	sender := &amqpSender{
+		exchange:     details.Exchange,
+		exchangeType: details.ExchangeType,
+		routingKey:   details.RoutingKey,
+	}
+	if sender.routingKey == "" {
+		sender.routingKey = addr.Topic
+	}
+
+	protocol := strings.ToLower(addr.Protocol)
+	if protocol == "amqps" ||
+		protocol == "ssl" ||
+		protocol == "tls" {
+
+		tlsConfig, err := newTLSConfig(configuration.AMQPCACert, configuration.AMQPCert,
+			configuration.AMQPKey, details.TLSSkipVerify)
+		if err != nil {
+			logger.Error("Invalid amqp TLS configuration", zap.Error(err))
+			return nil
+		}
+		uri.Scheme = "amqps"
+		sender.tlsConfig = tlsConfig
+	}
+	if uri.Port == 0 {
+		uri.Port = 5672
+		if uri.Scheme == "amqps" {
+			uri.Port = 5671
+		}
+	}
+	sender.uri = uri.String()
+
+	return sender
+}
+
+func (sender *amqpSender) connect() error {
+	var err error
+	if sender.tlsConfig != nil {
+		sender.conn, err = amqp.DialTLS(sender.uri, sender.tlsConfig)
+	} else {
+		sender.conn, err = amqp.Dial(sender.uri)
+	}
+	if err != nil {
+		return err
+	}
+
+	if sender.channel, err = sender.conn.Channel(); err == nil {
+		err = sender.channel.Confirm(false)
+	}
+	if err == nil && sender.exchangeType != "" {
+		err = sender.channel.ExchangeDeclare(sender.exchange, sender.exchangeType, true, false, false, false, nil)
+	}
+	if err != nil {
+		sender.Close()
+		return err
+	}
+
+	// The broker returns the mandatory messages no queue is bound for
+	returns := sender.channel.NotifyReturn(make(chan amqp.Return, 1))
+	go func() {
+		for r := range returns {
+			logger.Warn("amqp message returned: ", zap.String("exchange", r.Exchange),
+				zap.String("routingKey", r.RoutingKey), zap.String("reason", r.ReplyText))
+		}
+	}()
+	return nil
+}
+
+func (sender *amqpSender) Send(data []byte) {
+	sender.SendEvent(data, nil)
+}
+
+// SendEvent - send the data with the routing key of the event
+func (sender *amqpSender) SendEvent(data []byte, event *models.Event) {
+	if sender.conn == nil || sender.conn.IsClosed() {
+		logger.Info("Connecting to amqp broker")
+		if err := sender.connect(); err != nil {
+			logger.Warn("Could not connect to amqp broker", zap.Error(err))
+			sender.countFailed(1, err)
+			sender.deadLetterPayload(data, err, 1)
+			return
+		}
+	}
+
+	key := sender.routingKey
+	if event != nil {
+		key = eventKey(key, event)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), amqpConfirmTimeout)
+	defer cancel()
+	confirm, err := sender.channel.PublishWithDeferredConfirmWithContext(ctx, sender.exchange, key, true, false,
+		amqp.Publishing{
+			DeliveryMode: amqp.Persistent,
+			Timestamp:    time.Now(),
+			Body:         data,
+		})
+	if err == nil {
+		var acked bool
+		if acked, err = confirm.WaitContext(ctx); err == nil && !acked {
+			logger.Warn("amqp broker refused the event", zap.String("routingKey", key))
+			err = errors.New("amqp broker refused the event")
+			sender.countFailed(1, err)
+			sender.deadLetterPayload(data, err, 1)
+			return
+		}
+	}
+	if err != nil {
+		// Reconnect for the next event, the channel can't be trusted after a failure
+		logger.Warn("amqp error: ", zap.Error(err))
+		sender.countFailed(1, err)
+		sender.deadLetterPayload(data, err, 1)
+		sender.Close()
+		return
+	}
+	logger.Debug("Sent data: ", zap.ByteString("data", data))
+	sender.countSent(1)
+}
+
+// Close - close the connection, so a replaced registration doesn't leak it
+func (sender *amqpSender) Close() error {
+	if sender.conn == nil {
+		return nil
+	}
+	err := sender.conn.Close()
+	sender.conn = nil
+	sender.channel = nil
+	if err == amqp.ErrClosed {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,139 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	MQTT "github.com/eclipse/paho.mqtt.golang"
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+	"go.uber.org/zap"
+)
+
+const (
+	awsIoTPort       = 8883
+	awsIoTALPNPort   = 443 // MQTT over TLS on 443 needs the ALPN protocol of AWS IoT
+	awsIoTALPN       = "x-amzn-mqtt-ca"
+	awsIoTMinBackoff = time.Second
+	awsIoTMaxBackoff = 2 * time.Minute
+	awsIoTTimeout    = 10 * time.Second
+)
+
+type awsIoTSender struct {
+	senderStats
+	client  MQTT.Client
+	topic   string
+	qos     byte
+	backoff time.Duration // Before the next connection attempt after a failure
+	retryAt time.Time     // The events are dropped until then
+}
+
+// NewAWSIoTSender - create new AWS IoT Core sender
+// It connects with the X.509 client certificate of the thing, and after a failed connection
+// drops the events until the next attempt, waiting twice longer after each failure
+func NewAWSIoTSender(addr models.Addressable, details export.AWSIoTDetails) Sender {
+	tlsConfig, err := details.TLSConfig(addr.Address)
+	if err != nil {
+		logger.Error("Invalid AWS IoT certificates", zap.Error(err))
+		return nil
+	}
+	port := addr.Port
+	if port == 0 {
+		port = awsIoTPort
+	}
+	if port == awsIoTALPNPort {
+		tlsConfig.NextProtos = []string{awsIoTALPN}
+	}
+
+	opts := MQTT.NewClientOptions()
+	opts.AddBroker("ssl://" + addr.Address + ":" + strconv.Itoa(port))
+	opts.SetClientID(addr.Publisher)
+	opts.SetTLSConfig(tlsConfig)
+	opts.SetProtocolVersion(4)
+	opts.SetAutoReconnect(false)
+	opts.SetConnectTimeout(awsIoTTimeout)
+
+	sender := &awsIoTSender{
+		client: MQTT.NewClient(opts),
+		topic:  addr.Topic,
+		qos:    byte(details.QoS),
+	}
+
+	return sender
+}
+
+// Connect unless waiting for the next attempt, false when not connected
+func (sender *awsIoTSender) connect(now time.Time) bool {
+	if sender.client.IsConnected() {
+		return true
+	}
+	if now.Before(sender.retryAt) {
+		return false
+	}
+
+	logger.Info("Connecting to AWS IoT")
+	token := sender.client.Connect()
+	if !token.WaitTimeout(awsIoTTimeout) || token.Error() != nil {
+		sender.backoff *= 2
+		if sender.backoff < awsIoTMinBackoff {
+			sender.backoff = awsIoTMinBackoff
+		} else if sender.backoff > awsIoTMaxBackoff {
+			sender.backoff = awsIoTMaxBackoff
+		}
+		sender.retryAt = now.Add(sender.backoff)
+		logger.Warn("Could not connect to AWS IoT", zap.Error(token.Error()), zap.Duration("retry", sender.backoff))
+		return false
+	}
+	sender.backoff = 0
+	sender.retryAt = time.Time{}
+	return true
+}
+
+func (sender *awsIoTSender) Send(data []byte) {
+	sender.SendEvent(data, nil)
+}
+
+// SendEvent - send the data to the topic of the event
+func (sender *awsIoTSender) SendEvent(data []byte, event *models.Event) {
+	if !sender.connect(time.Now()) {
+		logger.Warn("Not connected to AWS IoT")
+		err := errors.New("not connected to AWS IoT")
+		sender.countFailed(1, err)
+		sender.deadLetterPayload(data, err, 1)
+		return
+	}
+
+	topic := sender.topic
+	if event != nil {
+		topic = eventKey(topic, event)
+	}
+	token := sender.client.Publish(topic, sender.qos, false, data)
+	if !token.WaitTimeout(awsIoTTimeout) {
+		logger.Warn("AWS IoT publish timed out")
+		err := errors.New("AWS IoT publish timed out")
+		sender.countFailed(1, err)
+		sender.deadLetterPayload(data, err, 1)
+	} else if token.Error() != nil {
+		logger.Warn("AWS IoT error: ", zap.Error(token.Error()))
+		sender.countFailed(1, token.Error())
+		sender.deadLetterPayload(data, token.Error(), 1)
+	} else {
+		logger.Debug("Sent data: ", zap.ByteString("data", data))
+		sender.countSent(1)
+	}
+}
+
+// Close - disconnect, so a replaced registration doesn't leak the connection
+func (sender *awsIoTSender) Close() error {
+	if sender.client.IsConnected() {
+		sender.client.Disconnect(250)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+	"go.uber.org/zap"
+)
+
+// Self signed certificate and key of a thing, in PEM
+func testThingCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating the key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "thing"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Error creating the certificate: %v", err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Error encoding the key: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}))
+}
+
+func TestAWSIoTSender(t *testing.T) {
+	logger = zap.NewNop()
+	cert, key := testThingCertificate(t)
+	addr := models.Addressable{Address: "abc-ats.iot.us-east-1.amazonaws.com", Publisher: "thing", Topic: "edgex/{device}"}
+
+	if NewAWSIoTSender(addr, export.AWSIoTDetails{Certificate: cert}) != nil {
+		t.Fatal("A certificate without its key should not create a sender")
+	}
+
+	sender := NewAWSIoTSender(addr, export.AWSIoTDetails{Certificate: cert, PrivateKey: key, QoS: 1}).(*awsIoTSender)
+	options := sender.client.OptionsReader()
+	tlsConfig := options.TLSConfig()
+	if options.Servers()[0].String() != "ssl://abc-ats.iot.us-east-1.amazonaws.com:8883" ||
+		len(tlsConfig.Certificates) != 1 || len(tlsConfig.NextProtos) != 0 || sender.qos != 1 {
+		t.Fatalf("The sender should connect to the endpoint on 8883 with the client certificate")
+	}
+
+	addr.Port = 443
+	sender = NewAWSIoTSender(addr, export.AWSIoTDetails{Certificate: cert, PrivateKey: key}).(*awsIoTSender)
+	options = sender.client.OptionsReader()
+	if protos := options.TLSConfig().NextProtos; len(protos) != 1 || protos[0] != awsIoTALPN {
+		t.Fatalf("Port 443 should use the AWS IoT ALPN protocol, not %v", protos)
+	}
+}
+
+func TestAWSIoTBackoff(t *testing.T) {
+	logger = zap.NewNop()
+	cert, key := testThingCertificate(t)
+
+	// Nothing listens on the port
+	addr := models.Addressable{Address: "127.0.0.1", Port: 1, Publisher: "thing", Topic: "edgex"}
+	sender := NewAWSIoTSender(addr, export.AWSIoTDetails{Certificate: cert, PrivateKey: key}).(*awsIoTSender)
+
+	now := time.Now()
+	if sender.connect(now) || sender.backoff != awsIoTMinBackoff || !sender.retryAt.Equal(now.Add(awsIoTMinBackoff)) {
+		t.Fatalf("A failed connection should wait %v, not %v", awsIoTMinBackoff, sender.backoff)
+	}
+	if sender.connect(now.Add(awsIoTMinBackoff/2)) || sender.backoff != awsIoTMinBackoff {
+		t.Fatal("No connection should be attempted before the retry time")
+	}
+	if sender.connect(now.Add(awsIoTMinBackoff)) || sender.backoff != 2*awsIoTMinBackoff {
+		t.Fatalf("The backoff should double after each failure, not %v", sender.backoff)
+	}
+
+	sender.backoff = awsIoTMaxBackoff
+	sender.retryAt = time.Time{}
+	if sender.connect(now) || sender.backoff != awsIoTMaxBackoff {
+		t.Fatalf("The backoff should not exceed %v, not %v", awsIoTMaxBackoff, sender.backoff)
+	}
+}
@@ -0,0 +1,142 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	MQTT "github.com/eclipse/paho.mqtt.golang"
+	"github.com/edgexfoundry/edgex-go/export"
+	"go.uber.org/zap"
+)
+
+const (
+	azureAPIVersion      = "2018-06-30"
+	azureMQTTPort        = 8883
+	azureDefaultTokenTTL = time.Hour
+)
+
+type azureSender struct {
+	senderStats
+	client   MQTT.Client
+	conn     export.AzureConnection
+	username string
+	topic    string
+	ttl      time.Duration
+	expiry   time.Time // Of the token the client connected with
+}
+
+// NewAzureSender - create new Azure IoT Hub sender
+// The events are sent over MQTT as the device of the connection string, authenticated with
+// SAS tokens generated from its key, and the client reconnects with a new token before the
+// one it connected with expires
+func NewAzureSender(details export.AzureDetails) Sender {
+	conn, err := export.ParseAzureConnectionString(details.ConnectionString)
+	if err != nil {
+		logger.Error("Invalid azure connection string", zap.Error(err))
+		return nil
+	}
+
+	sender := &azureSender{
+		conn:     conn,
+		username: conn.HostName + "/" + conn.DeviceID + "/?api-version=" + azureAPIVersion,
+		topic:    "devices/" + conn.DeviceID + "/messages/events/",
+		ttl:      time.Duration(details.TokenTTL) * time.Second,
+	}
+	if sender.ttl <= 0 {
+		sender.ttl = azureDefaultTokenTTL
+	}
+
+	opts := MQTT.NewClientOptions()
+	opts.AddBroker("ssl://" + conn.HostName + ":" + strconv.Itoa(azureMQTTPort))
+	opts.SetClientID(conn.DeviceID)
+	opts.SetCredentialsProvider(sender.credentials)
+	opts.SetProtocolVersion(4)
+	opts.SetAutoReconnect(false)
+	opts.SetTLSConfig(&tls.Config{ServerName: conn.HostName})
+	sender.client = MQTT.NewClient(opts)
+
+	return sender
+}
+
+// Credentials of each connection, with a new token
+func (sender *azureSender) credentials() (string, string) {
+	sender.expiry = time.Now().Add(sender.ttl)
+	return sender.username, azureSASToken(sender.conn, sender.expiry)
+}
+
+// Escape like encodeURIComponent, which the IoT Hub samples sign the resource with
+var uriComponentEscaper = strings.NewReplacer("+", "%20", "%21", "!", "%27", "'", "%28", "(", "%29", ")", "%2A", "*")
+
+func escapeURIComponent(s string) string {
+	return uriComponentEscaper.Replace(url.QueryEscape(s))
+}
+
+// SAS token of the device, valid until the expiry
+func azureSASToken(conn export.AzureConnection, expiry time.Time) string {
+	resource := escapeURIComponent(conn.HostName + "/devices/" + conn.DeviceID)
+	se := strconv.FormatInt(expiry.Unix(), 10)
+
+	mac := hmac.New(sha256.New, conn.SharedAccessKey)
+	mac.Write([]byte(resource + "\n" + se))
+	sig := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	return "SharedAccessSignature sr=" + resource + "&sig=" + escapeURIComponent(sig) + "&se=" + se
+}
+
+// The token is renewed once four fifths of its validity have passed
+func (sender *azureSender) tokenExpiring(now time.Time) bool {
+	return !now.Before(sender.expiry.Add(-sender.ttl / 5))
+}
+
+func (sender *azureSender) Send(data []byte) {
+	if err := sender.forward(data); err != nil {
+		sender.deadLetterPayload(data, err, 1)
+	}
+}
+
+// forward - publish the payload, the error says the hub didn't take it
+func (sender *azureSender) forward(data []byte) error {
+	if sender.client.IsConnected() && sender.tokenExpiring(time.Now()) {
+		logger.Info("Renewing the azure SAS token")
+		sender.client.Disconnect(250)
+	}
+	if !sender.client.IsConnected() {
+		logger.Info("Connecting to azure iot hub")
+		if token := sender.client.Connect(); token.Wait() && token.Error() != nil {
+			logger.Warn("Could not connect to azure iot hub", zap.Error(token.Error()))
+			sender.countFailed(1, token.Error())
+			return token.Error()
+		}
+	}
+
+	token := sender.client.Publish(sender.topic, 1, false, data)
+	token.Wait()
+	if token.Error() != nil {
+		logger.Warn("azure error: ", zap.Error(token.Error()))
+		sender.countFailed(1, token.Error())
+		return token.Error()
+	}
+	logger.Debug("Sent data: ", zap.ByteString("data", data))
+	sender.countSent(1)
+	return nil
+}
+
+// Close - disconnect, so a replaced registration doesn't leak the connection
+func (sender *azureSender) Close() error {
+	if sender.client.IsConnected() {
+		sender.client.Disconnect(250)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/export"
+	"go.uber.org/zap"
+)
+
+const testAzureConnectionString = "HostName=myhub.azure-devices.net;DeviceId=thermo stat(1);SharedAccessKey=c2VjcmV0IGtleSBvZiB0aGUgZGV2aWNl"
+
+func TestAzureSASToken(t *testing.T) {
+	conn, err := export.ParseAzureConnectionString(testAzureConnectionString)
+	if err != nil {
+		t.Fatalf("Error parsing the connection string: %v", err)
+	}
+
+	token := azureSASToken(conn, time.Unix(1538524800, 0))
+	expected := "SharedAccessSignature sr=myhub.azure-devices.net%2Fdevices%2Fthermo%20stat(1)" +
+		"&sig=oLeL%2F%2Bk5yKf2NR1JhCB357x5OeHHkZUdmn967RN%2FhjE%3D&se=1538524800"
+	if token != expected {
+		t.Fatalf("SAS token should be %s, not %s", expected, token)
+	}
+}
+
+func TestAzureSender(t *testing.T) {
+	logger = zap.NewNop()
+
+	if NewAzureSender(export.AzureDetails{ConnectionString: "HostName=myhub.azure-devices.net"}) != nil {
+		t.Fatal("An incomplete connection string should not create a sender")
+	}
+
+	sender := NewAzureSender(export.AzureDetails{ConnectionString: testAzureConnectionString, TokenTTL: 600}).(*azureSender)
+	if sender.username != "myhub.azure-devices.net/thermo stat(1)/?api-version="+azureAPIVersion ||
+		sender.topic != "devices/thermo stat(1)/messages/events/" {
+		t.Fatalf("Unexpected username %s or topic %s", sender.username, sender.topic)
+	}
+
+	// Each connection gets a new token, renewed after four fifths of its validity
+	before := time.Now()
+	username, password := sender.credentials()
+	if username != sender.username || password == "" {
+		t.Fatalf("The credentials should be the username and a token, not %s %s", username, password)
+	}
+	if sender.expiry.Before(before.Add(10 * time.Minute)) {
+		t.Fatalf("The token should be valid for the TTL, it expires at %v", sender.expiry)
+	}
+	if sender.tokenExpiring(before.Add(7 * time.Minute)) {
+		t.Fatal("The token should not be renewed yet")
+	}
+	if !sender.tokenExpiring(before.Add(9 * time.Minute)) {
+		t.Fatal("The token should be renewed before it expires")
+	}
+}
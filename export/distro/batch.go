@@ -0,0 +1,69 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+)
+
+const (
+	batchSize     = 100
+	batchInterval = 10 * time.Second
+)
+
+// Events of a registration waiting to be sent in a single payload
+type eventBatch struct {
+	size   int
+	ticker *time.Ticker
+	events []*models.Event
+}
+
+// Batch of the registration, nil when it sends the events one by one
+func newEventBatch(details export.BatchDetails) *eventBatch {
+	if !details.Enabled() {
+		return nil
+	}
+	size := details.Size
+	if size == 0 {
+		size = batchSize
+	}
+	interval := time.Duration(details.Interval) * time.Millisecond
+	if interval == 0 {
+		interval = batchInterval
+	}
+	return &eventBatch{size: size, ticker: time.NewTicker(interval)}
+}
+
+// Add the event, and return whether the batch is full
+func (b *eventBatch) add(event *models.Event) bool {
+	b.events = append(b.events, event)
+	return len(b.events) >= b.size
+}
+
+// Take the events to send
+func (b *eventBatch) take() []*models.Event {
+	events := b.events
+	b.events = nil
+	return events
+}
+
+// Ticks when the events waited long enough, never without a batch
+func (b *eventBatch) tick() <-chan time.Time {
+	if b == nil {
+		return nil
+	}
+	return b.ticker.C
+}
+
+func (b *eventBatch) stop() {
+	if b != nil {
+		b.ticker.Stop()
+	}
+}
@@ -0,0 +1,93 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+	"go.uber.org/zap"
+)
+
+type batchSender struct {
+	payloads [][]byte
+}
+
+func (sender *batchSender) Send(data []byte) {
+	sender.payloads = append(sender.payloads, data)
+}
+
+func TestNewEventBatch(t *testing.T) {
+	if b := newEventBatch(export.BatchDetails{}); b != nil || b.tick() != nil {
+		t.Fatal("The events should be sent one by one")
+	}
+
+	b := newEventBatch(export.BatchDetails{Interval: 50})
+	defer b.stop()
+	if b.size != batchSize {
+		t.Errorf("Batch size %d, should default to %d", b.size, batchSize)
+	}
+	select {
+	case <-b.tick():
+	case <-time.After(time.Second):
+		t.Error("The batch should tick once its interval passed")
+	}
+}
+
+func TestRegistrationInfoBatch(t *testing.T) {
+	logger = zap.NewNop()
+
+	r := validRegistration()
+	r.Filter = export.Filter{}
+	r.Batch = export.BatchDetails{Size: 3, Interval: 60000}
+	reg := newRegistrationInfo()
+	if err := reg.configure(r); err != nil {
+		t.Fatalf("Error configuring the registration: %v", err)
+	}
+	defer reg.batch.stop()
+	sender := &batchSender{}
+	reg.sender = sender
+
+	for i := 1; i <= 4; i++ {
+		reg.processEvent(&models.Event{Device: "dev", Origin: int64(i)})
+	}
+	if len(sender.payloads) != 1 {
+		t.Fatalf("A full batch should be sent in a single payload, %d sent", len(sender.payloads))
+	}
+	var events []models.Event
+	if err := json.Unmarshal(sender.payloads[0], &events); err != nil || len(events) != 3 || events[2].Origin != 3 {
+		t.Fatalf("The payload should be the array of the events: %s", sender.payloads[0])
+	}
+
+	// Pending events are sent before the registration changes
+	r.Batch = export.BatchDetails{}
+	if err := reg.configure(r); err != nil {
+		t.Fatalf("Error configuring the registration: %v", err)
+	}
+	if len(sender.payloads) != 2 || reg.batch != nil {
+		t.Fatalf("The pending event should be sent, %d payloads", len(sender.payloads))
+	}
+	if err := json.Unmarshal(sender.payloads[1], &events); err != nil || len(events) != 1 || events[0].Origin != 4 {
+		t.Errorf("The payload should be the pending event: %s", sender.payloads[1])
+	}
+}
+
+func TestRegistrationInfoBatchFormat(t *testing.T) {
+	logger = zap.NewNop()
+
+	r := validRegistration()
+	r.Format = export.FormatInfluxDBLine
+	r.Batch = export.BatchDetails{Size: 3}
+	reg := newRegistrationInfo()
+	defer reg.closeSender()
+	if err := reg.configure(r); err == nil {
+		t.Error("The InfluxDB line format can't batch the events")
+	}
+}
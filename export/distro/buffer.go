@@ -0,0 +1,188 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/export"
+	"go.uber.org/zap"
+)
+
+const (
+	bufferMaxSize  = 100 << 20
+	bufferInterval = 10 * time.Second
+	bufferExt      = ".buf"
+)
+
+// Payload stored in a file named after the nanoseconds it was stored at
+type bufferedPayload struct {
+	path    string
+	size    int64
+	created time.Time
+}
+
+// Payloads of a registration its destination didn't take, stored in its directory of the
+// BufferDir until they're forwarded, expire or the newer ones fill the buffer
+type payloadBuffer struct {
+	dir      string
+	maxSize  int64
+	maxAge   time.Duration
+	ticker   *time.Ticker
+	payloads []bufferedPayload // Oldest first
+	size     int64
+	last     int64 // Nanoseconds of the newest payload, the names keep the order
+}
+
+// Buffer of the registration, with the payloads it stored before distro restarted or the
+// registration was updated
+func newPayloadBuffer(root string, registration string, details export.BufferDetails) (*payloadBuffer, error) {
+	if root == "" {
+		return nil, fmt.Errorf("Buffer requires the BufferDir of export distro")
+	}
+	b := &payloadBuffer{
+		dir:     filepath.Join(root, deadLetterUnsafe.ReplaceAllString(registration, "_")),
+		maxSize: details.MaxSize,
+		maxAge:  time.Duration(details.MaxAge) * time.Millisecond,
+	}
+	if b.maxSize == 0 {
+		b.maxSize = bufferMaxSize
+	}
+	if err := os.MkdirAll(b.dir, os.FileMode(0700)); err != nil {
+		return nil, fmt.Errorf("Could not create the buffer: %v", err)
+	}
+	files, err := ioutil.ReadDir(b.dir)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read the buffer: %v", err)
+	}
+	// Sorted by name, so the oldest first
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), bufferExt) {
+			continue
+		}
+		nanos, err := strconv.ParseInt(strings.TrimSuffix(f.Name(), bufferExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		b.payloads = append(b.payloads, bufferedPayload{
+			path:    filepath.Join(b.dir, f.Name()),
+			size:    f.Size(),
+			created: time.Unix(0, nanos),
+		})
+		b.size += f.Size()
+		b.last = nanos
+	}
+	b.trim(time.Now())
+	if len(b.payloads) > 0 {
+		logger.Info("Buffered payloads to forward", zap.String("registration", registration),
+			zap.Int("payloads", len(b.payloads)))
+	}
+
+	interval := time.Duration(details.Interval) * time.Millisecond
+	if interval == 0 {
+		interval = bufferInterval
+	}
+	b.ticker = time.NewTicker(interval)
+	return b, nil
+}
+
+func (b *payloadBuffer) empty() bool {
+	return b == nil || len(b.payloads) == 0
+}
+
+// Store the payload after the others
+func (b *payloadBuffer) add(data []byte, now time.Time) error {
+	nanos := now.UnixNano()
+	if nanos <= b.last {
+		nanos = b.last + 1
+	}
+	path := filepath.Join(b.dir, fmt.Sprintf("%020d", nanos)+bufferExt)
+	// Written aside and renamed, so a partial payload is never forwarded
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, os.FileMode(0600)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	b.payloads = append(b.payloads, bufferedPayload{path: path, size: int64(len(data)), created: time.Unix(0, nanos)})
+	b.size += int64(len(data))
+	b.last = nanos
+	b.trim(now)
+	return nil
+}
+
+// Drop the payloads older than the max age, and the oldest ones beyond the max size
+func (b *payloadBuffer) trim(now time.Time) {
+	for len(b.payloads) > 0 {
+		p := b.payloads[0]
+		if b.maxAge > 0 && now.Sub(p.created) > b.maxAge {
+			logger.Warn("Buffered payload expired, drop data", zap.String("file", p.path))
+		} else if b.size > b.maxSize {
+			logger.Warn("Buffer full, drop the oldest data", zap.String("file", p.path))
+		} else {
+			return
+		}
+		b.remove()
+	}
+}
+
+// Remove the oldest payload
+func (b *payloadBuffer) remove() {
+	p := b.payloads[0]
+	if err := os.Remove(p.path); err != nil && !os.IsNotExist(err) {
+		logger.Warn("Could not remove the buffered payload", zap.Error(err), zap.String("file", p.path))
+	}
+	b.payloads = b.payloads[1:]
+	b.size -= p.size
+}
+
+// Forward the payloads in order, until the destination doesn't take one
+func (b *payloadBuffer) drain(sender forwarder, now time.Time) {
+	b.trim(now)
+	forwarded := 0
+	for len(b.payloads) > 0 {
+		p := b.payloads[0]
+		data, err := ioutil.ReadFile(p.path)
+		if err != nil {
+			logger.Error("Could not read the buffered payload, drop data", zap.Error(err), zap.String("file", p.path))
+			b.remove()
+			continue
+		}
+		if err := sender.forward(data); err != nil {
+			logger.Info("Destination unreachable, payloads kept", zap.Int("payloads", len(b.payloads)), zap.Error(err))
+			return
+		}
+		b.remove()
+		forwarded++
+	}
+	if forwarded > 0 {
+		logger.Info("Forwarded the buffered payloads", zap.Int("payloads", forwarded))
+	}
+}
+
+// Ticks when the payloads are forwarded again, never without a buffer
+func (b *payloadBuffer) tick() <-chan time.Time {
+	if b == nil {
+		return nil
+	}
+	return b.ticker.C
+}
+
+func (b *payloadBuffer) stop() {
+	if b != nil {
+		b.ticker.Stop()
+	}
+}
@@ -0,0 +1,223 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+	"go.uber.org/zap"
+)
+
+// Sender of a destination that takes the payloads unless it's down
+type forwardSender struct {
+	down     bool
+	payloads []string
+}
+
+func (sender *forwardSender) Send(data []byte) {
+	sender.forward(data)
+}
+
+func (sender *forwardSender) forward(data []byte) error {
+	if sender.down {
+		return errors.New("unreachable")
+	}
+	sender.payloads = append(sender.payloads, string(data))
+	return nil
+}
+
+// Formatter making the payload of the device of the event
+type deviceFormatter struct{}
+
+func (deviceFormatter) Format(event *models.Event) []byte {
+	return []byte(event.Device)
+}
+
+func testBuffer(t *testing.T, details export.BufferDetails) (*payloadBuffer, string) {
+	dir, err := ioutil.TempDir("", "buffer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := newPayloadBuffer(dir, "reg/1", details)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return b, dir
+}
+
+func bufferFiles(t *testing.T, b *payloadBuffer) int {
+	files, err := ioutil.ReadDir(b.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(files)
+}
+
+func TestPayloadBufferDrain(t *testing.T) {
+	logger = zap.NewNop()
+	b, dir := testBuffer(t, export.BufferDetails{MaxSize: 1 << 20})
+	defer os.RemoveAll(dir)
+	defer b.stop()
+
+	if filepath.Base(b.dir) != "reg_1" {
+		t.Errorf("The directory of the registration should be safe: %s", b.dir)
+	}
+	now := time.Now()
+	for _, p := range []string{"one", "two", "three"} {
+		if err := b.add([]byte(p), now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(b.payloads) != 3 || b.size != 11 || bufferFiles(t, b) != 3 {
+		t.Fatalf("The payloads should be stored: %v", b.payloads)
+	}
+
+	sender := &forwardSender{down: true}
+	b.drain(sender, now)
+	if len(b.payloads) != 3 || len(sender.payloads) != 0 {
+		t.Fatal("The payloads should be kept while the destination is down")
+	}
+
+	sender.down = false
+	b.drain(sender, now)
+	if !reflect.DeepEqual(sender.payloads, []string{"one", "two", "three"}) {
+		t.Errorf("The payloads should be forwarded in order: %v", sender.payloads)
+	}
+	if !b.empty() || b.size != 0 || bufferFiles(t, b) != 0 {
+		t.Error("The forwarded payloads should be removed")
+	}
+}
+
+func TestPayloadBufferCaps(t *testing.T) {
+	logger = zap.NewNop()
+	b, dir := testBuffer(t, export.BufferDetails{MaxSize: 10, MaxAge: 60000})
+	defer os.RemoveAll(dir)
+	defer b.stop()
+
+	now := time.Now()
+	b.add([]byte("1111"), now.Add(-2*time.Minute))
+	b.add([]byte("2222"), now)
+	b.add([]byte("3333"), now)
+	b.add([]byte("4444"), now)
+
+	sender := &forwardSender{}
+	b.drain(sender, now)
+	if !reflect.DeepEqual(sender.payloads, []string{"3333", "4444"}) {
+		t.Errorf("The expired and the oldest payloads beyond the max size should be dropped: %v", sender.payloads)
+	}
+	if bufferFiles(t, b) != 0 {
+		t.Error("The dropped payloads should be removed")
+	}
+}
+
+func TestPayloadBufferReload(t *testing.T) {
+	logger = zap.NewNop()
+	b, dir := testBuffer(t, export.BufferDetails{MaxSize: 1 << 20})
+	defer os.RemoveAll(dir)
+	now := time.Now()
+	b.add([]byte("one"), now)
+	b.add([]byte("two"), now)
+	b.stop()
+	// Left by a write that didn't complete
+	ioutil.WriteFile(filepath.Join(b.dir, "00000000000000000001.buf.tmp"), []byte("partial"), 0600)
+
+	reloaded, err := newPayloadBuffer(dir, "reg/1", export.BufferDetails{MaxSize: 1 << 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reloaded.stop()
+	if len(reloaded.payloads) != 2 || reloaded.size != 6 {
+		t.Fatalf("The stored payloads should be loaded: %v", reloaded.payloads)
+	}
+	reloaded.add([]byte("three"), now.Add(-time.Second))
+
+	sender := &forwardSender{}
+	reloaded.drain(sender, now)
+	if !reflect.DeepEqual(sender.payloads, []string{"one", "two", "three"}) {
+		t.Errorf("The payloads should be forwarded in the order they were stored: %v", sender.payloads)
+	}
+}
+
+func TestRegistrationInfoForward(t *testing.T) {
+	logger = zap.NewNop()
+	b, dir := testBuffer(t, export.BufferDetails{MaxSize: 1 << 20})
+	defer os.RemoveAll(dir)
+	defer b.stop()
+
+	sender := &forwardSender{down: true}
+	ri := newRegistrationInfo()
+	ri.format = deviceFormatter{}
+	ri.sender = sender
+	ri.buffer = b
+
+	ri.processEvent(&models.Event{Device: "one"})
+	ri.processEvent(&models.Event{Device: "two"})
+	if len(b.payloads) != 2 {
+		t.Fatal("The payloads should be buffered while the destination is down")
+	}
+
+	sender.down = false
+	ri.processEvent(&models.Event{Device: "three"})
+	if !reflect.DeepEqual(sender.payloads, []string{"one", "two", "three"}) || !b.empty() {
+		t.Errorf("The buffered payloads should be forwarded before the new one: %v", sender.payloads)
+	}
+
+	ri.processEvent(&models.Event{Device: "four"})
+	if len(sender.payloads) != 4 || !b.empty() {
+		t.Error("The payload should be sent when the buffer is empty")
+	}
+}
+
+func TestRegistrationInfoBuffer(t *testing.T) {
+	logger = zap.NewNop()
+	dir, err := ioutil.TempDir("", "buffer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := validRegistration()
+	r.Name = "reg"
+	r.Buffer = export.BufferDetails{MaxSize: 1 << 20}
+	r.Batch = export.BatchDetails{Size: 10}
+	ri := newRegistrationInfo()
+	if ri.configure(r) == nil {
+		t.Error("The buffer should require the buffer directory")
+	}
+	if ri.batch != nil || ri.sender != nil {
+		t.Error("The batch and the sender built before the error should be released")
+	}
+	r.Batch = export.BatchDetails{}
+
+	configuration.BufferDir = dir
+	defer func() { configuration.BufferDir = "" }()
+	if err := ri.configure(r); err != nil {
+		t.Fatal(err)
+	}
+	defer ri.closeSender()
+	if ri.buffer == nil || ri.buffer.tick() == nil {
+		t.Fatal("The registration should buffer the payloads")
+	}
+	ri.buffer.stop()
+
+	r.Buffer = export.BufferDetails{}
+	if err := ri.configure(r); err != nil {
+		t.Fatal(err)
+	}
+	if ri.buffer != nil {
+		t.Error("The registration should not buffer the payloads")
+	}
+}
@@ -0,0 +1,84 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+)
+
+const (
+	checkDevice = "export-test"
+	// Suffix of the client id the check connects with, so the broker doesn't disconnect the
+	// running registration using the same one
+	checkClientSuffix = "-check"
+)
+
+// Synthetic event sent to check the destination of a registration
+func checkEvent() *models.Event {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	return &models.Event{
+		Device: checkDevice,
+		Origin: now,
+		Readings: []models.Reading{
+			{Device: checkDevice, Name: "test", Value: "1", Origin: now},
+		},
+	}
+}
+
+// Send a test event with the registration, which doesn't need to be stored or running, to
+// check that distro supports it and that its destination accepts the event. The filters of
+// the registration are ignored, and an event the destination refuses is not dead lettered nor
+// buffered. The buffer directory of the registration is left alone, and the MQTT and AWS IoT
+// destinations are connected with their own client id. Azure IoT Hub only takes the device id
+// of the connection string, which would disconnect the running registration, so it's not tested
+func checkRegistration(r export.Registration) export.RegistrationCheck {
+	if r.Addressable.Publisher != "" {
+		r.Addressable.Publisher += checkClientSuffix
+	}
+	reg := newRegistrationInfo()
+	reg.check = true
+	defer func() {
+		reg.batch.stop()
+		reg.closeSender()
+		closeTransforms(reg.transforms)
+	}()
+	if err := reg.configure(r); err != nil {
+		return export.RegistrationCheck{Error: err.Error()}
+	}
+	reg.filter = nil
+	reg.aggregate.stop()
+	reg.aggregate = nil
+	if sender, ok := reg.sender.(*httpSender); ok {
+		sender.maxAttempts = 1
+		sender.deadLetter = func(export.DeadLetter) {}
+	}
+
+	sender, ok := reg.sender.(statsSender)
+	if !ok || r.Destination == export.DestAzureMQTT {
+		return export.RegistrationCheck{Valid: true, Error: "The destination can't be tested: " + r.Destination}
+	}
+	stats := &registrationStats{}
+	sender.setStats(stats)
+	reg.processEvent(checkEvent())
+	// A batch of the test event is sent right away, and the batching senders write it when
+	// they're closed
+	reg.flushBatch()
+	reg.batch.stop()
+	reg.closeSender()
+
+	s := stats.snapshot()
+	if s.Sent == 0 {
+		if s.LastError == "" {
+			s.LastError = "The test event was not sent"
+		}
+		return export.RegistrationCheck{Valid: true, Error: s.LastError}
+	}
+	return export.RegistrationCheck{Valid: true, Sent: true}
+}
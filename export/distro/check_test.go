@@ -0,0 +1,116 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+	"go.uber.org/zap"
+)
+
+func checkHTTPRegistration(t *testing.T, url string) export.Registration {
+	r := validRegistration()
+	r.Name = "check"
+	r.Destination = export.DestRest
+	r.Addressable = testHTTPAddressable(t, url)
+	return r
+}
+
+func TestCheckRegistration(t *testing.T) {
+	logger = zap.NewNop()
+
+	var events []models.Event
+	status := http.StatusOK
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event := models.Event{}
+		json.NewDecoder(r.Body).Decode(&event)
+		events = append(events, event)
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+
+	// The filters of the registration don't apply to the test event
+	check := checkRegistration(checkHTTPRegistration(t, ts.URL))
+	if !check.Valid || !check.Sent || check.Error != "" {
+		t.Fatalf("The test event should be sent: %+v", check)
+	}
+	if len(events) != 1 || events[0].Device != checkDevice || len(events[0].Readings) != 1 {
+		t.Fatalf("The endpoint should receive the test event: %v", events)
+	}
+
+	// Refused once, without retries nor dead letters
+	status = http.StatusInternalServerError
+	r := checkHTTPRegistration(t, ts.URL)
+	r.HTTP.MaxAttempts = 3
+	check = checkRegistration(r)
+	if !check.Valid || check.Sent || !strings.Contains(check.Error, "500") {
+		t.Errorf("The failure of the endpoint should be returned: %+v", check)
+	}
+	if len(events) != 2 {
+		t.Errorf("The test event should be sent once, %d requests", len(events)-1)
+	}
+	if getStats("check") != nil {
+		t.Error("A checked registration should not be running")
+	}
+}
+
+func TestCheckRegistrationRunning(t *testing.T) {
+	logger = zap.NewNop()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	dir, err := ioutil.TempDir("", "buffer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	configuration.BufferDir = dir
+	defer func() { configuration.BufferDir = "" }()
+
+	// The buffer of the running registration is left alone
+	r := checkHTTPRegistration(t, ts.URL)
+	r.Buffer = export.BufferDetails{MaxSize: 1 << 20}
+	if check := checkRegistration(r); !check.Sent {
+		t.Fatalf("The test event should be sent: %+v", check)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Error("The check should not create the buffer of the registration")
+	}
+
+	r = validRegistration()
+	r.Destination = export.DestAzureMQTT
+	r.Azure = export.AzureDetails{ConnectionString: testAzureConnectionString}
+	if check := checkRegistration(r); !check.Valid || check.Sent || !strings.Contains(check.Error, "can't be tested") {
+		t.Errorf("The device of the running registration should not be disconnected: %+v", check)
+	}
+}
+
+func TestCheckRegistrationInvalid(t *testing.T) {
+	logger = zap.NewNop()
+
+	r := validRegistration()
+	r.Format = "UNKNOWN"
+	check := checkRegistration(r)
+	if check.Valid || check.Sent || check.Error != "Format not supported: UNKNOWN" {
+		t.Errorf("The unsupported format should be returned: %+v", check)
+	}
+
+	r = validRegistration()
+	r.Destination = export.DestZMQ
+	check = checkRegistration(r)
+	if check.Valid || !strings.Contains(check.Error, export.DestZMQ) {
+		t.Errorf("The unsupported destination should be returned: %+v", check)
+	}
+}
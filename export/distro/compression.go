@@ -11,13 +11,41 @@ import (
 	"compress/gzip"
 	"compress/zlib"
 	"encoding/base64"
+
+	"github.com/klauspost/compress/zstd"
 )
 
+// compressor - Transformer compressing the payloads, base64 encoded. The senders supporting a
+// content coding send the compressed bytes raw instead
+type compressor interface {
+	Transformer
+	compress(data []byte) []byte
+	contentEncoding() string
+}
+
+// contentEncoder - Sender telling the destination the content coding of the payloads
+type contentEncoder interface {
+	setContentEncoding(encoding string)
+}
+
+// rawCompression - compressor sending the compressed bytes as they are
+type rawCompression struct {
+	compressor
+}
+
+func (rc rawCompression) Transform(data []byte) []byte {
+	return rc.compress(data)
+}
+
 type gzipTransformer struct {
 	writer *gzip.Writer
 }
 
 func (gzt *gzipTransformer) Transform(data []byte) []byte {
+	return bytesToBase64(gzt.compress(data))
+}
+
+func (gzt *gzipTransformer) compress(data []byte) []byte {
 	var buf bytes.Buffer
 
 	if gzt.writer == nil {
@@ -29,7 +57,11 @@ func (gzt *gzipTransformer) Transform(data []byte) []byte {
 	gzt.writer.Write(data)
 	gzt.writer.Close()
 
-	return bytesBufferToBase64(buf)
+	return buf.Bytes()
+}
+
+func (gzt *gzipTransformer) contentEncoding() string {
+	return "gzip"
 }
 
 type zlibTransformer struct {
@@ -37,6 +69,10 @@ type zlibTransformer struct {
 }
 
 func (zlt *zlibTransformer) Transform(data []byte) []byte {
+	return bytesToBase64(zlt.compress(data))
+}
+
+func (zlt *zlibTransformer) compress(data []byte) []byte {
 	var buf bytes.Buffer
 
 	if zlt.writer == nil {
@@ -48,11 +84,38 @@ func (zlt *zlibTransformer) Transform(data []byte) []byte {
 	zlt.writer.Write(data)
 	zlt.writer.Close()
 
-	return bytesBufferToBase64(buf)
+	return buf.Bytes()
+}
+
+// The HTTP deflate coding is the zlib format
+func (zlt *zlibTransformer) contentEncoding() string {
+	return "deflate"
+}
+
+type zstdTransformer struct {
+	encoder *zstd.Encoder
+}
+
+func newZstdTransformer() *zstdTransformer {
+	// Without options the encoder can't fail
+	encoder, _ := zstd.NewWriter(nil)
+	return &zstdTransformer{encoder: encoder}
+}
+
+func (zst *zstdTransformer) Transform(data []byte) []byte {
+	return bytesToBase64(zst.compress(data))
+}
+
+func (zst *zstdTransformer) compress(data []byte) []byte {
+	return zst.encoder.EncodeAll(data, nil)
+}
+
+func (zst *zstdTransformer) contentEncoding() string {
+	return "zstd"
 }
 
-func bytesBufferToBase64(buf bytes.Buffer) []byte {
-	dst := make([]byte, base64.StdEncoding.EncodedLen(buf.Len()))
-	base64.StdEncoding.Encode(dst, buf.Bytes())
+func bytesToBase64(data []byte) []byte {
+	dst := make([]byte, base64.StdEncoding.EncodedLen(len(data)))
+	base64.StdEncoding.Encode(dst, data)
 	return dst
 }
@@ -13,6 +13,8 @@ import (
 	"encoding/base64"
 	"io/ioutil"
 	"testing"
+
+	"github.com/klauspost/compress/zstd"
 )
 
 const (
@@ -87,6 +89,55 @@ func TestZlib(t *testing.T) {
 	}
 }
 
+func TestZstd(t *testing.T) {
+
+	comp := newZstdTransformer()
+	enc := comp.Transform([]byte(clearString))
+
+	compressed, err := base64.StdEncoding.DecodeString(string(enc))
+	if err != nil {
+		t.Fatal("Error base64 ", err)
+	}
+
+	zr, err := zstd.NewReader(nil)
+	if err != nil {
+		t.Fatal("Error creating decoder ", err)
+	}
+	defer zr.Close()
+	decoded, err := zr.DecodeAll(compressed, nil)
+	if err != nil {
+		t.Fatal("Error decoding buffer ", err)
+	}
+
+	if string(decoded) != clearString {
+		t.Fatal("Decoded string ", string(enc), " is not ", clearString)
+	}
+}
+
+func TestRawCompression(t *testing.T) {
+	var tests = []struct {
+		name     string
+		comp     compressor
+		encoding string
+	}{
+		{"gzip", &gzipTransformer{}, "gzip"},
+		{"zlib", &zlibTransformer{}, "deflate"},
+		{"zstd", newZstdTransformer(), "zstd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := rawCompression{tt.comp}.Transform([]byte(clearString))
+			if !bytes.Equal(bytesToBase64(raw), tt.comp.Transform([]byte(clearString))) {
+				t.Error("The raw payload should be the compressed bytes")
+			}
+			if tt.comp.contentEncoding() != tt.encoding {
+				t.Errorf("Content coding %s, should be %s", tt.comp.contentEncoding(), tt.encoding)
+			}
+		})
+	}
+}
+
 var result []byte
 
 func BenchmarkGzip(b *testing.B) {
@@ -112,3 +163,15 @@ func BenchmarkZlib(b *testing.B) {
 	b.SetBytes(int64(len(enc)))
 	result = enc
 }
+
+func BenchmarkZstd(b *testing.B) {
+
+	comp := newZstdTransformer()
+
+	var enc []byte
+	for i := 0; i < b.N; i++ {
+		enc = comp.Transform([]byte(clearString))
+	}
+	b.SetBytes(int64(len(enc)))
+	result = enc
+}
@@ -18,6 +18,25 @@ type ConfigurationStruct struct {
 	CheckInterval        string
 	MQTTSCert            string
 	MQTTSKey             string
+	KafkaCACert          string
+	KafkaCert            string
+	KafkaKey             string
+	AMQPCACert           string
+	AMQPCert             string
+	AMQPKey              string
+	HTTPDeadLetterDir    string
+	BufferDir            string
+	PluginDir            string
+	MessageBus           string
+	NATSURL              string
+	NATSSubject          string
+	NATSStream           string
+	NATSStreamMaxAge     int
+	NATSDurable          string
+	NATSUsername         string
+	NATSPassword         string
+	NATSAckWait          int
+	NATSTimeout          int
 }
 
 var configuration ConfigurationStruct = ConfigurationStruct{} // Needs to be initialized before used
@@ -0,0 +1,82 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/export"
+	"go.uber.org/zap"
+)
+
+const deadLetterTimeout = 5 * time.Second
+
+var deadLetterClient = &http.Client{Timeout: deadLetterTimeout}
+
+func getDeadLetterURL(host string) string {
+	return "http://" + host + ":" + strconv.Itoa(clientPort) + "/api/v1/deadletter"
+}
+
+// Store the payload a registration couldn't deliver in the dead letters of the client, or in
+// the dead letter directory when the client is unavailable
+func storeDeadLetter(letter export.DeadLetter) {
+	storeDeadLetterURL(getDeadLetterURL(configuration.ClientHost), letter)
+}
+
+func storeDeadLetterURL(url string, letter export.DeadLetter) {
+	letter.Created = time.Now().UnixNano() / int64(time.Millisecond)
+	err := postDeadLetter(url, letter)
+	if err == nil {
+		logger.Warn("Dead lettered data", zap.String("registration", letter.Registration))
+		return
+	}
+	logger.Warn("Could not store the dead letter in the client", zap.Error(err))
+	writeDeadLetter(letter)
+}
+
+func postDeadLetter(url string, letter export.DeadLetter) error {
+	data, err := json.Marshal(letter)
+	if err != nil {
+		return err
+	}
+	response, err := deadLetterClient.Post(url, mimeTypeJSON, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	ioutil.ReadAll(response.Body)
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("client returned %s", response.Status)
+	}
+	return nil
+}
+
+var deadLetterUnsafe = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// Write the payload to the dead letter directory, or drop it when there's none
+func writeDeadLetter(letter export.DeadLetter) {
+	if configuration.HTTPDeadLetterDir == "" {
+		logger.Warn("No dead letter directory, drop data", zap.String("registration", letter.Registration))
+		return
+	}
+	name := deadLetterUnsafe.ReplaceAllString(letter.Registration, "_") + "-" + strconv.FormatInt(time.Now().UnixNano(), 10) + ".dead"
+	path := filepath.Join(configuration.HTTPDeadLetterDir, name)
+	if err := ioutil.WriteFile(path, letter.Payload, os.FileMode(0600)); err != nil {
+		logger.Error("Could not write the dead letter, drop data", zap.Error(err), zap.String("file", path))
+		return
+	}
+	logger.Warn("Dead lettered data", zap.String("file", path))
+}
@@ -0,0 +1,56 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/edgexfoundry/edgex-go/export"
+	"go.uber.org/zap"
+)
+
+func TestStoreDeadLetter(t *testing.T) {
+	logger = zap.NewNop()
+	dir, err := ioutil.TempDir("", "deadletter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	configuration.HTTPDeadLetterDir = dir
+	defer func() { configuration.HTTPDeadLetterDir = "" }()
+
+	var stored export.DeadLetter
+	status := http.StatusOK
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&stored)
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+
+	letter := export.DeadLetter{Registration: "hook/1", Destination: export.DestRest, Payload: []byte("data"), Error: "refused", Attempts: 3}
+	storeDeadLetterURL(ts.URL, letter)
+	if stored.Registration != "hook/1" || string(stored.Payload) != "data" || stored.Attempts != 3 || stored.Created == 0 {
+		t.Errorf("The dead letter should be stored in the client: %v", stored)
+	}
+
+	// Written to the directory when the client refuses it
+	status = http.StatusInternalServerError
+	storeDeadLetterURL(ts.URL, letter)
+	files, _ := filepath.Glob(filepath.Join(dir, "hook_1-*.dead"))
+	if len(files) != 1 {
+		t.Fatalf("The dead letter should be written to the directory: %v", files)
+	}
+	if data, _ := ioutil.ReadFile(files[0]); string(data) != "data" {
+		t.Errorf("The dead letter file should be the payload, not %s", data)
+	}
+}
@@ -10,8 +10,12 @@ import (
 	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/json"
 
 	"github.com/edgexfoundry/edgex-go/export"
 	"go.uber.org/zap"
@@ -64,3 +68,84 @@ func (aesData aesEncryption) Transform(data []byte) []byte {
 
 	return encodedData
 }
+
+// Encrypts the payloads with AES-256-GCM, and sends them as export.EncryptedPayload with a
+// random nonce each
+type gcmEncryption struct {
+	algo  string
+	keyID string
+	aead  cipher.AEAD
+	// Key of the payload, encrypted for the RSA envelopes
+	wrapKey func(key []byte) ([]byte, error)
+}
+
+func newGCMEncryption(details export.EncryptionDetails) (Transformer, error) {
+	key, err := details.AESKey()
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return &gcmEncryption{algo: export.EncAesGcm, keyID: details.KeyID, aead: aead}, nil
+}
+
+// Envelope encryption, each payload has its own AES-256-GCM key encrypted with the RSA public
+// key of the registration
+func newEnvelopeEncryption(details export.EncryptionDetails) (Transformer, error) {
+	publicKey, err := details.RSAPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	return &gcmEncryption{
+		algo:  export.EncRsaEnvelope,
+		keyID: details.KeyID,
+		wrapKey: func(key []byte) ([]byte, error) {
+			return rsa.EncryptOAEP(sha256.New(), rand.Reader, publicKey, key, nil)
+		},
+	}, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (encryption *gcmEncryption) Transform(data []byte) []byte {
+	payload := export.EncryptedPayload{Algo: encryption.algo, KeyID: encryption.keyID}
+	aead := encryption.aead
+	if encryption.wrapKey != nil {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			logger.Error("Error generating the key", zap.Error(err))
+			return nil
+		}
+		var err error
+		if aead, err = newGCM(key); err != nil {
+			logger.Error("Error", zap.Error(err))
+			return nil
+		}
+		if payload.Key, err = encryption.wrapKey(key); err != nil {
+			logger.Error("Error encrypting the key", zap.Error(err))
+			return nil
+		}
+	}
+
+	payload.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(payload.Nonce); err != nil {
+		logger.Error("Error generating the nonce", zap.Error(err))
+		return nil
+	}
+	payload.Data = aead.Seal(nil, payload.Nonce, data, payload.AdditionalData())
+
+	encrypted, err := json.Marshal(payload)
+	if err != nil {
+		logger.Error("Error", zap.Error(err))
+		return nil
+	}
+	return encrypted
+}
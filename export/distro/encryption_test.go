@@ -7,10 +7,17 @@
 package distro
 
 import (
+	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
 	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
 
 	"github.com/edgexfoundry/edgex-go/export"
 
@@ -72,3 +79,90 @@ func TestAES(t *testing.T) {
 		t.Fatal("Encoded string ", string(plainString), " is not ", string(decphrd))
 	}
 }
+
+func gcmDecrypt(t *testing.T, encrypted []byte, key []byte) ([]byte, export.EncryptedPayload) {
+	payload := export.EncryptedPayload{}
+	if err := json.Unmarshal(encrypted, &payload); err != nil {
+		t.Fatalf("Error parsing the encrypted payload: %v", err)
+	}
+	block, _ := aes.NewCipher(key)
+	aead, _ := cipher.NewGCM(block)
+	data, err := aead.Open(nil, payload.Nonce, payload.Data, payload.AdditionalData())
+	if err != nil {
+		t.Fatalf("Error decrypting the payload: %v", err)
+	}
+	return data, payload
+}
+
+func TestAESGCM(t *testing.T) {
+	aesKey := make([]byte, 32)
+	rand.Read(aesKey)
+	details := export.EncryptionDetails{
+		Algo:  export.EncAesGcm,
+		Key:   base64.StdEncoding.EncodeToString(aesKey),
+		KeyID: "2018-10",
+	}
+
+	enc, err := newGCMEncryption(details)
+	if err != nil {
+		t.Fatalf("Error creating the encryption: %v", err)
+	}
+	encrypted := enc.Transform([]byte(plainString))
+	data, payload := gcmDecrypt(t, encrypted, aesKey)
+	if string(data) != plainString || payload.Algo != export.EncAesGcm || payload.KeyID != "2018-10" || payload.Key != nil {
+		t.Fatalf("Unexpected payload %+v: %s", payload, data)
+	}
+
+	if again := enc.Transform([]byte(plainString)); bytes.Equal(again, encrypted) {
+		t.Error("Each payload should have its own nonce")
+	}
+
+	// The key id is authenticated
+	payload.KeyID = "2018-11"
+	block, _ := aes.NewCipher(aesKey)
+	aead, _ := cipher.NewGCM(block)
+	if _, err := aead.Open(nil, payload.Nonce, payload.Data, payload.AdditionalData()); err == nil {
+		t.Error("A payload with another key id should not be decrypted")
+	}
+
+	details.Key = base64.StdEncoding.EncodeToString([]byte("short"))
+	if _, err := newGCMEncryption(details); err == nil {
+		t.Error("A key of 5 bytes should be refused")
+	}
+}
+
+func TestRSAEnvelope(t *testing.T) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Error generating the key: %v", err)
+	}
+	public, _ := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	details := export.EncryptionDetails{
+		Algo:      export.EncRsaEnvelope,
+		PublicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public})),
+		KeyID:     "backend-1",
+	}
+
+	enc, err := newEnvelopeEncryption(details)
+	if err != nil {
+		t.Fatalf("Error creating the encryption: %v", err)
+	}
+	encrypted := enc.Transform([]byte(plainString))
+	payload := export.EncryptedPayload{}
+	if err := json.Unmarshal(encrypted, &payload); err != nil {
+		t.Fatalf("Error parsing the encrypted payload: %v", err)
+	}
+	aesKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, private, payload.Key, nil)
+	if err != nil {
+		t.Fatalf("Error decrypting the key: %v", err)
+	}
+	data, payload := gcmDecrypt(t, encrypted, aesKey)
+	if string(data) != plainString || payload.Algo != export.EncRsaEnvelope || payload.KeyID != "backend-1" {
+		t.Fatalf("Unexpected payload %+v: %s", payload, data)
+	}
+
+	details.PublicKey = "not a key"
+	if _, err := newEnvelopeEncryption(details); err == nil {
+		t.Error("An invalid public key should be refused")
+	}
+}
@@ -7,6 +7,9 @@
 package distro
 
 import (
+	"math"
+	"strconv"
+
 	"github.com/edgexfoundry/edgex-go/core/domain/models"
 	"github.com/edgexfoundry/edgex-go/export"
 	"go.uber.org/zap"
@@ -75,3 +78,65 @@ func (filter valueDescFilterDetails) Filter(event *models.Event) (bool, *models.
 	}
 	return len(auxEvent.Readings) > 0, auxEvent
 }
+
+type valueFilterDetails struct {
+	filters map[string]export.ValueFilter
+	// Last value kept, by device and value descriptor
+	last map[string]float64
+}
+
+func newValueFilter(filter export.Filter) Filterer {
+	filterer := &valueFilterDetails{
+		filters: make(map[string]export.ValueFilter),
+		last:    make(map[string]float64),
+	}
+	for _, f := range filter.ValueFilters {
+		filterer.filters[f.ValueDescriptor] = f
+	}
+	return filterer
+}
+
+func (filter *valueFilterDetails) Filter(event *models.Event) (bool, *models.Event) {
+
+	if event == nil {
+		return false, nil
+	}
+
+	// The event is shared by the registrations
+	auxEvent := *event
+	auxEvent.Readings = make([]models.Reading, 0, len(event.Readings))
+	for _, reading := range event.Readings {
+		if filter.keep(event, reading) {
+			auxEvent.Readings = append(auxEvent.Readings, reading)
+		} else {
+			logger.Debug("Reading filtered out", zap.Any("Reading", reading))
+		}
+	}
+	return len(auxEvent.Readings) > 0, &auxEvent
+}
+
+func (filter *valueFilterDetails) keep(event *models.Event, reading models.Reading) bool {
+	f, ok := filter.filters[reading.Name]
+	if !ok {
+		return true
+	}
+	value, err := strconv.ParseFloat(reading.Value, 64)
+	if err != nil {
+		return true
+	}
+	if (f.Min != nil && value < *f.Min) || (f.Max != nil && value > *f.Max) {
+		return false
+	}
+	if f.Deadband > 0 {
+		device := reading.Device
+		if device == "" {
+			device = event.Device
+		}
+		key := device + "/" + reading.Name
+		if last, ok := filter.last[key]; ok && math.Abs(value-last) <= f.Deadband {
+			return false
+		}
+		filter.last[key] = value
+	}
+	return true
+}
@@ -136,3 +136,56 @@ func TestFilterValue(t *testing.T) {
 		t.Fatal("Event should be one reading, there are ", len(res.Readings))
 	}
 }
+
+func TestFilterValueRange(t *testing.T) {
+	logger = zap.NewNop()
+	defer logger.Sync()
+
+	min, max := -10.0, 50.0
+	f := export.Filter{ValueFilters: []export.ValueFilter{
+		{ValueDescriptor: descriptor1, Min: &min, Max: &max},
+		{ValueDescriptor: descriptor2, Deadband: 0.5},
+	}}
+	filter := newValueFilter(f)
+
+	reading := func(device, name, value string) *models.Event {
+		return &models.Event{Device: device, Readings: []models.Reading{{Name: name, Value: value}}}
+	}
+	var tests = []struct {
+		name     string
+		event    *models.Event
+		accepted bool
+	}{
+		{"inRange", reading(deviceID1, descriptor1, "20"), true},
+		{"min", reading(deviceID1, descriptor1, "-10"), true},
+		{"belowMin", reading(deviceID1, descriptor1, "-10.5"), false},
+		{"aboveMax", reading(deviceID1, descriptor1, "51"), false},
+		{"notNumber", reading(deviceID1, descriptor1, "high"), true},
+		{"otherDescriptor", reading(deviceID1, "other", "1000"), true},
+		{"first", reading(deviceID1, descriptor2, "20"), true},
+		{"withinDeadband", reading(deviceID1, descriptor2, "20.5"), false},
+		{"otherDevice", reading(deviceID2, descriptor2, "20.4"), true},
+		{"outsideDeadband", reading(deviceID1, descriptor2, "20.6"), true},
+		// Compared to the last value kept, 20.6
+		{"drift", reading(deviceID1, descriptor2, "20.2"), false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		accepted, _ := filter.Filter(tt.event)
+		if accepted != tt.accepted {
+			t.Errorf("%s: accepted %v, should be %v", tt.name, accepted, tt.accepted)
+		}
+	}
+
+	event := &models.Event{Device: deviceID1, Readings: []models.Reading{
+		{Name: descriptor1, Value: "100"},
+		{Name: descriptor1, Value: "10"},
+	}}
+	accepted, filtered := filter.Filter(event)
+	if !accepted || len(filtered.Readings) != 1 || filtered.Readings[0].Value != "10" {
+		t.Errorf("Only the reading in range should be kept: %v", filtered)
+	}
+	if len(event.Readings) != 2 {
+		t.Error("The event shared by the registrations should not change")
+	}
+}
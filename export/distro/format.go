@@ -7,10 +7,17 @@
 package distro
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"encoding/xml"
+	"strconv"
+	"text/template"
 
 	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+	"github.com/edgexfoundry/edgex-go/export/pb"
+	"github.com/golang/protobuf/proto"
 	"go.uber.org/zap"
 )
 
@@ -27,6 +34,16 @@ func (jsonTr jsonFormatter) Format(event *models.Event) []byte {
 	return b
 }
 
+// FormatBatch - JSON array of the events
+func (jsonTr jsonFormatter) FormatBatch(events []*models.Event) []byte {
+	b, err := json.Marshal(events)
+	if err != nil {
+		logger.Error("Error parsing JSON", zap.Error(err))
+		return nil
+	}
+	return b
+}
+
 type xmlFormatter struct {
 }
 
@@ -39,28 +56,44 @@ func (xmlTr xmlFormatter) Format(event *models.Event) []byte {
 	return b
 }
 
+// FormatBatch - Events elements of an Events element
+func (xmlTr xmlFormatter) FormatBatch(events []*models.Event) []byte {
+	b, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"Events"`
+		Events  []*models.Event `xml:"Event"`
+	}{Events: events})
+	if err != nil {
+		logger.Error("Error parsing XML", zap.Error(err))
+		return nil
+	}
+	return b
+}
+
 type thingsboardJSONFormatter struct {
 }
 
 // ThingsBoard JSON formatter
 // https://thingsboard.io/docs/reference/gateway-mqtt-api/#telemetry-upload-api
 func (thingsboardjsonTr thingsboardJSONFormatter) Format(event *models.Event) []byte {
+	return thingsboardjsonTr.FormatBatch([]*models.Event{event})
+}
+
+// FormatBatch - Values of the events, grouped by device
+func (thingsboardjsonTr thingsboardJSONFormatter) FormatBatch(events []*models.Event) []byte {
 
 	type Device struct {
 		Ts     int64             `json:"ts"`
 		Values map[string]string `json:"values"`
 	}
 
-	values := make(map[string]string)
-	for _, reading := range event.Readings {
-		values[reading.Name] = reading.Value
-	}
-
-	var deviceValues []Device
-	deviceValues = append(deviceValues, Device{Ts: event.Origin, Values: values})
-
 	device := make(map[string][]Device)
-	device[event.Device] = deviceValues
+	for _, event := range events {
+		values := make(map[string]string)
+		for _, reading := range event.Readings {
+			values[reading.Name] = reading.Value
+		}
+		device[event.Device] = append(device[event.Device], Device{Ts: event.Origin, Values: values})
+	}
 
 	b, err := json.Marshal(device)
 	if err != nil {
@@ -69,3 +102,190 @@ func (thingsboardjsonTr thingsboardJSONFormatter) Format(event *models.Event) []
 	}
 	return b
 }
+
+// Payload made by the template of the registration with each event
+type templateFormatter struct {
+	template *template.Template
+}
+
+func (templateTr templateFormatter) Format(event *models.Event) []byte {
+	var buf bytes.Buffer
+	if err := templateTr.template.Execute(&buf, event); err != nil {
+		logger.Error("Error executing the template", zap.Error(err))
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// contentTyper - Sender telling the destination the media type of the payloads
+type contentTyper interface {
+	setContentType(contentType string)
+}
+
+// Event message of export/pb/export.proto
+type protobufFormatter struct {
+}
+
+func (protobufTr protobufFormatter) Format(event *models.Event) []byte {
+	b, err := proto.Marshal(eventToProto(event))
+	if err != nil {
+		logger.Error("Error generating protobuf", zap.Error(err))
+		return nil
+	}
+	return b
+}
+
+// FormatBatch - Events message of the events
+func (protobufTr protobufFormatter) FormatBatch(events []*models.Event) []byte {
+	batch := &pb.Events{}
+	for _, event := range events {
+		batch.Events = append(batch.Events, eventToProto(event))
+	}
+	b, err := proto.Marshal(batch)
+	if err != nil {
+		logger.Error("Error generating protobuf", zap.Error(err))
+		return nil
+	}
+	return b
+}
+
+func eventToProto(e *models.Event) *pb.Event {
+	event := &pb.Event{
+		Id:       e.ID.Hex(),
+		Pushed:   e.Pushed,
+		Device:   e.Device,
+		Created:  e.Created,
+		Modified: e.Modified,
+		Origin:   e.Origin,
+		Schedule: e.Schedule,
+		Event:    e.Event,
+	}
+	for _, r := range e.Readings {
+		event.Readings = append(event.Readings, &pb.Reading{
+			Id:          r.Id.Hex(),
+			Pushed:      r.Pushed,
+			Created:     r.Created,
+			Origin:      r.Origin,
+			Modified:    r.Modified,
+			Device:      r.Device,
+			Name:        r.Name,
+			Value:       r.Value,
+			BinaryValue: r.BinaryValue,
+		})
+	}
+	return event
+}
+
+// Header of the rows written by EventsToCSV and ReadingsToCSV
+var csvHeader = []string{
+	export.CSVColumnDevice,
+	export.CSVColumnName,
+	export.CSVColumnValue,
+	export.CSVColumnCreated,
+	export.CSVColumnOrigin,
+}
+
+// Writes the columns of the registration, csvHeader when it has none
+type csvFormatter struct {
+	columns []string
+}
+
+func (csvTr csvFormatter) Format(event *models.Event) []byte {
+	b, err := eventsToCSV([]models.Event{*event}, csvTr.columns)
+	if err != nil {
+		logger.Error("Error generating CSV", zap.Error(err))
+		return nil
+	}
+	return b
+}
+
+// FormatBatch - Readings of the events, after a single header row
+func (csvTr csvFormatter) FormatBatch(events []*models.Event) []byte {
+	list := make([]models.Event, 0, len(events))
+	for _, event := range events {
+		list = append(list, *event)
+	}
+	b, err := eventsToCSV(list, csvTr.columns)
+	if err != nil {
+		logger.Error("Error generating CSV", zap.Error(err))
+		return nil
+	}
+	return b
+}
+
+// EventsToCSV flattens the readings of the events to CSV rows, after a header row
+// A reading without a device gets the device of its event
+func EventsToCSV(events []models.Event) ([]byte, error) {
+	return eventsToCSV(events, nil)
+}
+
+func eventsToCSV(events []models.Event, columns []string) ([]byte, error) {
+	var readings []models.Reading
+	for _, event := range events {
+		for _, reading := range event.Readings {
+			if reading.Device == "" {
+				reading.Device = event.Device
+			}
+			readings = append(readings, reading)
+		}
+	}
+	return readingsToCSV(readings, columns)
+}
+
+// ReadingsToCSV writes a CSV row per reading, after a header row
+// Values with commas, quotes or line breaks are quoted
+func ReadingsToCSV(readings []models.Reading) ([]byte, error) {
+	return readingsToCSV(readings, nil)
+}
+
+func readingsToCSV(readings []models.Reading, columns []string) ([]byte, error) {
+	if len(columns) == 0 {
+		columns = csvHeader
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write(columns); err != nil {
+		return nil, err
+	}
+	row := make([]string, len(columns))
+	for _, reading := range readings {
+		for i, column := range columns {
+			row[i] = csvValue(reading, column)
+		}
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Value of the column of the reading, empty for an unknown column
+func csvValue(reading models.Reading, column string) string {
+	switch column {
+	case export.CSVColumnID:
+		return reading.Id.Hex()
+	case export.CSVColumnDevice:
+		return reading.Device
+	case export.CSVColumnName:
+		return reading.Name
+	case export.CSVColumnValue:
+		return reading.Value
+	case export.CSVColumnCreated:
+		return strconv.FormatInt(reading.Created, 10)
+	case export.CSVColumnModified:
+		return strconv.FormatInt(reading.Modified, 10)
+	case export.CSVColumnOrigin:
+		return strconv.FormatInt(reading.Origin, 10)
+	case export.CSVColumnPushed:
+		return strconv.FormatInt(reading.Pushed, 10)
+	default:
+		return ""
+	}
+}
@@ -7,6 +7,8 @@
 package distro
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"encoding/xml"
 	"reflect"
@@ -14,6 +16,11 @@ import (
 	"testing"
 
 	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+	"github.com/edgexfoundry/edgex-go/export/pb"
+	"github.com/golang/protobuf/proto"
+	"go.uber.org/zap"
+	"gopkg.in/mgo.v2/bson"
 )
 
 const (
@@ -76,3 +83,198 @@ func TestThingsBoardJson(t *testing.T) {
 		t.Fatalf("Invalid ThingsBoard JSON format: %v", s)
 	}
 }
+
+func TestCsv(t *testing.T) {
+	eventIn := models.Event{
+		Device: devID1,
+		Readings: []models.Reading{
+			{Name: "temperature", Value: "21", Created: 10, Origin: 5},
+			{Name: "label", Value: "a, \"quoted\" value", Device: "other", Created: 11, Origin: 6},
+		},
+	}
+
+	cf := csvFormatter{}
+	out := cf.Format(&eventIn)
+	if out == nil {
+		t.Fatal("out should not be nil")
+	}
+
+	rows, err := csv.NewReader(bytes.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatalf("Error reading CSV: %v", err)
+	}
+	expected := [][]string{
+		csvHeader,
+		{devID1, "temperature", "21", "10", "5"},
+		{"other", "label", "a, \"quoted\" value", "11", "6"},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Fatalf("Rows should be equal: %v %v", rows, expected)
+	}
+}
+
+func TestReadingsToCsvEmpty(t *testing.T) {
+	out, err := ReadingsToCSV(nil)
+	if err != nil {
+		t.Fatalf("Error generating CSV: %v", err)
+	}
+	if string(out) != strings.Join(csvHeader, ",")+"\n" {
+		t.Fatalf("Only the header should be written: %q", out)
+	}
+}
+
+func TestCsvColumns(t *testing.T) {
+	event := &models.Event{Device: devID1, Readings: []models.Reading{
+		{Name: "temperature", Value: "21", Origin: 5, Modified: 7},
+	}}
+	out := csvFormatter{columns: []string{"origin", "name", "value", "modified"}}.Format(event)
+	rows, err := csv.NewReader(bytes.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatalf("Error reading CSV: %v", err)
+	}
+	expected := [][]string{
+		{"origin", "name", "value", "modified"},
+		{"5", "temperature", "21", "7"},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Fatalf("Rows should be equal: %v %v", rows, expected)
+	}
+
+	out = csvFormatter{}.FormatBatch([]*models.Event{event, event})
+	if rows, _ := csv.NewReader(bytes.NewReader(out)).ReadAll(); len(rows) != 3 || !reflect.DeepEqual(rows[0], csvHeader) {
+		t.Errorf("The rows should have the default columns: %v", rows)
+	}
+}
+
+func TestProtobuf(t *testing.T) {
+	id := bson.NewObjectId()
+	event := &models.Event{ID: id, Device: devID1, Origin: 10, Readings: []models.Reading{
+		{Id: id, Device: devID1, Name: "temperature", Value: "21", Origin: 10},
+		{Device: devID1, Name: "image", BinaryValue: []byte{1, 2, 3}, Origin: 10},
+	}}
+	out := protobufFormatter{}.Format(event)
+
+	decoded := &pb.Event{}
+	if err := proto.Unmarshal(out, decoded); err != nil {
+		t.Fatalf("Error decoding the event: %v", err)
+	}
+	if decoded.Id != id.Hex() || decoded.Device != devID1 || decoded.Origin != 10 || len(decoded.Readings) != 2 {
+		t.Fatalf("Unexpected event: %v", decoded)
+	}
+	r := decoded.Readings[0]
+	if r.Id != id.Hex() || r.Name != "temperature" || r.Value != "21" || r.Origin != 10 {
+		t.Errorf("Unexpected reading: %v", r)
+	}
+	if !bytes.Equal(decoded.Readings[1].BinaryValue, []byte{1, 2, 3}) {
+		t.Errorf("Unexpected binary value: %v", decoded.Readings[1].BinaryValue)
+	}
+
+	if json := (jsonFormatter{}).Format(event); len(out) >= len(json)/2 {
+		t.Errorf("The protobuf event should be much smaller than the JSON one: %d, %d", len(out), len(json))
+	}
+
+	batch := &pb.Events{}
+	if err := proto.Unmarshal(protobufFormatter{}.FormatBatch([]*models.Event{event, event}), batch); err != nil || len(batch.Events) != 2 {
+		t.Errorf("The batch should decode to the events: %v, %v", batch, err)
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	logger = zap.NewNop()
+
+	event := &models.Event{Device: "dev \"1\"", Origin: 10, Readings: []models.Reading{
+		{Name: "temperature", Value: "21"},
+		{Name: "humidity", Value: "40"},
+	}}
+	var tests = []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"losant", `{"time":{{.Origin}},"data":{ {{- range $i, $r := .Readings}}{{if $i}},{{end}}{{json $r.Name}}:{{$r.Value}}{{end -}} }}`,
+			`{"time":10,"data":{"temperature":21,"humidity":40}}`},
+		{"text", `{{.Device}};{{len .Readings}}`, `dev "1";2`},
+		{"json", `{"device":{{json .Device}}}`, `{"device":"dev \"1\""}`},
+		{"error", `{{index .Readings 5}}`, ""},
+		{"empty", `{{if not .Readings}}nothing{{end}}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := export.TemplateDetails{Text: tt.text}.Parse(tt.name)
+			if err != nil {
+				t.Fatalf("Error parsing the template: %v", err)
+			}
+			out := templateFormatter{template: tmpl}.Format(event)
+			if tt.expected == "" && out != nil {
+				t.Errorf("Nothing should be formatted: %q", out)
+			}
+			if string(out) != tt.expected {
+				t.Errorf("The payload is %s, should be %s", out, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTemplateNotSent(t *testing.T) {
+	logger = zap.NewNop()
+
+	r := validRegistration()
+	r.Filter = export.Filter{}
+	r.Format = export.FormatTemplate
+	r.Template.Text = `{{(index .Readings 0).Value}}`
+	reg := newRegistrationInfo()
+	if err := reg.configure(r); err != nil {
+		t.Fatalf("Error configuring the registration: %v", err)
+	}
+	sender := &batchSender{}
+	reg.sender = sender
+
+	reg.processEvent(&models.Event{Device: "dev"})
+	reg.processEvent(&models.Event{Device: "dev", Readings: []models.Reading{{Name: "temperature", Value: "21"}}})
+	if len(sender.payloads) != 1 || string(sender.payloads[0]) != "21" {
+		t.Errorf("Only the formatted event should be sent: %q", sender.payloads)
+	}
+
+	r.Template.Text = `{{.Missing`
+	if err := reg.configure(r); err == nil {
+		t.Error("An invalid template should not be configured")
+	}
+}
+
+func TestFormatBatch(t *testing.T) {
+	events := []*models.Event{
+		{Device: devID1, Origin: 1, Readings: []models.Reading{{Name: "temperature", Value: "21"}}},
+		{Device: devID1, Origin: 2, Readings: []models.Reading{{Name: "temperature", Value: "22"}}},
+		{Device: "id2", Origin: 3, Readings: []models.Reading{{Name: "humidity", Value: "40"}}},
+	}
+
+	var jsonOut []models.Event
+	if err := json.Unmarshal(jsonFormatter{}.FormatBatch(events), &jsonOut); err != nil || len(jsonOut) != 3 || jsonOut[2].Device != "id2" {
+		t.Errorf("The JSON batch should be an array of the events: %v, %v", jsonOut, err)
+	}
+
+	var xmlOut struct {
+		Events []models.Event `xml:"Event"`
+	}
+	out := xmlFormatter{}.FormatBatch(events)
+	if err := xml.Unmarshal(out, &xmlOut); err != nil || len(xmlOut.Events) != 3 || !strings.HasPrefix(string(out), "<Events>") {
+		t.Errorf("The XML batch should be an Events element: %s, %v", out, err)
+	}
+
+	rows, err := csv.NewReader(bytes.NewReader(csvFormatter{}.FormatBatch(events))).ReadAll()
+	if err != nil || len(rows) != 4 || !reflect.DeepEqual(rows[0], csvHeader) {
+		t.Errorf("The CSV batch should have a single header row: %v, %v", rows, err)
+	}
+
+	type device struct {
+		Ts     int64             `json:"ts"`
+		Values map[string]string `json:"values"`
+	}
+	var tbOut map[string][]device
+	if err := json.Unmarshal(thingsboardJSONFormatter{}.FormatBatch(events), &tbOut); err != nil {
+		t.Fatalf("Error unmarshalling ThingsBoard JSON: %v", err)
+	}
+	if len(tbOut[devID1]) != 2 || tbOut[devID1][1].Values["temperature"] != "22" || len(tbOut["id2"]) != 1 {
+		t.Errorf("The ThingsBoard batch should group the values by device: %v", tbOut)
+	}
+}
@@ -10,44 +10,308 @@ package distro
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
 	"net/http"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
 	"go.uber.org/zap"
 )
 
+const (
+	httpInitialBackoff = time.Second
+	httpMaxBackoff     = 30 * time.Second
+	httpTimeout        = 10 * time.Second
+	httpRetryQueue     = 100
+)
+
+// Payload waiting for its next attempt, after the attempts it failed
+type httpRetry struct {
+	data     []byte
+	attempts int
+	err      error
+}
+
 type httpSender struct {
-	url    string
-	method string
+	senderStats
+	client         *http.Client
+	url            string
+	method         string
+	registration   string
+	secret         []byte
+	headers        map[string]string
+	contentType    string // Media type of the payloads
+	encoding       string // Content coding of the payloads
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	sleep          func(time.Duration) bool // False when the sender was closed while waiting
+	deadLetter     func(export.DeadLetter)
+
+	retries      chan httpRetry
+	failed       chan httpRetry // Payloads of Send dead lettered off the registration loop
+	done         chan struct{}
+	stopped      chan struct{}
+	deadLettered chan struct{}
+	once         sync.Once
 }
 
-const mimeTypeJSON = "application/json"
+const (
+	mimeTypeJSON     = "application/json"
+	mimeTypeProtobuf = "application/x-protobuf"
+)
 
-// NewHTTPSender - create http sender
-func NewHTTPSender(addr models.Addressable) Sender {
+// NewHTTPSender - create http sender of the registration
+// The payloads are signed with the HMAC secret, retried with an exponential backoff and, once
+// the attempts are exhausted, dead lettered. The retries and the dead letters wait in queues of
+// the sender, so the registration keeps sending the next payloads
+func NewHTTPSender(registration string, addr models.Addressable, details export.HTTPDetails) Sender {
 
-	sender := httpSender{
-		url:    addr.Protocol + "://" + addr.Address + ":" + strconv.Itoa(addr.Port) + addr.Path,
-		method: addr.HTTPMethod,
+	sender := &httpSender{
+		client:         &http.Client{Timeout: httpTimeout},
+		url:            addr.Protocol + "://" + addr.Address + ":" + strconv.Itoa(addr.Port) + addr.Path,
+		method:         addr.HTTPMethod,
+		registration:   registration,
+		headers:        details.Headers,
+		contentType:    mimeTypeJSON,
+		maxAttempts:    details.MaxAttempts,
+		initialBackoff: time.Duration(details.InitialBackoff) * time.Millisecond,
+		maxBackoff:     time.Duration(details.MaxBackoff) * time.Millisecond,
+		deadLetter:     storeDeadLetter,
+		retries:        make(chan httpRetry, httpRetryQueue),
+		failed:         make(chan httpRetry, httpRetryQueue),
+		done:           make(chan struct{}),
+		stopped:        make(chan struct{}),
+		deadLettered:   make(chan struct{}),
+	}
+	sender.sleep = sender.backoff
+	if details.Secret != "" {
+		sender.secret = []byte(details.Secret)
+	}
+	if sender.maxAttempts <= 0 {
+		sender.maxAttempts = 1
+	}
+	if sender.initialBackoff <= 0 {
+		sender.initialBackoff = httpInitialBackoff
 	}
+	if sender.maxBackoff <= 0 {
+		sender.maxBackoff = httpMaxBackoff
+	}
+	go sender.retryLoop()
+	go sender.deadLetterLoop()
 	return sender
 }
 
-func (sender httpSender) Send(data []byte) {
-	switch sender.method {
-	case http.MethodPost:
-		response, err := http.Post(sender.url, mimeTypeJSON, bytes.NewReader(data))
-		if err != nil {
-			logger.Error("Error: ", zap.Error(err))
-			return
-		}
-		defer response.Body.Close()
-		logger.Info("Response: ", zap.String("status", response.Status))
-	default:
+func (sender *httpSender) setContentType(contentType string) {
+	sender.contentType = contentType
+}
+
+func (sender *httpSender) setContentEncoding(encoding string) {
+	sender.encoding = encoding
+}
+
+// Signature of the payload, sha256=<hex of its HMAC-SHA256>
+func httpSignature(secret, data []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(data)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send the payload, and queue it for the next attempts when the endpoint may take it later
+func (sender *httpSender) Send(data []byte) {
+	if sender.method != http.MethodPost {
 		logger.Info("Unsupported method: ", zap.String("method", sender.method))
 		return
 	}
 
-	logger.Info("Sent data: ", zap.ByteString("data", data))
+	retry, err := sender.post(data)
+	if err == nil {
+		logger.Info("Sent data: ", zap.ByteString("data", data))
+		sender.countSent(1)
+		return
+	}
+	r := httpRetry{data: data, attempts: 1, err: err}
+	if !retry || sender.maxAttempts <= 1 {
+		sender.queueFail(r)
+		return
+	}
+	select {
+	case sender.retries <- r:
+		logger.Warn("Error, retrying: ", zap.Error(err), zap.Int("attempt", 1))
+		sender.countRetried(err)
+	default:
+		logger.Warn("Too many payloads retried")
+		sender.queueFail(r)
+	}
+}
+
+// Queue the payload to be dead lettered, posting the dead letter could block the registration
+// for the dead letter timeout
+func (sender *httpSender) queueFail(r httpRetry) {
+	select {
+	case sender.failed <- r:
+	default:
+		logger.Error("Too many payloads dead lettered, drop data", zap.Error(r.err), zap.String("registration", sender.registration))
+		sender.countFailed(1, r.err)
+	}
+}
+
+// Dead letter the payloads queued by Send, until the sender is closed
+func (sender *httpSender) deadLetterLoop() {
+	defer close(sender.deadLettered)
+	for r := range sender.failed {
+		sender.fail(r.data, r.err, r.attempts)
+	}
+}
+
+// forward - post the payload once, the error says the endpoint may take it later
+func (sender *httpSender) forward(data []byte) error {
+	if sender.method != http.MethodPost {
+		logger.Info("Unsupported method: ", zap.String("method", sender.method))
+		return nil
+	}
+
+	retry, err := sender.post(data)
+	if err == nil {
+		logger.Info("Sent data: ", zap.ByteString("data", data))
+		sender.countSent(1)
+		return nil
+	}
+	if retry {
+		logger.Warn("Error: ", zap.Error(err))
+		sender.countFailed(1, err)
+		return err
+	}
+	sender.fail(data, err, 1)
+	return nil
+}
+
+// Retry the queued payloads one after the other, until the sender is closed
+func (sender *httpSender) retryLoop() {
+	defer close(sender.stopped)
+	for {
+		select {
+		case r := <-sender.retries:
+			sender.retry(r)
+		case <-sender.done:
+			// The payloads still queued are dead lettered, to be replayed
+			for {
+				select {
+				case r := <-sender.retries:
+					sender.fail(r.data, r.err, r.attempts)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// Post the payload after the backoff of its attempts, until the endpoint takes it or the
+// attempts are exhausted
+func (sender *httpSender) retry(r httpRetry) {
+	backoff := sender.initialBackoff
+	for i := 1; i < r.attempts; i++ {
+		if backoff *= 2; backoff > sender.maxBackoff {
+			backoff = sender.maxBackoff
+		}
+	}
+	for {
+		if !sender.sleep(backoff) {
+			sender.fail(r.data, r.err, r.attempts)
+			return
+		}
+		r.attempts++
+		var retry bool
+		retry, r.err = sender.post(r.data)
+		if r.err == nil {
+			logger.Info("Sent data: ", zap.ByteString("data", r.data), zap.Int("attempts", r.attempts))
+			sender.countSent(1)
+			return
+		}
+		if !retry || r.attempts >= sender.maxAttempts {
+			sender.fail(r.data, r.err, r.attempts)
+			return
+		}
+		logger.Warn("Error, retrying: ", zap.Error(r.err), zap.Int("attempt", r.attempts), zap.Duration("backoff", backoff))
+		sender.countRetried(r.err)
+		if backoff *= 2; backoff > sender.maxBackoff {
+			backoff = sender.maxBackoff
+		}
+	}
+}
+
+// Wait for the backoff, return false when the sender is closed first
+func (sender *httpSender) backoff(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-sender.done:
+		return false
+	}
+}
+
+// Dead letter the payload the endpoint didn't take
+func (sender *httpSender) fail(data []byte, err error, attempts int) {
+	logger.Error("Error: ", zap.Error(err), zap.Int("attempts", attempts))
+	sender.countFailed(1, err)
+	sender.deadLetter(export.DeadLetter{
+		Registration: sender.registration,
+		Destination:  export.DestRest,
+		Payload:      data,
+		Error:        err.Error(),
+		Attempts:     attempts,
+	})
+}
+
+// Close - stop the retries, the payloads waiting for them are dead lettered, and wait for the
+// queued dead letters to be stored
+func (sender *httpSender) Close() error {
+	sender.once.Do(func() {
+		close(sender.done)
+		<-sender.stopped
+		close(sender.failed)
+		<-sender.deadLettered
+	})
+	return nil
+}
+
+// Post the payload, retry when the endpoint may accept it later
+func (sender *httpSender) post(data []byte) (bool, error) {
+	request, err := http.NewRequest(http.MethodPost, sender.url, bytes.NewReader(data))
+	if err != nil {
+		return false, err
+	}
+	request.Header.Set("Content-Type", sender.contentType)
+	if sender.encoding != "" {
+		request.Header.Set("Content-Encoding", sender.encoding)
+	}
+	for name, value := range sender.headers {
+		request.Header.Set(name, value)
+	}
+	if sender.secret != nil {
+		request.Header.Set(export.SignatureHeader, httpSignature(sender.secret, data))
+	}
+
+	response, err := sender.client.Do(request)
+	if err != nil {
+		return true, err
+	}
+	defer response.Body.Close()
+	ioutil.ReadAll(response.Body)
+	logger.Info("Response: ", zap.String("status", response.Status))
+	if response.StatusCode/100 == 2 {
+		return false, nil
+	}
+	return response.StatusCode >= 500 || response.StatusCode == http.StatusTooManyRequests,
+		fmt.Errorf("endpoint returned %s", response.Status)
 }
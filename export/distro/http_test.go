@@ -8,15 +8,22 @@ package distro
 
 import (
 	"bytes"
+	"compress/gzip"
+	"encoding/json"
 	"io/ioutil"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+	"github.com/klauspost/compress/zstd"
+	"go.uber.org/zap"
 )
 
 const ()
@@ -100,8 +107,321 @@ func TestHttpSender(t *testing.T) {
 			if addressableTest.Port == 0 {
 				addressableTest.Port = port
 			}
-			sender := NewHTTPSender(addressableTest)
+			sender := NewHTTPSender("reg", addressableTest, export.HTTPDetails{}).(*httpSender)
+			defer sender.Close()
 			sender.Send(msg)
 		})
 	}
 }
+
+func TestHttpSenderSignature(t *testing.T) {
+	logger = zap.NewNop()
+	var header http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+	}))
+	defer ts.Close()
+
+	addr := testHTTPAddressable(t, ts.URL)
+	details := export.HTTPDetails{Secret: "secret", Headers: map[string]string{"X-Api-Key": "key"}}
+	sender := NewHTTPSender("reg", addr, details).(*httpSender)
+	defer sender.Close()
+	sender.Send([]byte("test message"))
+
+	// echo -n "test message" | openssl dgst -sha256 -hmac secret
+	expected := "sha256=3bcebf43c85d20bba6e3b6ba278af1d2ba3ab0d57de271b0ad30b833e851c5a6"
+	if header.Get(export.SignatureHeader) != expected || header.Get("X-Api-Key") != "key" {
+		t.Errorf("The payload should be signed with the custom headers: %v", header)
+	}
+}
+
+func TestHttpSenderRetry(t *testing.T) {
+	logger = zap.NewNop()
+
+	var tests = []struct {
+		name       string
+		statuses   []int
+		attempts   int
+		backoffs   []time.Duration
+		deadLetter bool
+		stats      export.RegistrationStats
+	}{
+		{"success", []int{http.StatusOK}, 1, nil, false, export.RegistrationStats{Sent: 1}},
+		{"retried", []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK}, 3,
+			[]time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, false, export.RegistrationStats{Sent: 1, Retried: 2}},
+		{"exhausted", []int{http.StatusInternalServerError}, 4,
+			[]time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 250 * time.Millisecond}, true,
+			export.RegistrationStats{Failed: 1, Retried: 3}},
+		{"refused", []int{http.StatusBadRequest}, 1, nil, true, export.RegistrationStats{Failed: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			last := make(chan struct{})
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[len(tt.statuses)-1]
+				if attempts < len(tt.statuses) {
+					status = tt.statuses[attempts]
+				}
+				if attempts++; attempts == tt.attempts {
+					close(last)
+				}
+				w.WriteHeader(status)
+			}))
+			defer ts.Close()
+
+			addr := testHTTPAddressable(t, ts.URL)
+			details := export.HTTPDetails{MaxAttempts: 4, InitialBackoff: 100, MaxBackoff: 250}
+			sender := NewHTTPSender("reg", addr, details).(*httpSender)
+			var backoffs []time.Duration
+			sender.sleep = func(d time.Duration) bool {
+				backoffs = append(backoffs, d)
+				return true
+			}
+			var letters []export.DeadLetter
+			sender.deadLetter = func(letter export.DeadLetter) { letters = append(letters, letter) }
+			stats := &registrationStats{}
+			sender.setStats(stats)
+			sender.Send([]byte("test message"))
+			// Once the endpoint got the last attempt, closing waits for the retry to finish
+			select {
+			case <-last:
+			case <-time.After(5 * time.Second):
+				t.Fatal("The endpoint should get the attempts")
+			}
+			sender.Close()
+
+			if attempts != tt.attempts || !reflect.DeepEqual(backoffs, tt.backoffs) {
+				t.Errorf("%d attempts after %v, expected %d after %v", attempts, backoffs, tt.attempts, tt.backoffs)
+			}
+			if (len(letters) == 1) != tt.deadLetter {
+				t.Fatalf("Dead letters %v, expected %v", letters, tt.deadLetter)
+			}
+			if tt.deadLetter {
+				letter := letters[0]
+				if letter.Registration != "reg" || letter.Destination != export.DestRest || string(letter.Payload) != "test message" ||
+					letter.Attempts != tt.attempts || letter.Error == "" {
+					t.Errorf("The dead letter should have the payload and the failure: %v", letter)
+				}
+			}
+			s := stats.snapshot()
+			if s.Sent != tt.stats.Sent || s.Failed != tt.stats.Failed || s.Retried != tt.stats.Retried {
+				t.Errorf("Stats %+v, expected %+v", s, tt.stats)
+			}
+		})
+	}
+}
+
+func TestHttpSenderForward(t *testing.T) {
+	logger = zap.NewNop()
+
+	status := http.StatusServiceUnavailable
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+
+	sender := NewHTTPSender("reg", testHTTPAddressable(t, ts.URL), export.HTTPDetails{MaxAttempts: 2}).(*httpSender)
+	defer sender.Close()
+	var letters []export.DeadLetter
+	sender.deadLetter = func(letter export.DeadLetter) { letters = append(letters, letter) }
+
+	if err := sender.forward([]byte("test message")); err == nil || len(letters) != 0 {
+		t.Errorf("The payload the endpoint may take later should be returned, not dead lettered: %v", err)
+	}
+	status = http.StatusBadRequest
+	if err := sender.forward([]byte("test message")); err != nil || len(letters) != 1 {
+		t.Errorf("The payload the endpoint refuses should be dead lettered: %v", err)
+	}
+	status = http.StatusOK
+	if err := sender.forward([]byte("test message")); err != nil {
+		t.Errorf("The payload should be sent: %v", err)
+	}
+}
+
+func TestHttpSenderRetryQueue(t *testing.T) {
+	logger = zap.NewNop()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	sender := NewHTTPSender("reg", testHTTPAddressable(t, ts.URL), export.HTTPDetails{MaxAttempts: 3, InitialBackoff: 60000}).(*httpSender)
+	letters := make(chan export.DeadLetter, 2)
+	sender.deadLetter = func(letter export.DeadLetter) { letters <- letter }
+
+	// The retries wait for their backoff while the registration sends the next payloads
+	sent := make(chan struct{})
+	go func() {
+		sender.Send([]byte("first"))
+		sender.Send([]byte("second"))
+		close(sent)
+	}()
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("The payloads should be sent without waiting for the retries")
+	}
+
+	sender.Close()
+	if len(letters) != 2 {
+		t.Fatalf("The payloads waiting for their retries should be dead lettered when closed, %d were", len(letters))
+	}
+	if letter := <-letters; string(letter.Payload) != "first" || letter.Attempts != 1 {
+		t.Errorf("The dead letter should have the attempts made: %+v", letter)
+	}
+}
+
+func TestHttpSenderDeadLetterQueue(t *testing.T) {
+	logger = zap.NewNop()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	sender := NewHTTPSender("reg", testHTTPAddressable(t, ts.URL), export.HTTPDetails{MaxAttempts: 3, InitialBackoff: 60000}).(*httpSender)
+	release := make(chan struct{})
+	letters := make(chan export.DeadLetter, httpRetryQueue+2)
+	sender.deadLetter = func(letter export.DeadLetter) {
+		<-release
+		letters <- letter
+	}
+
+	// The retry queue fills up, the next payload is dead lettered without waiting for the client
+	sent := make(chan struct{})
+	go func() {
+		for i := 0; i < httpRetryQueue+2; i++ {
+			sender.Send([]byte("test message"))
+		}
+		close(sent)
+	}()
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("The payloads should be sent without waiting for the dead letters")
+	}
+
+	close(release)
+	sender.Close()
+	if len(letters) != httpRetryQueue+2 {
+		t.Errorf("The queued payloads should be dead lettered when closed, %d were", len(letters))
+	}
+}
+
+func TestHttpSenderContentEncoding(t *testing.T) {
+	logger = zap.NewNop()
+
+	var tests = []struct {
+		name        string
+		compression string
+		encryption  string
+		encoding    string
+	}{
+		{"none", export.CompNone, export.EncNone, ""},
+		{"gzip", export.CompGzip, export.EncNone, "gzip"},
+		{"zstd", export.CompZstd, export.EncNone, "zstd"},
+		{"encrypted", export.CompGzip, export.EncAes, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var encoding string
+			var body []byte
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				encoding = r.Header.Get("Content-Encoding")
+				body, _ = ioutil.ReadAll(r.Body)
+			}))
+			defer ts.Close()
+
+			r := validRegistration()
+			r.Destination = export.DestRest
+			r.Addressable = testHTTPAddressable(t, ts.URL)
+			r.Compression = tt.compression
+			r.Encryption = export.EncryptionDetails{Algo: tt.encryption, Key: "123", InitVector: "123"}
+			reg := newRegistrationInfo()
+			if err := reg.configure(r); err != nil {
+				t.Fatalf("Error configuring the registration: %v", err)
+			}
+			defer reg.closeSender()
+			reg.filter = nil
+			reg.processEvent(&models.Event{Device: "dev"})
+
+			if encoding != tt.encoding {
+				t.Fatalf("Content coding %q, should be %q", encoding, tt.encoding)
+			}
+			switch encoding {
+			case "gzip":
+				zr, err := gzip.NewReader(bytes.NewReader(body))
+				if err != nil {
+					t.Fatalf("Error reading the gzip body: %v", err)
+				}
+				body, _ = ioutil.ReadAll(zr)
+			case "zstd":
+				zr, _ := zstd.NewReader(nil)
+				defer zr.Close()
+				body, _ = zr.DecodeAll(body, nil)
+			}
+			event := models.Event{}
+			if tt.encryption == export.EncNone && (json.Unmarshal(body, &event) != nil || event.Device != "dev") {
+				t.Errorf("The body should decode to the event: %q", body)
+			}
+		})
+	}
+}
+
+func TestHttpSenderContentType(t *testing.T) {
+	logger = zap.NewNop()
+
+	var tests = []struct {
+		name        string
+		format      string
+		compression string
+		encryption  string
+		contentType string
+	}{
+		{"json", export.FormatJSON, export.CompNone, export.EncNone, mimeTypeJSON},
+		{"protobuf", export.FormatProtobuf, export.CompNone, export.EncNone, mimeTypeProtobuf},
+		{"compressedProtobuf", export.FormatProtobuf, export.CompGzip, export.EncNone, mimeTypeProtobuf},
+		{"encryptedProtobuf", export.FormatProtobuf, export.CompNone, export.EncAes, mimeTypeJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var contentType string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				contentType = r.Header.Get("Content-Type")
+			}))
+			defer ts.Close()
+
+			r := validRegistration()
+			r.Format = tt.format
+			r.Destination = export.DestRest
+			r.Addressable = testHTTPAddressable(t, ts.URL)
+			r.Compression = tt.compression
+			r.Encryption = export.EncryptionDetails{Algo: tt.encryption, Key: "123", InitVector: "123"}
+			reg := newRegistrationInfo()
+			if err := reg.configure(r); err != nil {
+				t.Fatalf("Error configuring the registration: %v", err)
+			}
+			defer reg.closeSender()
+			reg.filter = nil
+			reg.processEvent(&models.Event{Device: "dev"})
+
+			if contentType != tt.contentType {
+				t.Errorf("Content type %q, should be %q", contentType, tt.contentType)
+			}
+		})
+	}
+}
+
+func testHTTPAddressable(t *testing.T, rawurl string) models.Addressable {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		t.Fatal("Could not parse url")
+	}
+	port, _ := strconv.Atoi(u.Port())
+	return models.Addressable{Protocol: "http", HTTPMethod: http.MethodPost, Address: u.Hostname(), Port: port, Path: "/hook"}
+}
@@ -0,0 +1,274 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+	"go.uber.org/zap"
+)
+
+const (
+	influxDBPort          = 8086
+	influxDBBatchSize     = 1000
+	influxDBFlushInterval = time.Second
+	influxDBTimeout       = 10 * time.Second
+	// Batches kept while the server is unavailable, the oldest points are dropped beyond
+	influxDBMaxBatches = 10
+)
+
+var (
+	influxDBMeasurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `, "\n", `\n`)
+	influxDBTagEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `, "\n", `\n`)
+	influxDBStringEscaper      = strings.NewReplacer(`"`, `\"`, `\`, `\\`, "\n", `\n`)
+)
+
+type influxDBLineFormatter struct {
+	labels map[string]string
+}
+
+// InfluxDB line protocol formatter
+// https://docs.influxdata.com/influxdb/v1.7/write_protocols/line_protocol_reference/
+// A point per reading, measured by its value descriptor, tagged with its device and the
+// labels, with a millisecond timestamp. Binary readings are skipped
+func (influxTr influxDBLineFormatter) Format(event *models.Event) []byte {
+	keys := make([]string, 0, len(influxTr.labels))
+	for k := range influxTr.labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	for _, reading := range event.Readings {
+		if reading.Name == "" || len(reading.BinaryValue) > 0 {
+			continue
+		}
+		device := reading.Device
+		if device == "" {
+			device = event.Device
+		}
+
+		buf.WriteString(influxDBMeasurementEscaper.Replace(reading.Name))
+		if device != "" {
+			buf.WriteString(",device=" + influxDBTagEscaper.Replace(device))
+		}
+		for _, k := range keys {
+			if v := influxTr.labels[k]; v != "" {
+				buf.WriteString("," + influxDBTagEscaper.Replace(k) + "=" + influxDBTagEscaper.Replace(v))
+			}
+		}
+		buf.WriteString(" value=" + influxDBFieldValue(reading.Value))
+
+		timestamp := reading.Origin
+		if timestamp == 0 {
+			timestamp = event.Origin
+		}
+		if timestamp == 0 {
+			timestamp = reading.Created
+		}
+		if timestamp != 0 {
+			buf.WriteString(" " + strconv.FormatInt(timestamp, 10))
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// Numbers are floats, so a descriptor keeps one field type, booleans stay booleans and the
+// other values are strings
+func influxDBFieldValue(value string) string {
+	if f, err := strconv.ParseFloat(value, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	if b, err := strconv.ParseBool(value); err == nil {
+		return strconv.FormatBool(b)
+	}
+	return `"` + influxDBStringEscaper.Replace(value) + `"`
+}
+
+type influxDBSender struct {
+	senderStats
+	client    *http.Client
+	url       string
+	user      string
+	password  string
+	token     string
+	batchSize int
+
+	mutex  sync.Mutex
+	points [][]byte // Lines waiting to be written
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewInfluxDBSender - create new InfluxDB sender
+// The points are written in batches, once a batch is full or the flush interval passed, and
+// kept for the next flush while the server is unavailable
+func NewInfluxDBSender(addr models.Addressable, details export.InfluxDBDetails) Sender {
+	protocol := addr.Protocol
+	if protocol == "" {
+		protocol = "http"
+	}
+	port := addr.Port
+	if port == 0 {
+		port = influxDBPort
+	}
+
+	query := url.Values{}
+	query.Set("precision", "ms")
+	path := "/write"
+	if details.Bucket != "" {
+		path = "/api/v2/write"
+		query.Set("org", details.Organization)
+		query.Set("bucket", details.Bucket)
+	} else {
+		query.Set("db", details.Database)
+		if details.RetentionPolicy != "" {
+			query.Set("rp", details.RetentionPolicy)
+		}
+	}
+
+	sender := &influxDBSender{
+		client:    &http.Client{Timeout: influxDBTimeout},
+		url:       strings.ToLower(protocol) + "://" + addr.Address + ":" + strconv.Itoa(port) + strings.TrimSuffix(addr.Path, "/") + path + "?" + query.Encode(),
+		user:      addr.User,
+		password:  addr.Password,
+		token:     details.Token,
+		batchSize: details.BatchSize,
+		done:      make(chan struct{}),
+	}
+	if sender.batchSize <= 0 {
+		sender.batchSize = influxDBBatchSize
+	}
+	interval := time.Duration(details.FlushInterval) * time.Millisecond
+	if interval <= 0 {
+		interval = influxDBFlushInterval
+	}
+
+	go sender.loop(interval)
+	return sender
+}
+
+func (sender *influxDBSender) loop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			sender.flush(true)
+		case <-sender.done:
+			return
+		}
+	}
+}
+
+func (sender *influxDBSender) Send(data []byte) {
+	sender.mutex.Lock()
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) > 0 {
+			sender.points = append(sender.points, line)
+		}
+	}
+	full := len(sender.points) >= sender.batchSize
+	sender.mutex.Unlock()
+
+	if full {
+		sender.flush(false)
+	}
+}
+
+// Write the waiting points a batch at a time, the last one even when it isn't full if all. The
+// points dropped are dead lettered once the points are unlocked
+func (sender *influxDBSender) flush(all bool) {
+	var dropped [][]byte
+	var droppedErr error
+	sender.mutex.Lock()
+	defer func() {
+		sender.mutex.Unlock()
+		if len(dropped) > 0 {
+			sender.deadLetterPayload(bytes.Join(dropped, []byte("\n")), droppedErr, 1)
+		}
+	}()
+
+	for len(sender.points) >= sender.batchSize || (all && len(sender.points) > 0) {
+		n := sender.batchSize
+		if n > len(sender.points) {
+			n = len(sender.points)
+		}
+		retry, err := sender.write(bytes.Join(sender.points[:n], []byte("\n")))
+		if err != nil && retry {
+			logger.Warn("InfluxDB unavailable, keep points", zap.Error(err), zap.Int("points", len(sender.points)))
+			sender.countRetried(err)
+			if max := influxDBMaxBatches * sender.batchSize; len(sender.points) > max {
+				logger.Warn("InfluxDB buffer full, drop points", zap.Int("points", len(sender.points)-max))
+				sender.countFailed(len(sender.points)-max, err)
+				dropped, droppedErr = append(dropped, sender.points[:len(sender.points)-max]...), err
+				sender.points = sender.points[len(sender.points)-max:]
+			}
+			return
+		}
+		if err != nil {
+			logger.Warn("InfluxDB refused the points, drop them", zap.Error(err), zap.Int("points", n))
+			sender.countFailed(n, err)
+			dropped, droppedErr = append(dropped, sender.points[:n]...), err
+		} else {
+			logger.Debug("Sent points: ", zap.Int("points", n))
+			sender.countSent(n)
+		}
+		sender.points = sender.points[n:]
+	}
+	if len(sender.points) == 0 {
+		sender.points = nil
+	}
+}
+
+// Write a batch, retry when the server may accept it later
+func (sender *influxDBSender) write(batch []byte) (bool, error) {
+	request, err := http.NewRequest(http.MethodPost, sender.url, bytes.NewReader(batch))
+	if err != nil {
+		return false, err
+	}
+	request.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if sender.token != "" {
+		request.Header.Set("Authorization", "Token "+sender.token)
+	} else if sender.user != "" {
+		request.SetBasicAuth(sender.user, sender.password)
+	}
+
+	response, err := sender.client.Do(request)
+	if err != nil {
+		return true, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 == 2 {
+		return false, nil
+	}
+	body, _ := ioutil.ReadAll(response.Body)
+	return response.StatusCode >= 500 || response.StatusCode == http.StatusTooManyRequests,
+		fmt.Errorf("%s: %s", response.Status, strings.TrimSpace(string(body)))
+}
+
+// Close - write the waiting points and stop flushing
+func (sender *influxDBSender) Close() error {
+	sender.once.Do(func() {
+		close(sender.done)
+		sender.flush(true)
+	})
+	return nil
+}
@@ -0,0 +1,166 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+	"go.uber.org/zap"
+)
+
+func TestInfluxDBLineFormat(t *testing.T) {
+	event := models.Event{Device: "my device", Origin: 1000, Readings: []models.Reading{
+		{Name: "temperature", Value: "21.5", Origin: 2000},
+		{Name: "count", Value: "42", Device: "other,device"},
+		{Name: "open", Value: "true"},
+		{Name: "status", Value: `say "hi"`},
+		{Name: "image", BinaryValue: []byte{1}},
+		{Name: "nan", Value: "NaN"},
+	}}
+
+	out := influxDBLineFormatter{labels: map[string]string{"site": "a=b", "floor": "2", "empty": ""}}.Format(&event)
+	expected := `temperature,device=my\ device,floor=2,site=a\=b value=21.5 2000
+count,device=other\,device,floor=2,site=a\=b value=42 1000
+open,device=my\ device,floor=2,site=a\=b value=true 1000
+status,device=my\ device,floor=2,site=a\=b value="say \"hi\"" 1000
+nan,device=my\ device,floor=2,site=a\=b value="NaN" 1000
+`
+	if string(out) != expected {
+		t.Errorf("Invalid line protocol, got:\n%s\nexpected:\n%s", out, expected)
+	}
+}
+
+type influxDBServer struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   []string
+}
+
+func newInfluxDBServer() *influxDBServer {
+	s := &influxDBServer{status: http.StatusNoContent}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		s.requests = append(s.requests, r)
+		s.bodies = append(s.bodies, string(body))
+		w.WriteHeader(s.status)
+	}))
+	return s
+}
+
+func (s *influxDBServer) addressable() models.Addressable {
+	u, _ := url.Parse(s.URL)
+	port, _ := strconv.Atoi(u.Port())
+	return models.Addressable{Address: u.Hostname(), Port: port, User: "user", Password: "pass"}
+}
+
+func (s *influxDBServer) written() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string(nil), s.bodies...)
+}
+
+func TestInfluxDBSenderBatch(t *testing.T) {
+	logger = zap.NewNop()
+	server := newInfluxDBServer()
+	defer server.Close()
+
+	// A flush interval longer than the test, only full batches are written
+	sender := NewInfluxDBSender(server.addressable(), export.InfluxDBDetails{Database: "edgex", BatchSize: 2, FlushInterval: 60000})
+	sender.Send([]byte("a value=1 1\n"))
+	if len(server.written()) != 0 {
+		t.Fatal("A point shouldn't be written before its batch is full")
+	}
+	sender.Send([]byte("b value=2 2\nc value=3 3\n"))
+	if bodies := server.written(); len(bodies) != 1 || bodies[0] != "a value=1 1\nb value=2 2" {
+		t.Fatalf("The full batch should be written, not %v", bodies)
+	}
+
+	r := server.requests[0]
+	if user, pass, _ := r.BasicAuth(); r.URL.Path != "/write" || r.URL.Query().Get("db") != "edgex" ||
+		r.URL.Query().Get("precision") != "ms" || user != "user" || pass != "pass" {
+		t.Errorf("The points should be written to the database with the credentials: %v", r.URL)
+	}
+
+	sender.(*influxDBSender).Close()
+	if bodies := server.written(); len(bodies) != 2 || bodies[1] != "c value=3 3" {
+		t.Fatalf("The waiting points should be written on close, not %v", bodies)
+	}
+}
+
+func TestInfluxDBSenderFlushInterval(t *testing.T) {
+	logger = zap.NewNop()
+	server := newInfluxDBServer()
+	defer server.Close()
+
+	details := export.InfluxDBDetails{Organization: "org", Bucket: "edgex", Token: "secret", FlushInterval: 10}
+	sender := NewInfluxDBSender(server.addressable(), details).(*influxDBSender)
+	defer sender.Close()
+	sender.Send([]byte("a value=1 1\n"))
+
+	for i := 0; i < 100 && len(server.written()) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if bodies := server.written(); len(bodies) != 1 {
+		t.Fatalf("The point should be written after the flush interval")
+	}
+	r := server.requests[0]
+	if r.URL.Path != "/api/v2/write" || r.URL.Query().Get("org") != "org" || r.URL.Query().Get("bucket") != "edgex" ||
+		r.Header.Get("Authorization") != "Token secret" {
+		t.Errorf("The points should be written to the bucket with the token: %v", r.URL)
+	}
+}
+
+func TestInfluxDBSenderRetry(t *testing.T) {
+	logger = zap.NewNop()
+	server := newInfluxDBServer()
+	defer server.Close()
+
+	sender := NewInfluxDBSender(server.addressable(), export.InfluxDBDetails{Database: "edgex", FlushInterval: 60000}).(*influxDBSender)
+	defer sender.Close()
+
+	server.status = http.StatusServiceUnavailable
+	sender.Send([]byte("a value=1 1\n"))
+	sender.flush(true)
+	if len(sender.points) != 1 {
+		t.Fatal("The points should be kept while the server is unavailable")
+	}
+
+	var letters []export.DeadLetter
+	sender.setDeadLetter("reg", export.DestInfluxDB, func(letter export.DeadLetter) { letters = append(letters, letter) })
+	server.status = http.StatusBadRequest
+	sender.flush(true)
+	if len(sender.points) != 0 {
+		t.Fatal("The points refused by the server should be dropped")
+	}
+	if len(letters) != 1 || string(letters[0].Payload) != "a value=1 1" {
+		t.Fatalf("The points refused should be dead lettered: %v", letters)
+	}
+
+	server.status = http.StatusServiceUnavailable
+	sender.batchSize = 1
+	sender.Send([]byte(strings.Repeat("a value=1 1\n", influxDBMaxBatches+5)))
+	if len(sender.points) != influxDBMaxBatches {
+		t.Fatalf("The oldest points should be dropped beyond %d batches, %d kept", influxDBMaxBatches, len(sender.points))
+	}
+	if len(letters) != 2 || string(letters[1].Payload) != strings.TrimSuffix(strings.Repeat("a value=1 1\n", 5), "\n") {
+		t.Errorf("The points dropped should be dead lettered: %v", letters)
+	}
+}
@@ -0,0 +1,178 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"strconv"
+	"strings"
+
+	"github.com/IBM/sarama"
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+	"github.com/xdg-go/scram"
+	"go.uber.org/zap"
+)
+
+const kafkaDefaultKey = "{device}"
+
+type kafkaSender struct {
+	senderStats
+	config   *sarama.Config
+	brokers  []string
+	topic    string
+	key      string
+	producer sarama.SyncProducer
+}
+
+// NewKafkaSender - create new kafka sender
+func NewKafkaSender(addr models.Addressable, details export.KafkaDetails) Sender {
+	config, err := newKafkaConfig(addr, details)
+	if err != nil {
+		logger.Error("Invalid kafka configuration", zap.Error(err))
+		return nil
+	}
+
+	brokers := details.Brokers
+	if len(brokers) == 0 {
+		brokers = []string{addr.Address + ":" + strconv.Itoa(addr.Port)}
+	}
+	key := details.KeyTemplate
+	if key == "" {
+		key = kafkaDefaultKey
+	}
+
+	sender := &kafkaSender{
+		config:  config,
+		brokers: brokers,
+		topic:   addr.Topic,
+		key:     key,
+	}
+
+	return sender
+}
+
+func newKafkaConfig(addr models.Addressable, details export.KafkaDetails) (*sarama.Config, error) {
+	config := sarama.NewConfig()
+	if addr.Publisher != "" {
+		config.ClientID = addr.Publisher
+	}
+	config.Producer.Return.Successes = true
+	if details.MaxRetries > 0 {
+		config.Producer.Retry.Max = details.MaxRetries
+	}
+	if details.Idempotent {
+		config.Producer.Idempotent = true
+		config.Producer.RequiredAcks = sarama.WaitForAll
+		config.Net.MaxOpenRequests = 1
+	}
+
+	protocol := strings.ToLower(addr.Protocol)
+	if protocol == "tcps" ||
+		protocol == "ssl" ||
+		protocol == "tls" {
+
+		tlsConfig, err := newTLSConfig(configuration.KafkaCACert, configuration.KafkaCert,
+			configuration.KafkaKey, details.TLSSkipVerify)
+		if err != nil {
+			return nil, err
+		}
+		config.Net.TLS.Enable = true
+		config.Net.TLS.Config = tlsConfig
+	}
+
+	if details.SASLMechanism != export.KafkaSASLNone {
+		config.Net.SASL.Enable = true
+		config.Net.SASL.Mechanism = sarama.SASLMechanism(details.SASLMechanism)
+		config.Net.SASL.User = addr.User
+		config.Net.SASL.Password = addr.Password
+		switch details.SASLMechanism {
+		case export.KafkaSASLScramSHA256:
+			config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+				return &scramClient{hash: sha256.New}
+			}
+		case export.KafkaSASLScramSHA512:
+			config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+				return &scramClient{hash: sha512.New}
+			}
+		}
+	}
+
+	return config, config.Validate()
+}
+
+func (sender *kafkaSender) Send(data []byte) {
+	sender.SendEvent(data, nil)
+}
+
+// SendEvent - send the data with the partition key of the event
+func (sender *kafkaSender) SendEvent(data []byte, event *models.Event) {
+	if sender.producer == nil {
+		logger.Info("Connecting to kafka brokers")
+		producer, err := sarama.NewSyncProducer(sender.brokers, sender.config)
+		if err != nil {
+			logger.Warn("Could not connect to kafka brokers", zap.Error(err))
+			sender.countFailed(1, err)
+			sender.deadLetterPayload(data, err, 1)
+			return
+		}
+		sender.producer = producer
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic: sender.topic,
+		Value: sarama.ByteEncoder(data),
+	}
+	if event != nil {
+		msg.Key = sarama.StringEncoder(eventKey(sender.key, event))
+	}
+
+	partition, offset, err := sender.producer.SendMessage(msg)
+	if err != nil {
+		logger.Warn("kafka error: ", zap.Error(err))
+		sender.countFailed(1, err)
+		sender.deadLetterPayload(data, err, sender.config.Producer.Retry.Max+1)
+	} else {
+		logger.Debug("Sent data: ", zap.ByteString("data", data),
+			zap.Int32("partition", partition), zap.Int64("offset", offset))
+		sender.countSent(1)
+	}
+}
+
+// Close - close the producer, so a replaced registration doesn't leak its connections
+func (sender *kafkaSender) Close() error {
+	if sender.producer == nil {
+		return nil
+	}
+	err := sender.producer.Close()
+	sender.producer = nil
+	return err
+}
+
+// SCRAM conversation of the SASL authentication
+type scramClient struct {
+	hash         scram.HashGeneratorFcn
+	conversation *scram.ClientConversation
+}
+
+func (c *scramClient) Begin(userName, password, authzID string) error {
+	client, err := c.hash.NewClient(userName, password, authzID)
+	if err != nil {
+		return err
+	}
+	c.conversation = client.NewConversation()
+	return nil
+}
+
+func (c *scramClient) Step(challenge string) (string, error) {
+	return c.conversation.Step(challenge)
+}
+
+func (c *scramClient) Done() bool {
+	return c.conversation.Done()
+}
@@ -0,0 +1,113 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+	"go.uber.org/zap"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func kafkaAddressable() models.Addressable {
+	return models.Addressable{
+		Protocol:  "TCP",
+		Address:   "localhost",
+		Port:      9092,
+		Publisher: "edgex-export",
+		User:      "edgex",
+		Password:  "secret",
+		Topic:     "edgex-events",
+	}
+}
+
+func TestEventKey(t *testing.T) {
+	event := &models.Event{ID: bson.ObjectIdHex("57ed24f0502fdf73bb637917"), Device: "thermostat"}
+	var tests = []struct {
+		template string
+		key      string
+	}{
+		{kafkaDefaultKey, "thermostat"},
+		{"{device}-{id}", "thermostat-57ed24f0502fdf73bb637917"},
+		{"static", "static"},
+	}
+	for _, tt := range tests {
+		if key := eventKey(tt.template, event); key != tt.key {
+			t.Errorf("Key of %s should be %s, not %s", tt.template, tt.key, key)
+		}
+	}
+}
+
+func TestKafkaConfig(t *testing.T) {
+	logger = zap.NewNop()
+
+	sender := NewKafkaSender(kafkaAddressable(), export.KafkaDetails{})
+	if sender == nil {
+		t.Fatal("The kafka sender should be created")
+	}
+	ks := sender.(*kafkaSender)
+	if len(ks.brokers) != 1 || ks.brokers[0] != "localhost:9092" {
+		t.Fatalf("The broker should be the addressable, not %v", ks.brokers)
+	}
+	if ks.config.Net.SASL.Enable || ks.config.Net.TLS.Enable || ks.config.ClientID != "edgex-export" {
+		t.Fatal("Only the client id of the addressable should be set")
+	}
+
+	config, err := newKafkaConfig(kafkaAddressable(), export.KafkaDetails{
+		SASLMechanism: export.KafkaSASLScramSHA512,
+		Idempotent:    true,
+		MaxRetries:    5,
+	})
+	if err != nil {
+		t.Fatalf("Error creating the kafka configuration: %v", err)
+	}
+	if !config.Producer.Idempotent || config.Producer.RequiredAcks != sarama.WaitForAll ||
+		config.Net.MaxOpenRequests != 1 || config.Producer.Retry.Max != 5 {
+		t.Fatal("The idempotent producer should wait for all the replicas with a single request in flight")
+	}
+	if !config.Net.SASL.Enable || config.Net.SASL.User != "edgex" || config.Net.SASL.SCRAMClientGeneratorFunc == nil {
+		t.Fatal("SCRAM should be set with the user of the addressable")
+	}
+
+	addr := kafkaAddressable()
+	addr.Protocol = "TLS"
+	config, err = newKafkaConfig(addr, export.KafkaDetails{})
+	if err != nil {
+		t.Fatalf("Error creating the kafka TLS configuration: %v", err)
+	}
+	if !config.Net.TLS.Enable || config.Net.TLS.Config.InsecureSkipVerify {
+		t.Fatal("TLS should be enabled and verify the brokers")
+	}
+}
+
+func TestKafkaSendEvent(t *testing.T) {
+	logger = zap.NewNop()
+
+	producer := mocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		key, _ := msg.Key.Encode()
+		if msg.Topic != "edgex-events" || string(key) != "thermostat" {
+			return fmt.Errorf("unexpected message on %s with key %s", msg.Topic, key)
+		}
+		return nil
+	})
+	sender := &kafkaSender{topic: "edgex-events", key: kafkaDefaultKey, producer: producer}
+
+	// The registration hands the event to the sender to key the data with it
+	reg := registrationInfo{format: jsonFormatter{}, sender: sender}
+	reg.processEvent(&models.Event{Device: "thermostat"})
+
+	reg.closeSender()
+	if sender.producer != nil || reg.sender != nil {
+		t.Fatal("The producer should be closed with the registration")
+	}
+}
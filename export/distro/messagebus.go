@@ -0,0 +1,67 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/messagebus"
+	"go.uber.org/zap"
+)
+
+const (
+	messageBusRetry = 5 * time.Second
+)
+
+// MessageBusReceiver - receive the events from core data over the NATS or JetStream message bus
+func MessageBusReceiver(eventCh chan *models.Event) {
+	go initMessageBus(eventCh)
+}
+
+func initMessageBus(eventCh chan *models.Event) {
+	s, err := messagebus.NewSubscriber(messagebus.Configuration{
+		Type:     configuration.MessageBus,
+		URL:      configuration.NATSURL,
+		Subject:  configuration.NATSSubject,
+		Stream:   configuration.NATSStream,
+		MaxAge:   configuration.NATSStreamMaxAge,
+		Durable:  configuration.NATSDurable,
+		Username: configuration.NATSUsername,
+		Password: configuration.NATSPassword,
+		AckWait:  configuration.NATSAckWait,
+		Timeout:  configuration.NATSTimeout,
+	})
+	if err != nil {
+		logger.Error("Error creating the message bus subscriber", zap.Error(err))
+		return
+	}
+
+	logger.Info("Subscribing to the message bus", zap.String("type", configuration.MessageBus),
+		zap.String("subject", configuration.NATSSubject))
+	// With JetStream the event is acknowledged once queued for the registrations, the events
+	// published while distro is down are received when it's back
+	handler := func(data []byte) error {
+		event := parseEvent(string(data))
+		if event == nil {
+			// It would fail the same way when delivered again
+			return nil
+		}
+		logger.Info("Event received", zap.Any("event", event))
+		eventCh <- event
+		return nil
+	}
+	// The stream can't be set up until the server is reachable
+	for {
+		if err = s.Subscribe(handler); err == nil {
+			break
+		}
+		logger.Error("Error subscribing to the message bus, retrying", zap.Error(err))
+		time.Sleep(messageBusRetry)
+	}
+	logger.Info("Subscribed to the message bus")
+}
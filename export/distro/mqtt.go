@@ -19,6 +19,7 @@ import (
 )
 
 type mqttSender struct {
+	senderStats
 	client MQTT.Client
 	topic  string
 }
@@ -66,11 +67,19 @@ func NewMqttSender(addr models.Addressable) Sender {
 }
 
 func (sender *mqttSender) Send(data []byte) {
+	if err := sender.forward(data); err != nil {
+		sender.deadLetterPayload(data, err, 1)
+	}
+}
+
+// forward - publish the payload, the error says the server didn't take it
+func (sender *mqttSender) forward(data []byte) error {
 	if !sender.client.IsConnected() {
 		logger.Info("Connecting to mqtt server")
 		if token := sender.client.Connect(); token.Wait() && token.Error() != nil {
-			logger.Warn("Could not connect to mqtt server, drop event", zap.Error(token.Error()))
-			return
+			logger.Warn("Could not connect to mqtt server", zap.Error(token.Error()))
+			sender.countFailed(1, token.Error())
+			return token.Error()
 		}
 	}
 
@@ -79,7 +88,10 @@ func (sender *mqttSender) Send(data []byte) {
 	token.Wait()
 	if token.Error() != nil {
 		logger.Warn("mqtt error: ", zap.Error(token.Error()))
-	} else {
-		logger.Debug("Sent data: ", zap.ByteString("data", data))
+		sender.countFailed(1, token.Error())
+		return token.Error()
 	}
+	logger.Debug("Sent data: ", zap.ByteString("data", data))
+	sender.countSent(1)
+	return nil
 }
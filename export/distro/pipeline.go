@@ -0,0 +1,280 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"plugin"
+	"strconv"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+	"go.uber.org/zap"
+)
+
+// Build the stages of the pipeline, the ones transforming the events and, once the events are
+// formatted, the ones transforming the payloads, in order. The plugins aren't opened, and their
+// stages are left out, when the registration is only checked
+func newPipeline(stages []export.TransformStage, check bool) (_ []Filterer, _ []Transformer, err error) {
+	var filters []Filterer
+	var transforms []Transformer
+	// The scripts of the stages built before the one that failed are released
+	defer func() {
+		if err != nil {
+			closeTransforms(transforms)
+		}
+	}()
+	encoded := false
+	aggregated := false
+	for _, s := range stages {
+		if len(transforms) > 0 && !transformsPayload(s.Type) && s.Type != export.StagePlugin {
+			return nil, nil, fmt.Errorf("Stage %s transforms the events after the payloads", s.Name)
+		}
+		if s.Type == export.StageCompress || s.Type == export.StageEncrypt {
+			encoded = true
+		}
+		switch s.Type {
+		case export.StageFilter:
+			if s.Filter == nil {
+				return nil, nil, fmt.Errorf("Stage %s without filter", s.Name)
+			}
+			if len(s.Filter.DeviceIDs) > 0 {
+				filters = append(filters, newDevIdFilter(*s.Filter))
+				logger.Debug("Device ID filter added: ", zap.Any("filters", s.Filter.DeviceIDs))
+			}
+			if len(s.Filter.ValueDescriptorIDs) > 0 {
+				filters = append(filters, newValueDescFilter(*s.Filter))
+				logger.Debug("Value descriptor filter added: ", zap.Any("filters", s.Filter.ValueDescriptorIDs))
+			}
+			if len(s.Filter.ValueFilters) > 0 {
+				filters = append(filters, newValueFilter(*s.Filter))
+				logger.Debug("Value filter added: ", zap.Any("filters", s.Filter.ValueFilters))
+			}
+		case export.StageEnrich:
+			filters = append(filters, enrichStage{readings: s.Readings})
+		case export.StageConvert:
+			filters = append(filters, newConvertStage(s.Conversions))
+		case export.StageAggregate:
+			if s.Aggregation == nil || s.Aggregation.Window <= 0 {
+				return nil, nil, fmt.Errorf("Stage %s without aggregation window", s.Name)
+			}
+			if aggregated {
+				return nil, nil, fmt.Errorf("Stage %s aggregates the events again", s.Name)
+			}
+			aggregated = true
+			filters = append(filters, newAggregateStage(*s.Aggregation))
+		case export.StageCompress:
+			switch s.Compression {
+			case export.CompGzip:
+				transforms = append(transforms, &gzipTransformer{})
+			case export.CompZip:
+				transforms = append(transforms, &zlibTransformer{})
+			case export.CompZstd:
+				transforms = append(transforms, newZstdTransformer())
+			default:
+				return nil, nil, fmt.Errorf("Compression not supported: %s", s.Compression)
+			}
+		case export.StageEncrypt:
+			if s.Encryption == nil {
+				return nil, nil, fmt.Errorf("Stage %s without encryption", s.Name)
+			}
+			switch s.Encryption.Algo {
+			case export.EncAes:
+				transforms = append(transforms, NewAESEncryption(*s.Encryption))
+			case export.EncAesGcm:
+				encryption, err := newGCMEncryption(*s.Encryption)
+				if err != nil {
+					return nil, nil, err
+				}
+				transforms = append(transforms, encryption)
+			case export.EncRsaEnvelope:
+				encryption, err := newEnvelopeEncryption(*s.Encryption)
+				if err != nil {
+					return nil, nil, err
+				}
+				transforms = append(transforms, encryption)
+			default:
+				return nil, nil, fmt.Errorf("Encryption not supported: %s", s.Encryption.Algo)
+			}
+		case export.StageScript:
+			if s.Script == nil {
+				return nil, nil, fmt.Errorf("Stage %s without script", s.Name)
+			}
+			if encoded {
+				return nil, nil, fmt.Errorf("Stage %s runs after the compression or encryption", s.Name)
+			}
+			script, err := newScriptStage(s.Name, *s.Script)
+			if err != nil {
+				return nil, nil, err
+			}
+			transforms = append(transforms, script)
+		case export.StagePlugin:
+			if check {
+				logger.Debug("Plugin stage not checked", zap.String("stage", s.Name))
+				continue
+			}
+			stage, err := openPluginStage(configuration.PluginDir, s.Plugin)
+			if err != nil {
+				return nil, nil, fmt.Errorf("Stage %s: %v", s.Name, err)
+			}
+			switch stage := stage.(type) {
+			case Filterer:
+				if len(transforms) > 0 {
+					return nil, nil, fmt.Errorf("Stage %s transforms the events after the payloads", s.Name)
+				}
+				filters = append(filters, stage)
+			case Transformer:
+				transforms = append(transforms, stage)
+			}
+		default:
+			return nil, nil, fmt.Errorf("Stage not supported: %s", s.Type)
+		}
+	}
+	return filters, transforms, nil
+}
+
+// Release the stages holding resources, like the scripts, before they're replaced or the
+// registration removed
+func closeTransforms(transforms []Transformer) {
+	for _, transform := range transforms {
+		if closer, ok := transform.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				logger.Warn("Error closing the stage", zap.Error(err))
+			}
+		}
+	}
+}
+
+func transformsPayload(stageType string) bool {
+	return stageType == export.StageScript || stageType == export.StageCompress ||
+		stageType == export.StageEncrypt
+}
+
+// Adds readings to the events
+type enrichStage struct {
+	readings map[string]string
+}
+
+func (stage enrichStage) Filter(event *models.Event) (bool, *models.Event) {
+	if event == nil {
+		return false, nil
+	}
+	// The event is shared by the registrations
+	enriched := *event
+	enriched.Readings = make([]models.Reading, len(event.Readings), len(event.Readings)+len(stage.readings))
+	copy(enriched.Readings, event.Readings)
+	for name, value := range stage.readings {
+		enriched.Readings = append(enriched.Readings, models.Reading{
+			Device: event.Device,
+			Name:   name,
+			Value:  value,
+			Origin: event.Origin,
+		})
+	}
+	return true, &enriched
+}
+
+// Converts the numeric readings
+type convertStage struct {
+	conversions map[string]export.UnitConversion
+}
+
+func newConvertStage(conversions []export.UnitConversion) Filterer {
+	stage := convertStage{conversions: make(map[string]export.UnitConversion)}
+	for _, c := range conversions {
+		stage.conversions[c.Reading] = c
+	}
+	return stage
+}
+
+func (stage convertStage) Filter(event *models.Event) (bool, *models.Event) {
+	if event == nil {
+		return false, nil
+	}
+	converted := *event
+	converted.Readings = make([]models.Reading, len(event.Readings))
+	for i, reading := range event.Readings {
+		if c, ok := stage.conversions[reading.Name]; ok {
+			value, err := strconv.ParseFloat(reading.Value, 64)
+			if err != nil {
+				logger.Debug("Reading not numeric, not converted", zap.String("name", reading.Name))
+			} else {
+				reading.Value = strconv.FormatFloat(value*c.Scale+c.Offset, 'f', -1, 64)
+				if c.Rename != "" {
+					reading.Name = c.Rename
+				}
+			}
+		}
+		converted.Readings[i] = reading
+	}
+	return true, &converted
+}
+
+// Transforms the events with the function of a plugin, which drops them returning nil
+type pluginFilter func(*models.Event) *models.Event
+
+func (f pluginFilter) Filter(event *models.Event) (bool, *models.Event) {
+	if event == nil {
+		return false, nil
+	}
+	// The event is shared by the registrations, and the plugin may change it
+	transformed := f(copyEvent(event))
+	return transformed != nil, transformed
+}
+
+// Copy of the event, with copies of its readings and their binary values
+func copyEvent(event *models.Event) *models.Event {
+	copied := *event
+	copied.Readings = make([]models.Reading, len(event.Readings))
+	for i, reading := range event.Readings {
+		if reading.BinaryValue != nil {
+			reading.BinaryValue = append([]byte(nil), reading.BinaryValue...)
+		}
+		copied.Readings[i] = reading
+	}
+	return &copied
+}
+
+// Transforms the payloads with the function of a plugin
+type pluginTransformer func([]byte) []byte
+
+func (f pluginTransformer) Transform(data []byte) []byte {
+	return f(data)
+}
+
+// Stage of the function the plugin exports, a Filterer or a Transformer. The plugins are only
+// loaded from the PluginDir of export distro, since opening one runs its code and it can't be
+// unloaded
+func openPluginStage(dir string, details *export.PluginDetails) (interface{}, error) {
+	if details == nil {
+		return nil, fmt.Errorf("plugin required")
+	}
+	if dir == "" {
+		return nil, fmt.Errorf("plugins require the PluginDir of export distro")
+	}
+	if !export.ValidPluginFile(details.File) {
+		return nil, fmt.Errorf("plugin file invalid: %s", details.File)
+	}
+	p, err := plugin.Open(filepath.Join(dir, details.File))
+	if err != nil {
+		return nil, err
+	}
+	symbol, err := p.Lookup(details.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	switch f := symbol.(type) {
+	case func(*models.Event) *models.Event:
+		return pluginFilter(f), nil
+	case func([]byte) []byte:
+		return pluginTransformer(f), nil
+	default:
+		return nil, fmt.Errorf("plugin symbol %s is a %T", details.Symbol, symbol)
+	}
+}
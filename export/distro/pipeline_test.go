@@ -0,0 +1,191 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+	"go.uber.org/zap"
+)
+
+func TestEnrichStage(t *testing.T) {
+	event := &models.Event{Device: "dev", Origin: 10, Readings: []models.Reading{{Name: "temperature", Value: "21"}}}
+	ok, enriched := enrichStage{readings: map[string]string{"site": "plant-1"}}.Filter(event)
+	if !ok || len(enriched.Readings) != 2 {
+		t.Fatalf("The reading should be added: %+v", enriched)
+	}
+	added := enriched.Readings[1]
+	if added.Name != "site" || added.Value != "plant-1" || added.Device != "dev" || added.Origin != 10 {
+		t.Errorf("Unexpected reading: %+v", added)
+	}
+	if len(event.Readings) != 1 {
+		t.Error("The event shared by the registrations should not change")
+	}
+}
+
+func TestConvertStage(t *testing.T) {
+	stage := newConvertStage([]export.UnitConversion{
+		{Reading: "temperature", Scale: 1.8, Offset: 32, Rename: "temperatureF"},
+		{Reading: "pressure", Scale: 0.001},
+	})
+	event := &models.Event{Readings: []models.Reading{
+		{Name: "temperature", Value: "20"},
+		{Name: "pressure", Value: "101325"},
+		{Name: "temperature", Value: "n/a"},
+		{Name: "humidity", Value: "40"},
+	}}
+	ok, converted := stage.Filter(event)
+	if !ok {
+		t.Fatal("The event should be kept")
+	}
+	expected := []models.Reading{
+		{Name: "temperatureF", Value: "68"},
+		{Name: "pressure", Value: "101.325"},
+		{Name: "temperature", Value: "n/a"},
+		{Name: "humidity", Value: "40"},
+	}
+	for i, r := range converted.Readings {
+		if r.Name != expected[i].Name || r.Value != expected[i].Value {
+			t.Errorf("Reading %d is %s=%s, should be %s=%s", i, r.Name, r.Value, expected[i].Name, expected[i].Value)
+		}
+	}
+	if event.Readings[0].Value != "20" {
+		t.Error("The event shared by the registrations should not change")
+	}
+}
+
+func TestPluginFilter(t *testing.T) {
+	stage := pluginFilter(func(e *models.Event) *models.Event {
+		e.Device = "changed"
+		e.Readings[0].Value = "0"
+		e.Readings[0].BinaryValue[0] = 0
+		e.Readings = append(e.Readings, models.Reading{Name: "added"})
+		return e
+	})
+	event := &models.Event{Device: "dev", Readings: []models.Reading{{Name: "image", Value: "21", BinaryValue: []byte{1}}}}
+	ok, transformed := stage.Filter(event)
+	if !ok || transformed.Device != "changed" || len(transformed.Readings) != 2 {
+		t.Fatalf("The plugin should transform the event: %+v", transformed)
+	}
+	if event.Device != "dev" || len(event.Readings) != 1 || event.Readings[0].Value != "21" || event.Readings[0].BinaryValue[0] != 1 {
+		t.Error("The event shared by the registrations should not change")
+	}
+
+	if ok, _ := pluginFilter(func(*models.Event) *models.Event { return nil }).Filter(event); ok {
+		t.Error("The event the plugin returns nil for should be dropped")
+	}
+}
+
+func TestNewPipeline(t *testing.T) {
+	logger = zap.NewNop()
+
+	filters, transforms, err := newPipeline([]export.TransformStage{
+		{Name: "filter", Type: export.StageFilter, Filter: &export.Filter{DeviceIDs: []string{"dev"}, ValueDescriptorIDs: []string{"temperature"}}},
+		{Name: "enrich", Type: export.StageEnrich, Readings: map[string]string{"site": "plant-1"}},
+		{Name: "compress", Type: export.StageCompress, Compression: export.CompGzip},
+		{Name: "encrypt", Type: export.StageEncrypt, Encryption: &export.EncryptionDetails{Algo: export.EncAes, Key: "123", InitVector: "123"}},
+	}, false)
+	if err != nil || len(filters) != 3 || len(transforms) != 2 {
+		t.Fatalf("Unexpected pipeline, %d filters and %d transforms: %v", len(filters), len(transforms), err)
+	}
+
+	var tests = []struct {
+		name   string
+		stages []export.TransformStage
+		err    string
+	}{
+		{"eventsAfterPayloads", []export.TransformStage{
+			{Name: "compress", Type: export.StageCompress, Compression: export.CompGzip},
+			{Name: "enrich", Type: export.StageEnrich, Readings: map[string]string{"site": "plant-1"}},
+		}, "transforms the events after the payloads"},
+		{"compression", []export.TransformStage{{Name: "compress", Type: export.StageCompress, Compression: "INVALID"}}, "Compression not supported: INVALID"},
+		{"type", []export.TransformStage{{Name: "stage", Type: "INVALID"}}, "Stage not supported: INVALID"},
+		{"plugin", []export.TransformStage{{Name: "plugin", Type: export.StagePlugin, Plugin: &export.PluginDetails{File: "nonexistent.so", Symbol: "Transform"}}}, "Stage plugin:"},
+		{"pluginPath", []export.TransformStage{{Name: "plugin", Type: export.StagePlugin, Plugin: &export.PluginDetails{File: "../lib/evil.so", Symbol: "Transform"}}}, "plugin file invalid"},
+	}
+	configuration.PluginDir = os.TempDir()
+	defer func() { configuration.PluginDir = "" }()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := newPipeline(tt.stages, false); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Error %v, should contain %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestNewPipelinePlugin(t *testing.T) {
+	logger = zap.NewNop()
+
+	stages := []export.TransformStage{
+		{Name: "plugin", Type: export.StagePlugin, Plugin: &export.PluginDetails{File: "nonexistent.so", Symbol: "Transform"}},
+		{Name: "compress", Type: export.StageCompress, Compression: export.CompGzip},
+	}
+	if _, _, err := newPipeline(stages, false); err == nil || !strings.Contains(err.Error(), "PluginDir") {
+		t.Errorf("The plugins should require the plugin directory: %v", err)
+	}
+
+	// A check doesn't open the plugins
+	filters, transforms, err := newPipeline(stages, true)
+	if err != nil || len(filters) != 0 || len(transforms) != 1 {
+		t.Errorf("The plugin stage should be left out of a check, %d filters and %d transforms: %v", len(filters), len(transforms), err)
+	}
+}
+
+func TestNewPipelineValueFilter(t *testing.T) {
+	logger = zap.NewNop()
+
+	filters, _, err := newPipeline(export.Registration{Filter: export.Filter{
+		ValueFilters: []export.ValueFilter{{ValueDescriptor: "temperature", Deadband: 1}},
+	}}.Stages(), false)
+	if err != nil || len(filters) != 1 {
+		t.Fatalf("The value filter should be added: %d filters, %v", len(filters), err)
+	}
+	event := &models.Event{Device: "dev", Readings: []models.Reading{{Name: "temperature", Value: "20"}}}
+	if ok, _ := filters[0].Filter(event); !ok {
+		t.Error("The first value should be kept")
+	}
+	if ok, _ := filters[0].Filter(event); ok {
+		t.Error("The same value should be filtered out")
+	}
+}
+
+func TestRegistrationInfoPipeline(t *testing.T) {
+	logger = zap.NewNop()
+
+	r := validRegistration()
+	r.Filter = export.Filter{}
+	r.Pipeline = []export.TransformStage{
+		{Name: "devices", Type: export.StageFilter, Filter: &export.Filter{DeviceIDs: []string{"dev"}}},
+		{Name: "fahrenheit", Type: export.StageConvert, Conversions: []export.UnitConversion{{Reading: "temperature", Scale: 1.8, Offset: 32}}},
+		{Name: "site", Type: export.StageEnrich, Readings: map[string]string{"site": "plant-1"}},
+	}
+	reg := newRegistrationInfo()
+	if err := reg.configure(r); err != nil {
+		t.Fatalf("Error configuring the registration: %v", err)
+	}
+	sender := &batchSender{}
+	reg.sender = sender
+
+	reg.processEvent(&models.Event{Device: "other"})
+	reg.processEvent(&models.Event{Device: "dev", Readings: []models.Reading{{Name: "temperature", Value: "100"}}})
+	if len(sender.payloads) != 1 {
+		t.Fatalf("The filter stage should drop the other device, %d sent", len(sender.payloads))
+	}
+	event := models.Event{}
+	if err := json.Unmarshal(sender.payloads[0], &event); err != nil {
+		t.Fatalf("Error parsing the payload: %v", err)
+	}
+	if len(event.Readings) != 2 || event.Readings[0].Value != "212" || event.Readings[1].Name != "site" {
+		t.Errorf("The stages should convert then enrich the event: %+v", event.Readings)
+	}
+}
@@ -0,0 +1,317 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+const (
+	postgresPort          = 5432
+	postgresBatchSize     = 1000
+	postgresFlushInterval = time.Second
+	// Batches kept while the database is unavailable, the oldest readings are dropped beyond
+	postgresMaxBatches = 10
+)
+
+// Row of a reading
+type postgresRow struct {
+	time    time.Time
+	eventID string
+	device  string
+	name    string
+	value   string
+	number  sql.NullFloat64
+}
+
+type postgresSender struct {
+	senderStats
+	db         *sql.DB
+	table      string // Quoted
+	index      string // Quoted
+	hypertable bool
+	created    bool // Schema created
+	batchSize  int
+
+	mutex sync.Mutex
+	rows  []postgresRow // Waiting to be inserted
+	done  chan struct{}
+	once  sync.Once
+}
+
+// NewPostgresSender - create new Postgres sender
+// The readings are inserted in batches, a transaction with a prepared statement each, once
+// a batch is full or the flush interval passed. The table is created on the first batch
+func NewPostgresSender(addr models.Addressable, details export.PostgresDetails) Sender {
+	port := addr.Port
+	if port == 0 {
+		port = postgresPort
+	}
+	sslMode := details.SSLMode
+	if sslMode == "" {
+		sslMode = "require"
+	}
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(addr.User, addr.Password),
+		Host:     net.JoinHostPort(addr.Address, strconv.Itoa(port)),
+		Path:     "/" + details.Database,
+		RawQuery: url.Values{"sslmode": {sslMode}}.Encode(),
+	}
+
+	// Doesn't connect yet
+	db, err := sql.Open("postgres", dsn.String())
+	if err != nil {
+		logger.Error("Invalid Postgres configuration", zap.Error(err))
+		return nil
+	}
+	return newPostgresSender(db, details)
+}
+
+func newPostgresSender(db *sql.DB, details export.PostgresDetails) *postgresSender {
+	table := details.Table
+	if table == "" {
+		table = export.PostgresTable
+	}
+	parts := strings.Split(table, ".")
+	quoted := make([]string, len(parts))
+	for i, part := range parts {
+		quoted[i] = pq.QuoteIdentifier(part)
+	}
+
+	sender := &postgresSender{
+		db:         db,
+		table:      strings.Join(quoted, "."),
+		index:      pq.QuoteIdentifier(parts[len(parts)-1] + "_device_name_time_idx"),
+		hypertable: details.Hypertable,
+		batchSize:  details.BatchSize,
+		done:       make(chan struct{}),
+	}
+	if sender.batchSize <= 0 {
+		sender.batchSize = postgresBatchSize
+	}
+	interval := time.Duration(details.FlushInterval) * time.Millisecond
+	if interval <= 0 {
+		interval = postgresFlushInterval
+	}
+
+	go sender.loop(interval)
+	return sender
+}
+
+func (sender *postgresSender) loop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			sender.flush(true)
+		case <-sender.done:
+			return
+		}
+	}
+}
+
+// Create the table, and its hypertable, when missing
+func (sender *postgresSender) createSchema() error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS ` + sender.table + ` (
+			time TIMESTAMPTZ NOT NULL,
+			event_id TEXT,
+			device TEXT NOT NULL,
+			name TEXT NOT NULL,
+			value TEXT,
+			number DOUBLE PRECISION
+		)`,
+		`CREATE INDEX IF NOT EXISTS ` + sender.index + ` ON ` + sender.table + ` (device, name, time DESC)`,
+	}
+	if sender.hypertable {
+		statements = append(statements,
+			`SELECT create_hypertable(`+pq.QuoteLiteral(sender.table)+`, 'time', if_not_exists => TRUE)`)
+	}
+	for _, statement := range statements {
+		if _, err := sender.db.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (sender *postgresSender) Send(data []byte) {
+	var event models.Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		logger.Error("Error parsing the event", zap.Error(err))
+		return
+	}
+	sender.SendEvent(data, &event)
+}
+
+// SendEvent - insert the readings of the event, binary readings are skipped
+func (sender *postgresSender) SendEvent(data []byte, event *models.Event) {
+	sender.mutex.Lock()
+	for _, reading := range event.Readings {
+		if reading.Name == "" || len(reading.BinaryValue) > 0 {
+			continue
+		}
+		row := postgresRow{
+			eventID: event.ID.Hex(),
+			device:  reading.Device,
+			name:    reading.Name,
+			value:   reading.Value,
+		}
+		if row.device == "" {
+			row.device = event.Device
+		}
+		timestamp := reading.Origin
+		if timestamp == 0 {
+			timestamp = event.Origin
+		}
+		if timestamp == 0 {
+			timestamp = reading.Created
+		}
+		row.time = time.Now()
+		if timestamp != 0 {
+			row.time = time.Unix(0, timestamp*int64(time.Millisecond))
+		}
+		if f, err := strconv.ParseFloat(reading.Value, 64); err == nil {
+			row.number = sql.NullFloat64{Float64: f, Valid: true}
+		}
+		sender.rows = append(sender.rows, row)
+	}
+	full := len(sender.rows) >= sender.batchSize
+	sender.mutex.Unlock()
+
+	if full {
+		sender.flush(false)
+	}
+}
+
+// Insert the waiting readings a batch at a time, the last one even when it isn't full if all. The
+// readings dropped are dead lettered once the readings are unlocked
+func (sender *postgresSender) flush(all bool) {
+	var dropped []postgresRow
+	var droppedErr error
+	sender.mutex.Lock()
+	defer func() {
+		sender.mutex.Unlock()
+		if len(dropped) > 0 {
+			sender.deadLetterPayload(postgresDeadLetter(dropped), droppedErr, 1)
+		}
+	}()
+
+	for len(sender.rows) >= sender.batchSize || (all && len(sender.rows) > 0) {
+		n := sender.batchSize
+		if n > len(sender.rows) {
+			n = len(sender.rows)
+		}
+		err := sender.insert(sender.rows[:n])
+		if err != nil && !postgresDataError(err) {
+			logger.Warn("Postgres unavailable, keep readings", zap.Error(err), zap.Int("readings", len(sender.rows)))
+			sender.countRetried(err)
+			if max := postgresMaxBatches * sender.batchSize; len(sender.rows) > max {
+				logger.Warn("Postgres buffer full, drop readings", zap.Int("readings", len(sender.rows)-max))
+				sender.countFailed(len(sender.rows)-max, err)
+				dropped, droppedErr = append(dropped, sender.rows[:len(sender.rows)-max]...), err
+				sender.rows = sender.rows[len(sender.rows)-max:]
+			}
+			return
+		}
+		if err != nil {
+			logger.Warn("Postgres refused the readings, drop them", zap.Error(err), zap.Int("readings", n))
+			sender.countFailed(n, err)
+			dropped, droppedErr = append(dropped, sender.rows[:n]...), err
+		} else {
+			logger.Debug("Inserted readings: ", zap.Int("readings", n))
+			sender.countSent(n)
+		}
+		sender.rows = sender.rows[n:]
+	}
+	if len(sender.rows) == 0 {
+		sender.rows = nil
+	}
+}
+
+// JSON event of the readings, which the sender inserts again when it's replayed
+func postgresDeadLetter(rows []postgresRow) []byte {
+	event := models.Event{}
+	for _, row := range rows {
+		event.Readings = append(event.Readings, models.Reading{
+			Device: row.device,
+			Name:   row.name,
+			Value:  row.value,
+			Origin: row.time.UnixNano() / int64(time.Millisecond),
+		})
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		logger.Error("Error encoding the dead letter", zap.Error(err))
+		return nil
+	}
+	return data
+}
+
+// Insert a batch in a transaction
+func (sender *postgresSender) insert(rows []postgresRow) error {
+	if !sender.created {
+		if err := sender.createSchema(); err != nil {
+			return err
+		}
+		sender.created = true
+	}
+
+	tx, err := sender.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(`INSERT INTO ` + sender.table +
+		` (time, event_id, device, name, value, number) VALUES ($1, $2, $3, $4, $5, $6)`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, row := range rows {
+		if _, err = stmt.Exec(row.time, row.eventID, row.device, row.name, row.value, row.number); err != nil {
+			stmt.Close()
+			tx.Rollback()
+			return err
+		}
+	}
+	stmt.Close()
+	return tx.Commit()
+}
+
+// Errors of the data (class 22) or of its constraints (class 23), which retrying won't fix
+func postgresDataError(err error) bool {
+	if e, ok := err.(*pq.Error); ok {
+		class := e.Code.Class()
+		return class == "22" || class == "23"
+	}
+	return false
+}
+
+// Close - insert the waiting readings and close the database
+func (sender *postgresSender) Close() error {
+	var err error
+	sender.once.Do(func() {
+		close(sender.done)
+		sender.flush(true)
+		err = sender.db.Close()
+	})
+	return err
+}
@@ -0,0 +1,109 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+func newPostgresMock(t *testing.T, details export.PostgresDetails) (*postgresSender, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating the mock: %v", err)
+	}
+	// A flush interval longer than the tests, the batches are flushed explicitly
+	details.FlushInterval = 60000
+	return newPostgresSender(db, details), mock
+}
+
+func TestPostgresSender(t *testing.T) {
+	logger = zap.NewNop()
+	sender, mock := newPostgresMock(t, export.PostgresDetails{Table: "iot.readings", Hypertable: true, BatchSize: 2})
+
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS "iot"."readings"`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE INDEX IF NOT EXISTS "readings_device_name_time_idx" ON "iot"."readings"`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT create_hypertable('"iot"."readings"', 'time'`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectBegin()
+	insert := mock.ExpectPrepare(regexp.QuoteMeta(`INSERT INTO "iot"."readings" (time, event_id, device, name, value, number)`))
+	insert.ExpectExec().WithArgs(time.Unix(2, 0), "", "dev", "temperature", "21.5", 21.5).WillReturnResult(sqlmock.NewResult(0, 1))
+	insert.ExpectExec().WithArgs(time.Unix(1, 0), "", "other", "status", "ok", nil).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	sender.SendEvent(nil, &models.Event{Device: "dev", Origin: 1000, Readings: []models.Reading{
+		{Name: "temperature", Value: "21.5", Origin: 2000},
+		{Name: "image", BinaryValue: []byte{1}},
+	}})
+	if len(sender.rows) != 1 {
+		t.Fatal("A reading shouldn't be inserted before its batch is full")
+	}
+	sender.Send([]byte(`{"device":"dev","origin":1000,"readings":[{"device":"other","name":"status","value":"ok"}]}`))
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("The full batch should be inserted in a transaction: %v", err)
+	}
+
+	mock.ExpectClose()
+	sender.Close()
+}
+
+func TestPostgresSenderRetry(t *testing.T) {
+	logger = zap.NewNop()
+	sender, mock := newPostgresMock(t, export.PostgresDetails{})
+	defer sender.Close()
+	event := &models.Event{Device: "dev", Readings: []models.Reading{{Name: "temperature", Value: "21.5"}}}
+
+	// The schema is created again after a failure
+	mock.ExpectExec("CREATE TABLE").WillReturnError(errors.New("connection refused"))
+	sender.SendEvent(nil, event)
+	sender.flush(true)
+	if len(sender.rows) != 1 || sender.created {
+		t.Fatal("The readings should be kept while the database is unavailable")
+	}
+
+	mock.ExpectExec("CREATE TABLE").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO").ExpectExec().WillReturnError(&pq.Error{Code: "22P02"})
+	mock.ExpectRollback()
+	var letters []export.DeadLetter
+	sender.setDeadLetter("reg", export.DestPostgres, func(letter export.DeadLetter) { letters = append(letters, letter) })
+	sender.flush(true)
+	if len(sender.rows) != 0 {
+		t.Fatal("The readings refused by the database should be dropped")
+	}
+	var dead models.Event
+	if len(letters) != 1 || json.Unmarshal(letters[0].Payload, &dead) != nil || len(dead.Readings) != 1 ||
+		dead.Readings[0].Device != "dev" || dead.Readings[0].Name != "temperature" || dead.Readings[0].Value != "21.5" {
+		t.Fatalf("The readings refused should be dead lettered as an event: %v", letters)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectBegin().WillReturnError(sql.ErrConnDone)
+	sender.batchSize = 1
+	for i := 0; i < postgresMaxBatches+5; i++ {
+		sender.rows = append(sender.rows, postgresRow{})
+	}
+	sender.flush(true)
+	if len(sender.rows) != postgresMaxBatches {
+		t.Fatalf("The oldest readings should be dropped beyond %d batches, %d kept", postgresMaxBatches, len(sender.rows))
+	}
+	mock.ExpectClose()
+}
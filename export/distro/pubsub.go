@@ -0,0 +1,173 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+	"go.uber.org/zap"
+)
+
+const (
+	pubSubEndpoint = "pubsub.googleapis.com"
+	pubSubAudience = "https://pubsub.googleapis.com/"
+	pubSubTokenTTL = time.Hour
+	pubSubTimeout  = 10 * time.Second
+)
+
+type pubSubSender struct {
+	senderStats
+	client      *http.Client
+	url         string
+	account     export.GoogleServiceAccount
+	orderingKey string
+	token       string
+	expiry      time.Time
+}
+
+type pubSubMessage struct {
+	Data        string `json:"data"`
+	OrderingKey string `json:"orderingKey,omitempty"`
+}
+
+type pubSubRequest struct {
+	Messages []pubSubMessage `json:"messages"`
+}
+
+// NewPubSubSender - create new Google Pub/Sub sender
+// The events are published with the REST API, authenticated with JWTs signed by the key of
+// the service account, and each one waits for the message id of the publish
+func NewPubSubSender(addr models.Addressable, details export.PubSubDetails) Sender {
+	account, err := export.ParseGoogleServiceAccount(details.ServiceAccount)
+	if err != nil {
+		logger.Error("Invalid Pub/Sub service account", zap.Error(err))
+		return nil
+	}
+
+	protocol := addr.Protocol
+	if protocol == "" {
+		protocol = "https"
+	}
+	endpoint := addr.Address
+	if endpoint == "" {
+		endpoint = pubSubEndpoint
+	}
+	if addr.Port != 0 {
+		endpoint += ":" + strconv.Itoa(addr.Port)
+	}
+
+	sender := &pubSubSender{
+		client:      &http.Client{Timeout: pubSubTimeout},
+		url:         protocol + "://" + endpoint + "/v1/" + export.PubSubTopic(addr.Topic, account) + ":publish",
+		account:     account,
+		orderingKey: details.OrderingKey,
+	}
+	return sender
+}
+
+// Self signed JWT of the service account, which Google APIs accept without an OAuth exchange
+func pubSubJWT(account export.GoogleServiceAccount, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": account.PrivateKeyID})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss": account.ClientEmail,
+		"sub": account.ClientEmail,
+		"aud": pubSubAudience,
+		"iat": now.Unix(),
+		"exp": now.Add(pubSubTokenTTL).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, account.Key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// Token of the request, renewed once four fifths of its validity have passed
+func (sender *pubSubSender) bearer(now time.Time) (string, error) {
+	if sender.token == "" || !now.Before(sender.expiry.Add(-pubSubTokenTTL/5)) {
+		token, err := pubSubJWT(sender.account, now)
+		if err != nil {
+			return "", err
+		}
+		sender.token = token
+		sender.expiry = now.Add(pubSubTokenTTL)
+	}
+	return sender.token, nil
+}
+
+func (sender *pubSubSender) Send(data []byte) {
+	sender.SendEvent(data, nil)
+}
+
+// SendEvent - send the data with the ordering key of the event
+func (sender *pubSubSender) SendEvent(data []byte, event *models.Event) {
+	msg := pubSubMessage{Data: base64.StdEncoding.EncodeToString(data)}
+	if event != nil && sender.orderingKey != "" {
+		msg.OrderingKey = eventKey(sender.orderingKey, event)
+	}
+	body, err := json.Marshal(pubSubRequest{Messages: []pubSubMessage{msg}})
+	if err != nil {
+		logger.Error("Error encoding the Pub/Sub message", zap.Error(err))
+		return
+	}
+
+	token, err := sender.bearer(time.Now())
+	if err != nil {
+		logger.Error("Error signing the Pub/Sub token", zap.Error(err))
+		sender.countFailed(1, err)
+		sender.deadLetterPayload(data, err, 1)
+		return
+	}
+	request, err := http.NewRequest(http.MethodPost, sender.url, bytes.NewReader(body))
+	if err != nil {
+		logger.Error("Error: ", zap.Error(err))
+		return
+	}
+	request.Header.Set("Content-Type", mimeTypeJSON)
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	response, err := sender.client.Do(request)
+	if err != nil {
+		logger.Warn("Pub/Sub error: ", zap.Error(err))
+		sender.countFailed(1, err)
+		sender.deadLetterPayload(data, err, 1)
+		return
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		status, _ := ioutil.ReadAll(response.Body)
+		logger.Warn("Pub/Sub refused the event", zap.String("status", response.Status), zap.ByteString("response", status))
+		err = fmt.Errorf("Pub/Sub returned %s", response.Status)
+		sender.countFailed(1, err)
+		sender.deadLetterPayload(data, err, 1)
+		return
+	}
+	logger.Debug("Sent data: ", zap.ByteString("data", data))
+	sender.countSent(1)
+}
@@ -0,0 +1,128 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+	"go.uber.org/zap"
+)
+
+// JSON key of a service account, with a new key
+func testServiceAccount(t *testing.T) (string, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Error generating the key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Error encoding the key: %v", err)
+	}
+	account, _ := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "edgex",
+		"private_key_id": "kid",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"client_email":   "export@edgex.iam.gserviceaccount.com",
+	})
+	return string(account), key
+}
+
+func TestPubSubSender(t *testing.T) {
+	logger = zap.NewNop()
+	account, key := testServiceAccount(t)
+
+	var path, auth string
+	var request pubSubRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		auth = r.Header.Get("Authorization")
+		request = pubSubRequest{}
+		json.NewDecoder(r.Body).Decode(&request)
+		w.Write([]byte(`{"messageIds":["1"]}`))
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(u.Port())
+
+	addr := models.Addressable{Protocol: "http", Address: u.Hostname(), Port: port, Topic: "events"}
+	sender := NewPubSubSender(addr, export.PubSubDetails{ServiceAccount: account, OrderingKey: "{device}"}).(*pubSubSender)
+	event := &models.Event{Device: "dev"}
+	sender.SendEvent([]byte("data"), event)
+
+	if path != "/v1/projects/edgex/topics/events:publish" {
+		t.Errorf("The event should be published to the topic of the project, not %s", path)
+	}
+	if len(request.Messages) != 1 || request.Messages[0].Data != base64.StdEncoding.EncodeToString([]byte("data")) ||
+		request.Messages[0].OrderingKey != "dev" {
+		t.Errorf("The message should have the data and ordering key of the event: %v", request.Messages)
+	}
+
+	parts := strings.Split(strings.TrimPrefix(auth, "Bearer "), ".")
+	if len(parts) != 3 {
+		t.Fatalf("The request should have a JWT, not %s", auth)
+	}
+	sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, hash[:], sig); err != nil {
+		t.Fatalf("The JWT should be signed by the service account: %v", err)
+	}
+	var claims map[string]interface{}
+	payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	json.Unmarshal(payload, &claims)
+	if claims["iss"] != "export@edgex.iam.gserviceaccount.com" || claims["aud"] != pubSubAudience {
+		t.Errorf("The JWT should be issued by the service account for Pub/Sub: %v", claims)
+	}
+
+	sender.Send([]byte("data"))
+	if request.Messages[0].OrderingKey != "" {
+		t.Errorf("Without event the message shouldn't be ordered")
+	}
+
+	if NewPubSubSender(addr, export.PubSubDetails{ServiceAccount: "{}"}) != nil {
+		t.Errorf("An invalid service account should not create a sender")
+	}
+}
+
+func TestPubSubTopic(t *testing.T) {
+	account, _ := testServiceAccount(t)
+	addr := models.Addressable{Topic: "projects/other/topics/events"}
+	sender := NewPubSubSender(addr, export.PubSubDetails{ServiceAccount: account}).(*pubSubSender)
+	if sender.url != "https://pubsub.googleapis.com/v1/projects/other/topics/events:publish" {
+		t.Errorf("The topic of another project should be kept, not %s", sender.url)
+	}
+}
+
+func TestPubSubTokenRenewal(t *testing.T) {
+	account, _ := testServiceAccount(t)
+	sender := NewPubSubSender(models.Addressable{Topic: "events"}, export.PubSubDetails{ServiceAccount: account}).(*pubSubSender)
+
+	now := time.Now()
+	token, _ := sender.bearer(now)
+	if renewed, _ := sender.bearer(now.Add(pubSubTokenTTL / 2)); renewed != token {
+		t.Errorf("The token should be kept before four fifths of its validity")
+	}
+	if renewed, _ := sender.bearer(now.Add(pubSubTokenTTL * 4 / 5)); renewed == token {
+		t.Errorf("The token should be renewed after four fifths of its validity")
+	}
+}
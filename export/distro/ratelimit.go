@@ -0,0 +1,156 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"math"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+)
+
+const rateMaxPending = 1000
+
+// Tokens added at a rate per second, up to a second of them. A take larger than the bucket
+// waits for it to be full and leaves it in debt, so the rate holds on average
+type tokenBucket struct {
+	rate     float64
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+// Full bucket of the rate, nil when it's unlimited
+func newTokenBucket(rate float64, now time.Time) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	capacity := math.Max(rate, 1)
+	return &tokenBucket{rate: rate, capacity: capacity, tokens: capacity, last: now}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(b.capacity, b.tokens+elapsed*b.rate)
+	}
+	b.last = now
+}
+
+// Time until n tokens can be taken
+func (b *tokenBucket) wait(n float64, now time.Time) time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.refill(now)
+	need := math.Min(n, b.capacity)
+	if b.tokens >= need {
+		return 0
+	}
+	return time.Duration(math.Ceil((need - b.tokens) / b.rate * float64(time.Second)))
+}
+
+func (b *tokenBucket) take(n float64) {
+	if b != nil {
+		b.tokens -= n
+	}
+}
+
+// Payload waiting for its turn, with the event it was made from unless it's a batch
+type pendingPayload struct {
+	data   []byte
+	event  *models.Event
+	events int
+}
+
+// Payloads of a registration waiting for the events and bytes rate limits, sent in order
+type rateLimiter struct {
+	events     *tokenBucket
+	bytes      *tokenBucket
+	maxPending int
+	pending    []pendingPayload
+	timer      *time.Timer // Nil until a payload waits
+}
+
+// Limiter of the registration, nil when it isn't rate limited
+func newRateLimiter(details export.RateLimitDetails) *rateLimiter {
+	if !details.Enabled() {
+		return nil
+	}
+	now := time.Now()
+	limiter := &rateLimiter{
+		events:     newTokenBucket(details.Events, now),
+		bytes:      newTokenBucket(float64(details.Bytes), now),
+		maxPending: details.MaxPending,
+	}
+	if limiter.maxPending == 0 {
+		limiter.maxPending = rateMaxPending
+	}
+	return limiter
+}
+
+// Add the payload after the others, and return whether the oldest was dropped for it
+func (l *rateLimiter) add(p pendingPayload) bool {
+	dropped := false
+	if len(l.pending) >= l.maxPending {
+		l.pending = l.pending[1:]
+		dropped = true
+	}
+	l.pending = append(l.pending, p)
+	return dropped
+}
+
+// Take the payloads the limits let through now, in order. The timer ticks when the next one
+// can be sent
+func (l *rateLimiter) ready(now time.Time) []pendingPayload {
+	var ready []pendingPayload
+	for len(l.pending) > 0 {
+		p := l.pending[0]
+		size := float64(len(p.data))
+		wait := l.events.wait(float64(p.events), now)
+		if w := l.bytes.wait(size, now); w > wait {
+			wait = w
+		}
+		if wait > 0 {
+			if l.timer == nil {
+				l.timer = time.NewTimer(wait)
+			} else {
+				l.timer.Reset(wait)
+			}
+			break
+		}
+		l.events.take(float64(p.events))
+		l.bytes.take(size)
+		ready = append(ready, p)
+		l.pending = l.pending[1:]
+	}
+	return ready
+}
+
+// Take the payloads waiting, regardless of the limits
+func (l *rateLimiter) take() []pendingPayload {
+	if l == nil {
+		return nil
+	}
+	pending := l.pending
+	l.pending = nil
+	return pending
+}
+
+// Ticks when the next payload can be sent, never without a limiter
+func (l *rateLimiter) tick() <-chan time.Time {
+	if l == nil || l.timer == nil {
+		return nil
+	}
+	return l.timer.C
+}
+
+func (l *rateLimiter) stop() {
+	if l != nil && l.timer != nil {
+		l.timer.Stop()
+	}
+}
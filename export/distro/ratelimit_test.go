@@ -0,0 +1,132 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+	"go.uber.org/zap"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	if newTokenBucket(0, now) != nil {
+		t.Fatal("A bucket without rate should be unlimited")
+	}
+	var unlimited *tokenBucket
+	if unlimited.wait(1000, now) != 0 {
+		t.Error("An unlimited bucket should not wait")
+	}
+
+	b := newTokenBucket(10, now)
+	if b.wait(10, now) != 0 {
+		t.Fatal("A full bucket should take a second of tokens")
+	}
+	b.take(10)
+	if wait := b.wait(1, now); wait != 100*time.Millisecond {
+		t.Errorf("An empty bucket should wait for a token: %v", wait)
+	}
+	if b.wait(1, now.Add(100*time.Millisecond)) != 0 {
+		t.Error("The bucket should be refilled at its rate")
+	}
+
+	// Larger than the bucket, it waits for the bucket to be full and leaves it in debt
+	b = newTokenBucket(10, now)
+	if b.wait(25, now) != 0 {
+		t.Fatal("A full bucket should take more tokens than it holds")
+	}
+	b.take(25)
+	if wait := b.wait(1, now); wait != 1600*time.Millisecond {
+		t.Errorf("The bucket in debt should wait for it to be paid: %v", wait)
+	}
+
+	b = newTokenBucket(0.5, now)
+	if b.capacity != 1 || b.wait(1, now) != 0 {
+		t.Error("A slow bucket should hold a token")
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	if newRateLimiter(export.RateLimitDetails{}) != nil {
+		t.Fatal("A registration without limits should not have a limiter")
+	}
+
+	l := newRateLimiter(export.RateLimitDetails{Events: 2, Bytes: 1000, MaxPending: 3})
+	defer l.stop()
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		l.add(pendingPayload{data: []byte("data"), events: 1})
+	}
+	if ready := l.ready(now); len(ready) != 2 || len(l.pending) != 1 {
+		t.Fatalf("The events limit should let 2 payloads through, %d sent", len(ready))
+	}
+	if l.tick() == nil {
+		t.Fatal("The limiter should tick for the payload waiting")
+	}
+	if l.add(pendingPayload{data: []byte("data"), events: 1}) ||
+		l.add(pendingPayload{data: []byte("data"), events: 1}) ||
+		!l.add(pendingPayload{data: []byte("data"), events: 1}) || len(l.pending) != 3 {
+		t.Error("The oldest payload should be dropped once the max pending wait")
+	}
+	if ready := l.ready(now.Add(500 * time.Millisecond)); len(ready) != 1 {
+		t.Errorf("A token should be added after half a second, %d sent", len(ready))
+	}
+
+	// A batch counts its events
+	l = newRateLimiter(export.RateLimitDetails{Events: 10})
+	defer l.stop()
+	l.add(pendingPayload{data: []byte("batch"), events: 10})
+	l.add(pendingPayload{data: []byte("batch"), events: 10})
+	if ready := l.ready(now); len(ready) != 1 {
+		t.Errorf("The batch should take the tokens of its events, %d sent", len(ready))
+	}
+	if pending := l.take(); len(pending) != 1 || len(l.pending) != 0 {
+		t.Error("The payloads waiting should be taken")
+	}
+}
+
+func TestRegistrationInfoRateLimit(t *testing.T) {
+	logger = zap.NewNop()
+
+	r := validRegistration()
+	r.Name = "reg"
+	r.RateLimit = export.RateLimitDetails{Events: 2}
+	ri := newRegistrationInfo()
+	if !ri.update(r) {
+		t.Fatal("The registration should be valid")
+	}
+	defer stopStats(r.Name)
+	if ri.limiter == nil {
+		t.Fatal("The registration should be rate limited")
+	}
+	dummy := &dummyStruct{}
+	ri.closeSender()
+	ri.sender = dummy
+	ri.filter = nil
+
+	for i := 0; i < 5; i++ {
+		ri.processEvent(&models.Event{Device: "dummyDev"})
+	}
+	if dummy.count != 2 {
+		t.Fatalf("The registration should send 2 events right away, %d sent", dummy.count)
+	}
+
+	// The events waiting are sent when the registration ends
+	done := make(chan struct{})
+	go func() {
+		registrationLoop(ri)
+		close(done)
+	}()
+	ri.chRegistration <- nil
+	<-done
+	if dummy.count != 5 {
+		t.Errorf("The events waiting should be sent, %d sent", dummy.count)
+	}
+}
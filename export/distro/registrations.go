@@ -13,7 +13,9 @@ package distro
 //   registration channel)
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
@@ -31,15 +33,96 @@ func RefreshRegistrations(update export.NotifyUpdate) {
 	registrationChanges <- update
 }
 
+// Dead letter to send again, the result is nil once its registration has it
+type replayRequest struct {
+	letter export.DeadLetter
+	result chan error
+}
+
+var replayRequests chan replayRequest = make(chan replayRequest, 2)
+
+var errNotRunning = errors.New("Registration not running")
+
+var errReplayBusy = errors.New("Registration busy replaying dead letters")
+
+// Dead letters a registration queues for replay, the next ones are refused until it sends them
+const replayQueue = 100
+
+var errRateLimited = errors.New("Payload dropped by the rate limit")
+
+// Queue the payload of the dead letter to be sent again by its registration. It doesn't wait
+// for the registration, which may be retrying a delivery
+func replayDeadLetter(running map[string]*registrationInfo, letter export.DeadLetter) error {
+	reg, ok := running[letter.Registration]
+	if !ok || reg.deleteMe {
+		return errNotRunning
+	}
+	select {
+	case reg.chReplay <- letter.Payload:
+		return nil
+	default:
+		return errReplayBusy
+	}
+}
+
 func newRegistrationInfo() *registrationInfo {
 	reg := &registrationInfo{}
 
 	reg.chRegistration = make(chan *export.Registration)
 	reg.chEvent = make(chan *models.Event)
+	reg.chReplay = make(chan []byte, replayQueue)
+	reg.deadLetter = storeDeadLetter
 	return reg
 }
 
+// Start the registration, or stop it when distro doesn't support it
 func (reg *registrationInfo) update(newReg export.Registration) bool {
+	if err := reg.configure(newReg); err != nil {
+		logger.Warn(err.Error(), zap.String("Name", newReg.Name))
+		return false
+	}
+
+	stats := startStats(newReg.Name)
+	reg.stats = stats
+	if sender, ok := reg.sender.(statsSender); ok {
+		sender.setStats(stats)
+	}
+	// The REST sender dead letters its payloads on its own, with the attempts it made
+	if sender, ok := reg.sender.(deadLetterSender); ok && newReg.Destination != export.DestRest {
+		sender.setDeadLetter(newReg.Name, newReg.Destination, storeDeadLetter)
+	}
+	return true
+}
+
+// Create the formatter, the sender and the transformations of the registration. The error
+// says what distro doesn't support, and what was built before it is released
+func (reg *registrationInfo) configure(newReg export.Registration) (err error) {
+	// The pending events are sent as they were configured
+	reg.flushAggregate()
+	reg.aggregate.stop()
+	reg.aggregate = nil
+	reg.flushBatch()
+	reg.batch.stop()
+	reg.batch = nil
+	reg.flushLimiter()
+	reg.limiter.stop()
+	reg.limiter = nil
+	reg.buffer.stop()
+	reg.buffer = nil
+
+	defer func() {
+		if err != nil {
+			reg.batch.stop()
+			reg.batch = nil
+			reg.buffer.stop()
+			reg.buffer = nil
+			reg.closeSender()
+			closeTransforms(reg.transforms)
+			reg.transforms = nil
+			reg.filter = nil
+		}
+	}()
+
 	reg.registration = newReg
 
 	reg.format = nil
@@ -55,28 +138,32 @@ func (reg *registrationInfo) update(newReg export.Registration) bool {
 	case export.FormatAzureJSON:
 		// TODO reg.format = distro.NewAzureFormat()
 	case export.FormatCSV:
-		// TODO reg.format = distro.NewCsvFormat()
+		reg.format = csvFormatter{columns: newReg.CSV.Columns}
 	case export.FormatThingsBoardJSON:
 		reg.format = thingsboardJSONFormatter{}
+	case export.FormatInfluxDBLine:
+		reg.format = influxDBLineFormatter{labels: newReg.InfluxDB.Labels}
+	case export.FormatProtobuf:
+		reg.format = protobufFormatter{}
+	case export.FormatTemplate:
+		tmpl, err := newReg.Template.Parse(newReg.Name)
+		if err != nil {
+			return fmt.Errorf("Template invalid: %v", err)
+		}
+		reg.format = templateFormatter{template: tmpl}
 	default:
-		logger.Warn("Format not supported: ", zap.String("format", newReg.Format))
-		return false
+		return fmt.Errorf("Format not supported: %s", newReg.Format)
 	}
 
-	reg.compression = nil
-	switch newReg.Compression {
-	case export.CompNone:
-		reg.compression = nil
-	case export.CompGzip:
-		reg.compression = &gzipTransformer{}
-	case export.CompZip:
-		reg.compression = &zlibTransformer{}
-	default:
-		logger.Warn("Compression not supported: ", zap.String("compression", newReg.Compression))
-		return false
+	filters, transforms, err := newPipeline(newReg.Stages(), reg.check)
+	if err != nil {
+		return err
 	}
+	reg.filter = filters
+	closeTransforms(reg.transforms)
+	reg.transforms = transforms
 
-	reg.sender = nil
+	reg.closeSender()
 	switch newReg.Destination {
 	case export.DestMQTT:
 		reg.sender = NewMqttSender(newReg.Addressable)
@@ -85,55 +172,104 @@ func (reg *registrationInfo) update(newReg export.Registration) bool {
 	case export.DestIotCoreMQTT:
 		// TODO reg.sender = distro.NewIotCoreSender("TODO URL")
 	case export.DestAzureMQTT:
-		// TODO reg.sender = distro.NewAzureSender("TODO URL")
+		reg.sender = NewAzureSender(newReg.Azure)
 	case export.DestRest:
-		reg.sender = NewHTTPSender(newReg.Addressable)
+		reg.sender = NewHTTPSender(newReg.Name, newReg.Addressable, newReg.HTTP)
 	case export.DestXMPP:
 		reg.sender = NewXMPPSender(newReg.Addressable)
+	case export.DestKafka:
+		reg.sender = NewKafkaSender(newReg.Addressable, newReg.Kafka)
+	case export.DestAMQP:
+		reg.sender = NewAMQPSender(newReg.Addressable, newReg.AMQP)
+	case export.DestAWSIoT:
+		reg.sender = NewAWSIoTSender(newReg.Addressable, newReg.AWSIoT)
+	case export.DestPubSub:
+		reg.sender = NewPubSubSender(newReg.Addressable, newReg.PubSub)
+	case export.DestInfluxDB:
+		reg.sender = NewInfluxDBSender(newReg.Addressable, newReg.InfluxDB)
+	case export.DestPostgres:
+		reg.sender = NewPostgresSender(newReg.Addressable, newReg.Postgres)
 
 	default:
-		logger.Warn("Destination not supported: ", zap.String("destination", newReg.Destination))
-		return false
+		return fmt.Errorf("Destination not supported: %s", newReg.Destination)
 	}
 
 	if reg.sender == nil {
-		return false
+		return fmt.Errorf("Could not create the sender of destination %s", newReg.Destination)
 	}
 
-	reg.encrypt = nil
-	switch newReg.Encryption.Algo {
-	case export.EncNone:
-		reg.encrypt = nil
-	case export.EncAes:
-		reg.encrypt = NewAESEncryption(newReg.Encryption)
-	default:
-		logger.Warn("Encryption not supported: ", zap.String("Algorithm", newReg.Encryption.Algo))
-		return false
+	if newReg.Batch.Enabled() {
+		if _, ok := reg.format.(BatchFormatter); !ok {
+			return fmt.Errorf("Format can't batch the events: %s", newReg.Format)
+		}
+		reg.batch = newEventBatch(newReg.Batch)
 	}
 
-	reg.filter = nil
+	if newReg.Buffer.Enabled() {
+		if _, ok := reg.sender.(forwarder); !ok {
+			return fmt.Errorf("Destination can't buffer the payloads: %s", newReg.Destination)
+		}
+	}
+	if newReg.Buffer.Enabled() && !reg.check {
+		buffer, err := newPayloadBuffer(configuration.BufferDir, newReg.Name, newReg.Buffer)
+		if err != nil {
+			return err
+		}
+		reg.buffer = buffer
+	}
 
-	if len(newReg.Filter.DeviceIDs) > 0 {
-		reg.filter = append(reg.filter, newDevIdFilter(newReg.Filter))
-		logger.Debug("Device ID filter added: ", zap.Any("filters", newReg.Filter.DeviceIDs))
+	// Senders with a content coding, like HTTP, send the compressed payloads raw instead of
+	// base64 encoded, when the compression is the last stage
+	if sender, ok := reg.sender.(contentEncoder); ok && len(reg.transforms) > 0 {
+		last := len(reg.transforms) - 1
+		if c, ok := reg.transforms[last].(compressor); ok {
+			reg.transforms[last] = rawCompression{c}
+			sender.setContentEncoding(c.contentEncoding())
+		}
 	}
 
-	if len(newReg.Filter.ValueDescriptorIDs) > 0 {
-		reg.filter = append(reg.filter, newValueDescFilter(newReg.Filter))
-		logger.Debug("Value descriptor filter added: ", zap.Any("filters", newReg.Filter.ValueDescriptorIDs))
+	// The protobuf messages are sent as such, unless they're encrypted
+	if sender, ok := reg.sender.(contentTyper); ok && newReg.Format == export.FormatProtobuf {
+		binary := true
+		for _, transform := range reg.transforms {
+			if _, ok := transform.(rawCompression); !ok {
+				binary = false
+			}
+		}
+		if binary {
+			sender.setContentType(mimeTypeProtobuf)
+		}
 	}
 
-	return true
+	if !reg.check {
+		reg.limiter = newRateLimiter(newReg.RateLimit)
+	}
+
+	for _, f := range reg.filter {
+		if stage, ok := f.(*aggregateStage); ok {
+			reg.aggregate = stage
+			stage.start()
+		}
+	}
+	return nil
+}
+
+func (reg *registrationInfo) processEvent(event *models.Event) {
+	reg.processStages(event, reg.filter)
 }
 
-func (reg registrationInfo) processEvent(event *models.Event) {
+// Transform the event with the stages, then send or batch it
+func (reg *registrationInfo) processStages(event *models.Event, stages []Filterer) {
 	// Valid Event Filter, needed?
 
-	for _, f := range reg.filter {
+	for _, f := range stages {
 		var accepted bool
 		accepted, event = f.Filter(event)
 		if !accepted {
-			logger.Info("Event filtered")
+			// The aggregate stage keeps every event until its window ends
+			if _, ok := f.(*aggregateStage); !ok {
+				logger.Info("Event filtered")
+			}
 			return
 		}
 	}
@@ -142,24 +278,185 @@ func (reg registrationInfo) processEvent(event *models.Event) {
 		logger.Warn("registrationInfo with nil format")
 		return
 	}
-	formated := reg.format.Format(event)
+	if reg.batch != nil {
+		if reg.batch.add(event) {
+			reg.flushBatch()
+		}
+		return
+	}
+	reg.send(reg.format.Format(event), event, 1)
+}
 
-	compressed := formated
-	if reg.compression != nil {
-		compressed = reg.compression.Transform(formated)
+// Send the events aggregated over the window ending, through the stages after the aggregation
+func (reg *registrationInfo) flushAggregate() {
+	if reg.aggregate == nil {
+		return
 	}
+	var next []Filterer
+	for i, f := range reg.filter {
+		if stage, ok := f.(*aggregateStage); ok && stage == reg.aggregate {
+			next = reg.filter[i+1:]
+		}
+	}
+	for _, event := range reg.aggregate.take() {
+		reg.processStages(event, next)
+	}
+}
 
-	encrypted := compressed
-	if reg.encrypt != nil {
-		encrypted = reg.encrypt.Transform(compressed)
+// Send the batched events in a single payload
+func (reg *registrationInfo) flushBatch() {
+	if reg.batch == nil || len(reg.batch.events) == 0 {
+		return
+	}
+	events := reg.batch.take()
+	reg.send(reg.format.(BatchFormatter).FormatBatch(events), nil, len(events))
+	logger.Debug("Sent batch with registration:",
+		zap.Int("events", len(events)),
+		zap.String("Name", reg.registration.Name))
+}
+
+// Transform and send the formatted data of the events, made from the event unless it's a batch
+func (reg *registrationInfo) send(formated []byte, event *models.Event, events int) {
+	// The format failed, or made nothing of the event
+	if formated == nil {
+		return
+	}
+	data := formated
+	for _, transform := range reg.transforms {
+		data = transform.Transform(data)
+		// Dropped by a script or a plugin
+		if data == nil {
+			return
+		}
+	}
+
+	if reg.limiter == nil {
+		reg.deliver(data, event)
+		return
+	}
+	if reg.limiter.add(pendingPayload{data: data, event: event, events: events}) {
+		logger.Warn("Too many payloads over the rate limit, drop the oldest",
+			zap.String("Name", reg.registration.Name))
+		reg.stats.failed(1, errRateLimited)
+	}
+	reg.sendReady()
+}
+
+// Send the payloads the rate limits let through
+func (reg *registrationInfo) sendReady() {
+	for _, p := range reg.limiter.ready(time.Now()) {
+		reg.deliver(p.data, p.event)
+	}
+}
+
+// Send the payloads waiting for the rate limits right away
+func (reg *registrationInfo) flushLimiter() {
+	for _, p := range reg.limiter.take() {
+		reg.deliver(p.data, p.event)
+	}
+}
+
+// Send the payload to the destination, made from the event unless it's a batch
+func (reg *registrationInfo) deliver(data []byte, event *models.Event) {
+	if reg.buffer != nil {
+		reg.forward(data)
+	} else if sender, ok := reg.sender.(EventSender); ok && event != nil {
+		sender.SendEvent(data, event)
+	} else {
+		reg.sender.Send(data)
+	}
+	if event != nil {
+		logger.Debug("Sent event with registration:",
+			zap.Any("Event", event),
+			zap.String("Name", reg.registration.Name))
+	}
+}
+
+// Send the payload, and store it when the destination doesn't take it. The stored payloads
+// are forwarded first, so the destination gets them in order
+func (reg *registrationInfo) forward(data []byte) {
+	sender := reg.sender.(forwarder)
+	now := time.Now()
+	if !reg.buffer.empty() {
+		if err := reg.buffer.add(data, now); err != nil {
+			logger.Error("Could not buffer the payload, drop data", zap.Error(err),
+				zap.String("Name", reg.registration.Name))
+		}
+		reg.buffer.drain(sender, now)
+		return
+	}
+	if sender.forward(data) == nil {
+		return
 	}
+	if err := reg.buffer.add(data, now); err != nil {
+		logger.Error("Could not buffer the payload, drop data", zap.Error(err),
+			zap.String("Name", reg.registration.Name))
+		return
+	}
+	logger.Info("Buffered payload with registration:", zap.String("Name", reg.registration.Name))
+}
 
-	reg.sender.Send(encrypted)
-	logger.Debug("Sent event with registration:",
-		zap.Any("Event", event),
+// Send the payload of a dead letter again
+func (reg *registrationInfo) replay(data []byte) {
+	reg.sender.Send(data)
+	logger.Info("Replayed dead letter with registration:",
 		zap.String("Name", reg.registration.Name))
 }
 
+// Send the dead letters queued for replay, their dead letters are already removed
+func (reg *registrationInfo) flushReplays() {
+	for {
+		select {
+		case data := <-reg.chReplay:
+			reg.replay(data)
+		default:
+			return
+		}
+	}
+}
+
+// Dead letter again the payloads queued for replay, without sending them. Their dead letters
+// are already removed from the client
+func (reg *registrationInfo) deadLetterReplays(destination string, reason string) {
+	for {
+		select {
+		case data := <-reg.chReplay:
+			reg.deadLetter(export.DeadLetter{
+				Registration: reg.registration.Name,
+				Destination:  destination,
+				Payload:      data,
+				Error:        reason,
+			})
+		default:
+			return
+		}
+	}
+}
+
+// Stop the registration its update made invalid. The sender may not work anymore, so the
+// payloads queued for replay are dead lettered again instead of sent
+func (reg *registrationInfo) stopUpdateKO(destination string) {
+	reg.deleteMe = true
+	reg.aggregate.stop()
+	reg.batch.stop()
+	reg.limiter.stop()
+	reg.buffer.stop()
+	reg.closeSender()
+	closeTransforms(reg.transforms)
+	stopStats(reg.registration.Name)
+	reg.deadLetterReplays(destination, "Registration updated: KO")
+}
+
+// Release the connections of the sender before it's replaced or the registration removed
+func (reg *registrationInfo) closeSender() {
+	if closer, ok := reg.sender.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			logger.Warn("Error closing the sender", zap.Error(err))
+		}
+	}
+	reg.sender = nil
+}
+
 func registrationLoop(reg *registrationInfo) {
 	logger.Info("registration loop started",
 		zap.String("Name", reg.registration.Name))
@@ -168,18 +465,46 @@ func registrationLoop(reg *registrationInfo) {
 		case event := <-reg.chEvent:
 			reg.processEvent(event)
 
+		case <-reg.aggregate.tick():
+			reg.flushAggregate()
+
+		case <-reg.batch.tick():
+			reg.flushBatch()
+
+		case <-reg.limiter.tick():
+			reg.sendReady()
+
+		case <-reg.buffer.tick():
+			reg.buffer.drain(reg.sender.(forwarder), time.Now())
+
+		case data := <-reg.chReplay:
+			reg.replay(data)
+
 		case newReg := <-reg.chRegistration:
 			if newReg == nil {
 				logger.Info("Terminating registration goroutine")
+				reg.flushAggregate()
+				reg.aggregate.stop()
+				reg.flushBatch()
+				reg.batch.stop()
+				reg.flushLimiter()
+				reg.limiter.stop()
+				reg.flushReplays()
+				reg.buffer.stop()
+				reg.closeSender()
+				closeTransforms(reg.transforms)
+				stopStats(reg.registration.Name)
 				return
 			} else {
+				// The payloads queued for replay were made for the destination it had
+				destination := reg.registration.Destination
 				if reg.update(*newReg) {
 					logger.Info("Registration updated: OK",
 						zap.String("Name", reg.registration.Name))
 				} else {
 					logger.Info("Registration updated: KO, terminating goroutine",
 						zap.String("Name", reg.registration.Name))
-					reg.deleteMe = true
+					reg.stopUpdateKO(destination)
 					return
 				}
 			}
@@ -275,6 +600,9 @@ func Loop(errChan chan error, eventCh chan *models.Event) {
 			logger.Info("exit msg", zap.Error(e))
 			return
 
+		case replay := <-replayRequests:
+			replay.result <- replayDeadLetter(registrations, replay.letter)
+
 		case update := <-registrationChanges:
 			logger.Info("Registration changes")
 			err := updateRunningRegistrations(registrations, update)
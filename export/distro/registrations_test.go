@@ -98,8 +98,7 @@ func TestRegistrationInfoEvent(t *testing.T) {
 
 	ri.format = dummy
 	ri.sender = dummy
-	ri.encrypt = dummy
-	ri.compression = dummy
+	ri.transforms = []Transformer{dummy, dummy}
 
 	// Filter only accepting events from dummyDev
 	f := export.Filter{}
@@ -123,8 +122,7 @@ func TestRegistrationInfoLoop(t *testing.T) {
 
 	ri.format = &dummyStruct{}
 	ri.sender = &dummyStruct{}
-	ri.encrypt = &dummyStruct{}
-	ri.compression = &dummyStruct{}
+	ri.transforms = []Transformer{&dummyStruct{}, &dummyStruct{}}
 	ri.filter = nil
 
 	go func() {
@@ -163,8 +161,7 @@ func TestRegistrationInfoLoop(t *testing.T) {
 	}()
 	ri.format = &dummyStruct{}
 	ri.sender = &dummyStruct{}
-	ri.encrypt = &dummyStruct{}
-	ri.compression = &dummyStruct{}
+	ri.transforms = []Transformer{&dummyStruct{}, &dummyStruct{}}
 	ri.filter = nil
 	// Process an event and terminate
 	registrationLoop(ri)
@@ -204,8 +201,7 @@ func BenchmarkProcessEvent(b *testing.B) {
 
 	ri.format = Dummy
 	ri.sender = Dummy
-	ri.encrypt = Dummy
-	ri.compression = Dummy
+	ri.transforms = []Transformer{Dummy, Dummy}
 	ri.filter = nil
 
 	b.Run("nil", func(b *testing.B) {
@@ -216,7 +212,7 @@ func BenchmarkProcessEvent(b *testing.B) {
 	})
 
 	ri.format = jsonFormatter{}
-	ri.compression = &gzipTransformer{}
+	ri.transforms = []Transformer{&gzipTransformer{}}
 
 	b.Run("json_gzip", func(b *testing.B) {
 		for i := 0; i < b.N; i++ {
@@ -225,3 +221,76 @@ func BenchmarkProcessEvent(b *testing.B) {
 		b.SetBytes(int64(Dummy.lastSize))
 	})
 }
+
+func TestReplayDeadLetter(t *testing.T) {
+	logger = zap.NewNop()
+	ri := newRegistrationInfo()
+	ri.update(validRegistration())
+	dummy := &dummyStruct{}
+	ri.sender = dummy
+	running := map[string]*registrationInfo{"reg": ri}
+
+	if err := replayDeadLetter(running, export.DeadLetter{Registration: "other", Payload: []byte("data")}); err != errNotRunning {
+		t.Fatalf("A dead letter of another registration should not be replayed: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		registrationLoop(ri)
+		close(done)
+	}()
+	if err := replayDeadLetter(running, export.DeadLetter{Registration: "reg", Payload: []byte("data")}); err != nil {
+		t.Fatalf("The dead letter should be replayed: %v", err)
+	}
+	ri.chRegistration <- nil
+	<-done
+	if dummy.count != 1 || dummy.lastSize != 4 {
+		t.Fatal("The registration should send the payload again")
+	}
+
+	// The registration isn't taking the dead letters, the replay doesn't wait for it
+	for i := 0; i < replayQueue; i++ {
+		if err := replayDeadLetter(running, export.DeadLetter{Registration: "reg", Payload: []byte("data")}); err != nil {
+			t.Fatalf("The dead letter should be queued: %v", err)
+		}
+	}
+	if err := replayDeadLetter(running, export.DeadLetter{Registration: "reg", Payload: []byte("data")}); err != errReplayBusy {
+		t.Fatalf("The dead letter should be refused once the queue is full: %v", err)
+	}
+}
+
+func TestReplayDeadLetterUpdateKO(t *testing.T) {
+	logger = zap.NewNop()
+	r := validRegistration()
+	r.Name = "reg"
+	ri := newRegistrationInfo()
+	if !ri.update(r) {
+		t.Fatal("The registration should be valid")
+	}
+	dummy := &dummyStruct{}
+	ri.closeSender()
+	ri.sender = dummy
+	var letters []export.DeadLetter
+	ri.deadLetter = func(letter export.DeadLetter) { letters = append(letters, letter) }
+	running := map[string]*registrationInfo{"reg": ri}
+
+	for _, p := range []string{"one", "two"} {
+		if err := replayDeadLetter(running, export.DeadLetter{Registration: "reg", Payload: []byte(p)}); err != nil {
+			t.Fatalf("The dead letter should be queued: %v", err)
+		}
+	}
+	invalid := r
+	invalid.Format = "UNKNOWN"
+	if ri.update(invalid) {
+		t.Fatal("The update should be KO")
+	}
+	ri.stopUpdateKO(r.Destination)
+
+	if dummy.count != 0 {
+		t.Errorf("The replays should not be sent, %d sent", dummy.count)
+	}
+	if len(letters) != 2 || string(letters[0].Payload) != "one" || string(letters[1].Payload) != "two" ||
+		letters[0].Registration != "reg" || letters[0].Destination != r.Destination {
+		t.Errorf("The replays should be dead lettered again: %v", letters)
+	}
+}
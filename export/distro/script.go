@@ -0,0 +1,205 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/export"
+	lua "github.com/yuin/gopher-lua"
+	"go.uber.org/zap"
+)
+
+const (
+	scriptFunction = "transform"
+	scriptTimeout  = time.Second
+	scriptMaxDepth = 100
+)
+
+// Reshapes the JSON payloads with the transform function of a Lua script. The script only has
+// the base, table, string and math libraries, and each call is stopped after scriptTimeout
+type luaScript struct {
+	name    string
+	state   *lua.LState
+	timeout time.Duration
+}
+
+func newScriptStage(name string, details export.ScriptDetails) (Transformer, error) {
+	if details.Language != export.ScriptLua {
+		return nil, fmt.Errorf("Script language not supported: %s", details.Language)
+	}
+
+	state := lua.NewState(lua.Options{SkipOpenLibs: true})
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		state.Push(state.NewFunction(lib.open))
+		state.Push(lua.LString(lib.name))
+		state.Call(1, 0)
+	}
+	// Scripts don't read files
+	for _, f := range []string{"dofile", "loadfile"} {
+		state.SetGlobal(f, lua.LNil)
+	}
+
+	if err := state.DoString(details.Source); err != nil {
+		state.Close()
+		return nil, fmt.Errorf("Stage %s script: %v", name, err)
+	}
+	if state.GetGlobal(scriptFunction).Type() != lua.LTFunction {
+		state.Close()
+		return nil, fmt.Errorf("Stage %s script doesn't define the function %s", name, scriptFunction)
+	}
+	return &luaScript{name: name, state: state, timeout: scriptTimeout}, nil
+}
+
+// Transform returns nil, and the payload is not sent, when the script drops it or fails
+func (script *luaScript) Transform(data []byte) []byte {
+	payload, err := script.run(data)
+	if err != nil {
+		logger.Warn("Script failed", zap.String("stage", script.name), zap.Error(err))
+		return nil
+	}
+	return payload
+}
+
+// Close - release the Lua state of the script, once its stage is replaced or removed
+func (script *luaScript) Close() error {
+	script.state.Close()
+	return nil
+}
+
+func (script *luaScript) run(data []byte) ([]byte, error) {
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), script.timeout)
+	defer cancel()
+	script.state.SetContext(ctx)
+	defer script.state.RemoveContext()
+
+	if err := script.state.CallByParam(lua.P{
+		Fn:      script.state.GetGlobal(scriptFunction),
+		NRet:    1,
+		Protect: true,
+	}, toLua(script.state, decoded)); err != nil {
+		return nil, err
+	}
+	ret := script.state.Get(-1)
+	script.state.Pop(1)
+
+	switch ret := ret.(type) {
+	case lua.LString:
+		return []byte(ret), nil
+	case *lua.LTable:
+		value, err := fromLua(ret, map[*lua.LTable]bool{}, 0)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(value)
+	default:
+		if ret == lua.LNil {
+			logger.Debug("Payload dropped by the script", zap.String("stage", script.name))
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s returned a %s, instead of a string or a table", scriptFunction, ret.Type())
+	}
+}
+
+// Lua value of the decoded JSON
+func toLua(state *lua.LState, value interface{}) lua.LValue {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		table := state.NewTable()
+		for k, v := range value {
+			table.RawSetString(k, toLua(state, v))
+		}
+		return table
+	case []interface{}:
+		table := state.NewTable()
+		for _, v := range value {
+			table.Append(toLua(state, v))
+		}
+		return table
+	case string:
+		return lua.LString(value)
+	case float64:
+		return lua.LNumber(value)
+	case bool:
+		return lua.LBool(value)
+	default:
+		return lua.LNil
+	}
+}
+
+// Value to encode in JSON of the Lua value. Tables with the keys 1 to n are arrays, and the
+// others objects. The tables containing themselves, or nested deeper than scriptMaxDepth, are
+// errors
+func fromLua(value lua.LValue, parents map[*lua.LTable]bool, depth int) (interface{}, error) {
+	switch value := value.(type) {
+	case *lua.LTable:
+		if parents[value] {
+			return nil, fmt.Errorf("%s returned a table containing itself", scriptFunction)
+		}
+		if depth >= scriptMaxDepth {
+			return nil, fmt.Errorf("%s returned tables nested deeper than %d", scriptFunction, scriptMaxDepth)
+		}
+		parents[value] = true
+		defer delete(parents, value)
+
+		n := value.MaxN()
+		count := 0
+		value.ForEach(func(lua.LValue, lua.LValue) { count++ })
+		if n > 0 && n == count {
+			array := make([]interface{}, 0, n)
+			for i := 1; i <= n; i++ {
+				v, err := fromLua(value.RawGetInt(i), parents, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				array = append(array, v)
+			}
+			return array, nil
+		}
+		object := make(map[string]interface{}, count)
+		var err error
+		value.ForEach(func(k lua.LValue, v lua.LValue) {
+			if err != nil {
+				return
+			}
+			key := k.String()
+			if number, ok := k.(lua.LNumber); ok {
+				key = strconv.FormatFloat(float64(number), 'f', -1, 64)
+			}
+			object[key], err = fromLua(v, parents, depth+1)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return object, nil
+	case lua.LString:
+		return string(value), nil
+	case lua.LNumber:
+		return float64(value), nil
+	case lua.LBool:
+		return bool(value), nil
+	default:
+		return nil, nil
+	}
+}
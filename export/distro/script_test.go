@@ -0,0 +1,150 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+	"github.com/edgexfoundry/edgex-go/export"
+	"go.uber.org/zap"
+)
+
+func luaDetails(source string) export.ScriptDetails {
+	return export.ScriptDetails{Language: export.ScriptLua, Source: source}
+}
+
+func TestScriptStage(t *testing.T) {
+	logger = zap.NewNop()
+
+	event := []byte(`{"device":"dev","origin":10,"readings":[{"name":"temperature","value":"21"},{"name":"humidity","value":"40"}]}`)
+	var tests = []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{"string", `function transform(e) return e.device .. "=" .. e.readings[1].value end`, "dev=21"},
+		{"table", `
+function transform(e)
+  local values = {}
+  for _, r in ipairs(e.readings) do
+    values[r.name] = tonumber(r.value)
+  end
+  return {id = e.device, ts = e.origin, values = values}
+end`, `{"id":"dev","ts":10,"values":{"humidity":40,"temperature":21}}`},
+		{"array", `function transform(e) return {e.readings[2].name, e.readings[1].name} end`, `["humidity","temperature"]`},
+		{"drop", `function transform(e) if e.device == "dev" then return nil end return "kept" end`, ""},
+		{"error", `function transform(e) return e.missing.value end`, ""},
+		{"wrongType", `function transform(e) return 1 end`, ""},
+		{"cycle", `function transform(e) local t = {} t.self = t return t end`, ""},
+		{"deep", `function transform(e) local t = {} for i = 1, 200 do t = {t} end return t end`, ""},
+		{"shared", `function transform(e) local t = {1} return {a = t, b = t} end`, `{"a":[1],"b":[1]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, err := newScriptStage(tt.name, luaDetails(tt.source))
+			if err != nil {
+				t.Fatalf("Error creating the script: %v", err)
+			}
+			payload := script.Transform(event)
+			if tt.expected == "" {
+				if payload != nil {
+					t.Errorf("The payload should be dropped: %s", payload)
+				}
+				return
+			}
+			if string(payload) != tt.expected {
+				t.Errorf("The payload is %s, should be %s", payload, tt.expected)
+			}
+		})
+	}
+}
+
+func TestScriptStageInvalid(t *testing.T) {
+	var tests = []struct {
+		name    string
+		details export.ScriptDetails
+		err     string
+	}{
+		{"language", export.ScriptDetails{Language: "JS", Source: "function transform(e) return e end"}, "Script language not supported: JS"},
+		{"syntax", luaDetails("function transform(e"), "Stage syntax script:"},
+		{"withoutFunction", luaDetails("local x = 1"), "doesn't define the function transform"},
+		{"files", luaDetails(`dofile("/etc/passwd")`), "Stage files script:"},
+		{"os", luaDetails(`os.exit(1)`), "Stage os script:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newScriptStage(tt.name, tt.details); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Error %v, should contain %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestScriptStageTimeout(t *testing.T) {
+	logger = zap.NewNop()
+
+	script, err := newScriptStage("loop", luaDetails("function transform(e) while true do end end"))
+	if err != nil {
+		t.Fatalf("Error creating the script: %v", err)
+	}
+	script.(*luaScript).timeout = 50 * time.Millisecond
+	if payload := script.Transform([]byte("{}")); payload != nil {
+		t.Errorf("The script should be stopped: %s", payload)
+	}
+	// The script still runs after it was stopped
+	script.(*luaScript).timeout = scriptTimeout
+	script.(*luaScript).state.DoString(`function transform(e) return "ok" end`)
+	if payload := script.Transform([]byte("{}")); string(payload) != "ok" {
+		t.Errorf("The script should run again: %s", payload)
+	}
+}
+
+func TestRegistrationInfoScript(t *testing.T) {
+	logger = zap.NewNop()
+
+	r := validRegistration()
+	r.Filter = export.Filter{}
+	r.Pipeline = []export.TransformStage{
+		{Name: "legacy", Type: export.StageScript, Script: &export.ScriptDetails{Language: export.ScriptLua, Source: `
+function transform(e)
+  if e.readings == nil or #e.readings == 0 then return nil end
+  return e.device .. ";" .. e.readings[1].name .. ";" .. e.readings[1].value
+end`}},
+	}
+	reg := newRegistrationInfo()
+	if err := reg.configure(r); err != nil {
+		t.Fatalf("Error configuring the registration: %v", err)
+	}
+	script := reg.transforms[0].(*luaScript)
+	sender := &batchSender{}
+	reg.sender = sender
+
+	reg.processEvent(&models.Event{Device: "dev"})
+	reg.processEvent(&models.Event{Device: "dev", Readings: []models.Reading{{Name: "temperature", Value: "21"}}})
+	if len(sender.payloads) != 1 || string(sender.payloads[0]) != "dev;temperature;21" {
+		t.Errorf("The script should drop the event without readings and reshape the other: %q", sender.payloads)
+	}
+
+	r.Pipeline = []export.TransformStage{
+		{Name: "compress", Type: export.StageCompress, Compression: export.CompGzip},
+		{Name: "legacy", Type: export.StageScript, Script: &export.ScriptDetails{Language: export.ScriptLua, Source: "function transform(e) return e end"}},
+	}
+	if err := reg.configure(r); err == nil {
+		t.Error("A script should not run after the compression")
+	}
+
+	r.Pipeline = nil
+	if err := reg.configure(r); err != nil {
+		t.Fatalf("Error configuring the registration: %v", err)
+	}
+	if !script.state.IsClosed() {
+		t.Error("The script replaced should be closed")
+	}
+}
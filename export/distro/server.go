@@ -11,6 +11,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	"github.com/edgexfoundry/edgex-go/export"
 	"github.com/go-zoo/bone"
@@ -20,6 +21,11 @@ import (
 const (
 	apiV1NotifyRegistrations = "/api/v1/notify/registrations"
 	apiV1Ping                = "/api/v1/ping"
+	apiV1Replay              = "/api/v1/replay"
+	apiV1Stats               = "/api/v1/stats/:name"
+	apiV1Check               = "/api/v1/check"
+
+	replayTimeout = 5 * time.Second
 )
 
 func replyPing(w http.ResponseWriter, r *http.Request) {
@@ -63,11 +69,78 @@ func replyNotifyRegistrations(w http.ResponseWriter, r *http.Request) {
 	RefreshRegistrations(update)
 }
 
+func replyReplay(w http.ResponseWriter, r *http.Request) {
+	letter := export.DeadLetter{}
+	if err := json.NewDecoder(r.Body).Decode(&letter); err != nil {
+		logger.Error("Failed to parse dead letter", zap.Error(err))
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, err.Error())
+		return
+	}
+	if letter.Registration == "" || len(letter.Payload) == 0 {
+		logger.Error("Missing json field", zap.String("registration", letter.Registration))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	replay := replayRequest{letter: letter, result: make(chan error, 1)}
+	select {
+	case replayRequests <- replay:
+	case <-time.After(replayTimeout):
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	if err := <-replay.result; err != nil {
+		logger.Warn("Could not replay dead letter", zap.Error(err), zap.String("registration", letter.Registration))
+		if err == errReplayBusy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		io.WriteString(w, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func replyStats(w http.ResponseWriter, r *http.Request) {
+	name := bone.GetValue(r, "name")
+	stats := getStats(name)
+	if stats == nil {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, "Registration not running: "+name)
+		return
+	}
+	w.Header().Set("Content-Type", mimeTypeJSON)
+	json.NewEncoder(w).Encode(stats)
+}
+
+// Send a test event with the registration of the body
+func replyCheck(w http.ResponseWriter, r *http.Request) {
+	reg := export.Registration{}
+	if err := json.NewDecoder(r.Body).Decode(&reg); err != nil {
+		logger.Error("Failed to parse registration", zap.Error(err))
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, err.Error())
+		return
+	}
+
+	check := checkRegistration(reg)
+	if !check.Sent {
+		logger.Info("Registration check failed", zap.String("Name", reg.Name), zap.String("error", check.Error))
+	}
+	w.Header().Set("Content-Type", mimeTypeJSON)
+	json.NewEncoder(w).Encode(&check)
+}
+
 // HTTPServer function
 func httpServer() http.Handler {
 	mux := bone.New()
 	mux.Get(apiV1Ping, http.HandlerFunc(replyPing))
 	mux.Put(apiV1NotifyRegistrations, http.HandlerFunc(replyNotifyRegistrations))
+	mux.Post(apiV1Replay, http.HandlerFunc(replyReplay))
+	mux.Get(apiV1Stats, http.HandlerFunc(replyStats))
+	mux.Post(apiV1Check, http.HandlerFunc(replyCheck))
 
 	return mux
 }
@@ -7,10 +7,15 @@
 package distro
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/edgexfoundry/edgex-go/export"
 )
 
 func TestPing(t *testing.T) {
@@ -72,3 +77,112 @@ func TestReplyNotifyRegistrations(t *testing.T) {
 		})
 	}
 }
+
+func TestReplyReplay(t *testing.T) {
+	var tests = []struct {
+		name   string
+		data   string
+		result error
+		status int
+	}{
+		{"invalid", "{", nil, http.StatusBadRequest},
+		{"noRegistration", `{"payload": "ZGF0YQ=="}`, nil, http.StatusBadRequest},
+		{"noPayload", `{"registration": "reg"}`, nil, http.StatusBadRequest},
+		{"notRunning", `{"registration": "reg", "payload": "ZGF0YQ=="}`, errNotRunning, http.StatusNotFound},
+		{"busy", `{"registration": "reg", "payload": "ZGF0YQ=="}`, errReplayBusy, http.StatusServiceUnavailable},
+		{"replayed", `{"registration": "reg", "payload": "ZGF0YQ=="}`, nil, http.StatusAccepted},
+	}
+	ts := httptest.NewServer(httpServer())
+	defer ts.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Answer like the registration loop
+			if tt.status != http.StatusBadRequest {
+				go func() {
+					replay := <-replayRequests
+					if string(replay.letter.Payload) != "data" {
+						replay.result <- fmt.Errorf("invalid payload %s", replay.letter.Payload)
+						return
+					}
+					replay.result <- tt.result
+				}()
+			}
+
+			response, err := http.Post(ts.URL+apiV1Replay, "application/json", strings.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("Error replaying: %v", err)
+			}
+			response.Body.Close()
+			if response.StatusCode != tt.status {
+				t.Errorf("Returned status %d, should be %d", response.StatusCode, tt.status)
+			}
+		})
+	}
+}
+
+func TestReplyStats(t *testing.T) {
+	stats := startStats("running")
+	defer stopStats("running")
+	stats.sent(2)
+	stats.failed(1, errors.New("refused"))
+
+	ts := httptest.NewServer(httpServer())
+	defer ts.Close()
+
+	response, err := http.Get(ts.URL + "/api/v1/stats/running")
+	if err != nil {
+		t.Fatalf("Error getting stats: %v", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Returned status %d, should be %d", response.StatusCode, http.StatusOK)
+	}
+	got := export.RegistrationStats{}
+	if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+		t.Fatalf("Error decoding stats: %v", err)
+	}
+	if got.Name != "running" || !got.Running || got.Sent != 2 || got.Failed != 1 || got.LastError != "refused" {
+		t.Errorf("Unexpected stats: %+v", got)
+	}
+
+	response, err = http.Get(ts.URL + "/api/v1/stats/stopped")
+	if err != nil {
+		t.Fatalf("Error getting stats: %v", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusNotFound {
+		t.Errorf("Returned status %d, should be %d", response.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestReplyCheck(t *testing.T) {
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer endpoint.Close()
+
+	ts := httptest.NewServer(httpServer())
+	defer ts.Close()
+
+	response, err := http.Post(ts.URL+apiV1Check, mimeTypeJSON, strings.NewReader("{"))
+	if err != nil {
+		t.Fatalf("Error checking registration: %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusBadRequest {
+		t.Errorf("Returned status %d, should be %d", response.StatusCode, http.StatusBadRequest)
+	}
+
+	data, _ := json.Marshal(checkHTTPRegistration(t, endpoint.URL))
+	response, err = http.Post(ts.URL+apiV1Check, mimeTypeJSON, strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("Error checking registration: %v", err)
+	}
+	defer response.Body.Close()
+	check := export.RegistrationCheck{}
+	json.NewDecoder(response.Body).Decode(&check)
+	if response.StatusCode != http.StatusOK || !check.Valid || !check.Sent {
+		t.Errorf("Returned status %d with %+v", response.StatusCode, check)
+	}
+}
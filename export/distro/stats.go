@@ -0,0 +1,174 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/export"
+)
+
+// Delivery counters of a registration, updated by its sender. A nil one counts nothing
+type registrationStats struct {
+	mutex sync.Mutex
+	stats export.RegistrationStats
+}
+
+func nowMillis() int64 {
+	return time.Now().UnixNano() / int64(time.Millisecond)
+}
+
+func (s *registrationStats) sent(n int) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.stats.Sent += int64(n)
+	s.stats.LastSuccess = nowMillis()
+}
+
+func (s *registrationStats) failed(n int, err error) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.stats.Failed += int64(n)
+	s.stats.LastFailure = nowMillis()
+	if err != nil {
+		s.stats.LastError = err.Error()
+	}
+}
+
+func (s *registrationStats) retried(err error) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.stats.Retried++
+	s.stats.LastFailure = nowMillis()
+	if err != nil {
+		s.stats.LastError = err.Error()
+	}
+}
+
+func (s *registrationStats) snapshot() export.RegistrationStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.stats
+}
+
+// senderStats - embedded by the senders to count their deliveries, and dead letter the payloads
+// they give up on. The counters and the dead letters are set once the sender is created, while
+// the batching senders may already flush
+type senderStats struct {
+	statsMutex   sync.Mutex
+	stats        *registrationStats
+	registration string
+	destination  string
+	letters      func(export.DeadLetter) // Nil when the payloads are dropped, as for a check
+}
+
+func (s *senderStats) setStats(stats *registrationStats) {
+	s.statsMutex.Lock()
+	defer s.statsMutex.Unlock()
+	s.stats = stats
+}
+
+func (s *senderStats) counters() *registrationStats {
+	s.statsMutex.Lock()
+	defer s.statsMutex.Unlock()
+	return s.stats
+}
+
+func (s *senderStats) countSent(n int) {
+	s.counters().sent(n)
+}
+
+func (s *senderStats) countFailed(n int, err error) {
+	s.counters().failed(n, err)
+}
+
+func (s *senderStats) countRetried(err error) {
+	s.counters().retried(err)
+}
+
+func (s *senderStats) setDeadLetter(registration string, destination string, store func(export.DeadLetter)) {
+	s.statsMutex.Lock()
+	defer s.statsMutex.Unlock()
+	s.registration = registration
+	s.destination = destination
+	s.letters = store
+}
+
+// Dead letter the payload the destination didn't take after the attempts
+func (s *senderStats) deadLetterPayload(data []byte, err error, attempts int) {
+	s.statsMutex.Lock()
+	store := s.letters
+	letter := export.DeadLetter{
+		Registration: s.registration,
+		Destination:  s.destination,
+		Payload:      data,
+		Attempts:     attempts,
+	}
+	s.statsMutex.Unlock()
+	if store == nil {
+		return
+	}
+	if err != nil {
+		letter.Error = err.Error()
+	}
+	store(letter)
+}
+
+// statsSender - Sender counting its deliveries
+type statsSender interface {
+	setStats(stats *registrationStats)
+}
+
+// deadLetterSender - Sender dead lettering the payloads it gives up on
+type deadLetterSender interface {
+	setDeadLetter(registration string, destination string, store func(export.DeadLetter))
+}
+
+// Counters of the running registrations, kept while their senders are replaced
+var runningStats = struct {
+	sync.Mutex
+	registrations map[string]*registrationStats
+}{registrations: make(map[string]*registrationStats)}
+
+func startStats(name string) *registrationStats {
+	runningStats.Lock()
+	defer runningStats.Unlock()
+	s, ok := runningStats.registrations[name]
+	if !ok {
+		s = &registrationStats{stats: export.RegistrationStats{Name: name, Running: true}}
+		runningStats.registrations[name] = s
+	}
+	return s
+}
+
+func stopStats(name string) {
+	runningStats.Lock()
+	defer runningStats.Unlock()
+	delete(runningStats.registrations, name)
+}
+
+// Counters of the registration, nil when it isn't running
+func getStats(name string) *export.RegistrationStats {
+	runningStats.Lock()
+	s, ok := runningStats.registrations[name]
+	runningStats.Unlock()
+	if !ok {
+		return nil
+	}
+	stats := s.snapshot()
+	return &stats
+}
@@ -0,0 +1,71 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/edgexfoundry/edgex-go/export"
+)
+
+func TestRegistrationStats(t *testing.T) {
+	stats := &registrationStats{}
+	stats.sent(3)
+	stats.retried(errors.New("unavailable"))
+	stats.failed(2, errors.New("refused"))
+
+	s := stats.snapshot()
+	if s.Sent != 3 || s.Retried != 1 || s.Failed != 2 {
+		t.Errorf("Unexpected counters: %+v", s)
+	}
+	if s.LastSuccess == 0 || s.LastFailure == 0 || s.LastError != "refused" {
+		t.Errorf("The last success and failure should be kept: %+v", s)
+	}
+}
+
+func TestSenderStatsUnset(t *testing.T) {
+	// Senders created outside a running registration count nothing
+	sender := senderStats{}
+	sender.countSent(1)
+	sender.countFailed(1, errors.New("refused"))
+	sender.countRetried(nil)
+	sender.deadLetterPayload([]byte("data"), errors.New("refused"), 1)
+}
+
+func TestSenderStatsDeadLetter(t *testing.T) {
+	sender := senderStats{}
+	var letters []export.DeadLetter
+	sender.setDeadLetter("reg", export.DestMQTT, func(letter export.DeadLetter) { letters = append(letters, letter) })
+	sender.deadLetterPayload([]byte("data"), errors.New("refused"), 2)
+	if len(letters) != 1 || letters[0].Registration != "reg" || letters[0].Destination != export.DestMQTT ||
+		string(letters[0].Payload) != "data" || letters[0].Error != "refused" || letters[0].Attempts != 2 {
+		t.Errorf("The payload should be dead lettered with its failure: %+v", letters)
+	}
+}
+
+func TestRunningStats(t *testing.T) {
+	if getStats("reg") != nil {
+		t.Fatal("The registration should not be running")
+	}
+
+	stats := startStats("reg")
+	stats.sent(1)
+	// Replacing the sender keeps the counters
+	if startStats("reg") != stats {
+		t.Error("The counters should be kept while the registration runs")
+	}
+	s := getStats("reg")
+	if s == nil || s.Name != "reg" || !s.Running || s.Sent != 1 {
+		t.Errorf("Unexpected stats: %+v", s)
+	}
+
+	stopStats("reg")
+	if getStats("reg") != nil {
+		t.Error("The counters should be removed once the registration stops")
+	}
+}
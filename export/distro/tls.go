@@ -0,0 +1,49 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+)
+
+// TLS configuration of a sender, the server is verified with the CA file, or the system CAs
+// when empty, and the client certificate is presented when set
+func newTLSConfig(caFile, certFile, keyFile string, skipVerify bool) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: skipVerify}
+
+	if caFile != "" {
+		ca, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading the CA: %v", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificate in the CA %s", caFile)
+		}
+	}
+
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed loading x509 data: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// Key of the event from the template, {device} and {id} are replaced by those of the event
+func eventKey(template string, event *models.Event) string {
+	return strings.NewReplacer("{device}", event.Device, "{id}", event.ID.Hex()).Replace(template)
+}
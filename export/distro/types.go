@@ -18,11 +18,29 @@ type Sender interface {
 	Send(data []byte)
 }
 
+// EventSender - Sender that also uses the event the data was made from, e.g. to key it
+type EventSender interface {
+	Sender
+	SendEvent(data []byte, event *models.Event)
+}
+
+// forwarder - Sender telling whether the destination took the payload. The error says the
+// destination may take it later, the payload is stored and forwarded again
+type forwarder interface {
+	forward(data []byte) error
+}
+
 // Formatter - Format interface
 type Formatter interface {
 	Format(event *models.Event) []byte
 }
 
+// BatchFormatter - Formatter combining several events in a single payload
+type BatchFormatter interface {
+	Formatter
+	FormatBatch(events []*models.Event) []byte
+}
+
 // Transformer - Transform interface
 type Transformer interface {
 	Transform(data []byte) []byte
@@ -37,13 +55,20 @@ type Filterer interface {
 type registrationInfo struct {
 	registration export.Registration
 	format       Formatter
-	compression  Transformer
-	encrypt      Transformer
+	transforms   []Transformer // Stages transforming the formatted payloads, in order
 	sender       Sender
-	filter       []Filterer
+	filter       []Filterer      // Stages transforming the events, in order
+	batch        *eventBatch     // Nil when the events are sent one by one
+	aggregate    *aggregateStage // Nil when the events aren't aggregated
+	buffer       *payloadBuffer  // Nil when the undelivered payloads are dropped
+	limiter      *rateLimiter    // Nil when the registration isn't rate limited
+	stats        *registrationStats
 
 	chRegistration chan *export.Registration
 	chEvent        chan *models.Event
+	chReplay       chan []byte
+	deadLetter     func(export.DeadLetter) // Stores the replays the registration can't send
 
 	deleteMe bool
+	check    bool // Only checking the destination, without storing nor limiting the payloads
 }
@@ -15,6 +15,7 @@ import (
 )
 
 type xmppSender struct {
+	senderStats
 	client  *xmpp.Client
 	remote  string
 	msgType string
@@ -58,7 +59,7 @@ func NewXMPPSender(addr models.Addressable) Sender {
 func (sender *xmppSender) Send(data []byte) {
 	stringData := string(data)
 
-	sender.client.Send(xmpp.Chat{
+	_, err := sender.client.Send(xmpp.Chat{
 		Text:    stringData,
 		Remote:  sender.remote,
 		Subject: sender.subject,
@@ -66,6 +67,13 @@ func (sender *xmppSender) Send(data []byte) {
 		Other:   sender.other,
 		Stamp:   sender.stamp,
 	})
+	if err != nil {
+		logger.Warn(err.Error())
+		sender.countFailed(1, err)
+		sender.deadLetterPayload(data, err, 1)
+	} else {
+		sender.countSent(1)
+	}
 }
 
 func serverName(host string) string {
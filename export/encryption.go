@@ -8,16 +8,100 @@
 
 package export
 
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+)
+
 // Encryption types
 const (
 	EncNone = "NONE"
 	EncAes  = "AES"
+	// AES-256-GCM with the key of the registration
+	EncAesGcm = "AES256_GCM"
+	// AES-256-GCM with a key per payload, encrypted with the RSA public key of the registration
+	EncRsaEnvelope = "RSA_AES256_GCM"
 )
 
+// Min size of the RSA keys, in bits
+const rsaMinBits = 2048
+
 // EncryptionDetails - Provides details for encryption
 // of export data per client request
 type EncryptionDetails struct {
 	Algo       string `bson:"encryptionAlgorithm,omitempty" json:"encryptionAlgorithm,omitempty"`
 	Key        string `bson:"encryptionKey,omitempty" json:"encryptionKey,omitempty"`
 	InitVector string `bson:"initializingVector,omitempty" json:"initializingVector,omitempty"`
+	// PEM public key of the RSA envelopes
+	PublicKey string `bson:"publicKey,omitempty" json:"publicKey,omitempty"`
+	// Identifier of the key, in the payloads, telling the receivers which key decrypts them
+	// while the keys are rotated
+	KeyID string `bson:"keyId,omitempty" json:"keyId,omitempty"`
+}
+
+// EncryptedPayload - Payload of the AES-256-GCM and RSA envelope encryptions, sent as JSON.
+// The algorithm and the key id are authenticated with the data, as "<algorithm>:<key id>"
+type EncryptedPayload struct {
+	Algo  string `json:"algorithm"`
+	KeyID string `json:"keyId,omitempty"`
+	// AES key of the payload encrypted with RSA-OAEP and SHA-256, for the RSA envelopes
+	Key   []byte `json:"key,omitempty"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+// AdditionalData - Data authenticated with the encrypted data
+func (p EncryptedPayload) AdditionalData() []byte {
+	return []byte(p.Algo + ":" + p.KeyID)
+}
+
+// AESKey - 32 bytes key of the AES-256-GCM encryption, base64 encoded in Key
+func (e EncryptionDetails) AESKey() ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(e.Key)
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("%s requires a base64 encoded 32 bytes key", EncAesGcm)
+	}
+	return key, nil
+}
+
+// RSAPublicKey - Public key of the RSA envelopes, PKIX or PKCS #1, of 2048 bits at least
+func (e EncryptionDetails) RSAPublicKey() (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(e.PublicKey))
+	if block == nil {
+		return nil, fmt.Errorf("%s requires a PEM public key", EncRsaEnvelope)
+	}
+	var key *rsa.PublicKey
+	if parsed, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		rsaKey, ok := parsed.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("%s requires an RSA public key", EncRsaEnvelope)
+		}
+		key = rsaKey
+	} else if key, err = x509.ParsePKCS1PublicKey(block.Bytes); err != nil {
+		return nil, fmt.Errorf("%s public key invalid: %v", EncRsaEnvelope, err)
+	}
+	if key.N.BitLen() < rsaMinBits {
+		return nil, fmt.Errorf("%s requires a key of %d bits at least", EncRsaEnvelope, rsaMinBits)
+	}
+	return key, nil
+}
+
+func (e EncryptionDetails) validate() error {
+	switch e.Algo {
+	case EncNone, EncAes:
+	case EncAesGcm:
+		if _, err := e.AESKey(); err != nil {
+			return err
+		}
+	case EncRsaEnvelope:
+		if _, err := e.RSAPublicKey(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("Encryption invalid: %s", e.Algo)
+	}
+	return nil
 }
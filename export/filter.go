@@ -6,8 +6,51 @@
 
 package export
 
+import (
+	"fmt"
+)
+
 // Filter - Specifies the client filters on reading data
 type Filter struct {
-	DeviceIDs          []string `bson:"deviceIdentifiers,omitempty" json:"deviceIdentifiers,omitempty"`
-	ValueDescriptorIDs []string `bson:"valueDescriptorIdentifiers,omitempty" json:"valueDescriptorIdentifiers,omitempty"`
+	DeviceIDs          []string      `bson:"deviceIdentifiers,omitempty" json:"deviceIdentifiers,omitempty"`
+	ValueDescriptorIDs []string      `bson:"valueDescriptorIdentifiers,omitempty" json:"valueDescriptorIdentifiers,omitempty"`
+	ValueFilters       []ValueFilter `bson:"valueFilters,omitempty" json:"valueFilters,omitempty"`
+}
+
+// ValueFilter - Numeric filter of the readings of a value descriptor. The readings below Min
+// or above Max are dropped, and so are the ones within Deadband of the last value the filter
+// kept for their device. Readings that aren't numbers are kept
+type ValueFilter struct {
+	ValueDescriptor string   `bson:"valueDescriptor" json:"valueDescriptor"`
+	Min             *float64 `bson:"min,omitempty" json:"min,omitempty"`
+	Max             *float64 `bson:"max,omitempty" json:"max,omitempty"`
+	Deadband        float64  `bson:"deadband,omitempty" json:"deadband,omitempty"`
+}
+
+// Empty - whether the filter keeps every event
+func (f Filter) Empty() bool {
+	return len(f.DeviceIDs) == 0 && len(f.ValueDescriptorIDs) == 0 && len(f.ValueFilters) == 0
+}
+
+func (f Filter) validate() error {
+	descriptors := make(map[string]bool)
+	for _, v := range f.ValueFilters {
+		if v.ValueDescriptor == "" {
+			return fmt.Errorf("Value filter requires a value descriptor")
+		}
+		if descriptors[v.ValueDescriptor] {
+			return fmt.Errorf("Value filter not unique: %s", v.ValueDescriptor)
+		}
+		descriptors[v.ValueDescriptor] = true
+		if v.Min == nil && v.Max == nil && v.Deadband == 0 {
+			return fmt.Errorf("Value filter %s requires a min, a max or a deadband", v.ValueDescriptor)
+		}
+		if v.Min != nil && v.Max != nil && *v.Min > *v.Max {
+			return fmt.Errorf("Value filter %s min above max", v.ValueDescriptor)
+		}
+		if v.Deadband < 0 {
+			return fmt.Errorf("Value filter %s deadband invalid: %v", v.ValueDescriptor, v.Deadband)
+		}
+	}
+	return nil
 }
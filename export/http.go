@@ -0,0 +1,50 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package export
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SignatureHeader - header of the HMAC-SHA256 of the payload, sha256=<hex>
+const SignatureHeader = "X-Signature"
+
+// HTTPDetails - Provides the signing and retry policy of a REST endpoint destination
+type HTTPDetails struct {
+	// HMAC secret signing the payloads, unsigned when empty
+	Secret string `json:"secret,omitempty"`
+	// Sent with each payload
+	Headers map[string]string `json:"headers,omitempty"`
+	// Attempts before the payload is dead-lettered, 1 when 0
+	MaxAttempts int `json:"maxAttempts,omitempty"`
+	// Milliseconds before the first retry, doubling up to the max. 1000 and 30000 when 0
+	InitialBackoff int `json:"initialBackoff,omitempty"`
+	MaxBackoff     int `json:"maxBackoff,omitempty"`
+}
+
+func (h HTTPDetails) validate() error {
+	if h.MaxAttempts < 0 {
+		return fmt.Errorf("HTTP max attempts invalid: %d", h.MaxAttempts)
+	}
+	if h.InitialBackoff < 0 || h.MaxBackoff < 0 {
+		return fmt.Errorf("HTTP backoff invalid: %d, %d", h.InitialBackoff, h.MaxBackoff)
+	}
+	if h.InitialBackoff > 0 && h.MaxBackoff > 0 && h.MaxBackoff < h.InitialBackoff {
+		return fmt.Errorf("HTTP max backoff %d shorter than the initial one %d", h.MaxBackoff, h.InitialBackoff)
+	}
+	for name, value := range h.Headers {
+		if name == "" || strings.ContainsAny(name, " \t\r\n:") || strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("HTTP header invalid: %s", name)
+		}
+		if h.Secret != "" && http.CanonicalHeaderKey(name) == SignatureHeader {
+			return fmt.Errorf("HTTP header %s is the signature", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package export
+
+import (
+	"fmt"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+)
+
+// InfluxDBDetails - Provides the database of an InfluxDB destination
+// The address and port of the addressable are those of the server, 8086 when 0, its path the
+// prefix of the API, and its user and password those of a 1.x database
+type InfluxDBDetails struct {
+	// 1.x database and retention policy, the default one when empty
+	Database        string `json:"database,omitempty"`
+	RetentionPolicy string `json:"retentionPolicy,omitempty"`
+	// 2.x organization, bucket and token, instead of the database
+	Organization string `json:"organization,omitempty"`
+	Bucket       string `json:"bucket,omitempty"`
+	Token        string `json:"token,omitempty"`
+	// Tags of every point, besides the device
+	Labels map[string]string `json:"labels,omitempty"`
+	// Points written at once, 1000 when 0
+	BatchSize int `json:"batchSize,omitempty"`
+	// Milliseconds the points wait for a full batch before being written, 1000 when 0
+	FlushInterval int `json:"flushInterval,omitempty"`
+}
+
+func (i InfluxDBDetails) validate(addr models.Addressable) error {
+	if addr.Address == "" {
+		return fmt.Errorf("InfluxDB address is required")
+	}
+	if (i.Database == "") == (i.Bucket == "") {
+		return fmt.Errorf("InfluxDB requires either a database or a bucket")
+	}
+	if i.Bucket != "" && i.Organization == "" {
+		return fmt.Errorf("InfluxDB bucket requires an organization")
+	}
+	if i.BatchSize < 0 {
+		return fmt.Errorf("InfluxDB batch size invalid: %d", i.BatchSize)
+	}
+	if i.FlushInterval < 0 {
+		return fmt.Errorf("InfluxDB flush interval invalid: %d", i.FlushInterval)
+	}
+	for k := range i.Labels {
+		if k == "" || k == "device" {
+			return fmt.Errorf("InfluxDB label invalid: %s", k)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package export
+
+import (
+	"fmt"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+)
+
+// Kafka SASL mechanisms
+const (
+	KafkaSASLNone        = ""
+	KafkaSASLPlain       = "PLAIN"
+	KafkaSASLScramSHA256 = "SCRAM-SHA-256"
+	KafkaSASLScramSHA512 = "SCRAM-SHA-512"
+)
+
+// KafkaDetails - Provides the producer settings of a Kafka destination
+// The topic, user and password are those of the addressable, a TLS/SSL protocol
+// connects with TLS
+type KafkaDetails struct {
+	// host:port of the bootstrap brokers, the address and port of the addressable when empty
+	Brokers []string `json:"brokers,omitempty"`
+	// Partition key, {device} and {id} are replaced by those of the event, "{device}" when empty
+	KeyTemplate   string `json:"keyTemplate,omitempty"`
+	SASLMechanism string `json:"saslMechanism,omitempty"`
+	TLSSkipVerify bool   `json:"tlsSkipVerify,omitempty"`
+	// Write each event once per partition, it waits for all the in sync replicas
+	Idempotent bool `json:"idempotent,omitempty"`
+	// Retries of a failed send, the producer default when 0
+	MaxRetries int `json:"maxRetries,omitempty"`
+}
+
+func (k KafkaDetails) validate(addr models.Addressable) error {
+	if addr.Topic == "" {
+		return fmt.Errorf("Kafka topic is required")
+	}
+	if len(k.Brokers) == 0 && addr.Address == "" {
+		return fmt.Errorf("Kafka brokers are required")
+	}
+	if k.MaxRetries < 0 {
+		return fmt.Errorf("Kafka max retries invalid: %d", k.MaxRetries)
+	}
+
+	switch k.SASLMechanism {
+	case KafkaSASLNone:
+	case KafkaSASLPlain, KafkaSASLScramSHA256, KafkaSASLScramSHA512:
+		if addr.User == "" {
+			return fmt.Errorf("Kafka SASL requires the user of the addressable")
+		}
+	default:
+		return fmt.Errorf("Kafka SASL mechanism invalid: %s", k.SASLMechanism)
+	}
+	return nil
+}
@@ -0,0 +1,298 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: export.proto
+
+package pb
+
+import (
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type Reading struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Pushed               int64    `protobuf:"varint,2,opt,name=pushed,proto3" json:"pushed,omitempty"`
+	Created              int64    `protobuf:"varint,3,opt,name=created,proto3" json:"created,omitempty"`
+	Origin               int64    `protobuf:"varint,4,opt,name=origin,proto3" json:"origin,omitempty"`
+	Modified             int64    `protobuf:"varint,5,opt,name=modified,proto3" json:"modified,omitempty"`
+	Device               string   `protobuf:"bytes,6,opt,name=device,proto3" json:"device,omitempty"`
+	Name                 string   `protobuf:"bytes,7,opt,name=name,proto3" json:"name,omitempty"`
+	Value                string   `protobuf:"bytes,8,opt,name=value,proto3" json:"value,omitempty"`
+	BinaryValue          []byte   `protobuf:"bytes,9,opt,name=binary_value,json=binaryValue,proto3" json:"binary_value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Reading) Reset()         { *m = Reading{} }
+func (m *Reading) String() string { return proto.CompactTextString(m) }
+func (*Reading) ProtoMessage()    {}
+func (*Reading) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3aa074eea61e559c, []int{0}
+}
+
+func (m *Reading) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Reading.Unmarshal(m, b)
+}
+func (m *Reading) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Reading.Marshal(b, m, deterministic)
+}
+func (m *Reading) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Reading.Merge(m, src)
+}
+func (m *Reading) XXX_Size() int {
+	return xxx_messageInfo_Reading.Size(m)
+}
+func (m *Reading) XXX_DiscardUnknown() {
+	xxx_messageInfo_Reading.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Reading proto.InternalMessageInfo
+
+func (m *Reading) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Reading) GetPushed() int64 {
+	if m != nil {
+		return m.Pushed
+	}
+	return 0
+}
+
+func (m *Reading) GetCreated() int64 {
+	if m != nil {
+		return m.Created
+	}
+	return 0
+}
+
+func (m *Reading) GetOrigin() int64 {
+	if m != nil {
+		return m.Origin
+	}
+	return 0
+}
+
+func (m *Reading) GetModified() int64 {
+	if m != nil {
+		return m.Modified
+	}
+	return 0
+}
+
+func (m *Reading) GetDevice() string {
+	if m != nil {
+		return m.Device
+	}
+	return ""
+}
+
+func (m *Reading) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Reading) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+func (m *Reading) GetBinaryValue() []byte {
+	if m != nil {
+		return m.BinaryValue
+	}
+	return nil
+}
+
+type Event struct {
+	Id                   string     `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Pushed               int64      `protobuf:"varint,2,opt,name=pushed,proto3" json:"pushed,omitempty"`
+	Device               string     `protobuf:"bytes,3,opt,name=device,proto3" json:"device,omitempty"`
+	Created              int64      `protobuf:"varint,4,opt,name=created,proto3" json:"created,omitempty"`
+	Modified             int64      `protobuf:"varint,5,opt,name=modified,proto3" json:"modified,omitempty"`
+	Origin               int64      `protobuf:"varint,6,opt,name=origin,proto3" json:"origin,omitempty"`
+	Schedule             string     `protobuf:"bytes,7,opt,name=schedule,proto3" json:"schedule,omitempty"`
+	Event                string     `protobuf:"bytes,8,opt,name=event,proto3" json:"event,omitempty"`
+	Readings             []*Reading `protobuf:"bytes,9,rep,name=readings,proto3" json:"readings,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *Event) Reset()         { *m = Event{} }
+func (m *Event) String() string { return proto.CompactTextString(m) }
+func (*Event) ProtoMessage()    {}
+func (*Event) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3aa074eea61e559c, []int{1}
+}
+
+func (m *Event) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Event.Unmarshal(m, b)
+}
+func (m *Event) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Event.Marshal(b, m, deterministic)
+}
+func (m *Event) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Event.Merge(m, src)
+}
+func (m *Event) XXX_Size() int {
+	return xxx_messageInfo_Event.Size(m)
+}
+func (m *Event) XXX_DiscardUnknown() {
+	xxx_messageInfo_Event.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Event proto.InternalMessageInfo
+
+func (m *Event) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Event) GetPushed() int64 {
+	if m != nil {
+		return m.Pushed
+	}
+	return 0
+}
+
+func (m *Event) GetDevice() string {
+	if m != nil {
+		return m.Device
+	}
+	return ""
+}
+
+func (m *Event) GetCreated() int64 {
+	if m != nil {
+		return m.Created
+	}
+	return 0
+}
+
+func (m *Event) GetModified() int64 {
+	if m != nil {
+		return m.Modified
+	}
+	return 0
+}
+
+func (m *Event) GetOrigin() int64 {
+	if m != nil {
+		return m.Origin
+	}
+	return 0
+}
+
+func (m *Event) GetSchedule() string {
+	if m != nil {
+		return m.Schedule
+	}
+	return ""
+}
+
+func (m *Event) GetEvent() string {
+	if m != nil {
+		return m.Event
+	}
+	return ""
+}
+
+func (m *Event) GetReadings() []*Reading {
+	if m != nil {
+		return m.Readings
+	}
+	return nil
+}
+
+// Batch of events
+type Events struct {
+	Events               []*Event `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Events) Reset()         { *m = Events{} }
+func (m *Events) String() string { return proto.CompactTextString(m) }
+func (*Events) ProtoMessage()    {}
+func (*Events) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3aa074eea61e559c, []int{2}
+}
+
+func (m *Events) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Events.Unmarshal(m, b)
+}
+func (m *Events) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Events.Marshal(b, m, deterministic)
+}
+func (m *Events) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Events.Merge(m, src)
+}
+func (m *Events) XXX_Size() int {
+	return xxx_messageInfo_Events.Size(m)
+}
+func (m *Events) XXX_DiscardUnknown() {
+	xxx_messageInfo_Events.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Events proto.InternalMessageInfo
+
+func (m *Events) GetEvents() []*Event {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Reading)(nil), "export.Reading")
+	proto.RegisterType((*Event)(nil), "export.Event")
+	proto.RegisterType((*Events)(nil), "export.Events")
+}
+
+func init() { proto.RegisterFile("export.proto", fileDescriptor_3aa074eea61e559c) }
+
+var fileDescriptor_3aa074eea61e559c = []byte{
+	// 300 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x92, 0xcf, 0x4a, 0xc3, 0x40,
+	0x10, 0xc6, 0xd9, 0x24, 0xdd, 0xb6, 0xd3, 0xaa, 0xb0, 0x88, 0x2c, 0x9e, 0x62, 0x40, 0x08, 0x08,
+	0x15, 0xf4, 0x0d, 0x04, 0x5f, 0x60, 0x0f, 0x1e, 0xbc, 0x48, 0x92, 0x1d, 0xdb, 0x85, 0x36, 0x09,
+	0x9b, 0x3f, 0xe8, 0xfb, 0xfa, 0x08, 0x3e, 0x80, 0x64, 0x76, 0x13, 0xad, 0x17, 0xbd, 0xe5, 0xf7,
+	0x7d, 0xf9, 0xc8, 0x7c, 0x33, 0x81, 0x35, 0xbe, 0xd5, 0x95, 0x6d, 0x37, 0xb5, 0xad, 0xda, 0x4a,
+	0x70, 0x47, 0xc9, 0x07, 0x83, 0xb9, 0xc2, 0x4c, 0x9b, 0x72, 0x2b, 0x4e, 0x21, 0x30, 0x5a, 0xb2,
+	0x98, 0xa5, 0x4b, 0x15, 0x18, 0x2d, 0x2e, 0x80, 0xd7, 0x5d, 0xb3, 0x43, 0x2d, 0x83, 0x98, 0xa5,
+	0xa1, 0xf2, 0x24, 0x24, 0xcc, 0x0b, 0x8b, 0x59, 0x8b, 0x5a, 0x86, 0x64, 0x8c, 0x38, 0x24, 0x2a,
+	0x6b, 0xb6, 0xa6, 0x94, 0x91, 0x4b, 0x38, 0x12, 0x97, 0xb0, 0x38, 0x54, 0xda, 0xbc, 0x1a, 0xd4,
+	0x72, 0x46, 0xce, 0xc4, 0x43, 0x46, 0x63, 0x6f, 0x0a, 0x94, 0x9c, 0xbe, 0xec, 0x49, 0x08, 0x88,
+	0xca, 0xec, 0x80, 0x72, 0x4e, 0x2a, 0x3d, 0x8b, 0x73, 0x98, 0xf5, 0xd9, 0xbe, 0x43, 0xb9, 0x20,
+	0xd1, 0x81, 0xb8, 0x82, 0x75, 0x6e, 0xca, 0xcc, 0xbe, 0xbf, 0x38, 0x73, 0x19, 0xb3, 0x74, 0xad,
+	0x56, 0x4e, 0x7b, 0x1a, 0xa4, 0xe4, 0x93, 0xc1, 0xec, 0xb1, 0xc7, 0xb2, 0xfd, 0x77, 0xc9, 0xef,
+	0xb1, 0xc2, 0xa3, 0xb1, 0x7e, 0x94, 0x8f, 0x8e, 0xcb, 0xff, 0x51, 0xd2, 0x2f, 0x86, 0xff, 0x5e,
+	0x4c, 0x53, 0xec, 0x50, 0x77, 0xfb, 0xb1, 0xe8, 0xc4, 0x43, 0x59, 0x1c, 0x46, 0x1e, 0xcb, 0x12,
+	0x88, 0x1b, 0x58, 0x58, 0x77, 0xaf, 0x46, 0x2e, 0xe3, 0x30, 0x5d, 0xdd, 0x9d, 0x6d, 0xfc, 0x65,
+	0xfd, 0x1d, 0xd5, 0xf4, 0x42, 0x72, 0x0b, 0x9c, 0x5a, 0x37, 0xe2, 0x1a, 0x38, 0xe5, 0x1b, 0xc9,
+	0x28, 0x74, 0x32, 0x86, 0xc8, 0x57, 0xde, 0x7c, 0x88, 0x9e, 0x83, 0x3a, 0xcf, 0x39, 0xfd, 0x23,
+	0xf7, 0x5f, 0x03, 0x00, 0x76, 0x53, 0x01, 0xf9, 0x33, 0x02, 0x00, 0x00,
+}
@@ -0,0 +1,263 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package export
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Transform stage types
+const (
+	StageFilter    = "FILTER"
+	StageEnrich    = "ENRICH"
+	StageConvert   = "CONVERT_UNITS"
+	StageCompress  = "COMPRESS"
+	StageEncrypt   = "ENCRYPT"
+	StagePlugin    = "PLUGIN"
+	StageScript    = "SCRIPT"
+	StageAggregate = "AGGREGATE"
+)
+
+// Aggregation functions
+const (
+	AggregateAvg   = "AVG"
+	AggregateMin   = "MIN"
+	AggregateMax   = "MAX"
+	AggregateCount = "COUNT"
+)
+
+// Script languages
+const (
+	ScriptLua = "LUA"
+)
+
+// TransformStage - Named stage of the pipeline of a registration
+// The filter, enrich, convert and aggregate stages transform the events, before they're
+// formatted, the script, compress and encrypt stages the formatted payloads. Plugins transform
+// either, depending on the function they export
+type TransformStage struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// FILTER: devices and value descriptors of the readings kept
+	Filter *Filter `json:"filter,omitempty"`
+	// ENRICH: readings added to every event, by name
+	Readings map[string]string `json:"readings,omitempty"`
+	// CONVERT_UNITS: conversions of the numeric readings
+	Conversions []UnitConversion `json:"conversions,omitempty"`
+	// COMPRESS: GZIP, ZIP or ZSTD
+	Compression string `json:"compression,omitempty"`
+	// ENCRYPT: AES key and initialization vector
+	Encryption *EncryptionDetails `json:"encryption,omitempty"`
+	// PLUGIN: Go plugin exporting the transform function
+	Plugin *PluginDetails `json:"plugin,omitempty"`
+	// SCRIPT: script reshaping the JSON payloads
+	Script *ScriptDetails `json:"script,omitempty"`
+	// AGGREGATE: window and functions of the aggregated readings
+	Aggregation *AggregationDetails `json:"aggregation,omitempty"`
+}
+
+// UnitConversion - Converts the values of the readings of a name to value*Scale+Offset, and
+// renames them when Rename is set, e.g. from Celsius to Fahrenheit with 1.8 and 32
+type UnitConversion struct {
+	Reading string  `json:"reading"`
+	Scale   float64 `json:"scale"`
+	Offset  float64 `json:"offset,omitempty"`
+	Rename  string  `json:"rename,omitempty"`
+}
+
+// PluginDetails - Go plugin of a stage, built with -buildmode=plugin against the same EdgeX
+// sources as export distro. Its symbol is a func(*models.Event) *models.Event transforming the
+// events, nil to drop one, or a func([]byte) []byte transforming the payloads
+type PluginDetails struct {
+	// Name of the plugin file in the PluginDir of export distro, not a path
+	File   string `json:"file"`
+	Symbol string `json:"symbol"`
+}
+
+// ScriptDetails - Script of a stage, run by export distro without rebuilding it. The Lua
+// source defines a function transform(payload), called with the JSON payload decoded to a
+// table, which returns the new payload as a string, or a table sent as JSON, or nil to drop it
+type ScriptDetails struct {
+	Language string `json:"language"`
+	Source   string `json:"source"`
+}
+
+// AggregationDetails - Numeric readings of each device and value descriptor aggregated over
+// a window of Window milliseconds. At the end of the window, an event per device replaces the
+// readings with one reading per function, e.g. temperature_avg and temperature_max. All the
+// functions are computed when there are none
+type AggregationDetails struct {
+	Window    int      `json:"window"`
+	Functions []string `json:"functions,omitempty"`
+}
+
+// Whether the stage transforms the formatted payloads
+func (s TransformStage) transformsPayload() bool {
+	return s.Type == StageScript || s.Type == StageCompress || s.Type == StageEncrypt
+}
+
+func (s TransformStage) validate() error {
+	switch s.Type {
+	case StageFilter:
+		if s.Filter == nil || s.Filter.Empty() {
+			return fmt.Errorf("Stage %s requires devices, value descriptors or value filters", s.Name)
+		}
+		if err := s.Filter.validate(); err != nil {
+			return fmt.Errorf("Stage %s: %v", s.Name, err)
+		}
+	case StageEnrich:
+		if len(s.Readings) == 0 {
+			return fmt.Errorf("Stage %s requires readings", s.Name)
+		}
+		for name := range s.Readings {
+			if name == "" {
+				return fmt.Errorf("Stage %s has a reading without name", s.Name)
+			}
+		}
+	case StageConvert:
+		if len(s.Conversions) == 0 {
+			return fmt.Errorf("Stage %s requires conversions", s.Name)
+		}
+		for _, c := range s.Conversions {
+			if c.Reading == "" || c.Scale == 0 {
+				return fmt.Errorf("Stage %s requires the reading and the scale of its conversions", s.Name)
+			}
+		}
+	case StageCompress:
+		if s.Compression != CompGzip && s.Compression != CompZip && s.Compression != CompZstd {
+			return fmt.Errorf("Stage %s compression invalid: %s", s.Name, s.Compression)
+		}
+	case StageEncrypt:
+		if s.Encryption == nil || s.Encryption.Algo == "" || s.Encryption.Algo == EncNone {
+			return fmt.Errorf("Stage %s requires an encryption", s.Name)
+		}
+		if err := s.Encryption.validate(); err != nil {
+			return fmt.Errorf("Stage %s: %v", s.Name, err)
+		}
+	case StagePlugin:
+		if s.Plugin == nil || s.Plugin.File == "" || s.Plugin.Symbol == "" {
+			return fmt.Errorf("Stage %s requires the file and the symbol of the plugin", s.Name)
+		}
+		if !ValidPluginFile(s.Plugin.File) {
+			return fmt.Errorf("Stage %s plugin file invalid, a name in the plugin directory: %s", s.Name, s.Plugin.File)
+		}
+	case StageAggregate:
+		if s.Aggregation == nil || s.Aggregation.Window <= 0 {
+			return fmt.Errorf("Stage %s requires an aggregation window", s.Name)
+		}
+		functions := make(map[string]bool)
+		for _, f := range s.Aggregation.Functions {
+			if f != AggregateAvg && f != AggregateMin && f != AggregateMax && f != AggregateCount {
+				return fmt.Errorf("Stage %s aggregation function invalid: %s", s.Name, f)
+			}
+			if functions[f] {
+				return fmt.Errorf("Stage %s aggregation function not unique: %s", s.Name, f)
+			}
+			functions[f] = true
+		}
+	case StageScript:
+		if s.Script == nil || s.Script.Source == "" {
+			return fmt.Errorf("Stage %s requires a script", s.Name)
+		}
+		if s.Script.Language != ScriptLua {
+			return fmt.Errorf("Stage %s script language invalid: %s", s.Name, s.Script.Language)
+		}
+	default:
+		return fmt.Errorf("Stage %s type invalid: %s", s.Name, s.Type)
+	}
+	return nil
+}
+
+// Validate the stages of the pipeline, which replace the filter, compression and encryption of
+// the registration
+func (reg *Registration) validatePipeline() error {
+	if !reg.Filter.Empty() ||
+		reg.Compression != CompNone ||
+		(reg.Encryption.Algo != "" && reg.Encryption.Algo != EncNone) {
+		return fmt.Errorf("The pipeline replaces the filter, compression and encryption")
+	}
+
+	names := make(map[string]bool)
+	payload := false
+	encoded := false
+	aggregated := false
+	for _, s := range reg.Pipeline {
+		if s.Name == "" {
+			return fmt.Errorf("Stage name is required")
+		}
+		if names[s.Name] {
+			return fmt.Errorf("Stage name not unique: %s", s.Name)
+		}
+		names[s.Name] = true
+		if err := s.validate(); err != nil {
+			return err
+		}
+		if s.transformsPayload() {
+			payload = true
+		} else if payload && s.Type != StagePlugin {
+			return fmt.Errorf("Stage %s transforms the events after the payloads", s.Name)
+		}
+		// The scripts are given the JSON events, not the compressed or encrypted payloads
+		if s.Type == StageScript {
+			if reg.Format != FormatJSON {
+				return fmt.Errorf("Stage %s requires the %s format", s.Name, FormatJSON)
+			}
+			if encoded {
+				return fmt.Errorf("Stage %s runs after the compression or encryption", s.Name)
+			}
+		}
+		if s.Type == StageCompress || s.Type == StageEncrypt {
+			encoded = true
+		}
+		if s.Type == StageAggregate {
+			if aggregated {
+				return fmt.Errorf("Stage %s aggregates the events again", s.Name)
+			}
+			aggregated = true
+		}
+	}
+	return nil
+}
+
+// Stages - Pipeline of the registration, made from its filter, compression and encryption when
+// it has none
+func (reg Registration) Stages() []TransformStage {
+	if len(reg.Pipeline) > 0 {
+		return reg.Pipeline
+	}
+
+	var stages []TransformStage
+	if !reg.Filter.Empty() {
+		filter := reg.Filter
+		stages = append(stages, TransformStage{Name: "filter", Type: StageFilter, Filter: &filter})
+	}
+	if reg.Compression != "" && reg.Compression != CompNone {
+		stages = append(stages, TransformStage{Name: "compress", Type: StageCompress, Compression: reg.Compression})
+	}
+	if reg.Encryption.Algo != "" && reg.Encryption.Algo != EncNone {
+		encryption := reg.Encryption
+		stages = append(stages, TransformStage{Name: "encrypt", Type: StageEncrypt, Encryption: &encryption})
+	}
+	return stages
+}
+
+// Whether the registration transforms its formatted payloads
+func (reg Registration) transformsPayload() bool {
+	for _, s := range reg.Stages() {
+		if s.transformsPayload() {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidPluginFile - whether the plugin file is a name in the plugin directory, without a path
+// leading out of it
+func ValidPluginFile(file string) bool {
+	return file != "" && file != "." && file != ".." && !strings.ContainsAny(file, `/\`)
+}
@@ -0,0 +1,61 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package export
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+)
+
+// PostgresTable - table of the readings when the registration has none
+const PostgresTable = "edgex_readings"
+
+// Table, optionally qualified by its schema, short enough to derive its index name
+var postgresTableName = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]{0,39}\.)?[A-Za-z_][A-Za-z0-9_]{0,39}$`)
+
+// PostgresDetails - Provides the table of a Postgres or TimescaleDB destination
+// The address and port of the addressable are those of the server, 5432 when 0, and its user
+// and password those of the database
+type PostgresDetails struct {
+	Database string `json:"database,omitempty"`
+	// Table of the readings, created when missing. edgex_readings when empty
+	Table string `json:"table,omitempty"`
+	// disable, require, verify-ca or verify-full, require when empty
+	SSLMode string `json:"sslMode,omitempty"`
+	// Make the table a TimescaleDB hypertable partitioned by time
+	Hypertable bool `json:"hypertable,omitempty"`
+	// Readings inserted in a transaction, 1000 when 0
+	BatchSize int `json:"batchSize,omitempty"`
+	// Milliseconds the readings wait for a full batch before being inserted, 1000 when 0
+	FlushInterval int `json:"flushInterval,omitempty"`
+}
+
+func (p PostgresDetails) validate(addr models.Addressable) error {
+	if addr.Address == "" {
+		return fmt.Errorf("Postgres address is required")
+	}
+	if p.Database == "" {
+		return fmt.Errorf("Postgres database is required")
+	}
+	if p.Table != "" && !postgresTableName.MatchString(p.Table) {
+		return fmt.Errorf("Postgres table invalid: %s", p.Table)
+	}
+	switch p.SSLMode {
+	case "", "disable", "require", "verify-ca", "verify-full":
+	default:
+		return fmt.Errorf("Postgres SSL mode invalid: %s", p.SSLMode)
+	}
+	if p.BatchSize < 0 {
+		return fmt.Errorf("Postgres batch size invalid: %d", p.BatchSize)
+	}
+	if p.FlushInterval < 0 {
+		return fmt.Errorf("Postgres flush interval invalid: %d", p.FlushInterval)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package export
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/edgexfoundry/edgex-go/core/domain/models"
+)
+
+// PubSubDetails - Provides the service account of a Google Pub/Sub destination
+// The topic of the addressable is the topic id in the project of the service account, or
+// projects/<project>/topics/<topic>. Its address is the endpoint, pubsub.googleapis.com when
+// empty, a regional endpoint keeping the ordered events in one region
+type PubSubDetails struct {
+	// JSON key of the service account, as downloaded from the console
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+	// Ordering key template, {device} and {id} being replaced by those of the event.
+	// The events aren't ordered when empty
+	OrderingKey string `json:"orderingKey,omitempty"`
+}
+
+// GoogleServiceAccount - Service account of a JSON key
+type GoogleServiceAccount struct {
+	Type         string          `json:"type"`
+	ProjectID    string          `json:"project_id"`
+	PrivateKeyID string          `json:"private_key_id"`
+	PrivateKey   string          `json:"private_key"`
+	ClientEmail  string          `json:"client_email"`
+	Key          *rsa.PrivateKey `json:"-"`
+}
+
+// ParseGoogleServiceAccount - parse the JSON key of a service account
+func ParseGoogleServiceAccount(s string) (GoogleServiceAccount, error) {
+	var account GoogleServiceAccount
+	if err := json.Unmarshal([]byte(s), &account); err != nil {
+		return account, fmt.Errorf("Google service account invalid: %v", err)
+	}
+	if account.Type != "service_account" || account.ClientEmail == "" {
+		return account, fmt.Errorf("Google service account requires type service_account and client_email")
+	}
+
+	block, _ := pem.Decode([]byte(account.PrivateKey))
+	if block == nil {
+		return account, fmt.Errorf("Google service account private key invalid")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return account, fmt.Errorf("Google service account private key invalid: %v", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return account, fmt.Errorf("Google service account private key isn't RSA")
+	}
+	account.Key = rsaKey
+	return account, nil
+}
+
+// PubSubTopic - full name of the topic, in the project of the account unless it has one
+func PubSubTopic(topic string, account GoogleServiceAccount) string {
+	if strings.HasPrefix(topic, "projects/") {
+		return topic
+	}
+	return "projects/" + account.ProjectID + "/topics/" + topic
+}
+
+func (p PubSubDetails) validate(addr models.Addressable) error {
+	account, err := ParseGoogleServiceAccount(p.ServiceAccount)
+	if err != nil {
+		return err
+	}
+	if addr.Topic == "" {
+		return fmt.Errorf("Pub/Sub topic is required")
+	}
+	parts := strings.Split(PubSubTopic(addr.Topic, account), "/")
+	if len(parts) != 4 || parts[0] != "projects" || parts[1] == "" || parts[2] != "topics" || parts[3] == "" {
+		return fmt.Errorf("Pub/Sub topic invalid: %s", addr.Topic)
+	}
+	return nil
+}
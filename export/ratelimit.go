@@ -0,0 +1,41 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package export
+
+import (
+	"fmt"
+)
+
+// RateLimitDetails - Events and bytes a registration sends per second at most. The payloads
+// beyond the limits wait for their turn, and the registration sends as fast as its
+// destination takes them when both limits are 0
+type RateLimitDetails struct {
+	// Events sent per second, a batch counting its events, unlimited when 0
+	Events float64 `json:"events,omitempty"`
+	// Bytes of payloads sent per second, unlimited when 0
+	Bytes int64 `json:"bytes,omitempty"`
+	// Payloads waiting for their turn, the oldest are dropped beyond it, 1000 when 0
+	MaxPending int `json:"maxPending,omitempty"`
+}
+
+// Enabled - whether the registration is rate limited
+func (r RateLimitDetails) Enabled() bool {
+	return r.Events != 0 || r.Bytes != 0
+}
+
+func (r RateLimitDetails) validate() error {
+	if r.Events < 0 {
+		return fmt.Errorf("Rate limit events invalid: %v", r.Events)
+	}
+	if r.Bytes < 0 {
+		return fmt.Errorf("Rate limit bytes invalid: %d", r.Bytes)
+	}
+	if r.MaxPending < 0 {
+		return fmt.Errorf("Rate limit max pending invalid: %d", r.MaxPending)
+	}
+	return nil
+}
@@ -19,6 +19,7 @@ const (
 	CompNone = "NONE"
 	CompGzip = "GZIP"
 	CompZip  = "ZIP"
+	CompZstd = "ZSTD"
 )
 
 // Data format types
@@ -30,6 +31,9 @@ const (
 	FormatAzureJSON       = "AZURE_JSON"
 	FormatCSV             = "CSV"
 	FormatThingsBoardJSON = "THINGSBOARD_JSON"
+	FormatInfluxDBLine    = "INFLUXDB_LINE"
+	FormatProtobuf        = "PROTOBUF"
+	FormatTemplate        = "TEMPLATE"
 )
 
 // Export destination types
@@ -40,6 +44,12 @@ const (
 	DestAzureMQTT   = "AZURE_TOPIC"
 	DestRest        = "REST_ENDPOINT"
 	DestXMPP        = "XMPP_TOPIC"
+	DestKafka       = "KAFKA_TOPIC"
+	DestAMQP        = "AMQP_EXCHANGE"
+	DestAWSIoT      = "AWSIOT_TOPIC"
+	DestPubSub      = "PUBSUB_TOPIC"
+	DestInfluxDB    = "INFLUXDB_WRITE"
+	DestPostgres    = "POSTGRES_TABLE"
 )
 
 // Registration - Defines the registration details
@@ -57,6 +67,20 @@ type Registration struct {
 	Compression string             `json:"compression,omitempty"`
 	Enable      bool               `json:"enable"`
 	Destination string             `json:"destination,omitempty"`
+	Kafka       KafkaDetails       `json:"kafka,omitempty"`
+	AMQP        AMQPDetails        `json:"amqp,omitempty"`
+	Azure       AzureDetails       `json:"azure,omitempty"`
+	AWSIoT      AWSIoTDetails      `json:"awsIoT,omitempty"`
+	PubSub      PubSubDetails      `json:"pubSub,omitempty"`
+	InfluxDB    InfluxDBDetails    `json:"influxDB,omitempty"`
+	Postgres    PostgresDetails    `json:"postgres,omitempty"`
+	HTTP        HTTPDetails        `json:"http,omitempty"`
+	Batch       BatchDetails       `json:"batch,omitempty"`
+	Buffer      BufferDetails      `json:"buffer,omitempty"`
+	RateLimit   RateLimitDetails   `json:"rateLimit,omitempty"`
+	CSV         CSVDetails         `json:"csv,omitempty"`
+	Template    TemplateDetails    `json:"template,omitempty"`
+	Pipeline    []TransformStage   `json:"pipeline,omitempty"`
 }
 
 const (
@@ -70,6 +94,13 @@ type NotifyUpdate struct {
 	Operation string `json:"operation"`
 }
 
+// RegistrationCheck - Result of validating a registration and sending it a test event
+type RegistrationCheck struct {
+	Valid bool   `json:"valid"` // Whether the registration is valid and distro supports it
+	Sent  bool   `json:"sent"`  // Whether the destination accepted the test event
+	Error string `json:"error,omitempty"`
+}
+
 func (reg *Registration) Validate() (bool, error) {
 
 	if reg.Name == "" {
@@ -82,7 +113,8 @@ func (reg *Registration) Validate() (bool, error) {
 
 	if reg.Compression != CompNone &&
 		reg.Compression != CompGzip &&
-		reg.Compression != CompZip {
+		reg.Compression != CompZip &&
+		reg.Compression != CompZstd {
 		return false, fmt.Errorf("Compression invalid: %s", reg.Compression)
 	}
 
@@ -92,7 +124,10 @@ func (reg *Registration) Validate() (bool, error) {
 		reg.Format != FormatIoTCoreJSON &&
 		reg.Format != FormatAzureJSON &&
 		reg.Format != FormatCSV &&
-		reg.Format != FormatThingsBoardJSON {
+		reg.Format != FormatThingsBoardJSON &&
+		reg.Format != FormatInfluxDBLine &&
+		reg.Format != FormatProtobuf &&
+		reg.Format != FormatTemplate {
 		return false, fmt.Errorf("Format invalid: %s", reg.Format)
 	}
 
@@ -100,17 +135,108 @@ func (reg *Registration) Validate() (bool, error) {
 		reg.Destination != DestZMQ &&
 		reg.Destination != DestIotCoreMQTT &&
 		reg.Destination != DestAzureMQTT &&
-		reg.Destination != DestRest {
+		reg.Destination != DestRest &&
+		reg.Destination != DestKafka &&
+		reg.Destination != DestAMQP &&
+		reg.Destination != DestAWSIoT &&
+		reg.Destination != DestPubSub &&
+		reg.Destination != DestInfluxDB &&
+		reg.Destination != DestPostgres {
 		return false, fmt.Errorf("Destination invalid: %s", reg.Destination)
 	}
 
+	if reg.Destination == DestRest {
+		if err := reg.HTTP.validate(); err != nil {
+			return false, err
+		}
+	}
+
+	if reg.Destination == DestKafka {
+		if err := reg.Kafka.validate(reg.Addressable); err != nil {
+			return false, err
+		}
+	}
+
+	if reg.Destination == DestAMQP {
+		if err := reg.AMQP.validate(reg.Addressable); err != nil {
+			return false, err
+		}
+	}
+
+	if reg.Destination == DestAzureMQTT {
+		if err := reg.Azure.validate(); err != nil {
+			return false, err
+		}
+	}
+
+	if reg.Destination == DestAWSIoT {
+		if err := reg.AWSIoT.validate(reg.Addressable); err != nil {
+			return false, err
+		}
+	}
+
+	if reg.Destination == DestPubSub {
+		if err := reg.PubSub.validate(reg.Addressable); err != nil {
+			return false, err
+		}
+	}
+
+	if reg.Destination == DestInfluxDB {
+		// The server parses the points, they can't be compressed or encrypted
+		if reg.Format != FormatInfluxDBLine || reg.transformsPayload() {
+			return false, fmt.Errorf("InfluxDB requires the %s format, without compression nor encryption", FormatInfluxDBLine)
+		}
+		if err := reg.InfluxDB.validate(reg.Addressable); err != nil {
+			return false, err
+		}
+	}
+
+	if reg.Destination == DestPostgres {
+		// The readings are inserted from the event, parsed from JSON without an event
+		if reg.Format != FormatJSON || reg.transformsPayload() {
+			return false, fmt.Errorf("Postgres requires the %s format, without compression nor encryption", FormatJSON)
+		}
+		if err := reg.Postgres.validate(reg.Addressable); err != nil {
+			return false, err
+		}
+	}
+
+	if err := reg.Filter.validate(); err != nil {
+		return false, err
+	}
+
+	if err := reg.Batch.validate(reg.Format, reg.Destination); err != nil {
+		return false, err
+	}
+
+	if err := reg.Buffer.validate(reg.Destination); err != nil {
+		return false, err
+	}
+
+	if err := reg.RateLimit.validate(); err != nil {
+		return false, err
+	}
+
+	if err := reg.CSV.validate(reg.Format); err != nil {
+		return false, err
+	}
+
+	if err := reg.Template.validate(reg.Format); err != nil {
+		return false, err
+	}
+
+	if len(reg.Pipeline) > 0 {
+		if err := reg.validatePipeline(); err != nil {
+			return false, err
+		}
+	}
+
 	if reg.Encryption.Algo == "" {
 		reg.Encryption.Algo = EncNone
 	}
 
-	if reg.Encryption.Algo != EncNone &&
-		reg.Encryption.Algo != EncAes {
-		return false, fmt.Errorf("Encryption invalid: %s", reg.Encryption.Algo)
+	if err := reg.Encryption.validate(); err != nil {
+		return false, err
 	}
 
 	return true, nil
@@ -6,7 +6,20 @@
 
 package export
 
-import "testing"
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
 
 func TestRegistrationValid(t *testing.T) {
 	var tests = []struct {
@@ -20,6 +33,7 @@ func TestRegistrationValid(t *testing.T) {
 	}{
 		{"empty", "", "", "", "", "", false},
 		{"valid", "reg", CompZip, FormatJSON, DestMQTT, EncAes, true},
+		{"zstd", "reg", CompZstd, FormatJSON, DestMQTT, EncAes, true},
 		{"defaultCompression", "reg", "", FormatJSON, DestMQTT, EncAes, true},
 		{"defaultEncryption", "reg", CompZip, FormatJSON, DestMQTT, "", true},
 		{"withoutName", "", CompZip, FormatJSON, DestMQTT, EncAes, false},
@@ -58,3 +72,595 @@ func TestRegistrationValid(t *testing.T) {
 		})
 	}
 }
+
+func TestRegistrationKafka(t *testing.T) {
+	var tests = []struct {
+		name    string
+		address string
+		topic   string
+		user    string
+		details KafkaDetails
+		valid   bool
+	}{
+		{"valid", "localhost", "events", "", KafkaDetails{}, true},
+		{"brokers", "", "events", "", KafkaDetails{Brokers: []string{"kafka:9092"}}, true},
+		{"withoutBrokers", "", "events", "", KafkaDetails{}, false},
+		{"withoutTopic", "localhost", "", "", KafkaDetails{}, false},
+		{"sasl", "localhost", "events", "edgex", KafkaDetails{SASLMechanism: KafkaSASLScramSHA256}, true},
+		{"saslWithoutUser", "localhost", "events", "", KafkaDetails{SASLMechanism: KafkaSASLPlain}, false},
+		{"wrongSasl", "localhost", "events", "edgex", KafkaDetails{SASLMechanism: "GSSAPI"}, false},
+		{"wrongRetries", "localhost", "events", "", KafkaDetails{MaxRetries: -1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Registration{Name: "reg", Format: FormatJSON, Destination: DestKafka, Kafka: tt.details}
+			r.Addressable.Address = tt.address
+			r.Addressable.Topic = tt.topic
+			r.Addressable.User = tt.user
+			if valid, err := r.Validate(); valid != tt.valid {
+				t.Errorf("Validate should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+		})
+	}
+}
+
+func TestRegistrationAMQP(t *testing.T) {
+	var tests = []struct {
+		name    string
+		address string
+		topic   string
+		details AMQPDetails
+		valid   bool
+	}{
+		{"valid", "localhost", "events", AMQPDetails{}, true},
+		{"exchange", "localhost", "", AMQPDetails{Exchange: "edgex", ExchangeType: AMQPExchangeFanout}, true},
+		{"routingKey", "localhost", "", AMQPDetails{RoutingKey: "events.{device}"}, true},
+		{"withoutAddress", "", "events", AMQPDetails{}, false},
+		{"withoutRoutingKey", "localhost", "", AMQPDetails{}, false},
+		{"wrongExchangeType", "localhost", "events", AMQPDetails{Exchange: "edgex", ExchangeType: "INVALID"}, false},
+		{"typeWithoutExchange", "localhost", "events", AMQPDetails{ExchangeType: AMQPExchangeDirect}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Registration{Name: "reg", Format: FormatJSON, Destination: DestAMQP, AMQP: tt.details}
+			r.Addressable.Address = tt.address
+			r.Addressable.Topic = tt.topic
+			if valid, err := r.Validate(); valid != tt.valid {
+				t.Errorf("Validate should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+		})
+	}
+}
+
+func TestRegistrationAzure(t *testing.T) {
+	var tests = []struct {
+		name    string
+		details AzureDetails
+		valid   bool
+	}{
+		{"valid", AzureDetails{ConnectionString: "HostName=hub.azure-devices.net;DeviceId=dev;SharedAccessKey=a2V5"}, true},
+		{"tokenTTL", AzureDetails{ConnectionString: "HostName=hub.azure-devices.net;DeviceId=dev;SharedAccessKey=a2V5", TokenTTL: 600}, true},
+		{"withoutConnectionString", AzureDetails{}, false},
+		{"withoutDevice", AzureDetails{ConnectionString: "HostName=hub.azure-devices.net;SharedAccessKey=a2V5"}, false},
+		{"wrongKey", AzureDetails{ConnectionString: "HostName=hub.azure-devices.net;DeviceId=dev;SharedAccessKey=not base64"}, false},
+		{"wrongPart", AzureDetails{ConnectionString: "HostName=hub.azure-devices.net;DeviceId"}, false},
+		{"wrongTokenTTL", AzureDetails{ConnectionString: "HostName=hub.azure-devices.net;DeviceId=dev;SharedAccessKey=a2V5", TokenTTL: -1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Registration{Name: "reg", Format: FormatJSON, Destination: DestAzureMQTT, Azure: tt.details}
+			if valid, err := r.Validate(); valid != tt.valid {
+				t.Errorf("Validate should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+		})
+	}
+}
+
+// Self signed certificate and key, in PEM
+func testCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating the key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "thing"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Error creating the certificate: %v", err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Error encoding the key: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}))
+}
+
+func TestRegistrationAWSIoT(t *testing.T) {
+	cert, key := testCertificate(t)
+	var tests = []struct {
+		name      string
+		address   string
+		publisher string
+		topic     string
+		details   AWSIoTDetails
+		valid     bool
+	}{
+		{"valid", "endpoint", "thing", "edgex/{device}", AWSIoTDetails{Certificate: cert, PrivateKey: key}, true},
+		{"ca", "endpoint", "thing", "edgex", AWSIoTDetails{Certificate: cert, PrivateKey: key, CACertificate: cert, QoS: 1}, true},
+		{"withoutEndpoint", "", "thing", "edgex", AWSIoTDetails{Certificate: cert, PrivateKey: key}, false},
+		{"withoutClientId", "endpoint", "", "edgex", AWSIoTDetails{Certificate: cert, PrivateKey: key}, false},
+		{"withoutTopic", "endpoint", "thing", "", AWSIoTDetails{Certificate: cert, PrivateKey: key}, false},
+		{"withoutKey", "endpoint", "thing", "edgex", AWSIoTDetails{Certificate: cert}, false},
+		{"wrongCA", "endpoint", "thing", "edgex", AWSIoTDetails{Certificate: cert, PrivateKey: key, CACertificate: "invalid"}, false},
+		{"wrongQoS", "endpoint", "thing", "edgex", AWSIoTDetails{Certificate: cert, PrivateKey: key, QoS: 2}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Registration{Name: "reg", Format: FormatJSON, Destination: DestAWSIoT, AWSIoT: tt.details}
+			r.Addressable.Address = tt.address
+			r.Addressable.Publisher = tt.publisher
+			r.Addressable.Topic = tt.topic
+			if valid, err := r.Validate(); valid != tt.valid {
+				t.Errorf("Validate should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+		})
+	}
+}
+
+// JSON key of a service account, with its fields replaced
+func testServiceAccount(t *testing.T, fields map[string]string) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Error generating the key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Error encoding the key: %v", err)
+	}
+	account := map[string]string{
+		"type":         "service_account",
+		"project_id":   "edgex",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"client_email": "export@edgex.iam.gserviceaccount.com",
+	}
+	for k, v := range fields {
+		account[k] = v
+	}
+	s, _ := json.Marshal(account)
+	return string(s)
+}
+
+func TestRegistrationPubSub(t *testing.T) {
+	valid := testServiceAccount(t, nil)
+	var tests = []struct {
+		name    string
+		topic   string
+		details PubSubDetails
+		valid   bool
+	}{
+		{"valid", "events", PubSubDetails{ServiceAccount: valid}, true},
+		{"orderingKey", "events", PubSubDetails{ServiceAccount: valid, OrderingKey: "{device}"}, true},
+		{"otherProject", "projects/other/topics/events", PubSubDetails{ServiceAccount: valid}, true},
+		{"withoutTopic", "", PubSubDetails{ServiceAccount: valid}, false},
+		{"wrongTopic", "projects/other/events", PubSubDetails{ServiceAccount: valid}, false},
+		{"withoutServiceAccount", "events", PubSubDetails{}, false},
+		{"withoutProject", "events", PubSubDetails{ServiceAccount: testServiceAccount(t, map[string]string{"project_id": ""})}, false},
+		{"wrongType", "events", PubSubDetails{ServiceAccount: testServiceAccount(t, map[string]string{"type": "user"})}, false},
+		{"wrongKey", "events", PubSubDetails{ServiceAccount: testServiceAccount(t, map[string]string{"private_key": "invalid"})}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Registration{Name: "reg", Format: FormatJSON, Destination: DestPubSub, PubSub: tt.details}
+			r.Addressable.Topic = tt.topic
+			if valid, err := r.Validate(); valid != tt.valid {
+				t.Errorf("Validate should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+		})
+	}
+}
+
+func TestRegistrationInfluxDB(t *testing.T) {
+	var tests = []struct {
+		name        string
+		address     string
+		format      string
+		compression string
+		details     InfluxDBDetails
+		valid       bool
+	}{
+		{"database", "influxdb", FormatInfluxDBLine, "", InfluxDBDetails{Database: "edgex"}, true},
+		{"bucket", "influxdb", FormatInfluxDBLine, "", InfluxDBDetails{Organization: "org", Bucket: "edgex", Token: "token"}, true},
+		{"labels", "influxdb", FormatInfluxDBLine, "", InfluxDBDetails{Database: "edgex", Labels: map[string]string{"site": "a"}, BatchSize: 100, FlushInterval: 500}, true},
+		{"withoutAddress", "", FormatInfluxDBLine, "", InfluxDBDetails{Database: "edgex"}, false},
+		{"withoutDatabase", "influxdb", FormatInfluxDBLine, "", InfluxDBDetails{}, false},
+		{"databaseAndBucket", "influxdb", FormatInfluxDBLine, "", InfluxDBDetails{Database: "edgex", Organization: "org", Bucket: "edgex"}, false},
+		{"withoutOrganization", "influxdb", FormatInfluxDBLine, "", InfluxDBDetails{Bucket: "edgex"}, false},
+		{"wrongFormat", "influxdb", FormatJSON, "", InfluxDBDetails{Database: "edgex"}, false},
+		{"compressed", "influxdb", FormatInfluxDBLine, CompGzip, InfluxDBDetails{Database: "edgex"}, false},
+		{"wrongBatchSize", "influxdb", FormatInfluxDBLine, "", InfluxDBDetails{Database: "edgex", BatchSize: -1}, false},
+		{"wrongFlushInterval", "influxdb", FormatInfluxDBLine, "", InfluxDBDetails{Database: "edgex", FlushInterval: -1}, false},
+		{"deviceLabel", "influxdb", FormatInfluxDBLine, "", InfluxDBDetails{Database: "edgex", Labels: map[string]string{"device": "a"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Registration{Name: "reg", Format: tt.format, Compression: tt.compression, Destination: DestInfluxDB, InfluxDB: tt.details}
+			r.Addressable.Address = tt.address
+			if valid, err := r.Validate(); valid != tt.valid {
+				t.Errorf("Validate should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+		})
+	}
+}
+
+func TestRegistrationPostgres(t *testing.T) {
+	var tests = []struct {
+		name    string
+		address string
+		format  string
+		details PostgresDetails
+		valid   bool
+	}{
+		{"valid", "postgres", FormatJSON, PostgresDetails{Database: "edgex"}, true},
+		{"table", "postgres", FormatJSON, PostgresDetails{Database: "edgex", Table: "iot.readings", SSLMode: "verify-full", Hypertable: true}, true},
+		{"withoutAddress", "", FormatJSON, PostgresDetails{Database: "edgex"}, false},
+		{"withoutDatabase", "postgres", FormatJSON, PostgresDetails{}, false},
+		{"wrongTable", "postgres", FormatJSON, PostgresDetails{Database: "edgex", Table: "readings; DROP TABLE x"}, false},
+		{"wrongSSLMode", "postgres", FormatJSON, PostgresDetails{Database: "edgex", SSLMode: "prefer"}, false},
+		{"wrongFormat", "postgres", FormatXML, PostgresDetails{Database: "edgex"}, false},
+		{"wrongBatchSize", "postgres", FormatJSON, PostgresDetails{Database: "edgex", BatchSize: -1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Registration{Name: "reg", Format: tt.format, Destination: DestPostgres, Postgres: tt.details}
+			r.Addressable.Address = tt.address
+			if valid, err := r.Validate(); valid != tt.valid {
+				t.Errorf("Validate should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+		})
+	}
+}
+
+func TestRegistrationHTTP(t *testing.T) {
+	var tests = []struct {
+		name    string
+		details HTTPDetails
+		valid   bool
+	}{
+		{"valid", HTTPDetails{}, true},
+		{"policy", HTTPDetails{Secret: "secret", Headers: map[string]string{"X-Api-Key": "key"}, MaxAttempts: 5, InitialBackoff: 500, MaxBackoff: 10000}, true},
+		{"wrongMaxAttempts", HTTPDetails{MaxAttempts: -1}, false},
+		{"wrongBackoff", HTTPDetails{InitialBackoff: -1}, false},
+		{"maxBackoffShorter", HTTPDetails{InitialBackoff: 1000, MaxBackoff: 500}, false},
+		{"wrongHeader", HTTPDetails{Headers: map[string]string{"X Api": "key"}}, false},
+		{"headerInjection", HTTPDetails{Headers: map[string]string{"X-Api-Key": "key\r\nHost: other"}}, false},
+		{"signatureHeader", HTTPDetails{Secret: "secret", Headers: map[string]string{"x-signature": "forged"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Registration{Name: "reg", Format: FormatJSON, Destination: DestRest, HTTP: tt.details}
+			if valid, err := r.Validate(); valid != tt.valid {
+				t.Errorf("Validate should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+		})
+	}
+}
+
+func TestRegistrationBatch(t *testing.T) {
+	var tests = []struct {
+		name        string
+		format      string
+		destination string
+		details     BatchDetails
+		valid       bool
+	}{
+		{"disabled", FormatJSON, DestRest, BatchDetails{}, true},
+		{"size", FormatJSON, DestRest, BatchDetails{Size: 50}, true},
+		{"interval", FormatXML, DestMQTT, BatchDetails{Interval: 30000}, true},
+		{"csv", FormatCSV, DestRest, BatchDetails{Size: 50, Interval: 30000}, true},
+		{"protobuf", FormatProtobuf, DestRest, BatchDetails{Size: 50}, true},
+		{"thingsBoard", FormatThingsBoardJSON, DestMQTT, BatchDetails{Size: 50}, true},
+		{"wrongSize", FormatJSON, DestRest, BatchDetails{Size: -1}, false},
+		{"wrongInterval", FormatJSON, DestRest, BatchDetails{Interval: -1}, false},
+		{"wrongFormat", FormatSerialized, DestRest, BatchDetails{Size: 50}, false},
+		{"postgres", FormatJSON, DestPostgres, BatchDetails{Size: 50}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Registration{Name: "reg", Format: tt.format, Destination: tt.destination, Batch: tt.details}
+			r.Addressable.Address = "localhost"
+			r.Postgres.Database = "edgex"
+			if valid, err := r.Validate(); valid != tt.valid {
+				t.Errorf("Validate should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+		})
+	}
+}
+
+func TestRegistrationBuffer(t *testing.T) {
+	var tests = []struct {
+		name        string
+		destination string
+		details     BufferDetails
+		valid       bool
+	}{
+		{"disabled", DestKafka, BufferDetails{}, true},
+		{"size", DestMQTT, BufferDetails{MaxSize: 1 << 20}, true},
+		{"age", DestRest, BufferDetails{MaxAge: 3600000, Interval: 5000}, true},
+		{"azure", DestAzureMQTT, BufferDetails{MaxSize: 1 << 20}, true},
+		{"wrongSize", DestMQTT, BufferDetails{MaxSize: -1}, false},
+		{"wrongAge", DestMQTT, BufferDetails{MaxAge: -1}, false},
+		{"wrongInterval", DestMQTT, BufferDetails{MaxSize: 1 << 20, Interval: -1}, false},
+		{"kafka", DestKafka, BufferDetails{MaxSize: 1 << 20}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Registration{Name: "reg", Format: FormatJSON, Destination: tt.destination, Buffer: tt.details}
+			r.Addressable.Address = "localhost"
+			r.Addressable.Topic = "topic"
+			r.Azure.ConnectionString = "HostName=hub.azure-devices.net;DeviceId=dev;SharedAccessKey=a2V5"
+			if valid, err := r.Validate(); valid != tt.valid {
+				t.Errorf("Validate should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+		})
+	}
+}
+
+func TestRegistrationRateLimit(t *testing.T) {
+	var tests = []struct {
+		name    string
+		details RateLimitDetails
+		valid   bool
+	}{
+		{"disabled", RateLimitDetails{}, true},
+		{"events", RateLimitDetails{Events: 0.5}, true},
+		{"bytes", RateLimitDetails{Bytes: 1 << 16, MaxPending: 10}, true},
+		{"wrongEvents", RateLimitDetails{Events: -1}, false},
+		{"wrongBytes", RateLimitDetails{Bytes: -1}, false},
+		{"wrongMaxPending", RateLimitDetails{Events: 10, MaxPending: -1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Registration{Name: "reg", Format: FormatJSON, Destination: DestMQTT, RateLimit: tt.details}
+			if valid, err := r.Validate(); valid != tt.valid {
+				t.Errorf("Validate should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+		})
+	}
+}
+
+func TestRegistrationPipeline(t *testing.T) {
+	filter := TransformStage{Name: "filter", Type: StageFilter, Filter: &Filter{DeviceIDs: []string{"dev"}}}
+	enrich := TransformStage{Name: "enrich", Type: StageEnrich, Readings: map[string]string{"site": "plant-1"}}
+	convert := TransformStage{Name: "convert", Type: StageConvert, Conversions: []UnitConversion{{Reading: "temperature", Scale: 1.8, Offset: 32}}}
+	compress := TransformStage{Name: "compress", Type: StageCompress, Compression: CompZstd}
+	encrypt := TransformStage{Name: "encrypt", Type: StageEncrypt, Encryption: &EncryptionDetails{Algo: EncAes, Key: "key", InitVector: "iv"}}
+	plugin := TransformStage{Name: "plugin", Type: StagePlugin, Plugin: &PluginDetails{File: "sign.so", Symbol: "Sign"}}
+	script := TransformStage{Name: "script", Type: StageScript, Script: &ScriptDetails{Language: ScriptLua, Source: "function transform(e) return e end"}}
+	invalid := func(s TransformStage, set func(*TransformStage)) TransformStage {
+		set(&s)
+		return s
+	}
+
+	var tests = []struct {
+		name        string
+		destination string
+		compression string
+		pipeline    []TransformStage
+		valid       bool
+	}{
+		{"valid", DestRest, "", []TransformStage{filter, enrich, convert, compress, encrypt, plugin}, true},
+		{"eventPlugin", DestRest, "", []TransformStage{plugin, filter, compress}, true},
+		{"script", DestRest, "", []TransformStage{filter, script, compress, plugin}, true},
+		{"scriptLanguage", DestRest, "", []TransformStage{invalid(script, func(s *TransformStage) { s.Script = &ScriptDetails{Language: "JS", Source: "transform"} })}, false},
+		{"withoutScript", DestRest, "", []TransformStage{invalid(script, func(s *TransformStage) { s.Script = nil })}, false},
+		{"scriptAfterCompression", DestRest, "", []TransformStage{compress, script}, false},
+		{"eventsAfterScript", DestRest, "", []TransformStage{script, enrich}, false},
+		{"postgresScript", DestPostgres, "", []TransformStage{script}, false},
+		{"withoutName", DestRest, "", []TransformStage{invalid(filter, func(s *TransformStage) { s.Name = "" })}, false},
+		{"nameNotUnique", DestRest, "", []TransformStage{filter, invalid(enrich, func(s *TransformStage) { s.Name = "filter" })}, false},
+		{"wrongType", DestRest, "", []TransformStage{invalid(filter, func(s *TransformStage) { s.Type = "INVALID" })}, false},
+		{"emptyFilter", DestRest, "", []TransformStage{invalid(filter, func(s *TransformStage) { s.Filter = &Filter{} })}, false},
+		{"emptyEnrich", DestRest, "", []TransformStage{invalid(enrich, func(s *TransformStage) { s.Readings = nil })}, false},
+		{"withoutScale", DestRest, "", []TransformStage{invalid(convert, func(s *TransformStage) { s.Conversions = []UnitConversion{{Reading: "temperature"}} })}, false},
+		{"wrongCompression", DestRest, "", []TransformStage{invalid(compress, func(s *TransformStage) { s.Compression = CompNone })}, false},
+		{"withoutEncryption", DestRest, "", []TransformStage{invalid(encrypt, func(s *TransformStage) { s.Encryption = nil })}, false},
+		{"withoutPlugin", DestRest, "", []TransformStage{invalid(plugin, func(s *TransformStage) { s.Plugin = &PluginDetails{File: "sign.so"} })}, false},
+		{"pluginPath", DestRest, "", []TransformStage{invalid(plugin, func(s *TransformStage) { s.Plugin = &PluginDetails{File: "/plugins/sign.so", Symbol: "Sign"} })}, false},
+		{"pluginParent", DestRest, "", []TransformStage{invalid(plugin, func(s *TransformStage) { s.Plugin = &PluginDetails{File: "..", Symbol: "Sign"} })}, false},
+		{"eventsAfterPayloads", DestRest, "", []TransformStage{compress, enrich}, false},
+		{"aggregate", DestRest, "", []TransformStage{filter, {Name: "aggregate", Type: StageAggregate, Aggregation: &AggregationDetails{Window: 60000, Functions: []string{AggregateAvg, AggregateCount}}}, enrich}, true},
+		{"withoutWindow", DestRest, "", []TransformStage{{Name: "aggregate", Type: StageAggregate, Aggregation: &AggregationDetails{}}}, false},
+		{"wrongFunction", DestRest, "", []TransformStage{{Name: "aggregate", Type: StageAggregate, Aggregation: &AggregationDetails{Window: 1000, Functions: []string{"MEDIAN"}}}}, false},
+		{"aggregatedTwice", DestRest, "", []TransformStage{
+			{Name: "aggregate", Type: StageAggregate, Aggregation: &AggregationDetails{Window: 1000}},
+			{Name: "again", Type: StageAggregate, Aggregation: &AggregationDetails{Window: 60000}},
+		}, false},
+		{"legacyCompression", DestRest, CompGzip, []TransformStage{enrich}, false},
+		{"postgresCompressed", DestPostgres, "", []TransformStage{enrich, compress}, false},
+		{"postgres", DestPostgres, "", []TransformStage{enrich, convert}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Registration{Name: "reg", Format: FormatJSON, Destination: tt.destination, Compression: tt.compression, Pipeline: tt.pipeline}
+			r.Addressable.Address = "localhost"
+			r.Postgres.Database = "edgex"
+			if valid, err := r.Validate(); valid != tt.valid {
+				t.Errorf("Validate should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+		})
+	}
+
+	r := Registration{Name: "reg", Format: FormatXML, Destination: DestRest, Pipeline: []TransformStage{script}}
+	r.Addressable.Address = "localhost"
+	if valid, _ := r.Validate(); valid {
+		t.Error("The scripts should require JSON payloads")
+	}
+}
+
+func TestRegistrationStages(t *testing.T) {
+	r := Registration{Name: "reg", Compression: CompGzip, Encryption: EncryptionDetails{Algo: EncAes}}
+	r.Filter.DeviceIDs = []string{"dev"}
+	stages := r.Stages()
+	if len(stages) != 3 || stages[0].Type != StageFilter || stages[1].Type != StageCompress || stages[2].Type != StageEncrypt {
+		t.Fatalf("The filter, compression and encryption should make the pipeline: %+v", stages)
+	}
+
+	r = Registration{Name: "reg", Compression: CompNone, Encryption: EncryptionDetails{Algo: EncNone}}
+	if stages := r.Stages(); len(stages) != 0 {
+		t.Errorf("The registration should have no stage: %+v", stages)
+	}
+
+	r.Pipeline = []TransformStage{{Name: "enrich", Type: StageEnrich, Readings: map[string]string{"site": "plant-1"}}}
+	if stages := r.Stages(); len(stages) != 1 || stages[0].Name != "enrich" {
+		t.Errorf("The pipeline should be the stages: %+v", stages)
+	}
+}
+
+func TestRegistrationCSV(t *testing.T) {
+	var tests = []struct {
+		name    string
+		format  string
+		columns []string
+		valid   bool
+	}{
+		{"default", FormatCSV, nil, true},
+		{"columns", FormatCSV, []string{CSVColumnOrigin, CSVColumnDevice, CSVColumnValue}, true},
+		{"all", FormatCSV, []string{"id", "device", "name", "value", "created", "modified", "origin", "pushed"}, true},
+		{"unknown", FormatCSV, []string{CSVColumnDevice, "unit"}, false},
+		{"notUnique", FormatCSV, []string{CSVColumnValue, CSVColumnValue}, false},
+		{"json", FormatJSON, []string{CSVColumnValue}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Registration{Name: "reg", Format: tt.format, Destination: DestMQTT, CSV: CSVDetails{Columns: tt.columns}}
+			if valid, err := r.Validate(); valid != tt.valid {
+				t.Errorf("Validate should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+		})
+	}
+}
+
+func TestRegistrationTemplate(t *testing.T) {
+	var tests = []struct {
+		name   string
+		format string
+		text   string
+		batch  BatchDetails
+		valid  bool
+	}{
+		{"valid", FormatTemplate, `{"device":{{json .Device}},"origin":{{.Origin}}}`, BatchDetails{}, true},
+		{"withoutTemplate", FormatTemplate, "", BatchDetails{}, false},
+		{"invalid", FormatTemplate, "{{.Device", BatchDetails{}, false},
+		{"unknownFunction", FormatTemplate, "{{xml .Device}}", BatchDetails{}, false},
+		{"otherFormat", FormatJSON, "{{.Device}}", BatchDetails{}, false},
+		{"batch", FormatTemplate, "{{.Device}}", BatchDetails{Size: 10}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Registration{Name: "reg", Format: tt.format, Destination: DestMQTT, Template: TemplateDetails{Text: tt.text}, Batch: tt.batch}
+			if valid, err := r.Validate(); valid != tt.valid {
+				t.Errorf("Validate should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+		})
+	}
+}
+
+func TestRegistrationValueFilters(t *testing.T) {
+	min, max := 0.0, 100.0
+	var tests = []struct {
+		name    string
+		filters []ValueFilter
+		valid   bool
+	}{
+		{"range", []ValueFilter{{ValueDescriptor: "temperature", Min: &min, Max: &max}}, true},
+		{"min", []ValueFilter{{ValueDescriptor: "temperature", Min: &max}}, true},
+		{"deadband", []ValueFilter{{ValueDescriptor: "temperature", Deadband: 0.5}, {ValueDescriptor: "humidity", Deadband: 2}}, true},
+		{"withoutDescriptor", []ValueFilter{{Deadband: 0.5}}, false},
+		{"notUnique", []ValueFilter{{ValueDescriptor: "temperature", Min: &min}, {ValueDescriptor: "temperature", Deadband: 1}}, false},
+		{"empty", []ValueFilter{{ValueDescriptor: "temperature"}}, false},
+		{"minAboveMax", []ValueFilter{{ValueDescriptor: "temperature", Min: &max, Max: &min}}, false},
+		{"negativeDeadband", []ValueFilter{{ValueDescriptor: "temperature", Deadband: -1}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Registration{Name: "reg", Format: FormatJSON, Destination: DestMQTT, Filter: Filter{ValueFilters: tt.filters}}
+			if valid, err := r.Validate(); valid != tt.valid {
+				t.Errorf("Validate should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+
+			stage := TransformStage{Name: "filter", Type: StageFilter, Filter: &Filter{ValueFilters: tt.filters}}
+			r = Registration{Name: "reg", Format: FormatJSON, Destination: DestMQTT, Pipeline: []TransformStage{stage}}
+			if valid, err := r.Validate(); valid != tt.valid {
+				t.Errorf("Validate of the pipeline should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+		})
+	}
+}
+
+func TestRegistrationEncryption(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Error generating the key: %v", err)
+	}
+	pkixKey, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	pkixPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkixKey}))
+	pkcs1PEM := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)}))
+	smallKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	smallPEM := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&smallKey.PublicKey)}))
+	aesKey := base64.StdEncoding.EncodeToString(make([]byte, 32))
+
+	var tests = []struct {
+		name       string
+		encryption EncryptionDetails
+		valid      bool
+	}{
+		{"none", EncryptionDetails{}, true},
+		{"aes", EncryptionDetails{Algo: EncAes, Key: "key", InitVector: "iv"}, true},
+		{"gcm", EncryptionDetails{Algo: EncAesGcm, Key: aesKey, KeyID: "1"}, true},
+		{"gcmShortKey", EncryptionDetails{Algo: EncAesGcm, Key: base64.StdEncoding.EncodeToString(make([]byte, 16))}, false},
+		{"gcmNotBase64", EncryptionDetails{Algo: EncAesGcm, Key: "not base64"}, false},
+		{"rsaPKIX", EncryptionDetails{Algo: EncRsaEnvelope, PublicKey: pkixPEM, KeyID: "1"}, true},
+		{"rsaPKCS1", EncryptionDetails{Algo: EncRsaEnvelope, PublicKey: pkcs1PEM}, true},
+		{"rsaSmallKey", EncryptionDetails{Algo: EncRsaEnvelope, PublicKey: smallPEM}, false},
+		{"rsaWithoutKey", EncryptionDetails{Algo: EncRsaEnvelope}, false},
+		{"unknown", EncryptionDetails{Algo: "DES"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Registration{Name: "reg", Format: FormatJSON, Destination: DestMQTT, Encryption: tt.encryption}
+			if valid, err := r.Validate(); valid != tt.valid {
+				t.Errorf("Validate should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+			if tt.encryption.Algo == "" {
+				return
+			}
+			encryption := tt.encryption
+			stage := TransformStage{Name: "encrypt", Type: StageEncrypt, Encryption: &encryption}
+			r = Registration{Name: "reg", Format: FormatJSON, Destination: DestMQTT, Pipeline: []TransformStage{stage}}
+			if valid, err := r.Validate(); valid != tt.valid {
+				t.Errorf("Validate of the pipeline should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package export
+
+// RegistrationStats - Delivery counters of a registration since export distro started it
+// The batching destinations count the points or rows they write, the others the payloads,
+// one per event unless the registration batches them
+type RegistrationStats struct {
+	Name        string `json:"name"`
+	Running     bool   `json:"running"` // Whether export distro runs the registration
+	Sent        int64  `json:"sent"`
+	Failed      int64  `json:"failed"`  // Dropped or dead lettered
+	Retried     int64  `json:"retried"` // Failed attempts that were retried
+	LastSuccess int64  `json:"lastSuccess,omitempty"`
+	LastFailure int64  `json:"lastFailure,omitempty"`
+	LastError   string `json:"lastError,omitempty"`
+}
@@ -0,0 +1,49 @@
+//
+// Copyright (c) 2018 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/template"
+)
+
+// TemplateDetails - Go text/template of the TEMPLATE format, executed with each event, a
+// models.Event, to make its payload
+type TemplateDetails struct {
+	Text string `json:"text,omitempty"`
+}
+
+// TemplateFuncs - Functions of the templates, besides the text/template ones
+// json encodes a value, e.g. {{json .Device}} for a quoted and escaped string
+var TemplateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// Parse - Template of the registration
+func (t TemplateDetails) Parse(name string) (*template.Template, error) {
+	return template.New(name).Funcs(TemplateFuncs).Parse(t.Text)
+}
+
+func (t TemplateDetails) validate(format string) error {
+	if format != FormatTemplate {
+		if t.Text != "" {
+			return fmt.Errorf("Template requires the %s format", FormatTemplate)
+		}
+		return nil
+	}
+	if t.Text == "" {
+		return fmt.Errorf("Template is required")
+	}
+	if _, err := t.Parse("template"); err != nil {
+		return fmt.Errorf("Template invalid: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,352 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+// Package cbor encodes and decodes the generic values of RFC 7049 CBOR documents
+// Values are nil, bool, int64, uint64 (above the int64 range), float64, string, []byte,
+// []interface{} and map[string]interface{}, like encoding/json decodes into interface{}
+package cbor
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Media type of CBOR documents
+const ContentType = "application/cbor"
+
+// Nesting allowed when decoding, so a hostile document can't exhaust the stack
+const maxDepth = 64
+
+var ErrTruncated = errors.New("cbor: unexpected end of data")
+var ErrTrailingData = errors.New("cbor: data after the end of the document")
+var ErrTooDeep = errors.New("cbor: document nested too deeply")
+
+const (
+	majorUint   = 0
+	majorNegInt = 1
+	majorBytes  = 2
+	majorText   = 3
+	majorArray  = 4
+	majorMap    = 5
+	majorTag    = 6
+	majorSimple = 7
+
+	simpleFalse = 20
+	simpleTrue  = 21
+	simpleNull  = 22
+	simpleUndef = 23
+
+	indefinite = 31
+	breakByte  = 0xff
+)
+
+// Encode the value, the map keys are sorted so the same value always gives the same bytes
+func Marshal(v interface{}) ([]byte, error) {
+	return appendValue(nil, v)
+}
+
+func appendValue(buf []byte, v interface{}) ([]byte, error) {
+	var err error
+	switch v := v.(type) {
+	case nil:
+		return append(buf, majorSimple<<5|simpleNull), nil
+	case bool:
+		if v {
+			return append(buf, majorSimple<<5|simpleTrue), nil
+		}
+		return append(buf, majorSimple<<5|simpleFalse), nil
+	case int:
+		return appendInt(buf, int64(v)), nil
+	case int64:
+		return appendInt(buf, v), nil
+	case uint64:
+		return appendHead(buf, majorUint, v), nil
+	case float64:
+		buf = append(buf, majorSimple<<5|27)
+		return appendUint64(buf, math.Float64bits(v)), nil
+	case string:
+		buf = appendHead(buf, majorText, uint64(len(v)))
+		return append(buf, v...), nil
+	case []byte:
+		buf = appendHead(buf, majorBytes, uint64(len(v)))
+		return append(buf, v...), nil
+	case []interface{}:
+		buf = appendHead(buf, majorArray, uint64(len(v)))
+		for _, item := range v {
+			if buf, err = appendValue(buf, item); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		buf = appendHead(buf, majorMap, uint64(len(v)))
+		for _, k := range keys {
+			buf = appendHead(buf, majorText, uint64(len(k)))
+			buf = append(buf, k...)
+			if buf, err = appendValue(buf, v[k]); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	default:
+		return nil, fmt.Errorf("cbor: unsupported type %T", v)
+	}
+}
+
+func appendInt(buf []byte, v int64) []byte {
+	if v < 0 {
+		return appendHead(buf, majorNegInt, uint64(-1-v))
+	}
+	return appendHead(buf, majorUint, uint64(v))
+}
+
+// Append the initial byte of a data item with its argument in the shortest form
+func appendHead(buf []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(buf, major<<5|byte(n))
+	case n <= math.MaxUint8:
+		return append(buf, major<<5|24, byte(n))
+	case n <= math.MaxUint16:
+		buf = append(buf, major<<5|25, 0, 0)
+		binary.BigEndian.PutUint16(buf[len(buf)-2:], uint16(n))
+		return buf
+	case n <= math.MaxUint32:
+		buf = append(buf, major<<5|26, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(buf[len(buf)-4:], uint32(n))
+		return buf
+	default:
+		return appendUint64(append(buf, major<<5|27), n)
+	}
+}
+
+func appendUint64(buf []byte, n uint64) []byte {
+	buf = append(buf, 0, 0, 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint64(buf[len(buf)-8:], n)
+	return buf
+}
+
+// Decode a single document, the indefinite length items and the tags are accepted, the tags are dropped
+func Unmarshal(data []byte) (interface{}, error) {
+	d := decoder{data: data}
+	v, err := d.value(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, ErrTrailingData
+	}
+	return v, nil
+}
+
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *decoder) value(depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, ErrTooDeep
+	}
+	major, info, n, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case majorUint:
+		if n > math.MaxInt64 {
+			return n, nil
+		}
+		return int64(n), nil
+	case majorNegInt:
+		if n > math.MaxInt64 {
+			return nil, fmt.Errorf("cbor: negative integer -1-%d overflows int64", n)
+		}
+		return -1 - int64(n), nil
+	case majorBytes, majorText:
+		b, err := d.str(major, info, n)
+		if err != nil {
+			return nil, err
+		}
+		if major == majorText {
+			return string(b), nil
+		}
+		return b, nil
+	case majorArray:
+		items := []interface{}{}
+		for i := uint64(0); info == indefinite || i < n; i++ {
+			if info == indefinite && d.atBreak() {
+				break
+			}
+			item, err := d.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case majorMap:
+		m := map[string]interface{}{}
+		for i := uint64(0); info == indefinite || i < n; i++ {
+			if info == indefinite && d.atBreak() {
+				break
+			}
+			k, err := d.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("cbor: map key of type %T, only text keys are supported", k)
+			}
+			if m[key], err = d.value(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	case majorTag:
+		return d.value(depth + 1)
+	default:
+		return d.simple(info, n)
+	}
+}
+
+// Read the initial byte and the argument of a data item
+// n is the argument, the length of the strings and containers or 0 when they're indefinite
+func (d *decoder) head() (byte, byte, uint64, error) {
+	if d.pos >= len(d.data) {
+		return 0, 0, 0, ErrTruncated
+	}
+	b := d.data[d.pos]
+	d.pos++
+	major, info := b>>5, b&0x1f
+
+	var size int
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	case info == indefinite && major >= majorBytes && major <= majorMap:
+		return major, info, 0, nil
+	default:
+		return 0, 0, 0, fmt.Errorf("cbor: invalid additional information %d for major type %d", info, major)
+	}
+
+	if len(d.data)-d.pos < size {
+		return 0, 0, 0, ErrTruncated
+	}
+	var n uint64
+	for _, c := range d.data[d.pos : d.pos+size] {
+		n = n<<8 | uint64(c)
+	}
+	d.pos += size
+	return major, info, n, nil
+}
+
+// Read a byte or text string, the chunks of an indefinite string are joined
+func (d *decoder) str(major, info byte, n uint64) ([]byte, error) {
+	if info != indefinite {
+		if n > uint64(len(d.data)-d.pos) {
+			return nil, ErrTruncated
+		}
+		b := append([]byte{}, d.data[d.pos:d.pos+int(n)]...)
+		d.pos += int(n)
+		return b, nil
+	}
+
+	b := []byte{}
+	for !d.atBreak() {
+		chunkMajor, chunkInfo, chunkLen, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if chunkMajor != major || chunkInfo == indefinite {
+			return nil, errors.New("cbor: invalid chunk in an indefinite length string")
+		}
+		chunk, err := d.str(major, chunkInfo, chunkLen)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, chunk...)
+	}
+	return b, nil
+}
+
+// Consume the break ending an indefinite length item if it's next
+func (d *decoder) atBreak() bool {
+	if d.pos < len(d.data) && d.data[d.pos] == breakByte {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+func (d *decoder) simple(info byte, n uint64) (interface{}, error) {
+	switch info {
+	case simpleFalse:
+		return false, nil
+	case simpleTrue:
+		return true, nil
+	case simpleNull, simpleUndef:
+		return nil, nil
+	case 25:
+		return float16(uint16(n)), nil
+	case 26:
+		return float64(math.Float32frombits(uint32(n))), nil
+	case 27:
+		return math.Float64frombits(n), nil
+	default:
+		return nil, fmt.Errorf("cbor: unsupported simple value %d", n)
+	}
+}
+
+// Convert a half precision float
+func float16(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		f = -f
+	}
+	return f
+}
@@ -0,0 +1,146 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package cbor
+
+import (
+	"encoding/hex"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// Examples of RFC 7049 appendix A
+var rfcExamples = []struct {
+	hex   string
+	value interface{}
+}{
+	{"00", int64(0)},
+	{"17", int64(23)},
+	{"1818", int64(24)},
+	{"1903e8", int64(1000)},
+	{"1a000f4240", int64(1000000)},
+	{"1b000000e8d4a51000", int64(1000000000000)},
+	{"1bffffffffffffffff", uint64(math.MaxUint64)},
+	{"20", int64(-1)},
+	{"3903e7", int64(-1000)},
+	{"fb3ff199999999999a", 1.1},
+	{"f4", false},
+	{"f5", true},
+	{"f6", nil},
+	{"40", []byte{}},
+	{"4401020304", []byte{1, 2, 3, 4}},
+	{"60", ""},
+	{"6449455446", "IETF"},
+	{"62c3bc", "ü"},
+	{"80", []interface{}{}},
+	{"83010203", []interface{}{int64(1), int64(2), int64(3)}},
+	{"a0", map[string]interface{}{}},
+	{"a26161016162820203", map[string]interface{}{"a": int64(1), "b": []interface{}{int64(2), int64(3)}}},
+}
+
+func TestMarshalRFCExamples(t *testing.T) {
+	for _, ex := range rfcExamples {
+		data, err := Marshal(ex.value)
+		if err != nil {
+			t.Errorf("Error encoding %v: %v", ex.value, err)
+			continue
+		}
+		if hex.EncodeToString(data) != ex.hex {
+			t.Errorf("%v should encode to %s, not %x", ex.value, ex.hex, data)
+		}
+	}
+}
+
+func TestUnmarshalRFCExamples(t *testing.T) {
+	for _, ex := range rfcExamples {
+		data, _ := hex.DecodeString(ex.hex)
+		v, err := Unmarshal(data)
+		if err != nil {
+			t.Errorf("Error decoding %s: %v", ex.hex, err)
+			continue
+		}
+		if !reflect.DeepEqual(v, ex.value) {
+			t.Errorf("%s should decode to %#v, not %#v", ex.hex, ex.value, v)
+		}
+	}
+}
+
+func TestUnmarshalOtherEncodings(t *testing.T) {
+	tests := []struct {
+		hex   string
+		value interface{}
+	}{
+		// Half and single precision floats
+		{"f93c00", 1.0},
+		{"f9c400", -4.0},
+		{"fa47c35000", 100000.0},
+		// Indefinite length items
+		{"5f42010243030405ff", []byte{1, 2, 3, 4, 5}},
+		{"7f657374726561646d696e67ff", "streaming"},
+		{"9f018202039f0405ffff", []interface{}{int64(1), []interface{}{int64(2), int64(3)}, []interface{}{int64(4), int64(5)}}},
+		{"bf61610161629f0203ffff", map[string]interface{}{"a": int64(1), "b": []interface{}{int64(2), int64(3)}}},
+		// A tag is dropped, here an epoch date
+		{"c11a514b67b0", int64(1363896240)},
+	}
+	for _, tt := range tests {
+		data, _ := hex.DecodeString(tt.hex)
+		v, err := Unmarshal(data)
+		if err != nil {
+			t.Errorf("Error decoding %s: %v", tt.hex, err)
+			continue
+		}
+		if !reflect.DeepEqual(v, tt.value) {
+			t.Errorf("%s should decode to %#v, not %#v", tt.hex, tt.value, v)
+		}
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	tests := []struct {
+		hex string
+		err error
+	}{
+		{"", ErrTruncated},
+		{"1903", ErrTruncated},
+		{"6449", ErrTruncated},
+		{"8301", ErrTruncated},
+		{"0000", ErrTrailingData},
+	}
+	for _, tt := range tests {
+		data, _ := hex.DecodeString(tt.hex)
+		if _, err := Unmarshal(data); err != tt.err {
+			t.Errorf("%q should return %v, not %v", tt.hex, tt.err, err)
+		}
+	}
+
+	// Not a text key
+	if _, err := Unmarshal([]byte{0xa1, 0x01, 0x02}); err == nil {
+		t.Errorf("A map with an integer key should be refused")
+	}
+
+	deep := make([]byte, maxDepth+2)
+	for i := range deep {
+		deep[i] = 0x81
+	}
+	if _, err := Unmarshal(append(deep, 0x00)); err != ErrTooDeep {
+		t.Errorf("A document nested too deeply should return ErrTooDeep, not %v", err)
+	}
+}
+
+func TestMarshalUnsupported(t *testing.T) {
+	if _, err := Marshal(struct{}{}); err == nil {
+		t.Fatalf("A struct should be refused")
+	}
+}
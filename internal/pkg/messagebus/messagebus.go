@@ -0,0 +1,76 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+// Package messagebus moves the messages between the services independently of the transport
+// The publishers and subscribers are created from a Configuration whose Type picks the transport
+package messagebus
+
+import "errors"
+
+// Types of message bus
+const (
+	NATS      = "nats"      // Core NATS, at most once, messages published while nobody subscribes are lost
+	JetStream = "jetstream" // NATS JetStream, the messages are kept until acknowledged, at least once
+)
+
+var ErrUnsupportedType = errors.New("messagebus: unsupported message bus type")
+
+// Configuration of the message bus clients
+type Configuration struct {
+	Type     string
+	URL      string
+	Subject  string
+	Username string
+	Password string
+	Stream   string // JetStream stream keeping the messages of the subject
+	Durable  string // JetStream consumer, its position survives the restarts of the subscriber
+	MaxAge   int    // Milliseconds the stream keeps the messages, 0 - forever
+	AckWait  int    // Milliseconds before a message that wasn't acknowledged is delivered again
+	Timeout  int    // Milliseconds to connect and to publish
+}
+
+// Send the messages to the subject
+type Publisher interface {
+	Publish(data []byte) error
+	Close()
+}
+
+// Process a received message
+// With JetStream the message is delivered again when an error is returned
+type Handler func(data []byte) error
+
+// Receive the messages of the subject
+type Subscriber interface {
+	// Start handing the messages to the handler, from their own goroutine
+	Subscribe(handler Handler) error
+	Close()
+}
+
+func NewPublisher(config Configuration) (Publisher, error) {
+	switch config.Type {
+	case NATS, JetStream:
+		return newNATSPublisher(config)
+	default:
+		return nil, ErrUnsupportedType
+	}
+}
+
+func NewSubscriber(config Configuration) (Subscriber, error) {
+	switch config.Type {
+	case NATS, JetStream:
+		return newNATSSubscriber(config)
+	default:
+		return nil, ErrUnsupportedType
+	}
+}
@@ -0,0 +1,196 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package messagebus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Connect to the NATS server, the connection is retried in the background so the services start
+// while the server is down
+func connectNATS(config Configuration) (*nats.Conn, error) {
+	opts := []nats.Option{
+		nats.Timeout(time.Duration(config.Timeout) * time.Millisecond),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+	}
+	if config.Username != "" {
+		opts = append(opts, nats.UserInfo(config.Username, config.Password))
+	}
+	conn, err := nats.Connect(config.URL, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to NATS: %v", err)
+	}
+	return conn, nil
+}
+
+// The JetStream stream of the subject, both sides create it so they can start in any order
+type natsStream struct {
+	js      jetstream.JetStream
+	config  Configuration
+	created bool
+	mux     sync.Mutex
+}
+
+func newNATSStream(conn *nats.Conn, config Configuration) (*natsStream, error) {
+	if config.Stream == "" {
+		return nil, fmt.Errorf("the JetStream stream is missing")
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		return nil, err
+	}
+	return &natsStream{js: js, config: config}, nil
+}
+
+func (s *natsStream) create(ctx context.Context) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.created {
+		return nil
+	}
+	_, err := s.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     s.config.Stream,
+		Subjects: []string{s.config.Subject},
+		Storage:  jetstream.FileStorage,
+		MaxAge:   time.Duration(s.config.MaxAge) * time.Millisecond,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating the JetStream stream %s: %v", s.config.Stream, err)
+	}
+	s.created = true
+	return nil
+}
+
+// NATS implementation of the publisher
+// With JetStream a message is only published once the server stored it
+type natsPublisher struct {
+	conn    *nats.Conn
+	stream  *natsStream // nil - core NATS
+	subject string
+	timeout time.Duration
+}
+
+func newNATSPublisher(config Configuration) (*natsPublisher, error) {
+	conn, err := connectNATS(config)
+	if err != nil {
+		return nil, err
+	}
+	p := &natsPublisher{conn: conn, subject: config.Subject, timeout: time.Duration(config.Timeout) * time.Millisecond}
+	if config.Type == JetStream {
+		if p.stream, err = newNATSStream(conn, config); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func (p *natsPublisher) Publish(data []byte) error {
+	if p.stream == nil {
+		return p.conn.Publish(p.subject, data)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+	if err := p.stream.create(ctx); err != nil {
+		return err
+	}
+	_, err := p.stream.js.Publish(ctx, p.subject, data)
+	return err
+}
+
+func (p *natsPublisher) Close() {
+	p.conn.Close()
+}
+
+// NATS implementation of the subscriber
+// With JetStream the messages are read through a durable consumer and acknowledged once handled
+type natsSubscriber struct {
+	conn    *nats.Conn
+	stream  *natsStream // nil - core NATS
+	config  Configuration
+	sub     *nats.Subscription
+	consume jetstream.ConsumeContext
+}
+
+func newNATSSubscriber(config Configuration) (*natsSubscriber, error) {
+	conn, err := connectNATS(config)
+	if err != nil {
+		return nil, err
+	}
+	s := &natsSubscriber{conn: conn, config: config}
+	if config.Type == JetStream {
+		if config.Durable == "" {
+			conn.Close()
+			return nil, fmt.Errorf("the JetStream durable consumer is missing")
+		}
+		if s.stream, err = newNATSStream(conn, config); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *natsSubscriber) Subscribe(handler Handler) error {
+	if s.stream == nil {
+		// Nothing redelivers the message, the error is the handler's to report
+		sub, err := s.conn.Subscribe(s.config.Subject, func(m *nats.Msg) { handler(m.Data) })
+		if err != nil {
+			return err
+		}
+		s.sub = sub
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.config.Timeout)*time.Millisecond)
+	defer cancel()
+	if err := s.stream.create(ctx); err != nil {
+		return err
+	}
+	consumer, err := s.stream.js.CreateOrUpdateConsumer(ctx, s.config.Stream, jetstream.ConsumerConfig{
+		Durable:       s.config.Durable,
+		FilterSubject: s.config.Subject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       time.Duration(s.config.AckWait) * time.Millisecond,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating the JetStream consumer %s: %v", s.config.Durable, err)
+	}
+	s.consume, err = consumer.Consume(func(m jetstream.Msg) {
+		if err := handler(m.Data()); err != nil {
+			m.Nak()
+			return
+		}
+		m.Ack()
+	})
+	return err
+}
+
+func (s *natsSubscriber) Close() {
+	if s.consume != nil {
+		s.consume.Stop()
+	}
+	if s.sub != nil {
+		s.sub.Unsubscribe()
+	}
+	s.conn.Close()
+}
@@ -0,0 +1,150 @@
+/*******************************************************************************
+ * Copyright 2018 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package messagebus
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+)
+
+// Run an embedded NATS server with JetStream and return the configuration to use it
+func startTestServer(t *testing.T, busType string) (Configuration, func()) {
+	dir, err := ioutil.TempDir("", "messagebus")
+	if err != nil {
+		t.Fatalf("Error creating the JetStream directory: %v", err)
+	}
+	s, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, JetStream: true, StoreDir: dir, NoSigs: true})
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("Error creating the NATS server: %v", err)
+	}
+	go s.Start()
+	if !s.ReadyForConnections(5 * time.Second) {
+		s.Shutdown()
+		os.RemoveAll(dir)
+		t.Fatalf("The NATS server didn't start")
+	}
+
+	config := Configuration{
+		Type:    busType,
+		URL:     s.ClientURL(),
+		Subject: "edgex.events",
+		Stream:  "EDGEX_EVENTS",
+		Durable: "export-distro",
+		AckWait: 1000,
+		Timeout: 5000,
+	}
+	return config, func() {
+		s.Shutdown()
+		os.RemoveAll(dir)
+	}
+}
+
+func receive(t *testing.T, received chan string) string {
+	select {
+	case data := <-received:
+		return data
+	case <-time.After(5 * time.Second):
+		t.Fatalf("No message received")
+		return ""
+	}
+}
+
+func TestNATS(t *testing.T) {
+	config, stop := startTestServer(t, NATS)
+	defer stop()
+
+	s, err := NewSubscriber(config)
+	if err != nil {
+		t.Fatalf("Error creating the subscriber: %v", err)
+	}
+	defer s.Close()
+	received := make(chan string, 1)
+	if err = s.Subscribe(func(data []byte) error {
+		received <- string(data)
+		return nil
+	}); err != nil {
+		t.Fatalf("Error subscribing: %v", err)
+	}
+
+	p, err := NewPublisher(config)
+	if err != nil {
+		t.Fatalf("Error creating the publisher: %v", err)
+	}
+	defer p.Close()
+	if err = p.Publish([]byte("event")); err != nil {
+		t.Fatalf("Error publishing: %v", err)
+	}
+	if data := receive(t, received); data != "event" {
+		t.Fatalf("The published message should be received, not %s", data)
+	}
+}
+
+func TestJetStreamAtLeastOnce(t *testing.T) {
+	config, stop := startTestServer(t, JetStream)
+	defer stop()
+
+	// The messages published while no subscriber runs are kept
+	p, err := NewPublisher(config)
+	if err != nil {
+		t.Fatalf("Error creating the publisher: %v", err)
+	}
+	defer p.Close()
+	for _, m := range []string{"first", "second"} {
+		if err = p.Publish([]byte(m)); err != nil {
+			t.Fatalf("Error publishing: %v", err)
+		}
+	}
+
+	s, err := NewSubscriber(config)
+	if err != nil {
+		t.Fatalf("Error creating the subscriber: %v", err)
+	}
+	defer s.Close()
+	received := make(chan string, 10)
+	failed := false
+	if err = s.Subscribe(func(data []byte) error {
+		received <- string(data)
+		// The first message fails once and is delivered again
+		if string(data) == "first" && !failed {
+			failed = true
+			return errors.New("handler failure")
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("Error subscribing: %v", err)
+	}
+
+	counts := map[string]int{}
+	for i := 0; i < 3; i++ {
+		counts[receive(t, received)]++
+	}
+	if counts["first"] != 2 || counts["second"] != 1 {
+		t.Fatalf("The failed message should be delivered again: %v", counts)
+	}
+}
+
+func TestUnsupportedType(t *testing.T) {
+	if _, err := NewPublisher(Configuration{Type: "kafka"}); err != ErrUnsupportedType {
+		t.Fatalf("An unknown type should be unsupported, not %v", err)
+	}
+	if _, err := NewSubscriber(Configuration{Type: "kafka"}); err != ErrUnsupportedType {
+		t.Fatalf("An unknown type should be unsupported, not %v", err)
+	}
+}